@@ -86,7 +86,7 @@ func main() {
 	apiGroup.Use(rateLimiter.RateLimit())
 
 	// Initialize and mount all functional routes
-	routes.SetupRoutes(router, apiGroup, db, cfg)
+	routes.SetupRoutes(router, apiGroup, routes.NewDatabaseRepositories(db), cfg)
 
 	// Start server
 	srv := &http.Server{
@@ -0,0 +1,88 @@
+// Command matchgen generates a synthetic population (users, survey answers
+// and crush lists) for load testing and for evaluating matching with matchsim.
+// The same seed and options always produce the same population.
+//
+//	matchgen -users 500 -seed 42 -out population.json
+//	matchgen -options options.json -campaign <id> -db   (needs DATABASE_URL)
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"log"
+	"os"
+
+	"wizard-connect/internal/infrastructure/database"
+	"wizard-connect/internal/simulation"
+)
+
+func main() {
+	opts := simulation.DefaultPopulationOptions()
+
+	optionsPath := flag.String("options", "", "JSON file with population options (distributions, rates)")
+	users := flag.Int("users", 0, "number of users (overrides -options)")
+	seed := flag.Int64("seed", 0, "random seed (overrides -options)")
+	campaignID := flag.String("campaign", "", "campaign the surveys belong to (overrides -options)")
+	mutualRate := flag.Float64("mutual-rate", -1, "chance a crush is returned (overrides -options)")
+	out := flag.String("out", "", "write the population to this JSON fixture")
+	toDB := flag.Bool("db", false, "insert the population into the database at DATABASE_URL")
+	flag.Parse()
+
+	if *optionsPath != "" {
+		data, err := os.ReadFile(*optionsPath)
+		if err != nil {
+			log.Fatalf("Failed to read options: %v", err)
+		}
+		if err := json.Unmarshal(data, &opts); err != nil {
+			log.Fatalf("Failed to parse options: %v", err)
+		}
+	}
+	if *users > 0 {
+		opts.Users = *users
+	}
+	if *seed != 0 {
+		opts.Seed = *seed
+	}
+	if *campaignID != "" {
+		opts.CampaignID = *campaignID
+	}
+	if *mutualRate >= 0 {
+		opts.MutualCrushRate = *mutualRate
+	}
+
+	if *out == "" && !*toDB {
+		log.Fatal("Nothing to do: pass -out and/or -db")
+	}
+
+	snapshot, err := simulation.GeneratePopulation(opts)
+	if err != nil {
+		log.Fatalf("Failed to generate population: %v", err)
+	}
+	log.Printf("Generated %d users, %d surveys, %d crushes (seed %d)",
+		len(snapshot.Users), len(snapshot.Surveys), len(snapshot.Crushes), opts.Seed)
+
+	if *out != "" {
+		if err := snapshot.WriteFile(*out); err != nil {
+			log.Fatalf("Failed to write fixture: %v", err)
+		}
+		log.Printf("Population written to %s", *out)
+	}
+
+	if *toDB {
+		dbURL := os.Getenv("DATABASE_URL")
+		if dbURL == "" {
+			log.Fatal("DATABASE_URL is required with -db")
+		}
+		db, err := database.NewDatabase(dbURL)
+		if err != nil {
+			log.Fatalf("Failed to connect to database: %v", err)
+		}
+		defer db.Close()
+
+		if err := simulation.WriteSnapshotToDB(context.Background(), db, snapshot); err != nil {
+			log.Fatalf("Failed to write population: %v", err)
+		}
+		log.Printf("Population written to the database (emails @%s)", opts.EmailDomain)
+	}
+}
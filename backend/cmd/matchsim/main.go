@@ -0,0 +1,187 @@
+// Command matchsim runs the matching algorithm against a snapshot of a
+// campaign without writing anything, and reports how good the result is.
+//
+//	matchsim -fixture campaign.json -config weights.json
+//	matchsim -campaign <id> -save-fixture campaign.json   (needs DATABASE_URL)
+//	matchsim -fixture campaign.json -config a.json -compare b.json
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"text/tabwriter"
+
+	"wizard-connect/internal/domain/entities"
+	"wizard-connect/internal/infrastructure/database"
+	"wizard-connect/internal/simulation"
+)
+
+func main() {
+	fixture := flag.String("fixture", "", "load the snapshot from this JSON fixture instead of the database")
+	campaignID := flag.String("campaign", "", "campaign to load from the database (default: the active campaign)")
+	saveFixture := flag.String("save-fixture", "", "write the loaded snapshot to this JSON fixture")
+	configPath := flag.String("config", "", "JSON matching config to run with (default: the campaign's own config)")
+	comparePath := flag.String("compare", "", "second JSON matching config to diff against -config")
+	asJSON := flag.Bool("json", false, "print results as JSON")
+	verbose := flag.Bool("verbose", false, "print the matcher's debug output to stderr")
+	seed := flag.Int64("seed", 1, "seed for breaking ties; the same snapshot, config and seed always give the same matches")
+	flag.Parse()
+
+	ctx := context.Background()
+
+	snapshot, err := loadSnapshot(ctx, *fixture, *campaignID)
+	if err != nil {
+		log.Fatalf("Failed to load snapshot: %v", err)
+	}
+	if *saveFixture != "" {
+		if err := snapshot.WriteFile(*saveFixture); err != nil {
+			log.Fatalf("Failed to write fixture: %v", err)
+		}
+		log.Printf("Snapshot written to %s", *saveFixture)
+	}
+
+	resultA, err := runWithConfig(ctx, snapshot, *configPath, *seed, *verbose)
+	if err != nil {
+		log.Fatalf("Simulation failed: %v", err)
+	}
+
+	if *comparePath == "" {
+		if *asJSON {
+			printJSON(struct {
+				simulation.Metrics
+				Fairness entities.FairnessReport `json:"fairness"`
+				Manifest entities.RunManifest    `json:"manifest"`
+			}{resultA.Metrics, resultA.Fairness, resultA.Manifest})
+			return
+		}
+		printMetrics(resultA.Metrics)
+		printFairness(resultA.Fairness)
+		printManifest(resultA.Manifest)
+		return
+	}
+
+	resultB, err := runWithConfig(ctx, snapshot, *comparePath, *seed, *verbose)
+	if err != nil {
+		log.Fatalf("Simulation failed: %v", err)
+	}
+
+	deltas := simulation.Compare(resultA.Metrics, resultB.Metrics)
+	if *asJSON {
+		printJSON(map[string]interface{}{"a": resultA.Metrics, "b": resultB.Metrics, "diff": deltas})
+		return
+	}
+	printDiff(*configPath, *comparePath, deltas)
+}
+
+func loadSnapshot(ctx context.Context, fixture, campaignID string) (*simulation.Snapshot, error) {
+	if fixture != "" {
+		return simulation.LoadSnapshotFile(fixture)
+	}
+
+	dbURL := os.Getenv("DATABASE_URL")
+	if dbURL == "" {
+		return nil, fmt.Errorf("set DATABASE_URL or pass -fixture")
+	}
+	db, err := database.NewDatabase(dbURL)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	return simulation.LoadSnapshotFromDB(ctx, db, campaignID)
+}
+
+// runWithConfig simulates the snapshot under the config in path, or the
+// campaign's stored config when path is empty
+func runWithConfig(ctx context.Context, snapshot *simulation.Snapshot, path string, seed int64, verbose bool) (*simulation.Result, error) {
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		var config map[string]interface{}
+		if err := json.Unmarshal(data, &config); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+		snapshot = snapshot.WithConfig(config)
+	}
+
+	// The matcher logs every candidate; keep it off stdout so the report
+	// (and -json output) stays readable
+	var logger *log.Logger
+	if verbose {
+		logger = log.New(os.Stderr, "", 0)
+	}
+
+	return simulation.Run(ctx, snapshot, seed, logger)
+}
+
+func printJSON(v interface{}) {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	encoder.Encode(v)
+}
+
+func printMetrics(m simulation.Metrics) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "participants\t%d\n", m.Participants)
+	fmt.Fprintf(w, "total matches\t%d\n", m.TotalMatches)
+	fmt.Fprintf(w, "score min / p25 / median / p75 / max\t%.1f / %.1f / %.1f / %.1f / %.1f\n",
+		m.Scores.Min, m.Scores.P25, m.Scores.Median, m.Scores.P75, m.Scores.Max)
+	fmt.Fprintf(w, "score mean\t%.2f\n", m.Scores.Mean)
+	fmt.Fprintf(w, "users with zero matches\t%d (coverage %.1f%%)\n", m.ZeroMatchUsers, m.Coverage*100)
+	fmt.Fprintf(w, "reciprocity rate\t%.1f%%\n", m.ReciprocityRate*100)
+	fmt.Fprintf(w, "mutual crushes captured\t%d / %d (%.1f%%)\n", m.MutualCrushCaptured, m.MutualCrushPairs, m.MutualCrushCaptureRate*100)
+	fmt.Fprintf(w, "popularity gini\t%.3f\n", m.PopularityGini)
+	fmt.Fprintf(w, "gender preference violations\t%d\n", m.GenderPreferenceViolations)
+	w.Flush()
+
+	fmt.Println("\nscore histogram")
+	for i, count := range m.Scores.Histogram {
+		fmt.Printf("  %3d-%-3d %6d\n", i*10, i*10+9, count)
+	}
+}
+
+// printFairness shows how the campaign's fairness controls changed the spread
+// of matches compared to greedy ranking
+func printFairness(report entities.FairnessReport) {
+	fmt.Println("\nexposure (appearances in other people's lists)")
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintf(w, "\tgreedy\tfair\t\n")
+	b, a := report.Before, report.After
+	fmt.Fprintf(w, "max appearances\t%d\t%d\t\n", b.MaxAppearances, a.MaxAppearances)
+	fmt.Fprintf(w, "mean appearances\t%.2f\t%.2f\t\n", b.MeanAppearances, a.MeanAppearances)
+	fmt.Fprintf(w, "gini\t%.3f\t%.3f\t\n", b.Gini, a.Gini)
+	fmt.Fprintf(w, "never shown\t%d\t%d\t\n", b.NeverShown, a.NeverShown)
+	fmt.Fprintf(w, "zero matches\t%d\t%d\t\n", b.ZeroMatchUsers, a.ZeroMatchUsers)
+	fmt.Fprintf(w, "below min matches\t%d\t%d\t\n", b.BelowMinMatches, a.BelowMinMatches)
+	fmt.Fprintf(w, "cap overrides\t\t%d\t\n", report.CapOverrides)
+	w.Flush()
+}
+
+func printManifest(m entities.RunManifest) {
+	fmt.Println("\nmanifest")
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "algorithm / code version\t%s / %s\n", m.AlgorithmVersion, m.CodeVersion)
+	fmt.Fprintf(w, "seed\t%d\n", m.Seed)
+	fmt.Fprintf(w, "config hash\t%s\n", m.ConfigHash)
+	fmt.Fprintf(w, "input hash\t%s\n", m.InputHash)
+	fmt.Fprintf(w, "output hash\t%s\n", m.OutputHash)
+	w.Flush()
+}
+
+func printDiff(nameA, nameB string, deltas []simulation.MetricDelta) {
+	if nameA == "" {
+		nameA = "campaign config"
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintf(w, "metric\t%s\t%s\tdelta\t\n", nameA, nameB)
+	for _, d := range deltas {
+		fmt.Fprintf(w, "%s\t%.3f\t%.3f\t%+.3f\t\n", d.Name, d.A, d.B, d.Delta)
+	}
+	w.Flush()
+}
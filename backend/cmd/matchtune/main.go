@@ -0,0 +1,110 @@
+// Command matchtune checks which scorer sections predicted matches that
+// worked out, using post-match feedback and interested/pass responses, and
+// suggests section weights for the next campaign.
+//
+//	matchtune -campaign <id>   (needs DATABASE_URL)
+//	matchtune -fixture campaign.json -out next.json
+//	matchsim -fixture campaign.json -compare next.json
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"text/tabwriter"
+
+	"wizard-connect/internal/infrastructure/database"
+	"wizard-connect/internal/simulation"
+)
+
+func main() {
+	fixture := flag.String("fixture", "", "load the snapshot, with its outcomes, from this JSON fixture instead of the database")
+	campaignID := flag.String("campaign", "", "campaign to load from the database (default: the active campaign)")
+	saveFixture := flag.String("save-fixture", "", "write the loaded snapshot to this JSON fixture")
+	minOutcomes := flag.Int("min-outcomes", simulation.DefaultMinOutcomes, "outcomes needed before the weights are moved")
+	out := flag.String("out", "", "write the campaign's config with the suggested weights to this JSON file")
+	asJSON := flag.Bool("json", false, "print the analysis as JSON")
+	flag.Parse()
+
+	ctx := context.Background()
+
+	snapshot, err := loadSnapshot(ctx, *fixture, *campaignID)
+	if err != nil {
+		log.Fatalf("Failed to load snapshot: %v", err)
+	}
+	if *saveFixture != "" {
+		if err := snapshot.WriteFile(*saveFixture); err != nil {
+			log.Fatalf("Failed to write fixture: %v", err)
+		}
+		log.Printf("Snapshot written to %s", *saveFixture)
+	}
+
+	analysis, err := simulation.AnalyzeOutcomes(snapshot, *minOutcomes)
+	if err != nil {
+		log.Fatalf("Analysis failed: %v", err)
+	}
+
+	if *out != "" {
+		// Keep the rest of the campaign's config so matchsim compares weights only
+		config := make(map[string]interface{}, len(snapshot.Campaign.Config)+1)
+		for key, value := range snapshot.Campaign.Config {
+			config[key] = value
+		}
+		config["weights"] = analysis.SuggestedWeights
+
+		data, err := json.MarshalIndent(config, "", "  ")
+		if err != nil {
+			log.Fatalf("Failed to encode config: %v", err)
+		}
+		if err := os.WriteFile(*out, data, 0o644); err != nil {
+			log.Fatalf("Failed to write config: %v", err)
+		}
+		log.Printf("Suggested config written to %s", *out)
+	}
+
+	if *asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		encoder.Encode(analysis)
+		return
+	}
+	printAnalysis(analysis)
+}
+
+func loadSnapshot(ctx context.Context, fixture, campaignID string) (*simulation.Snapshot, error) {
+	if fixture != "" {
+		return simulation.LoadSnapshotFile(fixture)
+	}
+
+	dbURL := os.Getenv("DATABASE_URL")
+	if dbURL == "" {
+		return nil, fmt.Errorf("set DATABASE_URL or pass -fixture")
+	}
+	db, err := database.NewDatabase(dbURL)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	return simulation.LoadSnapshotFromDB(ctx, db, campaignID)
+}
+
+func printAnalysis(a *simulation.FeedbackAnalysis) {
+	fmt.Printf("%d outcomes: %d positive, %d from feedback, %d from responses (%d skipped without both surveys)\n\n",
+		a.Outcomes, a.Positive, a.ByFeedback, a.ByResponse, a.Skipped)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintf(w, "section\tsamples\tcorrelation\tmean (positive)\tmean (negative)\tweight\tsuggested\t\n")
+	for _, s := range a.Sections {
+		fmt.Fprintf(w, "%s\t%d\t%+.3f\t%.1f\t%.1f\t%.3f\t%.3f\t\n",
+			s.Section, s.Samples, s.Correlation, s.MeanPositive, s.MeanNegative, s.CurrentWeight, s.SuggestedWeight)
+	}
+	w.Flush()
+
+	if !a.Sufficient {
+		fmt.Println("\nToo few outcomes to suggest new weights; the current ones are kept.")
+	}
+}
@@ -10,10 +10,13 @@ import (
 )
 
 type Config struct {
-	Server   ServerConfig
-	Supabase SupabaseConfig
-	Auth     AuthConfig
-	CORS     CORSConfig
+	Server       ServerConfig
+	Supabase     SupabaseConfig
+	Auth         AuthConfig
+	CORS         CORSConfig
+	Content      ContentConfig
+	Storage      StorageConfig
+	Registration RegistrationConfig
 }
 
 type ServerConfig struct {
@@ -42,6 +45,34 @@ type CORSConfig struct {
 	AllowedHeaders []string
 }
 
+type ContentConfig struct {
+	// PolicyFile is a JSON content policy laid over the built-in defaults
+	PolicyFile string
+}
+
+// RegistrationConfig limits who may sign up
+type RegistrationConfig struct {
+	// AllowedEmailDomains are the domains (and their subdomains) whose
+	// addresses may register; empty lets anyone in
+	AllowedEmailDomains []string
+}
+
+// StorageConfig picks where uploaded files go: "local" keeps them under
+// LocalDir and serves them from /uploads, "s3" puts them in an S3-compatible
+// bucket (AWS, MinIO, Supabase Storage's S3 endpoint)
+type StorageConfig struct {
+	Driver   string
+	LocalDir string
+	// PublicURL is the base URL files are served from; keys are appended
+	PublicURL string
+
+	S3Endpoint        string
+	S3Region          string
+	S3Bucket          string
+	S3AccessKeyID     string
+	S3SecretAccessKey string
+}
+
 // Load reads configuration from environment variables
 func Load() (*Config, error) {
 	// Load .env file if it exists
@@ -108,8 +139,24 @@ func Load() (*Config, error) {
 				}
 				return origins
 			}(),
-			AllowedMethods: []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-			AllowedHeaders: []string{"Content-Type", "Authorization", "Accept", "Origin", "X-Requested-With", "X-CSRF-Token", "Token", "session"},
+			AllowedMethods: []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+			AllowedHeaders: []string{"Content-Type", "Authorization", "Accept", "Origin", "X-Requested-With", "X-CSRF-Token", "Token", "session", "If-Match"},
+		},
+		Content: ContentConfig{
+			PolicyFile: getEnv("CONTENT_POLICY_FILE", ""),
+		},
+		Storage: StorageConfig{
+			Driver:            getEnv("STORAGE_DRIVER", "local"),
+			LocalDir:          getEnv("UPLOAD_DIR", "./uploads"),
+			PublicURL:         getEnv("STORAGE_PUBLIC_URL", ""),
+			S3Endpoint:        getEnv("S3_ENDPOINT", ""),
+			S3Region:          getEnv("S3_REGION", "us-east-1"),
+			S3Bucket:          getEnv("S3_BUCKET", ""),
+			S3AccessKeyID:     getEnv("S3_ACCESS_KEY_ID", ""),
+			S3SecretAccessKey: getEnv("S3_SECRET_ACCESS_KEY", ""),
+		},
+		Registration: RegistrationConfig{
+			AllowedEmailDomains: splitList(getEnv("ALLOWED_EMAIL_DOMAINS", "")),
 		},
 	}
 
@@ -122,3 +169,14 @@ func getEnv(key, defaultValue string) string {
 	}
 	return defaultValue
 }
+
+// splitList reads a comma-separated list, dropping blanks
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,24 @@
+package entities
+
+import "time"
+
+// AccountExport is everything stored about a user, as handed to them by
+// GET /users/me/export
+type AccountExport struct {
+	ExportedAt    time.Time             `json:"exported_at"`
+	Profile       *User                 `json:"profile"`
+	Surveys       []*SurveyResponse     `json:"surveys"`
+	Crushes       []*Crush              `json:"crushes"`
+	Matches       []*Match              `json:"matches"`
+	MatchFeedback []*MatchFeedback      `json:"match_feedback"`
+	Conversations []*ConversationExport `json:"conversations"`
+	Blocks        []*UserBlock          `json:"blocks"`
+	Reports       []*Report             `json:"reports"` // filed by the user
+	Moderation    []*ModerationAction   `json:"moderation_actions"`
+}
+
+// ConversationExport is a conversation with all of its messages
+type ConversationExport struct {
+	*Conversation
+	Messages []*Message `json:"messages"`
+}
@@ -14,6 +14,7 @@ type Campaign struct {
 	TotalParticipants      int                    `json:"total_participants"`
 	TotalMatchesGenerated  int                    `json:"total_matches_generated"`
 	AlgorithmVersion       string                 `json:"algorithm_version"`
+	SurveyVersion          string                 `json:"survey_version"`
 	Config                 map[string]interface{} `json:"config,omitempty"`
 	CreatedAt              time.Time              `json:"created_at"`
 	UpdatedAt              time.Time              `json:"updated_at"`
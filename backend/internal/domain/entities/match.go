@@ -0,0 +1,67 @@
+package entities
+
+import "time"
+
+// Where a match came from
+const (
+	MatchSourceAlgorithm = "algorithm"
+	MatchSourceManual    = "manual"
+)
+
+// A user's response to one of their matches
+const (
+	MatchResponseInterested = "interested"
+	MatchResponsePass       = "pass"
+)
+
+// MatchWithUserDetails is a match as seen by its owner, with the matched
+// user's profile joined in
+type MatchWithUserDetails struct {
+	ID                 string    `json:"id"`
+	UserID             string    `json:"user_id"`
+	MatchedUserID      string    `json:"matched_user_id"`
+	CompatibilityScore float64   `json:"compatibility_score"`
+	ScoreFromUser      float64   `json:"score_from_user"`
+	ScoreFromMatched   float64   `json:"score_from_matched"`
+	Rank               int       `json:"rank"`
+	IsMutualCrush      bool      `json:"is_mutual_crush"`
+	Source             string    `json:"source"`
+	UserAViewed        bool      `json:"user_a_viewed"`
+	UserBViewed        bool      `json:"user_b_viewed"`
+	UserAContacted     bool      `json:"user_a_contacted"`
+	UserBContacted     bool      `json:"user_b_contacted"`
+	MessagingUnlocked  bool      `json:"messaging_unlocked"`
+	UserAResponse      string    `json:"user_a_response"`
+	CreatedAt          time.Time `json:"created_at"`
+	MatchedEmail       string    `json:"matched_email"`
+	FirstName          string    `json:"first_name"`
+	LastName           string    `json:"last_name"`
+	AvatarURL          string    `json:"avatar_url"`
+	Bio                string    `json:"bio"`
+	Year               string    `json:"year"`
+	Major              string    `json:"major"`
+	Gender             string    `json:"gender"`
+	GenderPreference   string    `json:"gender_preference"`
+	Visibility         string    `json:"visibility"`
+}
+
+// MatchWithBothUserDetails is a match with both users' profiles, for admins
+type MatchWithBothUserDetails struct {
+	ID                 string    `json:"id"`
+	UserID             string    `json:"user_id"`
+	MatchedUserID      string    `json:"matched_user_id"`
+	CompatibilityScore float64   `json:"compatibility_score"`
+	Rank               int       `json:"rank"`
+	IsMutualCrush      bool      `json:"is_mutual_crush"`
+	Source             string    `json:"source"`
+	CreatedBy          string    `json:"created_by,omitempty"`
+	CreatedAt          time.Time `json:"created_at"`
+	User1Email         string    `json:"user1_email"`
+	User1FirstName     string    `json:"user1_first_name"`
+	User1LastName      string    `json:"user1_last_name"`
+	User1AvatarURL     string    `json:"user1_avatar_url"`
+	User2Email         string    `json:"user2_email"`
+	User2FirstName     string    `json:"user2_first_name"`
+	User2LastName      string    `json:"user2_last_name"`
+	User2AvatarURL     string    `json:"user2_avatar_url"`
+}
@@ -0,0 +1,42 @@
+package entities
+
+import "time"
+
+// MatchFeedback is what a user reported about one of their matches after
+// the messaging window. It keeps the pair, not just the match ID, so it
+// survives the match being republished.
+type MatchFeedback struct {
+	ID            string    `json:"id"`
+	MatchID       string    `json:"match_id"`
+	UserID        string    `json:"user_id"`
+	MatchedUserID string    `json:"matched_user_id"`
+	CampaignID    string    `json:"campaign_id,omitempty"`
+	Rating        int       `json:"rating"` // 1-5
+	Met           bool      `json:"met"`
+	Comment       string    `json:"comment,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// Positive reports whether the match worked out: the two met, or the user
+// rated it 4 or 5
+func (f *MatchFeedback) Positive() bool {
+	return f.Met || f.Rating >= 4
+}
+
+// Where a match outcome came from
+const (
+	OutcomeSourceFeedback = "feedback"
+	OutcomeSourceResponse = "response"
+)
+
+// MatchOutcome is how one match turned out from its user's side, the
+// training signal for tuning scoring weights. Feedback is the stronger
+// signal; an interested/pass response stands in when there is none.
+type MatchOutcome struct {
+	UserID        string `json:"user_id"`
+	MatchedUserID string `json:"matched_user_id"`
+	Source        string `json:"source"`
+	Positive      bool   `json:"positive"`
+	Reason        string `json:"reason,omitempty"` // pass reason or feedback comment
+}
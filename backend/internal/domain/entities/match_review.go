@@ -0,0 +1,92 @@
+package entities
+
+import "time"
+
+// StagedMatch is a match produced by a campaign run (or pinned by an admin)
+// that users can't see until the campaign's matches are published
+type StagedMatch struct {
+	ID                 string    `json:"id"`
+	CampaignID         string    `json:"campaign_id"`
+	RunID              string    `json:"run_id,omitempty"`
+	UserID             string    `json:"user_id"`
+	MatchedUserID      string    `json:"matched_user_id"`
+	CompatibilityScore float64   `json:"compatibility_score"`
+	ScoreFromUser      float64   `json:"score_from_user"`
+	ScoreFromMatched   float64   `json:"score_from_matched"`
+	Rank               int       `json:"rank"`
+	IsMutualCrush      bool      `json:"is_mutual_crush"`
+	Source             string    `json:"source"`
+	Pinned             bool      `json:"pinned"` // kept, ahead of the algorithm's picks, when the campaign is run again
+	PinnedBy           string    `json:"pinned_by,omitempty"`
+	Flagged            bool      `json:"flagged"` // marked for a second look before publishing
+	FlagReason         string    `json:"flag_reason,omitempty"`
+	FlaggedBy          string    `json:"flagged_by,omitempty"`
+	CreatedAt          time.Time `json:"created_at"`
+}
+
+// Match is the live match the staged one becomes when published
+func (m *StagedMatch) Match() *Match {
+	match := &Match{
+		UserID:             m.UserID,
+		MatchedUserID:      m.MatchedUserID,
+		CompatibilityScore: m.CompatibilityScore,
+		ScoreFromUser:      m.ScoreFromUser,
+		ScoreFromMatched:   m.ScoreFromMatched,
+		Rank:               m.Rank,
+		IsMutualCrush:      m.IsMutualCrush,
+		Source:             m.Source,
+	}
+	if m.Source == MatchSourceManual {
+		match.CreatedBy = m.PinnedBy
+	}
+	return match
+}
+
+// MatchVeto keeps two users from ever being matched. The pair is stored with
+// the smaller user ID first; CampaignID is the campaign it was vetoed from.
+type MatchVeto struct {
+	ID         string    `json:"id"`
+	UserAID    string    `json:"user_a_id"`
+	UserBID    string    `json:"user_b_id"`
+	CampaignID string    `json:"campaign_id,omitempty"`
+	Reason     string    `json:"reason"`
+	VetoedBy   string    `json:"vetoed_by"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// OrderedPair returns two user IDs smallest first, the way vetoes store them
+func OrderedPair(a, b string) (string, string) {
+	if b < a {
+		return b, a
+	}
+	return a, b
+}
+
+// Audit log actions
+const (
+	AuditActionMatchFlag                  = "match.flag"
+	AuditActionMatchUnflag                = "match.unflag"
+	AuditActionMatchVeto                  = "match.veto"
+	AuditActionVetoRevoke                 = "match.veto_revoke"
+	AuditActionMatchPin                   = "match.pin"
+	AuditActionMatchPublish               = "matches.publish"
+	AuditActionManualMatch                = "match.manual"
+	AuditActionReportAssign               = "report.assign"
+	AuditActionReportClose                = "report.close"
+	AuditActionModerate                   = "user.moderate"
+	AuditActionModerateRevoke             = "user.moderate_revoke"
+	AuditActionAccountDelete              = "account.delete"
+	AuditActionRegistrationOverride       = "registration.override"
+	AuditActionRegistrationOverrideRevoke = "registration.override_revoke"
+)
+
+// AuditEntry records an admin action: who did what to which record, and why
+type AuditEntry struct {
+	ID         string                 `json:"id"`
+	ActorID    string                 `json:"actor_id"`
+	Action     string                 `json:"action"`
+	TargetType string                 `json:"target_type"`
+	TargetID   string                 `json:"target_id"`
+	Details    map[string]interface{} `json:"details,omitempty"`
+	CreatedAt  time.Time              `json:"created_at"`
+}
@@ -0,0 +1,70 @@
+package entities
+
+import "time"
+
+// Matching run states
+const (
+	RunStatusRunning   = "running"
+	RunStatusCompleted = "completed"
+	RunStatusFailed    = "failed"
+)
+
+// MatchingRun records one campaign-wide run of the matching algorithm
+type MatchingRun struct {
+	ID           string          `json:"id"`
+	CampaignID   string          `json:"campaign_id"`
+	Status       string          `json:"status"`
+	Error        string          `json:"error,omitempty"`
+	Participants int             `json:"participants"`
+	TotalMatches int             `json:"total_matches"`
+	Fairness     *FairnessReport `json:"fairness,omitempty"`
+	Manifest     *RunManifest    `json:"manifest,omitempty"`
+	StartedAt    time.Time       `json:"started_at"`
+	CompletedAt  *time.Time      `json:"completed_at,omitempty"`
+}
+
+// RunManifest records everything that determines a run's output, so the run
+// can be repeated and checked for identical results
+type RunManifest struct {
+	AlgorithmVersion string                 `json:"algorithm_version"`
+	CodeVersion      string                 `json:"code_version"`
+	SurveyVersion    string                 `json:"survey_version"`
+	Seed             int64                  `json:"seed"`
+	Config           map[string]interface{} `json:"config"`
+	ConfigHash       string                 `json:"config_hash"`
+	Inputs           RunInputs              `json:"inputs"`
+	InputHash        string                 `json:"input_hash"`
+	OutputHash       string                 `json:"output_hash"`
+}
+
+// RunInputs counts what a run was given
+type RunInputs struct {
+	Participants int `json:"participants"`
+	Users        int `json:"users"`
+	Crushes      int `json:"crushes"`
+	Vetoes       int `json:"vetoes"`
+	Blocks       int `json:"blocks,omitempty"`
+}
+
+// FairnessReport compares how matches are spread across participants with
+// plain greedy ranking (Before) and with the campaign's fairness controls (After)
+type FairnessReport struct {
+	Before ExposureDistribution `json:"before"`
+	After  ExposureDistribution `json:"after"`
+	// CapOverrides counts matches that exceeded max_appearances to give
+	// someone their guaranteed minimum
+	CapOverrides int `json:"cap_overrides"`
+}
+
+// ExposureDistribution describes how often participants appear in other
+// people's match lists
+type ExposureDistribution struct {
+	MaxAppearances  int     `json:"max_appearances"`
+	MeanAppearances float64 `json:"mean_appearances"`
+	Gini            float64 `json:"gini"`
+	NeverShown      int     `json:"never_shown"`
+	ZeroMatchUsers  int     `json:"zero_match_users"`
+	BelowMinMatches int     `json:"below_min_matches"`
+	// Histogram[n] is the number of participants appearing in exactly n lists
+	Histogram []int `json:"histogram"`
+}
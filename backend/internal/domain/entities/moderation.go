@@ -0,0 +1,104 @@
+package entities
+
+import "time"
+
+// Moderation actions an admin can take against a user
+const (
+	ModerationActionWarn          = "warn"           // a recorded warning, sent to the user
+	ModerationActionMute          = "mute"           // no new conversations or messages
+	ModerationActionSuspend       = "suspend"        // locked out of the API and left out of matching
+	ModerationActionBan           = "ban"            // a suspension that never expires
+	ModerationActionRemoveContent = "remove_content" // deletes a message or clears a bio or avatar
+)
+
+// ModerationActions lists every action
+var ModerationActions = []string{
+	ModerationActionWarn,
+	ModerationActionMute,
+	ModerationActionSuspend,
+	ModerationActionBan,
+	ModerationActionRemoveContent,
+}
+
+// ModerationAction is one action taken against a user. Mutes, suspensions
+// and bans stay in force until they expire or are revoked.
+type ModerationAction struct {
+	ID          string     `json:"id"`
+	UserID      string     `json:"user_id"`
+	ReportID    string     `json:"report_id,omitempty"`
+	Action      string     `json:"action"`
+	Reason      string     `json:"reason"`
+	ContentType string     `json:"content_type,omitempty"` // what remove_content removed
+	ContentID   string     `json:"content_id,omitempty"`
+	ActorID     string     `json:"actor_id,omitempty"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"` // nil means indefinite
+	RevokedAt   *time.Time `json:"revoked_at,omitempty"`
+	RevokedBy   string     `json:"revoked_by,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// Restricts reports whether the action limits what the user can do while it
+// is in force, rather than being a one-off
+func (a *ModerationAction) Restricts() bool {
+	return a.Action == ModerationActionMute || a.Action == ModerationActionSuspend || a.Action == ModerationActionBan
+}
+
+// ActiveAt reports whether a restricting action is in force at t
+func (a *ModerationAction) ActiveAt(t time.Time) bool {
+	return a.Restricts() && a.RevokedAt == nil && (a.ExpiresAt == nil || a.ExpiresAt.After(t))
+}
+
+// LocksOut reports whether the action keeps the user out of the platform
+func (a *ModerationAction) LocksOut() bool {
+	return a.Action == ModerationActionSuspend || a.Action == ModerationActionBan
+}
+
+// ModerationStanding sums up a user's moderation record at a point in time
+type ModerationStanding struct {
+	Warnings       int        `json:"warnings"`
+	Muted          bool       `json:"muted"`
+	MutedUntil     *time.Time `json:"muted_until,omitempty"` // nil while muted means indefinitely
+	Suspended      bool       `json:"suspended"`
+	SuspendedUntil *time.Time `json:"suspended_until,omitempty"`
+	Banned         bool       `json:"banned"`
+}
+
+// StandingAt works out a user's standing at t from the actions taken
+// against them
+func StandingAt(actions []*ModerationAction, t time.Time) ModerationStanding {
+	var standing ModerationStanding
+	for _, a := range actions {
+		if a.Action == ModerationActionWarn && a.RevokedAt == nil {
+			standing.Warnings++
+		}
+		if !a.ActiveAt(t) {
+			continue
+		}
+		switch a.Action {
+		case ModerationActionMute:
+			standing.MutedUntil = laterExpiry(standing.Muted, standing.MutedUntil, a.ExpiresAt)
+			standing.Muted = true
+		case ModerationActionSuspend:
+			standing.SuspendedUntil = laterExpiry(standing.Suspended, standing.SuspendedUntil, a.ExpiresAt)
+			standing.Suspended = true
+		case ModerationActionBan:
+			standing.Banned = true
+		}
+	}
+	return standing
+}
+
+// laterExpiry combines the expiry of a restriction already in force (if
+// active) with another one's; nil means it never expires
+func laterExpiry(active bool, current, next *time.Time) *time.Time {
+	if !active {
+		return next
+	}
+	if current == nil {
+		return nil
+	}
+	if next == nil || next.After(*current) {
+		return next
+	}
+	return current
+}
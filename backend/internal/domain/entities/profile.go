@@ -0,0 +1,52 @@
+package entities
+
+// Profile visibility settings
+const (
+	VisibilityPublic      = "public"       // anyone signed in sees the public view
+	VisibilityMatchesOnly = "matches_only" // only matches see the profile
+	VisibilityPrivate     = "private"      // matches see the public view, nobody else sees it
+)
+
+// Contact preferences: the one contact method a user shares with matches
+const (
+	ContactPrefEmail     = "email"
+	ContactPrefPhone     = "phone"
+	ContactPrefInstagram = "instagram"
+)
+
+// Profile views, from least to most revealing
+const (
+	ProfileViewPublic  = "public"
+	ProfileViewMatched = "matched"
+	ProfileViewOwner   = "owner"
+)
+
+// ProfileView is a user's profile as one viewer may see it. Fields the
+// viewer isn't allowed to see are left empty and omitted.
+type ProfileView struct {
+	ID        string `json:"id"`
+	View      string `json:"view"`
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name,omitempty"`
+	AvatarURL string `json:"avatar_url"`
+	Bio       string `json:"bio"`
+	Year      string `json:"year"`
+	Major     string `json:"major"`
+	// Contact is the method the user chose to share, revealed to matches
+	Contact *ProfileContact `json:"contact,omitempty"`
+
+	// Owner only
+	Email            string `json:"email,omitempty"`
+	Instagram        string `json:"instagram,omitempty"`
+	Phone            string `json:"phone,omitempty"`
+	ContactPref      string `json:"contact_preference,omitempty"`
+	Visibility       string `json:"visibility,omitempty"`
+	Gender           string `json:"gender,omitempty"`
+	GenderPreference string `json:"gender_preference,omitempty"`
+}
+
+// ProfileContact is one way to reach a user
+type ProfileContact struct {
+	Method string `json:"method"`
+	Value  string `json:"value"`
+}
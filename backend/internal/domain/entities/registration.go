@@ -0,0 +1,12 @@
+package entities
+
+import "time"
+
+// RegistrationOverride lets one email address register even though its
+// domain isn't on the allow-list, e.g. a visiting student's
+type RegistrationOverride struct {
+	Email     string    `json:"email"` // lowercased
+	Reason    string    `json:"reason,omitempty"`
+	AddedBy   string    `json:"added_by,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
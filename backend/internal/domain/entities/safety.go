@@ -0,0 +1,121 @@
+package entities
+
+import "time"
+
+// UserBlock is one user blocking another. A block works in both directions:
+// neither user sees, messages or is matched with the other.
+type UserBlock struct {
+	ID        string    `json:"id"`
+	BlockerID string    `json:"blocker_id"`
+	BlockedID string    `json:"blocked_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Report categories
+const (
+	ReportCategoryHarassment    = "harassment"
+	ReportCategorySpam          = "spam"
+	ReportCategoryInappropriate = "inappropriate_content"
+	ReportCategoryFakeProfile   = "fake_profile"
+	ReportCategoryUnderage      = "underage"
+	ReportCategorySafety        = "safety_concern"
+	ReportCategoryOther         = "other"
+)
+
+// ReportCategories lists every category a report may have
+var ReportCategories = []string{
+	ReportCategoryHarassment,
+	ReportCategorySpam,
+	ReportCategoryInappropriate,
+	ReportCategoryFakeProfile,
+	ReportCategoryUnderage,
+	ReportCategorySafety,
+	ReportCategoryOther,
+}
+
+// IsReportCategory reports whether category is one of ReportCategories
+func IsReportCategory(category string) bool {
+	for _, c := range ReportCategories {
+		if c == category {
+			return true
+		}
+	}
+	return false
+}
+
+// Report states in the moderation queue. Open reports wait for an admin;
+// assigning one puts it in review, and it is closed as actioned or dismissed.
+const (
+	ReportStatusOpen      = "open"
+	ReportStatusInReview  = "in_review"
+	ReportStatusActioned  = "actioned"
+	ReportStatusDismissed = "dismissed"
+)
+
+// ReportClosed reports whether a report in status has been resolved
+func ReportClosed(status string) bool {
+	return status == ReportStatusActioned || status == ReportStatusDismissed
+}
+
+// Where a report came from
+const (
+	ReportSourceUser = "user" // filed by another user
+	ReportSourceAuto = "auto" // flagged automatically, e.g. by the content policy
+)
+
+// Kinds of content a report or moderation action can point at
+const (
+	ContentTypeMessage = "message"
+	ContentTypeBio     = "bio"
+	ContentTypeAvatar  = "avatar"
+)
+
+// Report is an item in the moderation queue: a user's complaint about
+// another user, or content flagged automatically
+type Report struct {
+	ID             string           `json:"id"`
+	ReporterID     string           `json:"reporter_id,omitempty"` // empty for automatic flags
+	ReportedUserID string           `json:"reported_user_id"`
+	Source         string           `json:"source"`
+	Category       string           `json:"category"`
+	Description    string           `json:"description,omitempty"`
+	ContentType    string           `json:"content_type,omitempty"` // the flagged content, if the report is about one item
+	ContentID      string           `json:"content_id,omitempty"`
+	Evidence       []ReportEvidence `json:"evidence"`
+	Status         string           `json:"status"`
+	AssignedTo     string           `json:"assigned_to,omitempty"`
+	AssignedAt     *time.Time       `json:"assigned_at,omitempty"`
+	Resolution     string           `json:"resolution,omitempty"`
+	ResolvedBy     string           `json:"resolved_by,omitempty"`
+	ResolvedAt     *time.Time       `json:"resolved_at,omitempty"`
+	CreatedAt      time.Time        `json:"created_at"`
+	UpdatedAt      time.Time        `json:"updated_at"`
+}
+
+// ReportFilter narrows a queue listing; empty fields match everything
+type ReportFilter struct {
+	Status         string
+	Source         string
+	AssignedTo     string
+	ReportedUserID string
+	ReporterID     string
+}
+
+// Matches reports whether report passes the filter
+func (f ReportFilter) Matches(report *Report) bool {
+	return (f.Status == "" || report.Status == f.Status) &&
+		(f.Source == "" || report.Source == f.Source) &&
+		(f.AssignedTo == "" || report.AssignedTo == f.AssignedTo) &&
+		(f.ReportedUserID == "" || report.ReportedUserID == f.ReportedUserID) &&
+		(f.ReporterID == "" || report.ReporterID == f.ReporterID)
+}
+
+// ReportEvidence is a message cited in a report, copied when the report is
+// made so moderators see it even if it is later deleted
+type ReportEvidence struct {
+	MessageID      string    `json:"message_id"`
+	ConversationID string    `json:"conversation_id"`
+	SenderID       string    `json:"sender_id"`
+	Content        string    `json:"content"`
+	SentAt         time.Time `json:"sent_at"`
+}
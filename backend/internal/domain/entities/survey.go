@@ -0,0 +1,67 @@
+package entities
+
+// Question types, matching the frontend survey configuration
+const (
+	QuestionTypeScale          = "scale"
+	QuestionTypeMultipleChoice = "multiple_choice"
+	QuestionTypeMultiSelect    = "multi_select"
+	QuestionTypeCrushList      = "crush_list"
+)
+
+// How a question's answers are compared between two respondents
+const (
+	ScoringSimilarity = "similarity" // closer answers score higher
+	ScoringComplement = "complement" // opposite answers score higher
+	ScoringMatch      = "match"      // used for filtering only, never scored
+)
+
+// SurveyDefinition is a versioned question set. Responses are always stored
+// against the version they were answered under.
+type SurveyDefinition struct {
+	Version   string           `json:"version"`
+	Questions []SurveyQuestion `json:"questions"`
+}
+
+type SurveyQuestion struct {
+	ID       string   `json:"id"`
+	Section  string   `json:"section"` // demographics, personality, values, lifestyle, interests
+	Type     string   `json:"type"`
+	Options  []string `json:"options,omitempty"`
+	Required bool     `json:"required"`
+	Weight   float64  `json:"weight"`
+	Scoring  string   `json:"scoring"`
+}
+
+// Question returns the question with the given ID, or nil
+func (d *SurveyDefinition) Question(id string) *SurveyQuestion {
+	for i := range d.Questions {
+		if d.Questions[i].ID == id {
+			return &d.Questions[i]
+		}
+	}
+	return nil
+}
+
+// SurveyAnswerChange describes how one answer differs between two survey versions
+type SurveyAnswerChange struct {
+	QuestionID string      `json:"question_id"`
+	Change     string      `json:"change"` // added, removed, changed
+	Before     interface{} `json:"before,omitempty"`
+	After      interface{} `json:"after,omitempty"`
+}
+
+// SurveyCompleteness reports progress against a survey definition. Only
+// required questions count towards Percent and Complete.
+type SurveyCompleteness struct {
+	Percent         float64                         `json:"percent"`
+	Complete        bool                            `json:"complete"`
+	MissingRequired []string                        `json:"missing_required"`
+	InvalidAnswers  []string                        `json:"invalid_answers"`
+	Sections        map[string]*SectionCompleteness `json:"sections"`
+}
+
+type SectionCompleteness struct {
+	Answered int  `json:"answered"`
+	Required int  `json:"required"`
+	Complete bool `json:"complete"`
+}
@@ -30,26 +30,43 @@ type Crush struct {
 }
 
 type SurveyResponse struct {
-	ID              string                 `json:"id" db:"id"`
-	UserID          string                 `json:"user_id" db:"user_id"`
-	Responses       map[string]interface{} `json:"responses" db:"responses"` // JSONB
-	PersonalityType string                 `json:"personality_type" db:"personality_type"`
-	Interests       []string               `json:"interests" db:"interests"`
-	Values          []string               `json:"values" db:"values"`
-	Lifestyle       string                 `json:"lifestyle" db:"lifestyle"`
-	CompletedAt     time.Time              `json:"completed_at" db:"completed_at"`
-	IsComplete      bool                   `json:"is_complete" db:"is_complete"`
-	CreatedAt       time.Time              `json:"created_at" db:"created_at"`
-	UpdatedAt       time.Time              `json:"updated_at" db:"updated_at"`
+	ID                string                 `json:"id" db:"id"`
+	UserID            string                 `json:"user_id" db:"user_id"`
+	CampaignID        string                 `json:"campaign_id,omitempty" db:"campaign_id"`
+	DefinitionVersion string                 `json:"definition_version" db:"definition_version"`
+	Responses         map[string]interface{} `json:"responses" db:"responses"` // JSONB
+	PersonalityType   string                 `json:"personality_type" db:"personality_type"`
+	PersonalityTraits []float64              `json:"personality_traits,omitempty" db:"personality_traits"` // E/I, S/N, T/F, J/P in [-1, 1]
+	Interests         []string               `json:"interests" db:"interests"`
+	Values            []string               `json:"values" db:"values"`
+	Lifestyle         string                 `json:"lifestyle" db:"lifestyle"`
+	CompletedAt       time.Time              `json:"completed_at" db:"completed_at"`
+	IsComplete        bool                   `json:"is_complete" db:"is_complete"`
+	Revision          int                    `json:"revision" db:"revision"` // bumped on every save, used as the ETag
+	CreatedAt         time.Time              `json:"created_at" db:"created_at"`
+	UpdatedAt         time.Time              `json:"updated_at" db:"updated_at"`
 }
 
 type Match struct {
 	ID                 string    `json:"id" db:"id"`
 	UserID             string    `json:"user_id" db:"user_id"`
 	MatchedUserID      string    `json:"matched_user_id" db:"matched_user_id"`
-	CompatibilityScore float64   `json:"compatibility_score" db:"compatibility_score"`
+	CompatibilityScore float64   `json:"compatibility_score" db:"compatibility_score"` // reciprocal score, used for ranking
+	ScoreFromUser      float64   `json:"score_from_user" db:"score_from_user"`         // how well the matched user fits the user's preferences
+	ScoreFromMatched   float64   `json:"score_from_matched" db:"score_from_matched"`   // how well the user fits the matched user's preferences
 	Rank               int       `json:"rank" db:"rank"`
 	IsMutualCrush      bool      `json:"is_mutual_crush" db:"is_mutual_crush"`
+	Source             string    `json:"source" db:"source"`                     // algorithm or manual
+	CreatedBy          string    `json:"created_by,omitempty" db:"created_by"`   // admin who created a manual match
+	UserAViewed        bool      `json:"user_a_viewed" db:"user_a_viewed"`       // user A is the match's user, user B the matched user
+	UserBViewed        bool      `json:"user_b_viewed" db:"user_b_viewed"`       // both directions of a pair are kept in step
+	UserAContacted     bool      `json:"user_a_contacted" db:"user_a_contacted"` // user A has messaged user B
+	UserBContacted     bool      `json:"user_b_contacted" db:"user_b_contacted"`
+	MessagingUnlocked  bool      `json:"messaging_unlocked" db:"messaging_unlocked"` // both users responded interested
+	UserAResponse      string    `json:"user_a_response" db:"user_a_response"`       // interested, pass or empty
+	UserAPassReason    string    `json:"user_a_pass_reason,omitempty" db:"user_a_pass_reason"`
+	UserBResponse      string    `json:"-" db:"user_b_response"` // never shown to user A
+	UserBPassReason    string    `json:"-" db:"user_b_pass_reason"`
 	CreatedAt          time.Time `json:"created_at" db:"created_at"`
 }
 
@@ -9,6 +9,7 @@ import (
 type CampaignRepository interface {
 	Create(ctx context.Context, campaign *entities.Campaign) error
 	GetByID(ctx context.Context, id string) (*entities.Campaign, error)
+	GetActive(ctx context.Context) (*entities.Campaign, error)
 	GetAll(ctx context.Context) ([]*entities.Campaign, error)
 	Update(ctx context.Context, campaign *entities.Campaign) error
 	Delete(ctx context.Context, id string) error
@@ -0,0 +1,15 @@
+package repositories
+
+import (
+	"context"
+
+	"wizard-connect/internal/domain/entities"
+)
+
+type MatchFeedbackRepository interface {
+	// Upsert stores a user's feedback on a match, replacing earlier feedback
+	// on the same match
+	Upsert(ctx context.Context, feedback *entities.MatchFeedback) error
+	GetByMatchID(ctx context.Context, matchID string) (*entities.MatchFeedback, error)
+	ListByCampaign(ctx context.Context, campaignID string) ([]*entities.MatchFeedback, error)
+}
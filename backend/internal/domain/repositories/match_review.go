@@ -0,0 +1,31 @@
+package repositories
+
+import (
+	"context"
+
+	"wizard-connect/internal/domain/entities"
+)
+
+type StagedMatchRepository interface {
+	// ReplaceForCampaign swaps everything staged for a campaign for matches
+	ReplaceForCampaign(ctx context.Context, campaignID string, matches []*entities.StagedMatch) error
+	Create(ctx context.Context, match *entities.StagedMatch) error
+	Update(ctx context.Context, match *entities.StagedMatch) error
+	Delete(ctx context.Context, id string) error
+	GetByID(ctx context.Context, id string) (*entities.StagedMatch, error)
+	ListByCampaign(ctx context.Context, campaignID string) ([]*entities.StagedMatch, error)
+	DeleteByCampaign(ctx context.Context, campaignID string) error
+}
+
+type MatchVetoRepository interface {
+	Create(ctx context.Context, veto *entities.MatchVeto) error
+	GetByID(ctx context.Context, id string) (*entities.MatchVeto, error)
+	Delete(ctx context.Context, id string) error
+	ListVetoes(ctx context.Context) ([]*entities.MatchVeto, error)
+}
+
+type AuditLogRepository interface {
+	Create(ctx context.Context, entry *entities.AuditEntry) error
+	// List returns entries newest first; empty filters match everything
+	List(ctx context.Context, targetType, targetID string, limit int) ([]*entities.AuditEntry, error)
+}
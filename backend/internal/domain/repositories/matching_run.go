@@ -0,0 +1,14 @@
+package repositories
+
+import (
+	"context"
+
+	"wizard-connect/internal/domain/entities"
+)
+
+type MatchingRunRepository interface {
+	Create(ctx context.Context, run *entities.MatchingRun) error
+	Update(ctx context.Context, run *entities.MatchingRun) error
+	GetByID(ctx context.Context, id string) (*entities.MatchingRun, error)
+	ListByCampaign(ctx context.Context, campaignID string) ([]*entities.MatchingRun, error)
+}
@@ -0,0 +1,16 @@
+package repositories
+
+import (
+	"context"
+
+	"wizard-connect/internal/domain/entities"
+)
+
+type RegistrationOverrideRepository interface {
+	// Add saves the override; adding an email again updates its reason
+	Add(ctx context.Context, override *entities.RegistrationOverride) error
+	Remove(ctx context.Context, email string) error
+	// List returns every override, newest first
+	List(ctx context.Context) ([]*entities.RegistrationOverride, error)
+	IsOverridden(ctx context.Context, email string) (bool, error)
+}
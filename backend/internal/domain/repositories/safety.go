@@ -0,0 +1,40 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"wizard-connect/internal/domain/entities"
+)
+
+type UserBlockRepository interface {
+	Create(ctx context.Context, block *entities.UserBlock) error
+	Delete(ctx context.Context, blockerID, blockedID string) error
+	// IsBlocked reports whether either user has blocked the other
+	IsBlocked(ctx context.Context, userID, otherUserID string) (bool, error)
+	// BlockedUserIDs lists everyone userID has blocked or been blocked by
+	BlockedUserIDs(ctx context.Context, userID string) ([]string, error)
+	ListByBlocker(ctx context.Context, blockerID string) ([]*entities.UserBlock, error)
+	ListBlocks(ctx context.Context) ([]*entities.UserBlock, error)
+}
+
+type ReportRepository interface {
+	Create(ctx context.Context, report *entities.Report) error
+	GetByID(ctx context.Context, id string) (*entities.Report, error)
+	// List returns the reports matching filter, newest first
+	List(ctx context.Context, filter entities.ReportFilter) ([]*entities.Report, error)
+	// Update saves a report's status, assignment and resolution
+	Update(ctx context.Context, report *entities.Report) error
+}
+
+type ModerationActionRepository interface {
+	Create(ctx context.Context, action *entities.ModerationAction) error
+	GetByID(ctx context.Context, id string) (*entities.ModerationAction, error)
+	Revoke(ctx context.Context, id, revokedBy string) error
+	// ListByUser returns every action taken against a user, newest first
+	ListByUser(ctx context.Context, userID string) ([]*entities.ModerationAction, error)
+	// Active returns the user's mutes, suspensions and bans in force at t
+	Active(ctx context.Context, userID string, at time.Time) ([]*entities.ModerationAction, error)
+	// ListSuspendedUserIDs lists users suspended or banned at t
+	ListSuspendedUserIDs(ctx context.Context, at time.Time) ([]string, error)
+}
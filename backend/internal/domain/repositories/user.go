@@ -2,6 +2,7 @@ package repositories
 
 import (
 	"context"
+	"errors"
 
 	"wizard-connect/internal/domain/entities"
 )
@@ -9,17 +10,42 @@ import (
 type UserRepository interface {
 	Create(ctx context.Context, user *entities.User) error
 	GetByID(ctx context.Context, id string) (*entities.User, error)
+	// Exists reports whether a profile is stored for id, without creating one
+	Exists(ctx context.Context, id string) (bool, error)
 	GetByEmail(ctx context.Context, email string) (*entities.User, error)
 	Update(ctx context.Context, user *entities.User) error
+	// ClearFields empties optional profile fields, which Update leaves alone
+	// when they are blank. Fields are column names: first_name, last_name,
+	// avatar_url, bio, instagram, phone, year, major, gender and
+	// gender_preference.
+	ClearFields(ctx context.Context, id string, fields ...string) error
+	// Delete erases the account and everything stored about it in one go:
+	// the profile, surveys, crushes (including other users' crushes naming
+	// its email), matches, staged matches, vetoes, feedback, blocks,
+	// conversations, messages, reports about the user and moderation
+	// actions. Reports the user filed are kept without the reporter. The
+	// auth account goes too, which ends its sessions, and the ID is
+	// remembered so tokens issued before the deletion stop working.
 	Delete(ctx context.Context, id string) error
+	// IsDeleted reports whether the account was deleted
+	IsDeleted(ctx context.Context, id string) (bool, error)
 	List(ctx context.Context, limit, offset int) ([]*entities.User, error)
 	ListAll(ctx context.Context) ([]*entities.User, error)
 }
 
+// ErrRevisionConflict is returned when a conditional save finds the record
+// was changed since the caller read it
+var ErrRevisionConflict = errors.New("record was modified by another request")
+
 type SurveyRepository interface {
 	CreateOrUpdate(ctx context.Context, survey *entities.SurveyResponse) error
+	SaveRevision(ctx context.Context, survey *entities.SurveyResponse, expectedRevision int) error
 	GetByUserID(ctx context.Context, userID string) (*entities.SurveyResponse, error)
+	GetByUserAndCampaign(ctx context.Context, userID, campaignID string) (*entities.SurveyResponse, error)
+	ListByUserID(ctx context.Context, userID string) ([]*entities.SurveyResponse, error)
 	GetCompletedSurveys(ctx context.Context) ([]*entities.SurveyResponse, error)
+	GetCompletedSurveysForCampaign(ctx context.Context, campaignID, definitionVersion string) ([]*entities.SurveyResponse, error)
+	ListByCampaign(ctx context.Context, campaignID string) ([]*entities.SurveyResponse, error)
 }
 
 type CrushRepository interface {
@@ -31,19 +57,34 @@ type CrushRepository interface {
 type MatchRepository interface {
 	Create(ctx context.Context, match *entities.Match) error
 	GetByUserID(ctx context.Context, userID string) ([]*entities.Match, error)
+	GetByID(ctx context.Context, id string) (*entities.Match, error)
 	GetMatch(ctx context.Context, userID, matchedUserID string) (*entities.Match, error)
+	// MarkViewed and MarkContacted record that userID viewed or messaged
+	// matchedUserID, on both directions of the pair
+	MarkViewed(ctx context.Context, userID, matchedUserID string) error
+	MarkContacted(ctx context.Context, userID, matchedUserID string) error
+	// Respond records userID's response to matchedUserID on both directions
+	// of the pair and unlocks messaging once both are interested
+	Respond(ctx context.Context, userID, matchedUserID, response, passReason string) error
+	UpdateRank(ctx context.Context, id string, rank int) error
 	DeleteByUserID(ctx context.Context, userID string) error
+	GetByUserIDWithUserDetails(ctx context.Context, userID string) ([]*entities.MatchWithUserDetails, error)
+	ListAllWithUserDetails(ctx context.Context) ([]*entities.MatchWithBothUserDetails, error)
 }
 
 type MessageRepository interface {
 	Create(ctx context.Context, message *entities.Message) error
+	GetByID(ctx context.Context, id string) (*entities.Message, error)
+	Delete(ctx context.Context, id string) error
 	GetByConversationID(ctx context.Context, conversationID string, limit, offset int) ([]*entities.Message, error)
 	MarkAsRead(ctx context.Context, messageID string) error
 	GetUnreadCount(ctx context.Context, userID string) (int, error)
+	CountByConversationID(ctx context.Context, conversationID string) (int, error)
 }
 
 type ConversationRepository interface {
 	Create(ctx context.Context, conv *entities.Conversation) error
+	GetByID(ctx context.Context, id string) (*entities.Conversation, error)
 	GetByParticipants(ctx context.Context, participant1, participant2 string) (*entities.Conversation, error)
 	GetByUserID(ctx context.Context, userID string) ([]*entities.Conversation, error)
 	UpdateLastMessage(ctx context.Context, conversationID, lastMessage string) error
@@ -0,0 +1,163 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Avatar upload limits
+const (
+	MaxAvatarBytes = 5 << 20 // 5 MB
+	// MaxAvatarPixels caps the decoded size, so a small file can't expand
+	// into a huge bitmap
+	MaxAvatarPixels = 40_000_000
+	// MinAvatarSide is the smallest width or height accepted
+	MinAvatarSide = 64
+	// avatarSide is the longest side the stored avatar is scaled down to
+	avatarSide = 1024
+)
+
+// AvatarThumbnailSizes are the square thumbnails made for every avatar
+var AvatarThumbnailSizes = []int{256, 64}
+
+// AvatarContentTypes are the image types accepted, by sniffed content type
+var AvatarContentTypes = []string{"image/jpeg", "image/png", "image/gif"}
+
+var (
+	ErrAvatarTooLarge = fmt.Errorf("avatar must be at most %d MB", MaxAvatarBytes>>20)
+	ErrAvatarType     = errors.New("avatar must be a JPEG, PNG or GIF image")
+	ErrAvatarSize     = fmt.Errorf("avatar must be at least %dx%d pixels and at most %d megapixels", MinAvatarSide, MinAvatarSide, MaxAvatarPixels/1_000_000)
+)
+
+// ObjectStorage stores files under keys like "avatars/<user>/<name>.jpg"
+// and serves them from public URLs. The URL of a key never changes.
+type ObjectStorage interface {
+	Put(ctx context.Context, key, contentType string, data []byte) error
+	// DeletePrefix removes every file whose key starts with prefix
+	DeletePrefix(ctx context.Context, prefix string) error
+	URL(key string) string
+}
+
+// Avatar is a processed and stored avatar
+type Avatar struct {
+	URL    string `json:"avatar_url"`
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+	// Thumbnails are square crops by side in pixels ("256", "64")
+	Thumbnails map[string]string `json:"thumbnails"`
+}
+
+// AvatarService validates uploaded avatars, strips their metadata, makes
+// thumbnails and stores them
+type AvatarService struct {
+	storage ObjectStorage
+}
+
+func NewAvatarService(storage ObjectStorage) *AvatarService {
+	return &AvatarService{storage: storage}
+}
+
+// Upload processes an uploaded image and stores the avatar and its
+// thumbnails. Files are named after a hash of the processed image, so the
+// same picture always gets the same URL and a new one never overwrites an
+// URL that's already cached.
+func (s *AvatarService) Upload(ctx context.Context, userID string, data []byte) (*Avatar, error) {
+	if len(data) > MaxAvatarBytes {
+		return nil, ErrAvatarTooLarge
+	}
+	if !oneOf(AvatarContentTypes, http.DetectContentType(data)) {
+		return nil, ErrAvatarType
+	}
+
+	// Check the dimensions before decoding the whole image
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrAvatarType
+	}
+	if config.Width < MinAvatarSide || config.Height < MinAvatarSide || config.Width*config.Height > MaxAvatarPixels {
+		return nil, ErrAvatarSize
+	}
+
+	img, err := decodeAvatar(data)
+	if err != nil {
+		return nil, ErrAvatarType
+	}
+	img = fitWithin(img, avatarSide)
+
+	full, err := encodeAvatar(img)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode avatar: %w", err)
+	}
+	sum := sha256.Sum256(full)
+	base := avatarPrefix(userID) + hex.EncodeToString(sum[:12])
+
+	avatar := &Avatar{
+		Width:      img.Bounds().Dx(),
+		Height:     img.Bounds().Dy(),
+		Thumbnails: make(map[string]string, len(AvatarThumbnailSizes)),
+	}
+
+	// Thumbnails go first so the avatar URL never points at a set that's
+	// only partly stored
+	for _, size := range AvatarThumbnailSizes {
+		thumb, err := encodeAvatar(squareThumbnail(img, size))
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode thumbnail: %w", err)
+		}
+		key := AvatarThumbnailKey(base+".jpg", size)
+		if err := s.storage.Put(ctx, key, "image/jpeg", thumb); err != nil {
+			return nil, fmt.Errorf("failed to store thumbnail: %w", err)
+		}
+		avatar.Thumbnails[strconv.Itoa(size)] = s.storage.URL(key)
+	}
+
+	if err := s.storage.Put(ctx, base+".jpg", "image/jpeg", full); err != nil {
+		return nil, fmt.Errorf("failed to store avatar: %w", err)
+	}
+	avatar.URL = s.storage.URL(base + ".jpg")
+
+	return avatar, nil
+}
+
+// DeleteAll removes every avatar and thumbnail the user has uploaded,
+// current or not
+func (s *AvatarService) DeleteAll(ctx context.Context, userID string) error {
+	if userID == "" {
+		return errors.New("user ID required")
+	}
+	return s.storage.DeletePrefix(ctx, avatarPrefix(userID))
+}
+
+// avatarName matches the file names Upload gives avatars: 12 bytes of the
+// image's hash in hex
+var avatarName = regexp.MustCompile(`^[0-9a-f]{24}\.jpg$`)
+
+// IsAvatarURL reports whether url is one of the user's uploaded avatars, so
+// a profile can only point at images that went through Upload
+func (s *AvatarService) IsAvatarURL(userID, url string) bool {
+	if userID == "" {
+		return false
+	}
+	prefix := s.storage.URL(avatarPrefix(userID))
+	return strings.HasPrefix(url, prefix) && avatarName.MatchString(strings.TrimPrefix(url, prefix))
+}
+
+// avatarPrefix is the folder holding a user's avatars
+func avatarPrefix(userID string) string {
+	return "avatars/" + userID + "/"
+}
+
+// AvatarThumbnailKey names a thumbnail after its avatar:
+// "avatars/u/abc.jpg" -> "avatars/u/abc_256.jpg". It works on URLs too.
+func AvatarThumbnailKey(avatarKey string, size int) string {
+	return fmt.Sprintf("%s_%d.jpg", strings.TrimSuffix(avatarKey, ".jpg"), size)
+}
@@ -0,0 +1,86 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+
+	"wizard-connect/internal/domain/entities"
+)
+
+var (
+	ErrCampaignYearMissing      = errors.New("add your year level to take part in this campaign")
+	ErrCampaignYearIneligible   = errors.New("this campaign isn't open to your year level")
+	ErrCampaignDomainIneligible = errors.New("this campaign isn't open to your email domain")
+)
+
+// CampaignEligibility limits who may take part in one campaign, read from
+// the "eligibility" key of campaigns.config:
+//
+//	{"eligibility": {"years": [1, 2], "email_domains": ["cs.example.edu"]}}
+type CampaignEligibility struct {
+	// Years are the year levels admitted, 1-5 with 6 for graduate
+	// students; empty admits every year
+	Years []int `json:"years"`
+	// EmailDomains narrow the registration allow-list for this campaign;
+	// empty admits everyone who could register
+	EmailDomains []string `json:"email_domains"`
+}
+
+// CampaignEligibilityRules reads a campaign's eligibility rules. A nil
+// campaign, or one without rules, admits everyone.
+func CampaignEligibilityRules(campaign *entities.Campaign) CampaignEligibility {
+	var rules CampaignEligibility
+	if campaign == nil || campaign.Config["eligibility"] == nil {
+		return rules
+	}
+	data, err := json.Marshal(campaign.Config["eligibility"])
+	if err != nil {
+		return rules
+	}
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return CampaignEligibility{}
+	}
+	rules.EmailDomains = normalizeDomains(rules.EmailDomains)
+	return rules
+}
+
+// Check returns nil when p may take part. The year comes from the survey's
+// year answer or, failing that, the profile.
+func (e CampaignEligibility) Check(p *Participant) error {
+	if len(e.EmailDomains) > 0 {
+		if p.User == nil || !EmailDomainAllowed(e.EmailDomains, p.User.Email) {
+			return ErrCampaignDomainIneligible
+		}
+	}
+	if len(e.Years) > 0 {
+		year, ok := participantYear(p)
+		if !ok {
+			return ErrCampaignYearMissing
+		}
+		admitted := false
+		for _, y := range e.Years {
+			if y == year {
+				admitted = true
+				break
+			}
+		}
+		if !admitted {
+			return ErrCampaignYearIneligible
+		}
+	}
+	return nil
+}
+
+// CampaignEligibilityErrorCode is the API error code for a participant a
+// campaign doesn't admit, or ""
+func CampaignEligibilityErrorCode(err error) string {
+	switch {
+	case errors.Is(err, ErrCampaignYearMissing):
+		return "year_required"
+	case errors.Is(err, ErrCampaignYearIneligible):
+		return "year_not_eligible"
+	case errors.Is(err, ErrCampaignDomainIneligible):
+		return "email_domain_not_eligible"
+	}
+	return ""
+}
@@ -0,0 +1,399 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"wizard-connect/internal/domain/entities"
+)
+
+// statisticsTTL bounds how stale cached statistics get between refreshes;
+// conversations and messages keep changing after a run
+const statisticsTTL = 5 * time.Minute
+
+// scoreBucketWidth is the width of a score histogram bucket
+const scoreBucketWidth = 10
+
+// Reads the statistics service needs beyond the matching service's
+type CampaignSurveyLister interface {
+	ListByCampaign(ctx context.Context, campaignID string) ([]*entities.SurveyResponse, error)
+}
+
+type UserMatchLister interface {
+	GetByUserID(ctx context.Context, userID string) ([]*entities.Match, error)
+}
+
+type StagedMatchLister interface {
+	ListByCampaign(ctx context.Context, campaignID string) ([]*entities.StagedMatch, error)
+}
+
+type UserConversationLister interface {
+	GetByUserID(ctx context.Context, userID string) ([]*entities.Conversation, error)
+}
+
+type MessageCounter interface {
+	CountByConversationID(ctx context.Context, conversationID string) (int, error)
+}
+
+type CampaignStore interface {
+	GetByID(ctx context.Context, id string) (*entities.Campaign, error)
+	Update(ctx context.Context, campaign *entities.Campaign) error
+}
+
+// CampaignStatistics summarizes a campaign from its surveys, published
+// matches, engagement with them and the conversations between matched users
+type CampaignStatistics struct {
+	CampaignID   string `json:"campaign_id"`
+	CampaignName string `json:"campaign_name"`
+
+	// TotalParticipants completed the campaign's survey
+	TotalParticipants int          `json:"total_participants"`
+	Funnel            SurveyFunnel `json:"funnel"`
+
+	// TotalMatches counts published matches in both directions; MatchedPairs
+	// counts each pair once
+	TotalMatches          int     `json:"total_matches"`
+	MatchedPairs          int     `json:"matched_pairs"`
+	ManualMatches         int     `json:"manual_matches"`
+	StagedMatches         int     `json:"staged_matches"`
+	MatchesPerParticipant float64 `json:"matches_per_participant"`
+	AverageCompatibility  float64 `json:"average_compatibility"`
+	MutualCrushRate       float64 `json:"mutual_crush_rate"`
+	// ScoreHistogram[i] counts matches scoring from 10*i up to 10*i+10; 100 falls in the last bucket
+	ScoreHistogram []int `json:"score_histogram"`
+
+	Engagement MatchEngagement `json:"engagement"`
+
+	// Conversations between matched pairs
+	Conversations           int     `json:"conversations"`
+	ConversationsPerMatch   float64 `json:"conversations_per_match"`
+	MessagesPerConversation float64 `json:"messages_per_conversation"`
+
+	ByYear  map[string]*SegmentStatistics `json:"by_year"`
+	ByMajor map[string]*SegmentStatistics `json:"by_major"`
+
+	ComputedAt time.Time `json:"computed_at"`
+}
+
+// SurveyFunnel follows users from sign-up to talking to a match
+type SurveyFunnel struct {
+	RegisteredUsers int `json:"registered_users"`
+	Started         int `json:"started"`
+	HalfComplete    int `json:"half_complete"`
+	Completed       int `json:"completed"`
+	Matched         int `json:"matched"`
+	Contacted       int `json:"contacted"`
+}
+
+// MatchEngagement is how users acted on their published matches. Viewed,
+// Contacted, Interested and Passed count matches by what their owner did; the
+// pair counts need both users.
+type MatchEngagement struct {
+	UsersViewing       int     `json:"users_viewing"`
+	Viewed             int     `json:"viewed"`
+	ViewRate           float64 `json:"view_rate"`
+	Contacted          int     `json:"contacted"`
+	ContactRate        float64 `json:"contact_rate"`
+	Interested         int     `json:"interested"`
+	Passed             int     `json:"passed"`
+	PairsBothViewed    int     `json:"pairs_both_viewed"`
+	PairsBothContacted int     `json:"pairs_both_contacted"`
+	PairsUnlocked      int     `json:"pairs_unlocked"`
+}
+
+// SegmentStatistics is the funnel and match quality for one year or major
+type SegmentStatistics struct {
+	Started              int     `json:"started"`
+	Completed            int     `json:"completed"`
+	Matched              int     `json:"matched"`
+	Matches              int     `json:"matches"`
+	AverageCompatibility float64 `json:"average_compatibility"`
+}
+
+// StatisticsService computes campaign statistics and caches them until the
+// next matching run or publish, or until they are statisticsTTL old
+type StatisticsService struct {
+	userRepo         UserRepository
+	surveyRepo       CampaignSurveyLister
+	matchRepo        UserMatchLister
+	stagedRepo       StagedMatchLister
+	conversationRepo UserConversationLister
+	messageRepo      MessageCounter
+	campaignRepo     CampaignStore
+
+	mu    sync.Mutex
+	cache map[string]*CampaignStatistics
+}
+
+func NewStatisticsService(
+	userRepo UserRepository,
+	surveyRepo CampaignSurveyLister,
+	matchRepo UserMatchLister,
+	stagedRepo StagedMatchLister,
+	conversationRepo UserConversationLister,
+	messageRepo MessageCounter,
+	campaignRepo CampaignStore,
+) *StatisticsService {
+	return &StatisticsService{
+		userRepo:         userRepo,
+		surveyRepo:       surveyRepo,
+		matchRepo:        matchRepo,
+		stagedRepo:       stagedRepo,
+		conversationRepo: conversationRepo,
+		messageRepo:      messageRepo,
+		campaignRepo:     campaignRepo,
+		cache:            make(map[string]*CampaignStatistics),
+	}
+}
+
+// Get returns the campaign's cached statistics, computing them if there are
+// none or they have expired
+func (s *StatisticsService) Get(ctx context.Context, campaign *entities.Campaign) (*CampaignStatistics, error) {
+	s.mu.Lock()
+	stats, ok := s.cache[campaign.ID]
+	s.mu.Unlock()
+	if ok && time.Since(stats.ComputedAt) < statisticsTTL {
+		return stats, nil
+	}
+	return s.Refresh(ctx, campaign)
+}
+
+// Refresh recomputes the campaign's statistics, caches them and stores the
+// participant and match totals on the campaign
+func (s *StatisticsService) Refresh(ctx context.Context, campaign *entities.Campaign) (*CampaignStatistics, error) {
+	stats, err := s.compute(ctx, campaign)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	s.cache[campaign.ID] = stats
+	s.mu.Unlock()
+
+	// Re-read the campaign so totals don't overwrite a concurrent edit
+	stored, err := s.campaignRepo.GetByID(ctx, campaign.ID)
+	if err != nil {
+		return stats, fmt.Errorf("failed to load campaign for totals: %w", err)
+	}
+	if stored.TotalParticipants != stats.TotalParticipants || stored.TotalMatchesGenerated != stats.TotalMatches {
+		stored.TotalParticipants = stats.TotalParticipants
+		stored.TotalMatchesGenerated = stats.TotalMatches
+		if err := s.campaignRepo.Update(ctx, stored); err != nil {
+			return stats, fmt.Errorf("failed to update campaign totals: %w", err)
+		}
+	}
+	return stats, nil
+}
+
+func (s *StatisticsService) compute(ctx context.Context, campaign *entities.Campaign) (*CampaignStatistics, error) {
+	def, err := GetSurveyDefinition(CampaignSurveyVersion(campaign))
+	if err != nil {
+		return nil, err
+	}
+
+	users, err := s.userRepo.ListAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	usersByID := make(map[string]*entities.User, len(users))
+	for _, u := range users {
+		usersByID[u.ID] = u
+	}
+
+	surveys, err := s.surveyRepo.ListByCampaign(ctx, campaign.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &CampaignStatistics{
+		CampaignID:     campaign.ID,
+		CampaignName:   campaign.Name,
+		ScoreHistogram: make([]int, 100/scoreBucketWidth),
+		ByYear:         make(map[string]*SegmentStatistics),
+		ByMajor:        make(map[string]*SegmentStatistics),
+		ComputedAt:     time.Now(),
+	}
+	stats.Funnel.RegisteredUsers = len(users)
+
+	segments := func(userID string) []*SegmentStatistics {
+		year, major := "unknown", "unknown"
+		if u := usersByID[userID]; u != nil {
+			year, major = segmentKey(u.Year), segmentKey(u.Major)
+		}
+		if stats.ByYear[year] == nil {
+			stats.ByYear[year] = &SegmentStatistics{}
+		}
+		if stats.ByMajor[major] == nil {
+			stats.ByMajor[major] = &SegmentStatistics{}
+		}
+		return []*SegmentStatistics{stats.ByYear[year], stats.ByMajor[major]}
+	}
+
+	// Survey funnel. A user has one survey per campaign.
+	var participants []string
+	for _, survey := range surveys {
+		stats.Funnel.Started++
+		complete := survey.IsComplete && survey.DefinitionVersion == def.Version
+		if complete || Completeness(def, survey.Responses).Percent >= 50 {
+			stats.Funnel.HalfComplete++
+		}
+		for _, seg := range segments(survey.UserID) {
+			seg.Started++
+			if complete {
+				seg.Completed++
+			}
+		}
+		if complete {
+			stats.Funnel.Completed++
+			participants = append(participants, survey.UserID)
+		}
+	}
+	stats.TotalParticipants = len(participants)
+
+	// Published matches of the campaign's participants
+	pairs := make(map[[2]string]bool)
+	scoreSum := 0.0
+	mutual := 0
+	for _, userID := range participants {
+		matches, err := s.matchRepo.GetByUserID(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+		if len(matches) == 0 {
+			continue
+		}
+
+		stats.Funnel.Matched++
+		userScore := 0.0
+		viewing := false
+		for _, m := range matches {
+			stats.TotalMatches++
+			scoreSum += m.CompatibilityScore
+			userScore += m.CompatibilityScore
+			if m.IsMutualCrush {
+				mutual++
+			}
+			if m.Source == entities.MatchSourceManual {
+				stats.ManualMatches++
+			}
+			stats.ScoreHistogram[scoreBucket(m.CompatibilityScore)]++
+
+			if m.UserAViewed {
+				stats.Engagement.Viewed++
+				viewing = true
+			}
+			if m.UserAContacted {
+				stats.Engagement.Contacted++
+			}
+			switch m.UserAResponse {
+			case entities.MatchResponseInterested:
+				stats.Engagement.Interested++
+			case entities.MatchResponsePass:
+				stats.Engagement.Passed++
+			}
+
+			// Both directions carry the pair's flags, so count it once
+			a, b := entities.OrderedPair(m.UserID, m.MatchedUserID)
+			if !pairs[[2]string{a, b}] {
+				if m.UserAViewed && m.UserBViewed {
+					stats.Engagement.PairsBothViewed++
+				}
+				if m.UserAContacted && m.UserBContacted {
+					stats.Engagement.PairsBothContacted++
+				}
+				if m.MessagingUnlocked {
+					stats.Engagement.PairsUnlocked++
+				}
+			}
+			pairs[[2]string{a, b}] = true
+		}
+		if viewing {
+			stats.Engagement.UsersViewing++
+		}
+		for _, seg := range segments(userID) {
+			// Running mean over every match in the segment
+			total := seg.AverageCompatibility*float64(seg.Matches) + userScore
+			seg.Matched++
+			seg.Matches += len(matches)
+			seg.AverageCompatibility = total / float64(seg.Matches)
+		}
+	}
+	stats.MatchedPairs = len(pairs)
+	if stats.TotalMatches > 0 {
+		stats.AverageCompatibility = scoreSum / float64(stats.TotalMatches)
+		stats.MutualCrushRate = float64(mutual) / float64(stats.TotalMatches)
+		stats.Engagement.ViewRate = float64(stats.Engagement.Viewed) / float64(stats.TotalMatches)
+		stats.Engagement.ContactRate = float64(stats.Engagement.Contacted) / float64(stats.TotalMatches)
+	}
+	if stats.TotalParticipants > 0 {
+		stats.MatchesPerParticipant = float64(stats.TotalMatches) / float64(stats.TotalParticipants)
+	}
+
+	staged, err := s.stagedRepo.ListByCampaign(ctx, campaign.ID)
+	if err != nil {
+		return nil, err
+	}
+	stats.StagedMatches = len(staged)
+
+	// Conversations between matched pairs, and who took part in one
+	conversations := make(map[string]bool)
+	contacted := make(map[string]bool)
+	messages := 0
+	for _, userID := range participants {
+		convs, err := s.conversationRepo.GetByUserID(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+		for _, conv := range convs {
+			a, b := entities.OrderedPair(conv.Participant1, conv.Participant2)
+			if !pairs[[2]string{a, b}] {
+				continue
+			}
+			contacted[userID] = true
+			if conversations[conv.ID] {
+				continue
+			}
+			conversations[conv.ID] = true
+
+			count, err := s.messageRepo.CountByConversationID(ctx, conv.ID)
+			if err != nil {
+				return nil, err
+			}
+			messages += count
+		}
+	}
+	stats.Conversations = len(conversations)
+	stats.Funnel.Contacted = len(contacted)
+	if stats.MatchedPairs > 0 {
+		stats.ConversationsPerMatch = float64(stats.Conversations) / float64(stats.MatchedPairs)
+	}
+	if stats.Conversations > 0 {
+		stats.MessagesPerConversation = float64(messages) / float64(stats.Conversations)
+	}
+
+	return stats, nil
+}
+
+func scoreBucket(score float64) int {
+	bucket := int(score) / scoreBucketWidth
+	if bucket < 0 {
+		return 0
+	}
+	if last := 100/scoreBucketWidth - 1; bucket > last {
+		return last
+	}
+	return bucket
+}
+
+// segmentKey is the breakdown key of a profile's year or major; users who
+// left it blank are grouped as unknown
+func segmentKey(value string) string {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return "unknown"
+	}
+	return value
+}
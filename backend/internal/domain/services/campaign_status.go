@@ -0,0 +1,46 @@
+package services
+
+import (
+	"time"
+
+	"wizard-connect/internal/domain/entities"
+)
+
+// CampaignStatus tells clients which phase the active campaign is in
+type CampaignStatus struct {
+	CampaignID          string    `json:"campaign_id"`
+	CampaignName        string    `json:"campaign_name"`
+	SurveyActive        bool      `json:"survey_active"`
+	ProfileUpdateActive bool      `json:"profile_update_active"`
+	MessagingActive     bool      `json:"messaging_active"`
+	ResultsReleased     bool      `json:"results_released"`
+	SurveyCloseDate     time.Time `json:"survey_close_date"`
+	ResultsReleaseDate  time.Time `json:"results_release_date"`
+	ServerTime          time.Time `json:"server_time"`
+}
+
+// GetCampaignStatus computes the phase of a campaign at now. A nil campaign
+// means nothing is running.
+func GetCampaignStatus(campaign *entities.Campaign, now time.Time) *CampaignStatus {
+	if campaign == nil {
+		return &CampaignStatus{ServerTime: now}
+	}
+
+	// Profile updates and messaging share the same window
+	profileActive := false
+	if campaign.ProfileUpdateStartDate != nil && campaign.ProfileUpdateEndDate != nil {
+		profileActive = now.After(*campaign.ProfileUpdateStartDate) && now.Before(*campaign.ProfileUpdateEndDate)
+	}
+
+	return &CampaignStatus{
+		CampaignID:          campaign.ID,
+		CampaignName:        campaign.Name,
+		SurveyActive:        now.After(campaign.SurveyOpenDate) && now.Before(campaign.SurveyCloseDate),
+		ProfileUpdateActive: profileActive,
+		MessagingActive:     profileActive,
+		ResultsReleased:     now.After(campaign.ResultsReleaseDate),
+		SurveyCloseDate:     campaign.SurveyCloseDate,
+		ResultsReleaseDate:  campaign.ResultsReleaseDate,
+		ServerTime:          now,
+	}
+}
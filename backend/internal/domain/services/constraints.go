@@ -0,0 +1,268 @@
+package services
+
+import (
+	"errors"
+	"strings"
+
+	"wizard-connect/internal/domain/entities"
+)
+
+// ConstraintsKey is the reserved response key holding a respondent's hard
+// filters on profile fields, e.g. {"year_range": 1, "same_major": true}
+const ConstraintsKey = "_constraints"
+
+// MaxYearRange is the widest year_range a respondent can set
+const MaxYearRange = 5
+
+var ErrInvalidConstraints = errors.New("year_range must be between 0 and 5")
+
+// Participant is one candidate for matching: their survey for the campaign
+// and, when known, their profile
+type Participant struct {
+	Survey *entities.SurveyResponse
+	User   *entities.User
+}
+
+// Constraint is a hard filter evaluated before any scoring. Allows is
+// directional: it reports whether self accepts other.
+type Constraint interface {
+	Name() string
+	Allows(self, other *Participant) bool
+}
+
+// ConstraintSet is the list of filters a pairing has to pass in both directions
+type ConstraintSet []Constraint
+
+// CampaignConstraints builds the filters enabled by a campaign's config
+func CampaignConstraints(def *entities.SurveyDefinition, config MatchingConfig) ConstraintSet {
+	var set ConstraintSet
+	if config.Constraints.GenderPreference {
+		set = append(set, genderConstraint{})
+	}
+	if config.Constraints.ProfileFilters {
+		set = append(set, yearConstraint{}, majorConstraint{})
+	}
+	if config.QuestionScoring.Dealbreakers {
+		set = append(set, dealbreakerConstraint{def: def})
+	}
+	return set
+}
+
+// Check reports whether a and b may be matched, and if not the name of the
+// first constraint that rules the pairing out
+func (cs ConstraintSet) Check(a, b *Participant) (bool, string) {
+	for _, constraint := range cs {
+		if !constraint.Allows(a, b) || !constraint.Allows(b, a) {
+			return false, constraint.Name()
+		}
+	}
+	return true, ""
+}
+
+// EligiblePool counts the candidates self could be matched with
+func (cs ConstraintSet) EligiblePool(self *Participant, candidates []*Participant) int {
+	pool := 0
+	for _, other := range candidates {
+		if other.Survey.UserID == self.Survey.UserID {
+			continue
+		}
+		if ok, _ := cs.Check(self, other); ok {
+			pool++
+		}
+	}
+	return pool
+}
+
+// ProfileConstraints are the filters a respondent set on profile fields
+type ProfileConstraints struct {
+	// YearRange allows candidates at most this many years apart; nil means any year
+	YearRange *int `json:"year_range,omitempty"`
+	SameMajor bool `json:"same_major,omitempty"`
+}
+
+// ConstraintsFromResponses reads the respondent's profile filters
+func ConstraintsFromResponses(responses map[string]interface{}) ProfileConstraints {
+	var constraints ProfileConstraints
+	raw, ok := responses[ConstraintsKey].(map[string]interface{})
+	if !ok {
+		return constraints
+	}
+	if v, ok := raw["year_range"].(float64); ok && v >= 0 && v <= MaxYearRange {
+		yearRange := int(v)
+		constraints.YearRange = &yearRange
+	}
+	if v, ok := raw["same_major"].(bool); ok {
+		constraints.SameMajor = v
+	}
+	return constraints
+}
+
+// ValidateConstraints checks filters sent by a client
+func ValidateConstraints(constraints *ProfileConstraints) error {
+	if constraints == nil || constraints.YearRange == nil {
+		return nil
+	}
+	if *constraints.YearRange < 0 || *constraints.YearRange > MaxYearRange {
+		return ErrInvalidConstraints
+	}
+	return nil
+}
+
+// ApplyConstraints stores a respondent's filters under ConstraintsKey,
+// removing the key when no filter is set
+func ApplyConstraints(responses map[string]interface{}, constraints ProfileConstraints) {
+	stored := make(map[string]interface{})
+	if constraints.YearRange != nil {
+		stored["year_range"] = float64(*constraints.YearRange)
+	}
+	if constraints.SameMajor {
+		stored["same_major"] = true
+	}
+	setPreference(responses, ConstraintsKey, stored)
+}
+
+type genderConstraint struct{}
+
+func (genderConstraint) Name() string { return "gender_preference" }
+
+// Allows checks other's gender against who self is looking for. Unknown
+// genders or preferences don't rule anyone out.
+func (genderConstraint) Allows(self, other *Participant) bool {
+	seeking := seekingGenders(self)
+	gender := participantGender(other)
+	if len(seeking) == 0 || gender == "" || gender == "prefer_not_say" {
+		return true
+	}
+	return seeking[gender]
+}
+
+func seekingGenders(p *Participant) map[string]bool {
+	seeking := make(map[string]bool)
+	for option := range answerSet(p.Survey.Responses["seeking_gender"]) {
+		seeking[normalizeGender(option)] = true
+	}
+	if len(seeking) > 0 || p.User == nil {
+		return seeking
+	}
+
+	switch pref := normalizeGender(p.User.GenderPreference); pref {
+	case "", "both", "any":
+		return seeking
+	default:
+		seeking[pref] = true
+	}
+	return seeking
+}
+
+func participantGender(p *Participant) string {
+	if gender, ok := answerString(p.Survey.Responses["gender"]); ok {
+		return normalizeGender(gender)
+	}
+	if p.User != nil {
+		return normalizeGender(p.User.Gender)
+	}
+	return ""
+}
+
+// normalizeGender maps profile spellings (non-binary, prefer_not_to_say)
+// onto the survey options
+func normalizeGender(gender string) string {
+	gender = strings.ReplaceAll(strings.ToLower(strings.TrimSpace(gender)), "-", "_")
+	if gender == "prefer_not_to_say" {
+		return "prefer_not_say"
+	}
+	return gender
+}
+
+type yearConstraint struct{}
+
+func (yearConstraint) Name() string { return "year_range" }
+
+func (yearConstraint) Allows(self, other *Participant) bool {
+	constraints := ConstraintsFromResponses(self.Survey.Responses)
+	if constraints.YearRange == nil {
+		return true
+	}
+	selfYear, ok1 := participantYear(self)
+	otherYear, ok2 := participantYear(other)
+	if !ok1 || !ok2 {
+		return true
+	}
+	diff := selfYear - otherYear
+	if diff < 0 {
+		diff = -diff
+	}
+	return diff <= *constraints.YearRange
+}
+
+// participantYear reads the year level from the survey ("2nd_year") or
+// the profile ("2nd Year")
+func participantYear(p *Participant) (int, bool) {
+	year := ""
+	if p.Survey != nil {
+		year, _ = answerString(p.Survey.Responses["year"])
+	}
+	if year == "" && p.User != nil {
+		year = p.User.Year
+	}
+	return YearLevel(year)
+}
+
+// YearLevel turns a year answer ("2nd_year", "Sophomore (2nd Year)") into
+// its number; super seniors count as year 5 and graduate students as 6
+func YearLevel(year string) (int, bool) {
+	year = strings.ToLower(year)
+	if strings.HasPrefix(year, "grad") {
+		return 6, true
+	}
+	if strings.HasPrefix(year, "super senior") {
+		return 5, true
+	}
+	for _, r := range year {
+		if r >= '1' && r <= '9' {
+			return int(r - '0'), true
+		}
+	}
+	return 0, false
+}
+
+type majorConstraint struct{}
+
+func (majorConstraint) Name() string { return "same_major" }
+
+func (majorConstraint) Allows(self, other *Participant) bool {
+	if !ConstraintsFromResponses(self.Survey.Responses).SameMajor {
+		return true
+	}
+	selfMajor, otherMajor := participantMajor(self), participantMajor(other)
+	if selfMajor == "" || otherMajor == "" {
+		return true
+	}
+	return selfMajor == otherMajor
+}
+
+func participantMajor(p *Participant) string {
+	if major, ok := answerString(p.Survey.Responses["major"]); ok {
+		return strings.ToLower(major)
+	}
+	if p.User != nil {
+		return strings.ToLower(strings.TrimSpace(p.User.Major))
+	}
+	return ""
+}
+
+type dealbreakerConstraint struct {
+	def *entities.SurveyDefinition
+}
+
+func (dealbreakerConstraint) Name() string { return "dealbreaker" }
+
+func (c dealbreakerConstraint) Allows(self, other *Participant) bool {
+	return !HasDealbreaker(c.def, self.Survey, other.Survey)
+}
+
+// GenderPreferenceConstraint returns the gender filter on its own, for
+// auditing runs that didn't apply it
+func GenderPreferenceConstraint() Constraint {
+	return genderConstraint{}
+}
@@ -0,0 +1,574 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"wizard-connect/internal/domain/entities"
+)
+
+// Pieces of user text the content policy reviews
+const (
+	ContentFieldMessage   = "message"
+	ContentFieldBio       = "bio"
+	ContentFieldFirstName = "first_name"
+	ContentFieldLastName  = "last_name"
+	ContentFieldMajor     = "major"
+)
+
+// Content policy rules
+const (
+	ContentRuleLength    = "length"    // longer than the field's maximum
+	ContentRuleProfanity = "profanity" // a word on the profanity list
+	ContentRuleSlur      = "slur"      // a word on the slur list
+	ContentRuleLink      = "link"      // a URL, domain or email address
+	ContentRulePhone     = "phone"     // a phone number
+)
+
+// What the policy does with text that breaks a rule
+const (
+	ContentActionReject = "reject" // refuse the text
+	ContentActionMask   = "mask"   // star out the offending part and keep the rest
+	ContentActionFlag   = "flag"   // keep the text and queue it for review
+)
+
+// ContentPolicy decides what happens to a piece of user text. field is one
+// of the ContentField constants.
+type ContentPolicy interface {
+	Review(field, text string) ContentVerdict
+}
+
+// ContentViolation is one rule the text broke and what the policy does about it
+type ContentViolation struct {
+	Rule   string `json:"rule"`
+	Action string `json:"action"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// ContentVerdict is the outcome of a review. Text is what may be stored:
+// the original text with any masking applied.
+type ContentVerdict struct {
+	Field      string
+	Text       string
+	Violations []ContentViolation
+}
+
+// Rejected reports whether the text must be refused
+func (v ContentVerdict) Rejected() bool {
+	return len(v.rules(ContentActionReject)) > 0
+}
+
+// Flagged reports whether the text should be queued for review
+func (v ContentVerdict) Flagged() bool {
+	return len(v.rules(ContentActionFlag)) > 0
+}
+
+// Reason says why rejected text was refused, phrased to follow the field's
+// name: "Bio can't contain phone numbers"
+func (v ContentVerdict) Reason() string {
+	for _, violation := range v.Violations {
+		if violation.Action != ContentActionReject {
+			continue
+		}
+		switch violation.Rule {
+		case ContentRuleLength:
+			return fmt.Sprintf("is longer than %s characters", violation.Detail)
+		case ContentRuleLink:
+			return "can't contain links or email addresses"
+		case ContentRulePhone:
+			return "can't contain phone numbers"
+		default:
+			return "contains language that isn't allowed"
+		}
+	}
+	return ""
+}
+
+// rules lists the rules broken with the given action
+func (v ContentVerdict) rules(action string) []string {
+	var rules []string
+	for _, violation := range v.Violations {
+		if violation.Action == action {
+			rules = append(rules, violation.Rule)
+		}
+	}
+	return rules
+}
+
+// ContentPolicyConfig is the setup of LocalContentPolicy, read from the
+// file named by CONTENT_POLICY_FILE. Keys that are missing fall back to
+// DefaultContentPolicyConfig.
+type ContentPolicyConfig struct {
+	// MaxLengths per field, in characters; text over the limit is rejected
+	MaxLengths map[string]int `json:"max_lengths"`
+	Profanity  []string       `json:"profanity"`
+	Slurs      []string       `json:"slurs"`
+	// Actions per field and rule, e.g. {"bio": {"phone": "mask"}}. A rule
+	// without an action isn't checked on that field.
+	Actions map[string]map[string]string `json:"actions"`
+}
+
+// DefaultContentPolicyConfig masks profanity in messages and bios, keeps
+// links and phone numbers out of bios (contact details are shared through
+// the contact preference once matched) and refuses names that aren't clean.
+// The slur list ships empty; deployments supply their own.
+func DefaultContentPolicyConfig() ContentPolicyConfig {
+	return ContentPolicyConfig{
+		MaxLengths: map[string]int{
+			ContentFieldMessage:   2000,
+			ContentFieldBio:       500,
+			ContentFieldFirstName: 50,
+			ContentFieldLastName:  50,
+			ContentFieldMajor:     100,
+		},
+		Profanity: []string{
+			"asshole", "bastard", "bitch", "bullshit", "cunt", "dick", "fuck",
+			"fucker", "motherfucker", "pussy", "shit", "slut", "whore",
+		},
+		Actions: map[string]map[string]string{
+			ContentFieldMessage: {
+				ContentRuleProfanity: ContentActionMask,
+				ContentRuleSlur:      ContentActionReject,
+			},
+			ContentFieldBio: {
+				ContentRuleProfanity: ContentActionMask,
+				ContentRuleSlur:      ContentActionReject,
+				ContentRuleLink:      ContentActionMask,
+				ContentRulePhone:     ContentActionMask,
+			},
+			ContentFieldFirstName: {
+				ContentRuleProfanity: ContentActionReject,
+				ContentRuleSlur:      ContentActionReject,
+			},
+			ContentFieldLastName: {
+				ContentRuleProfanity: ContentActionReject,
+				ContentRuleSlur:      ContentActionReject,
+			},
+			ContentFieldMajor: {
+				ContentRuleProfanity: ContentActionReject,
+				ContentRuleSlur:      ContentActionReject,
+			},
+		},
+	}
+}
+
+// LoadContentPolicyConfig reads a policy file over the defaults. An empty
+// path gives the defaults.
+func LoadContentPolicyConfig(path string) (ContentPolicyConfig, error) {
+	if path == "" {
+		return DefaultContentPolicyConfig(), nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ContentPolicyConfig{}, fmt.Errorf("failed to read content policy: %w", err)
+	}
+	return ParseContentPolicyConfig(data)
+}
+
+// ParseContentPolicyConfig overlays a JSON policy onto the defaults. Word
+// lists replace the default lists, and a field's actions replace that
+// field's default actions.
+func ParseContentPolicyConfig(data []byte) (ContentPolicyConfig, error) {
+	config := DefaultContentPolicyConfig()
+
+	var parsed ContentPolicyConfig
+	if err := json.Unmarshal(data, &parsed); err != nil {
+		return config, fmt.Errorf("invalid content policy: %w", err)
+	}
+
+	for field, max := range parsed.MaxLengths {
+		config.MaxLengths[field] = max
+	}
+	if parsed.Profanity != nil {
+		config.Profanity = parsed.Profanity
+	}
+	if parsed.Slurs != nil {
+		config.Slurs = parsed.Slurs
+	}
+	for field, actions := range parsed.Actions {
+		for rule, action := range actions {
+			switch action {
+			case ContentActionReject, ContentActionMask, ContentActionFlag:
+			default:
+				return config, fmt.Errorf("invalid content policy: unknown action %q for %s %s", action, field, rule)
+			}
+		}
+		config.Actions[field] = actions
+	}
+
+	return config, nil
+}
+
+// LocalContentPolicy checks text against word lists and patterns in
+// process, without calling out to any external service
+type LocalContentPolicy struct {
+	config    ContentPolicyConfig
+	profanity wordList
+	slurs     wordList
+}
+
+// NewLocalContentPolicy builds the policy from its config
+func NewLocalContentPolicy(config ContentPolicyConfig) *LocalContentPolicy {
+	return &LocalContentPolicy{
+		config:    config,
+		profanity: newWordList(config.Profanity),
+		slurs:     newWordList(config.Slurs),
+	}
+}
+
+var (
+	// URLs, bare domains (including "example (dot) com") and email addresses
+	linkPattern = regexp.MustCompile(`(?i)[a-z0-9._%+-]+@[a-z0-9.-]+\.[a-z]{2,}|\b(?:https?://|www\.)\S+|\b[a-z0-9-]+(?:\.[a-z0-9-]+)*(?:\.|\s*[\[(]dot[\])]\s*)(?:com|net|org|io|co|ly|gg|app|dev|xyz|link|info|biz|site|page|tv|edu|ph)\b(?:/\S*)?`)
+	// Digit groups with optional separators; matches need 7 to 15 digits
+	phonePattern = regexp.MustCompile(`\+?\(?\d{1,4}\)?(?:[ .-]?\(?\d{2,5}\)?){1,4}`)
+	// "2024-2028" and the like are class years, not phone numbers
+	yearRangePattern = regexp.MustCompile(`^(?:19|20)\d{2}\s?-\s?(?:19|20)\d{2}$`)
+)
+
+// Review applies the rules configured for field. Overlong text is rejected
+// outright; otherwise every rule is checked so that masking covers all of
+// the offending parts.
+func (p *LocalContentPolicy) Review(field, text string) ContentVerdict {
+	verdict := ContentVerdict{Field: field, Text: text}
+
+	if max := p.config.MaxLengths[field]; max > 0 && utf8.RuneCountInString(text) > max {
+		verdict.Violations = append(verdict.Violations, ContentViolation{
+			Rule:   ContentRuleLength,
+			Action: ContentActionReject,
+			Detail: strconv.Itoa(max),
+		})
+		return verdict
+	}
+
+	actions := p.config.Actions[field]
+	runes := []rune(text)
+	masked := make([]bool, len(runes))
+	apply := func(rule string, find func([]rune) [][2]int) {
+		action := actions[rule]
+		if action == "" {
+			return
+		}
+		spans := find(runes)
+		if len(spans) == 0 {
+			return
+		}
+		verdict.Violations = append(verdict.Violations, ContentViolation{Rule: rule, Action: action})
+		if action != ContentActionMask {
+			return
+		}
+		for _, span := range spans {
+			for i := span[0]; i < span[1]; i++ {
+				masked[i] = true
+			}
+		}
+	}
+
+	apply(ContentRuleProfanity, p.profanity.find)
+	apply(ContentRuleSlur, p.slurs.find)
+	apply(ContentRuleLink, func(runes []rune) [][2]int {
+		return findPattern(runes, linkPattern, nil)
+	})
+	apply(ContentRulePhone, func(runes []rune) [][2]int {
+		return findPattern(runes, phonePattern, isPhoneNumber)
+	})
+
+	for i, r := range runes {
+		if masked[i] && !unicode.IsSpace(r) {
+			runes[i] = '*'
+		}
+	}
+	verdict.Text = string(runes)
+
+	return verdict
+}
+
+// findPattern returns the rune spans of the pattern's matches that keep passes
+func findPattern(runes []rune, pattern *regexp.Regexp, keep func(string) bool) [][2]int {
+	text := string(runes)
+	var spans [][2]int
+	for _, loc := range pattern.FindAllStringIndex(text, -1) {
+		if keep != nil && !keep(text[loc[0]:loc[1]]) {
+			continue
+		}
+		start := utf8.RuneCountInString(text[:loc[0]])
+		spans = append(spans, [2]int{start, start + utf8.RuneCountInString(text[loc[0]:loc[1]])})
+	}
+	return spans
+}
+
+// isPhoneNumber reports whether a digit run looks like a phone number
+func isPhoneNumber(match string) bool {
+	digits := 0
+	for _, r := range match {
+		if unicode.IsDigit(r) {
+			digits++
+		}
+	}
+	return digits >= 7 && digits <= 15 && !yearRangePattern.MatchString(strings.TrimSpace(match))
+}
+
+// leetspeak maps the characters used to dodge word lists back to letters
+var leetspeak = map[rune]rune{
+	'0': 'o', '1': 'i', '3': 'e', '4': 'a', '5': 's', '7': 't', '8': 'b', '9': 'g',
+	'@': 'a', '$': 's', '!': 'i', '|': 'i', '+': 't', '€': 'e',
+}
+
+// wordList matches whole words against a list. Words are compared after
+// lowercasing and undoing leetspeak, with repeated letters ("fuuuck") and
+// common suffixes ("-ing", "-s") allowed, and letters spelled out one at a
+// time ("f u c k", "f.u.c.k") are joined back up. Words are never matched
+// inside longer words, so "Scunthorpe" and "class" pass.
+type wordList struct {
+	exact     map[string]bool
+	collapsed map[string]bool
+	longest   int
+}
+
+func newWordList(words []string) wordList {
+	list := wordList{exact: map[string]bool{}, collapsed: map[string]bool{}}
+	for _, word := range words {
+		word = normalizeWord([]rune(strings.TrimSpace(word)))
+		if word == "" {
+			continue
+		}
+		list.exact[word] = true
+		list.collapsed[collapseRepeats(word)] = true
+		if n := utf8.RuneCountInString(word); n > list.longest {
+			list.longest = n
+		}
+	}
+	return list
+}
+
+// suffixes a listed word may carry
+var wordSuffixes = []string{"s", "es", "ed", "er", "ers", "ing", "in"}
+
+// contains reports whether a normalized word is on the list
+func (l wordList) contains(word string) bool {
+	if l.matches(word) {
+		return true
+	}
+	for _, suffix := range wordSuffixes {
+		stem := strings.TrimSuffix(word, suffix)
+		if stem != word && utf8.RuneCountInString(stem) >= 3 && l.matches(stem) {
+			return true
+		}
+	}
+	return false
+}
+
+func (l wordList) matches(word string) bool {
+	if l.exact[word] {
+		return true
+	}
+	// Only words that had repeats may match by collapsing them, so that
+	// "as" doesn't match a listed "ass"
+	collapsed := collapseRepeats(word)
+	return collapsed != word && l.collapsed[collapsed]
+}
+
+// find returns the rune spans of listed words in the text
+func (l wordList) find(runes []rune) [][2]int {
+	if len(l.exact) == 0 {
+		return nil
+	}
+
+	tokens := tokenize(runes)
+	var spans [][2]int
+	for _, token := range tokens {
+		if token.end-token.start > 1 && l.contains(token.word) {
+			spans = append(spans, [2]int{token.start, token.end})
+		}
+	}
+
+	// Runs of single letters: "f u c k"
+	for i := 0; i < len(tokens); {
+		j := i
+		for j < len(tokens) && tokens[j].end-tokens[j].start == 1 &&
+			(j == i || spacedLetters(runes[tokens[j-1].end:tokens[j].start])) {
+			j++
+		}
+		if j-i < 2 {
+			i++
+			continue
+		}
+		for a := i; a < j; a++ {
+			word := ""
+			for b := a; b < j && b-a < l.longest+3; b++ {
+				word += tokens[b].word
+				if b > a && l.contains(word) {
+					for k := a; k <= b; k++ {
+						spans = append(spans, [2]int{tokens[k].start, tokens[k].end})
+					}
+				}
+			}
+		}
+		i = j
+	}
+
+	return spans
+}
+
+// spacedLetters reports whether the gap between two single letters is a
+// short separator, as in "f u c k" or "f.u.c.k"
+func spacedLetters(gap []rune) bool {
+	if len(gap) == 0 || len(gap) > 2 {
+		return false
+	}
+	for _, r := range gap {
+		if r == '\n' || unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// contentToken is a word of the text: its rune span and normalized form
+type contentToken struct {
+	start, end int
+	word       string
+}
+
+// tokenize splits text into words of letters, digits and leetspeak
+// symbols. Tokens without a letter, like numbers, are dropped.
+func tokenize(runes []rune) []contentToken {
+	var tokens []contentToken
+	for i := 0; i < len(runes); {
+		if !isWordRune(runes[i]) {
+			i++
+			continue
+		}
+		start := i
+		for i < len(runes) && isWordRune(runes[i]) {
+			i++
+		}
+		end := i
+
+		// "!" and friends only stand in for letters inside a word
+		for start < end && isTrailingSymbol(runes[start]) {
+			start++
+		}
+		for end > start && isTrailingSymbol(runes[end-1]) {
+			end--
+		}
+		if start == end || !hasLetter(runes[start:end]) {
+			continue
+		}
+		tokens = append(tokens, contentToken{start: start, end: end, word: normalizeWord(runes[start:end])})
+	}
+	return tokens
+}
+
+func isWordRune(r rune) bool {
+	_, leet := leetspeak[r]
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || leet
+}
+
+func isTrailingSymbol(r rune) bool {
+	return r == '!' || r == '|' || r == '+'
+}
+
+func hasLetter(runes []rune) bool {
+	for _, r := range runes {
+		if unicode.IsLetter(r) {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeWord lowercases a word and undoes leetspeak
+func normalizeWord(runes []rune) string {
+	var b strings.Builder
+	for _, r := range runes {
+		r = unicode.ToLower(r)
+		if letter, ok := leetspeak[r]; ok {
+			r = letter
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// collapseRepeats squeezes runs of the same letter: "fuuuck" -> "fuck"
+func collapseRepeats(word string) string {
+	var b strings.Builder
+	var last rune
+	for i, r := range word {
+		if i > 0 && r == last {
+			continue
+		}
+		b.WriteRune(r)
+		last = r
+	}
+	return b.String()
+}
+
+// ReportCreator files reports in the moderation queue
+type ReportCreator interface {
+	Create(ctx context.Context, report *entities.Report) error
+}
+
+// ContentService applies the content policy to user text and queues text
+// the policy flags for the moderators
+type ContentService struct {
+	policy  ContentPolicy
+	reports ReportCreator
+}
+
+// NewContentService uses policy to review text and reports to file flags
+func NewContentService(policy ContentPolicy, reports ReportCreator) *ContentService {
+	return &ContentService{policy: policy, reports: reports}
+}
+
+// Review runs the policy over one piece of text
+func (s *ContentService) Review(field, text string) ContentVerdict {
+	if s == nil || s.policy == nil {
+		return ContentVerdict{Field: field, Text: text}
+	}
+	return s.policy.Review(field, text)
+}
+
+// FlagForReview files an automatic report about stored content when its
+// verdict flagged it. contentType and contentID say what was flagged, e.g.
+// a message and its ID or a bio and its owner's ID.
+func (s *ContentService) FlagForReview(ctx context.Context, userID, contentType, contentID string, verdict ContentVerdict, evidence ...entities.ReportEvidence) error {
+	if s == nil || s.reports == nil || !verdict.Flagged() {
+		return nil
+	}
+
+	rules := verdict.rules(ContentActionFlag)
+	report := &entities.Report{
+		ReportedUserID: userID,
+		Source:         entities.ReportSourceAuto,
+		Category:       flagCategory(rules),
+		Description:    fmt.Sprintf("Content policy flagged %s (%s): %q", verdict.Field, strings.Join(rules, ", "), verdict.Text),
+		ContentType:    contentType,
+		ContentID:      contentID,
+		Evidence:       append([]entities.ReportEvidence{}, evidence...),
+		Status:         entities.ReportStatusOpen,
+	}
+	return s.reports.Create(ctx, report)
+}
+
+// flagCategory files slurs as harassment, other language as inappropriate
+// and contact details as spam
+func flagCategory(rules []string) string {
+	category := entities.ReportCategorySpam
+	for _, rule := range rules {
+		switch rule {
+		case ContentRuleSlur:
+			return entities.ReportCategoryHarassment
+		case ContentRuleProfanity:
+			category = entities.ReportCategoryInappropriate
+		}
+	}
+	return category
+}
@@ -0,0 +1,250 @@
+package services
+
+import (
+	"context"
+	"math/rand"
+	"sort"
+
+	"wizard-connect/internal/domain/entities"
+)
+
+// FairnessConfig spreads matches across a campaign so a few popular
+// participants don't fill everyone's list. Zero values turn a control off.
+type FairnessConfig struct {
+	// MaxAppearances caps how many lists any one participant appears in.
+	// Mutual crushes are always matched and don't count against the cap.
+	MaxAppearances int `json:"max_appearances"`
+	// MinMatches is how many matches every participant gets where their
+	// candidates allow it, even if that means going over MaxAppearances
+	MinMatches int `json:"min_matches"`
+	// ExposurePenalty is subtracted from a candidate's score, in points, for
+	// every list they already appear in
+	ExposurePenalty float64 `json:"exposure_penalty"`
+}
+
+// CampaignRun is the outcome of matching every participant of a campaign
+type CampaignRun struct {
+	Participants int
+	Matches      []*entities.Match
+	Fairness     entities.FairnessReport
+	Manifest     entities.RunManifest
+}
+
+// RunCampaign generates the match lists of every participant at once,
+// applying the campaign's fairness controls, and reports how the controls
+// changed the spread of matches compared to plain greedy ranking. The same
+// inputs, config and seed always give the same matches.
+func (s *matchingService) RunCampaign(ctx context.Context, campaign *entities.Campaign, seed int64) (*CampaignRun, error) {
+	pool, err := s.loadPool(ctx, campaign, seed)
+	if err != nil {
+		return nil, err
+	}
+
+	order := make([]string, 0, len(pool.participants))
+	lists := make(map[string][]matchCandidate, len(pool.participants))
+	for _, p := range pool.participants {
+		order = append(order, p.Survey.UserID)
+		lists[p.Survey.UserID] = pool.rankCandidates(p)
+	}
+
+	fair, report := pool.allocate(lists, order)
+	run := &CampaignRun{
+		Participants: len(order),
+		Fairness:     report,
+	}
+	for _, userID := range order {
+		for i, candidate := range fair[userID] {
+			run.Matches = append(run.Matches, candidate.match(userID, i+1))
+		}
+	}
+
+	run.Manifest, err = buildManifest(pool, campaign, run.Matches)
+	if err != nil {
+		return nil, err
+	}
+	return run, nil
+}
+
+// allocate picks every participant's matches from their ranked candidates
+// and reports how the fairness controls changed the spread compared to plain
+// greedy ranking
+func (pool *matchingPool) allocate(lists map[string][]matchCandidate, order []string) (map[string][]matchCandidate, entities.FairnessReport) {
+	limit := pool.config.NumMatches
+	fairness := pool.config.Fairness
+	greedy := greedyAllocation(lists, order, limit)
+	fair, overrides := pool.fairAllocation(lists, draftOrder(order, pool.seed), limit)
+	return fair, entities.FairnessReport{
+		Before:       exposureDistribution(greedy, order, fairness.MinMatches),
+		After:        exposureDistribution(fair, order, fairness.MinMatches),
+		CapOverrides: overrides,
+	}
+}
+
+// draftOrder shuffles the participants with the run's seed, so who picks
+// first in the fair allocation isn't decided by their user ID
+func draftOrder(order []string, seed int64) []string {
+	shuffled := append([]string(nil), order...)
+	rng := rand.New(rand.NewSource(seed))
+	rng.Shuffle(len(shuffled), func(i, j int) {
+		shuffled[i], shuffled[j] = shuffled[j], shuffled[i]
+	})
+	return shuffled
+}
+
+// greedyAllocation gives everyone their top candidates, ignoring how often
+// each candidate is picked
+func greedyAllocation(lists map[string][]matchCandidate, order []string, limit int) map[string][]matchCandidate {
+	allocation := make(map[string][]matchCandidate, len(order))
+	for _, userID := range order {
+		allocation[userID] = lists[userID][:min(limit, len(lists[userID]))]
+	}
+	return allocation
+}
+
+// fairAllocation fills lists one slot at a time in snake-draft rounds, so
+// everyone gets a first match before anyone gets a second. Each pick is the
+// best candidate after the exposure penalty who isn't at the appearance cap.
+// Participants still short of MinMatches afterwards are topped up past the
+// cap; the number of such picks is returned alongside the lists. Without
+// fairness controls this gives the same lists as greedyAllocation.
+func (pool *matchingPool) fairAllocation(lists map[string][]matchCandidate, order []string, limit int) (map[string][]matchCandidate, int) {
+	fairness := pool.config.Fairness
+	allocation := make(map[string][]matchCandidate, len(order))
+	appearances := make(map[string]int)
+	picked := make(map[string]map[string]bool, len(order))
+	for _, userID := range order {
+		picked[userID] = make(map[string]bool)
+	}
+
+	pick := func(userID string, enforceCap bool) bool {
+		best := -1
+		bestScore := 0.0
+		for i, candidate := range lists[userID] {
+			if picked[userID][candidate.userID] {
+				continue
+			}
+			if candidate.isMutual {
+				// Mutual crushes come first and are exempt from the controls
+				best = i
+				break
+			}
+			if enforceCap && fairness.MaxAppearances > 0 && appearances[candidate.userID] >= fairness.MaxAppearances {
+				continue
+			}
+			adjusted := candidate.score - fairness.ExposurePenalty*float64(appearances[candidate.userID])
+			if best < 0 || adjusted > bestScore {
+				best, bestScore = i, adjusted
+			}
+		}
+		if best < 0 {
+			return false
+		}
+
+		candidate := lists[userID][best]
+		picked[userID][candidate.userID] = true
+		appearances[candidate.userID]++
+		allocation[userID] = append(allocation[userID], candidate)
+		return true
+	}
+
+	round := make([]string, len(order))
+	for r := 0; r < limit; r++ {
+		// Alternate direction so the same people don't always pick first,
+		// and let those with the fewest matches go before the rest
+		copy(round, order)
+		if r%2 == 1 {
+			for i, j := 0, len(round)-1; i < j; i, j = i+1, j-1 {
+				round[i], round[j] = round[j], round[i]
+			}
+		}
+		sort.SliceStable(round, func(i, j int) bool {
+			return len(allocation[round[i]]) < len(allocation[round[j]])
+		})
+
+		progress := false
+		for _, userID := range round {
+			if len(allocation[userID]) < limit && pick(userID, true) {
+				progress = true
+			}
+		}
+		if !progress {
+			break
+		}
+	}
+
+	overrides := 0
+	minMatches := min(fairness.MinMatches, limit)
+	for _, userID := range order {
+		for len(allocation[userID]) < minMatches && pick(userID, false) {
+			overrides++
+		}
+	}
+
+	for _, userID := range order {
+		pool.sortCandidates(userID, allocation[userID])
+	}
+	return allocation, overrides
+}
+
+// exposureDistribution summarizes how often each participant appears in
+// other people's lists
+func exposureDistribution(allocation map[string][]matchCandidate, order []string, minMatches int) entities.ExposureDistribution {
+	appearances := make(map[string]int, len(order))
+	for _, userID := range order {
+		for _, candidate := range allocation[userID] {
+			appearances[candidate.userID]++
+		}
+	}
+
+	var dist entities.ExposureDistribution
+	values := make([]float64, 0, len(order))
+	total := 0
+	for _, userID := range order {
+		count := appearances[userID]
+		values = append(values, float64(count))
+		total += count
+		if count > dist.MaxAppearances {
+			dist.MaxAppearances = count
+		}
+		if count == 0 {
+			dist.NeverShown++
+		}
+		if len(allocation[userID]) == 0 {
+			dist.ZeroMatchUsers++
+		}
+		if len(allocation[userID]) < minMatches {
+			dist.BelowMinMatches++
+		}
+	}
+
+	dist.Histogram = make([]int, dist.MaxAppearances+1)
+	for _, userID := range order {
+		dist.Histogram[appearances[userID]]++
+	}
+	if len(order) > 0 {
+		dist.MeanAppearances = float64(total) / float64(len(order))
+	}
+	dist.Gini = Gini(values)
+	return dist
+}
+
+// Gini computes the Gini coefficient of non-negative values: 0 when they are
+// all equal, approaching 1 when one value holds everything
+func Gini(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+
+	total, weighted := 0.0, 0.0
+	for i, v := range sorted {
+		total += v
+		weighted += float64(i+1) * v
+	}
+	if total == 0 {
+		return 0
+	}
+	n := float64(len(sorted))
+	return (2*weighted)/(n*total) - (n+1)/n
+}
@@ -0,0 +1,162 @@
+package services
+
+import (
+	"sort"
+	"testing"
+)
+
+// rankedLists turns user ID -> candidate ID -> score into candidate lists
+// ordered the way rankCandidates leaves them
+func rankedLists(pool *matchingPool, scores map[string]map[string]float64) (map[string][]matchCandidate, []string) {
+	lists := make(map[string][]matchCandidate, len(scores))
+	order := make([]string, 0, len(scores))
+	for userID, candidates := range scores {
+		order = append(order, userID)
+		for candidateID, score := range candidates {
+			lists[userID] = append(lists[userID], matchCandidate{userID: candidateID, score: score})
+		}
+		pool.sortCandidates(userID, lists[userID])
+	}
+	sort.Strings(order)
+	return lists, order
+}
+
+func TestFairAllocation(t *testing.T) {
+	// Everyone but ada ranks her first
+	popular := map[string]map[string]float64{
+		"ada": {"bea": 80, "cal": 70, "dan": 60, "eve": 50},
+		"bea": {"ada": 90, "cal": 60, "dan": 55, "eve": 50},
+		"cal": {"ada": 90, "bea": 60, "dan": 55, "eve": 50},
+		"dan": {"ada": 90, "bea": 60, "cal": 55, "eve": 50},
+		"eve": {"ada": 90, "bea": 60, "cal": 55, "dan": 50},
+	}
+	// Only ada suits bea and cal, and she has no candidates of her own
+	scarce := map[string]map[string]float64{
+		"ada": {},
+		"bea": {"ada": 90},
+		"cal": {"ada": 90},
+	}
+	// bea and cal both rank ada first but are a close second for each other
+	contested := map[string]map[string]float64{
+		"ada": {},
+		"bea": {"ada": 80, "cal": 70},
+		"cal": {"ada": 80, "bea": 70},
+	}
+
+	tests := []struct {
+		name          string
+		scores        map[string]map[string]float64
+		limit         int
+		fairness      FairnessConfig
+		wantBeforeMax int
+		wantAfterMax  int
+		wantOverrides int
+		wantZeroAfter int
+		wantBelowMin  int
+	}{
+		{
+			name:          "no controls",
+			scores:        popular,
+			limit:         2,
+			wantBeforeMax: 4,
+			wantAfterMax:  4,
+		},
+		{
+			name:          "appearance cap holds",
+			scores:        popular,
+			limit:         2,
+			fairness:      FairnessConfig{MaxAppearances: 2},
+			wantBeforeMax: 4,
+			wantAfterMax:  2,
+		},
+		{
+			name:          "cap leaves a participant without matches",
+			scores:        scarce,
+			limit:         1,
+			fairness:      FairnessConfig{MaxAppearances: 1},
+			wantBeforeMax: 2,
+			wantAfterMax:  1,
+			wantZeroAfter: 2,
+		},
+		{
+			name:          "guaranteed minimum overrides the cap",
+			scores:        scarce,
+			limit:         1,
+			fairness:      FairnessConfig{MaxAppearances: 1, MinMatches: 1},
+			wantBeforeMax: 2,
+			wantAfterMax:  2,
+			wantOverrides: 1,
+			wantZeroAfter: 1,
+			wantBelowMin:  1,
+		},
+		{
+			name:          "exposure penalty spreads picks",
+			scores:        contested,
+			limit:         1,
+			fairness:      FairnessConfig{ExposurePenalty: 20},
+			wantBeforeMax: 2,
+			wantAfterMax:  1,
+			wantZeroAfter: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pool := &matchingPool{seed: 7}
+			pool.config.NumMatches = tt.limit
+			pool.config.Fairness = tt.fairness
+			lists, order := rankedLists(pool, tt.scores)
+
+			allocation, report := pool.allocate(lists, order)
+
+			if report.Before.MaxAppearances != tt.wantBeforeMax {
+				t.Errorf("Before.MaxAppearances = %d, want %d", report.Before.MaxAppearances, tt.wantBeforeMax)
+			}
+			if report.After.MaxAppearances != tt.wantAfterMax {
+				t.Errorf("After.MaxAppearances = %d, want %d", report.After.MaxAppearances, tt.wantAfterMax)
+			}
+			if report.CapOverrides != tt.wantOverrides {
+				t.Errorf("CapOverrides = %d, want %d", report.CapOverrides, tt.wantOverrides)
+			}
+			if report.After.ZeroMatchUsers != tt.wantZeroAfter {
+				t.Errorf("After.ZeroMatchUsers = %d, want %d", report.After.ZeroMatchUsers, tt.wantZeroAfter)
+			}
+			// Only ada, who has no candidates, can stay below the minimum
+			if report.After.BelowMinMatches != tt.wantBelowMin {
+				t.Errorf("After.BelowMinMatches = %d, want %d", report.After.BelowMinMatches, tt.wantBelowMin)
+			}
+			if report.After.Gini > report.Before.Gini {
+				t.Errorf("Gini rose from %.3f to %.3f", report.Before.Gini, report.After.Gini)
+			}
+			for _, userID := range order {
+				if len(allocation[userID]) > tt.limit {
+					t.Errorf("%s has %d matches, over the limit of %d", userID, len(allocation[userID]), tt.limit)
+				}
+			}
+		})
+	}
+}
+
+func TestExposurePenaltyChangesAllocation(t *testing.T) {
+	pool := &matchingPool{seed: 7}
+	pool.config.NumMatches = 1
+	lists, order := rankedLists(pool, map[string]map[string]float64{
+		"ada": {},
+		"bea": {"ada": 80, "cal": 70},
+		"cal": {"ada": 80, "bea": 70},
+	})
+
+	greedy, _ := pool.allocate(lists, order)
+	for _, userID := range []string{"bea", "cal"} {
+		if got := greedy[userID][0].userID; got != "ada" {
+			t.Errorf("without a penalty %s was matched with %s, want ada", userID, got)
+		}
+	}
+
+	pool.config.Fairness.ExposurePenalty = 20
+	fair, _ := pool.allocate(lists, order)
+	first, second := fair["bea"][0].userID, fair["cal"][0].userID
+	if (first == "ada") == (second == "ada") {
+		t.Errorf("with a penalty bea got %s and cal got %s; want exactly one of them matched with ada", first, second)
+	}
+}
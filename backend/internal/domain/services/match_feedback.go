@@ -0,0 +1,16 @@
+package services
+
+import (
+	"time"
+
+	"wizard-connect/internal/domain/entities"
+)
+
+// MessagingWindow is how long matched users get to talk before they are
+// asked how the match went
+const MessagingWindow = 7 * 24 * time.Hour
+
+// FeedbackOpensAt is when the user can first give feedback on a match
+func FeedbackOpensAt(match *entities.Match) time.Time {
+	return match.CreatedAt.Add(MessagingWindow)
+}
@@ -0,0 +1,97 @@
+package services
+
+import (
+	"context"
+	"sort"
+
+	"wizard-connect/internal/domain/entities"
+)
+
+// VetoRepository lists the pairs admins have ruled out
+type VetoRepository interface {
+	ListVetoes(ctx context.Context) ([]*entities.MatchVeto, error)
+}
+
+// VetoSet answers whether two users may never be matched, in either direction
+type VetoSet map[[2]string]bool
+
+func NewVetoSet(vetoes []*entities.MatchVeto) VetoSet {
+	set := make(VetoSet, len(vetoes))
+	for _, veto := range vetoes {
+		a, b := entities.OrderedPair(veto.UserAID, veto.UserBID)
+		set[[2]string{a, b}] = true
+	}
+	return set
+}
+
+func (s VetoSet) Has(userID, otherID string) bool {
+	a, b := entities.OrderedPair(userID, otherID)
+	return s[[2]string{a, b}]
+}
+
+// StageRun turns a run's matches into a campaign's staged matches. Pinned
+// matches already staged survive the run and stay at the top of their
+// owner's list; the algorithm's picks for the same pairs are dropped.
+func StageRun(campaignID, runID string, matches []*entities.Match, existing []*entities.StagedMatch) []*entities.StagedMatch {
+	var staged []*entities.StagedMatch
+	pinned := make(map[[2]string]bool)
+	for _, m := range existing {
+		if m.Pinned {
+			staged = append(staged, m)
+			pinned[[2]string{m.UserID, m.MatchedUserID}] = true
+		}
+	}
+
+	for _, m := range matches {
+		if pinned[[2]string{m.UserID, m.MatchedUserID}] {
+			continue
+		}
+		staged = append(staged, &entities.StagedMatch{
+			CampaignID:         campaignID,
+			RunID:              runID,
+			UserID:             m.UserID,
+			MatchedUserID:      m.MatchedUserID,
+			CompatibilityScore: m.CompatibilityScore,
+			ScoreFromUser:      m.ScoreFromUser,
+			ScoreFromMatched:   m.ScoreFromMatched,
+			Rank:               m.Rank,
+			IsMutualCrush:      m.IsMutualCrush,
+			Source:             entities.MatchSourceAlgorithm,
+		})
+	}
+
+	RerankStaged(staged)
+	return staged
+}
+
+// RerankStaged numbers every user's staged list from 1 again: pinned matches
+// first, then the rest in their current order. It returns the matches whose
+// rank changed.
+func RerankStaged(staged []*entities.StagedMatch) []*entities.StagedMatch {
+	lists := make(map[string][]*entities.StagedMatch)
+	var owners []string
+	for _, m := range staged {
+		if _, ok := lists[m.UserID]; !ok {
+			owners = append(owners, m.UserID)
+		}
+		lists[m.UserID] = append(lists[m.UserID], m)
+	}
+
+	var changed []*entities.StagedMatch
+	for _, owner := range owners {
+		list := lists[owner]
+		sort.SliceStable(list, func(i, j int) bool {
+			if list[i].Pinned != list[j].Pinned {
+				return list[i].Pinned
+			}
+			return list[i].Rank < list[j].Rank
+		})
+		for i, m := range list {
+			if m.Rank != i+1 {
+				m.Rank = i + 1
+				changed = append(changed, m)
+			}
+		}
+	}
+	return changed
+}
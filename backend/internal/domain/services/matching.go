@@ -4,8 +4,11 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"hash/fnv"
+	"log"
 	"math"
 	"sort"
+	"time"
 
 	"wizard-connect/internal/domain/entities"
 )
@@ -14,7 +17,7 @@ import (
 
 // Repository interfaces needed by the service
 type SurveyRepository interface {
-	GetCompletedSurveys(ctx context.Context) ([]*entities.SurveyResponse, error)
+	GetCompletedSurveysForCampaign(ctx context.Context, campaignID, definitionVersion string) ([]*entities.SurveyResponse, error)
 }
 
 type CrushRepository interface {
@@ -34,15 +37,23 @@ type UserRepository interface {
 // MatchingService handles compatibility calculations and match generation
 type MatchingService interface {
 	CalculateCompatibility(ctx context.Context, user1, user2 *entities.SurveyResponse) (float64, error)
-	GenerateMatches(ctx context.Context, userID string, limit int) ([]*entities.Match, error)
+	EligiblePool(ctx context.Context, campaign *entities.Campaign, survey *entities.SurveyResponse) (int, error)
+	EligiblePoolSizes(ctx context.Context, campaign *entities.Campaign) (map[string]int, error)
+	RunCampaign(ctx context.Context, campaign *entities.Campaign, seed int64) (*CampaignRun, error)
+	ReplayRun(ctx context.Context, campaign *entities.Campaign, manifest entities.RunManifest) (*CampaignRun, error)
 	MatchRepo() MatchRepository
 }
 
 type matchingService struct {
-	surveyRepo SurveyRepository
-	crushRepo  CrushRepository
-	matchRepo  MatchRepository
-	userRepo   UserRepository
+	surveyRepo  SurveyRepository
+	crushRepo   CrushRepository
+	matchRepo   MatchRepository
+	userRepo    UserRepository
+	vetoRepo    VetoRepository
+	blockRepo   BlockRepository
+	suspensions SuspensionRepository
+	// logger gets a line per candidate considered; nil keeps runs quiet
+	logger *log.Logger
 }
 
 func NewMatchingService(
@@ -50,12 +61,26 @@ func NewMatchingService(
 	crushRepo CrushRepository,
 	matchRepo MatchRepository,
 	userRepo UserRepository,
+	vetoRepo VetoRepository,
+	blockRepo BlockRepository,
+	suspensions SuspensionRepository,
+	logger *log.Logger,
 ) MatchingService {
 	return &matchingService{
-		surveyRepo: surveyRepo,
-		crushRepo:  crushRepo,
-		matchRepo:  matchRepo,
-		userRepo:   userRepo,
+		surveyRepo:  surveyRepo,
+		crushRepo:   crushRepo,
+		matchRepo:   matchRepo,
+		userRepo:    userRepo,
+		vetoRepo:    vetoRepo,
+		blockRepo:   blockRepo,
+		suspensions: suspensions,
+		logger:      logger,
+	}
+}
+
+func (s *matchingService) debugf(format string, args ...interface{}) {
+	if s.logger != nil {
+		s.logger.Printf("DEBUG: "+format, args...)
 	}
 }
 
@@ -63,65 +88,39 @@ func (s *matchingService) MatchRepo() MatchRepository {
 	return s.matchRepo
 }
 
-// CalculateCompatibility computes a compatibility score (0-100) between two users
+// CalculateCompatibility computes the reciprocal compatibility score (0-100)
+// of two users with the default matching config; it is symmetric
 func (s *matchingService) CalculateCompatibility(ctx context.Context, user1, user2 *entities.SurveyResponse) (float64, error) {
-	score := 0.0
-	weights := map[string]float64{
-		"personality": 0.30,
-		"interests":   0.20,
-		"values":      0.25,
-		"lifestyle":   0.15,
-		"demographic": 0.10,
+	def, err := GetSurveyDefinition(user1.DefinitionVersion)
+	if err != nil {
+		return 0, err
 	}
+	return ScoreReciprocal(def, DefaultMatchingConfig(), user1, user2).Score, nil
+}
 
-	// Personality compatibility (30%)
-	personalityScore := s.calculatePersonalityMatch(user1.PersonalityType, user2.PersonalityType)
-	score += personalityScore * weights["personality"]
-
-	// Interests overlap (20%)
-	interestsScore := s.calculateInterestsOverlap(user1.Interests, user2.Interests)
-	score += interestsScore * weights["interests"]
-
-	// Values alignment (25%)
-	valuesScore := s.calculateValuesAlignment(user1.Values, user2.Values)
-	score += valuesScore * weights["values"]
-
-	// Lifestyle compatibility (15%)
-	lifestyleScore := s.calculateLifestyleMatch(user1.Lifestyle, user2.Lifestyle)
-	score += lifestyleScore * weights["lifestyle"]
-
-	// Demographic compatibility (10%)
-	// Default base
-	demographicScore := 70.0
-	score += demographicScore * weights["demographic"]
+// personalityMatch prefers trait vectors derived from the raw answers
+// and falls back to the declared type for surveys that predate them
+func personalityMatch(user1, user2 *entities.SurveyResponse) float64 {
+	traits1, ok1 := surveyTraits(user1)
+	traits2, ok2 := surveyTraits(user2)
+	if ok1 && ok2 {
+		return TraitCompatibility(traits1, traits2)
+	}
 
-	return math.Min(score, 100.0), nil
+	if score, ok := TypeCompatibility(user1.PersonalityType, user2.PersonalityType); ok {
+		return score
+	}
+	return 60.0
 }
 
-func (s *matchingService) calculatePersonalityMatch(type1, type2 string) float64 {
-	// Simplified personality matching
-	compatibleTypes := map[string][]string{
-		"INTJ": {"INTJ", "INTP", "INFJ", "ENTJ"},
-		"INTP": {"INTP", "INTJ", "ENTP", "INFP"},
-		"INFJ": {"INFJ", "INTJ", "INFP", "ENFJ"},
-		"INFP": {"INFP", "INFJ", "INTP", "ENFP"},
-		"ENTJ": {"ENTJ", "INTJ", "ENTP", "ESTJ"},
-		"ENTP": {"ENTP", "INTP", "ENTJ", "ESTP"},
-		"ENFJ": {"ENFJ", "INFJ", "ENFP", "ESFJ"},
-		"ENFP": {"ENFP", "INFP", "ENFJ", "ENTP"},
-	}
-
-	if types, ok := compatibleTypes[type1]; ok {
-		for _, t := range types {
-			if t == type2 {
-				return 85.0
-			}
-		}
+func surveyTraits(survey *entities.SurveyResponse) (PersonalityTraits, bool) {
+	if traits, ok := TraitsFromSlice(survey.PersonalityTraits); ok {
+		return traits, true
 	}
-	return 60.0
+	return DeriveTraits(survey.Responses)
 }
 
-func (s *matchingService) calculateInterestsOverlap(interests1, interests2 []string) float64 {
+func calculateInterestsOverlap(interests1, interests2 []string) float64 {
 	if len(interests1) == 0 || len(interests2) == 0 {
 		return 50.0
 	}
@@ -147,7 +146,7 @@ func (s *matchingService) calculateInterestsOverlap(interests1, interests2 []str
 	return math.Min(percentage+40, 100.0) // Base 40 + overlap percentage
 }
 
-func (s *matchingService) calculateValuesAlignment(values1, values2 []string) float64 {
+func calculateValuesAlignment(values1, values2 []string) float64 {
 	if len(values1) == 0 || len(values2) == 0 {
 		return 50.0
 	}
@@ -168,143 +167,283 @@ func (s *matchingService) calculateValuesAlignment(values1, values2 []string) fl
 	return math.Min(percentage+30, 100.0)
 }
 
-func (s *matchingService) calculateLifestyle(lifestyle1, lifestyle2 string) float64 {
-	// Simplified lifestyle matching
+func calculateLifestyleMatch(lifestyle1, lifestyle2 string) float64 {
 	if lifestyle1 == lifestyle2 {
 		return 90.0
 	}
 	return 60.0
 }
 
-func (s *matchingService) calculateLifestyleMatch(lifestyle1, lifestyle2 string) float64 {
-	if lifestyle1 == lifestyle2 {
-		return 90.0
-	}
-	return 60.0
+// matchCandidate is one person a participant could be matched with. score
+// is the reciprocal score with crush bonuses; scoreFrom and scoreTo are the
+// directional scores from the participant's and the candidate's side.
+type matchCandidate struct {
+	userID    string
+	score     float64
+	scoreFrom float64
+	scoreTo   float64
+	isMutual  bool
 }
 
-// GenerateMatches creates matches for a user based on compatibility scores
-func (s *matchingService) GenerateMatches(ctx context.Context, userID string, limit int) ([]*entities.Match, error) {
-	// Get all completed surveys
-	surveys, err := s.surveyRepo.GetCompletedSurveys(ctx)
-	if err != nil {
-		return nil, err
+func (c matchCandidate) match(userID string, rank int) *entities.Match {
+	return &entities.Match{
+		UserID:             userID,
+		MatchedUserID:      c.userID,
+		CompatibilityScore: c.score,
+		ScoreFromUser:      c.scoreFrom,
+		ScoreFromMatched:   c.scoreTo,
+		Rank:               rank,
+		IsMutualCrush:      c.isMutual,
+		Source:             entities.MatchSourceAlgorithm,
 	}
+}
 
-	// Get user's survey
-	var userSurvey *entities.SurveyResponse
-	for _, survey := range surveys {
-		if survey.UserID == userID {
-			userSurvey = survey
-			break
+// sortCandidates orders self's candidates for a match list: mutual crushes
+// first, then by compatibility score. Equal scores are broken by a hash of
+// the run's seed and both user IDs, so the order never depends on input order.
+func (pool *matchingPool) sortCandidates(selfID string, candidates []matchCandidate) {
+	sort.SliceStable(candidates, func(i, j int) bool {
+		// Mutual crushes get top priority
+		if candidates[i].isMutual != candidates[j].isMutual {
+			return candidates[i].isMutual
 		}
-	}
+		if candidates[i].score != candidates[j].score {
+			return candidates[i].score > candidates[j].score
+		}
+		ti, tj := pool.tieBreak(selfID, candidates[i].userID), pool.tieBreak(selfID, candidates[j].userID)
+		if ti != tj {
+			return ti < tj
+		}
+		return candidates[i].userID < candidates[j].userID
+	})
+}
 
-	if userSurvey == nil {
-		return nil, ErrSurveyNotCompleted
-	}
+func (pool *matchingPool) tieBreak(selfID, otherID string) uint64 {
+	h := fnv.New64a()
+	fmt.Fprintf(h, "%d:%s:%s", pool.seed, selfID, otherID)
+	return h.Sum64()
+}
+
+// matchingPool is a campaign's participants with everything needed to score
+// them against each other
+type matchingPool struct {
+	def          *entities.SurveyDefinition
+	config       MatchingConfig
+	constraints  ConstraintSet
+	participants []*Participant
+	emails       map[string]string          // user ID -> email
+	crushes      map[string]map[string]bool // user ID -> emails they have a crush on
+	vetoes       VetoSet
+	blocks       VetoSet
+	seed         int64
+	debugf       func(format string, args ...interface{})
+}
 
-	// Get user's crushes
-	crushes, err := s.crushRepo.GetByUserID(ctx, userID)
+// loadPool loads a campaign's participants. The seed only breaks ties between
+// equal scores; any seed gives the same result for the same inputs every time.
+func (s *matchingService) loadPool(ctx context.Context, campaign *entities.Campaign, seed int64) (*matchingPool, error) {
+	def, config, participants, err := s.loadParticipants(ctx, campaign)
 	if err != nil {
 		return nil, err
 	}
 
-	// Build crush email set for mutual crush detection
-	crushEmails := make(map[string]bool)
-	for _, crush := range crushes {
-		crushEmails[crush.CrushEmail] = true
+	pool := &matchingPool{
+		def:          def,
+		config:       config,
+		constraints:  CampaignConstraints(def, config),
+		participants: participants,
+		emails:       make(map[string]string, len(participants)),
+		crushes:      make(map[string]map[string]bool, len(participants)),
+		seed:         seed,
+		debugf:       s.debugf,
 	}
+	for _, p := range participants {
+		if p.User != nil {
+			pool.emails[p.Survey.UserID] = p.User.Email
+		}
 
-	// Calculate compatibility with all other users
-	type matchCandidate struct {
-		userID   string
-		score    float64
-		isMutual bool
+		crushes, err := s.crushRepo.GetByUserID(ctx, p.Survey.UserID)
+		if err != nil {
+			return nil, err
+		}
+		emails := make(map[string]bool, len(crushes))
+		for _, crush := range crushes {
+			emails[crush.CrushEmail] = true
+		}
+		pool.crushes[p.Survey.UserID] = emails
 	}
-	var candidates []matchCandidate
 
-	// Fetch all users to get emails for mutual crush detection
-	users, err := s.userRepo.ListAll(ctx)
+	vetoes, err := s.vetoRepo.ListVetoes(ctx)
 	if err != nil {
 		return nil, err
 	}
+	pool.vetoes = NewVetoSet(vetoes)
 
-	userEmailMap := make(map[string]string)
-	for _, u := range users {
-		userEmailMap[u.ID] = u.Email
+	blocks, err := s.blockRepo.ListBlocks(ctx)
+	if err != nil {
+		return nil, err
 	}
+	pool.blocks = NewBlockSet(blocks)
+	return pool, nil
+}
 
-	currentUserEmail := userEmailMap[userID]
+// rankCandidates scores everyone self may be matched with, best first
+func (pool *matchingPool) rankCandidates(self *Participant) []matchCandidate {
+	userID := self.Survey.UserID
+	currentUserEmail := pool.emails[userID]
+	crushEmails := pool.crushes[userID]
 
-	fmt.Printf("DEBUG: Found %d completed surveys for matching (current user: %s)\n", len(surveys), userID)
+	var candidates []matchCandidate
+	for _, other := range pool.participants {
+		otherID := other.Survey.UserID
+		if otherID == userID {
+			continue
+		}
 
-	for _, survey := range surveys {
-		if survey.UserID == userID {
+		if pool.vetoes.Has(userID, otherID) {
+			pool.debugf("Candidate %s excluded by an admin veto\n", otherID)
+			continue
+		}
+		if pool.blocks.Has(userID, otherID) {
+			pool.debugf("Candidate %s excluded by a block\n", otherID)
 			continue
 		}
 
-		score, err := s.CalculateCompatibility(ctx, userSurvey, survey)
-		if err != nil {
-			fmt.Printf("DEBUG: Calculation failed for user %s: %v\n", survey.UserID, err)
+		// Hard filters run before scoring, so excluded pairs are never ranked
+		if ok, reason := pool.constraints.Check(self, other); !ok {
+			pool.debugf("Candidate %s excluded by %s\n", otherID, reason)
 			continue
 		}
 
-		// ... (mutual crush check)
-		isMutual := false
-		otherUserCrushes, _ := s.crushRepo.GetByUserID(ctx, survey.UserID)
-		hasCrushOnMe := false
-		for _, c := range otherUserCrushes {
-			if c.CrushEmail == currentUserEmail {
-				hasCrushOnMe = true
-				break
-			}
+		// Rank by how well the pair fits from both sides
+		compatibility := ScoreReciprocal(pool.def, pool.config, self.Survey, other.Survey)
+		if compatibility.Dealbreaker {
+			pool.debugf("Candidate %s excluded by a dealbreaker\n", otherID)
+			continue
 		}
+		score := compatibility.Score
+
+		isMutual := false
+		hasCrushOnMe := currentUserEmail != "" && pool.crushes[otherID][currentUserEmail]
+		iHaveCrush := pool.emails[otherID] != "" && crushEmails[pool.emails[otherID]]
 
-		if hasCrushOnMe && crushEmails[userEmailMap[survey.UserID]] {
+		if hasCrushOnMe && iHaveCrush {
 			isMutual = true
-			score = math.Min(score*1.2, 100.0)
-		} else if hasCrushOnMe || crushEmails[userEmailMap[survey.UserID]] {
-			score = math.Min(score*1.1, 100.0)
+			score = math.Min(score*(1+pool.config.MutualCrushBonus), 100.0)
+		} else if hasCrushOnMe || iHaveCrush {
+			score = math.Min(score*(1+pool.config.OneWayCrushBonus), 100.0)
+		}
+
+		if score < pool.config.MinimumCompatibilityScore {
+			continue
 		}
 
-		fmt.Printf("DEBUG: Candidate %s scored %.2f (Mutual: %v)\n", survey.UserID, score, isMutual)
+		pool.debugf("Candidate %s scored %.2f (Mutual: %v)\n", otherID, score, isMutual)
 
 		candidates = append(candidates, matchCandidate{
-			userID:   survey.UserID,
-			score:    score,
-			isMutual: isMutual,
+			userID:    otherID,
+			score:     score,
+			scoreFrom: compatibility.SelfToOther,
+			scoreTo:   compatibility.OtherToSelf,
+			isMutual:  isMutual,
 		})
 	}
 
-	fmt.Printf("DEBUG: Total candidates matched: %d\n", len(candidates))
+	pool.debugf("Total candidates matched: %d\n", len(candidates))
 
-	// Sort by compatibility score (descending)
-	sort.Slice(candidates, func(i, j int) bool {
-		// Mutual crushes get top priority
-		if candidates[i].isMutual && !candidates[j].isMutual {
-			return true
+	pool.sortCandidates(userID, candidates)
+	return candidates
+}
+
+// loadParticipants returns everyone who completed the campaign's survey
+// along with the campaign's definition and config
+func (s *matchingService) loadParticipants(ctx context.Context, campaign *entities.Campaign) (*entities.SurveyDefinition, MatchingConfig, []*Participant, error) {
+	config := CampaignMatchingConfig(campaign)
+	def, err := GetSurveyDefinition(CampaignSurveyVersion(campaign))
+	if err != nil {
+		return nil, config, nil, err
+	}
+
+	campaignID := ""
+	if campaign != nil {
+		campaignID = campaign.ID
+	}
+	surveys, err := s.surveyRepo.GetCompletedSurveysForCampaign(ctx, campaignID, def.Version)
+	if err != nil {
+		return nil, config, nil, err
+	}
+
+	users, err := s.userRepo.ListAll(ctx)
+	if err != nil {
+		return nil, config, nil, err
+	}
+	usersByID := make(map[string]*entities.User)
+	for _, u := range users {
+		usersByID[u.ID] = u
+	}
+
+	// Suspended and banned users take no part in matching
+	suspendedIDs, err := s.suspensions.ListSuspendedUserIDs(ctx, time.Now())
+	if err != nil {
+		return nil, config, nil, err
+	}
+	suspended := make(map[string]bool, len(suspendedIDs))
+	for _, id := range suspendedIDs {
+		suspended[id] = true
+	}
+
+	eligibility := CampaignEligibilityRules(campaign)
+	participants := make([]*Participant, 0, len(surveys))
+	for _, survey := range surveys {
+		if suspended[survey.UserID] {
+			s.debugf("Participant %s excluded by a suspension\n", survey.UserID)
+			continue
 		}
-		if !candidates[i].isMutual && candidates[j].isMutual {
-			return false
+		p := &Participant{Survey: survey, User: usersByID[survey.UserID]}
+		// The rules may have changed since the survey was completed
+		if err := eligibility.Check(p); err != nil {
+			s.debugf("Participant %s excluded by the campaign's eligibility rules: %v\n", survey.UserID, err)
+			continue
 		}
-		return candidates[i].score > candidates[j].score
+		participants = append(participants, p)
+	}
+	// A fixed order keeps runs reproducible whatever order the surveys load in
+	sort.Slice(participants, func(i, j int) bool {
+		return participants[i].Survey.UserID < participants[j].Survey.UserID
 	})
+	return def, config, participants, nil
+}
 
-	// Create top N matches
-	var matches []*entities.Match
-	maxMatches := min(limit, len(candidates))
-	for i := 0; i < maxMatches; i++ {
-		matches = append(matches, &entities.Match{
-			UserID:             userID,
-			MatchedUserID:      candidates[i].userID,
-			CompatibilityScore: candidates[i].score,
-			Rank:               i + 1,
-			IsMutualCrush:      candidates[i].isMutual,
-		})
+// EligiblePool counts the completed respondents that survey's owner could be
+// matched with under the campaign's hard filters. The survey itself doesn't
+// need to be complete, so it can be checked while the user is still answering.
+func (s *matchingService) EligiblePool(ctx context.Context, campaign *entities.Campaign, survey *entities.SurveyResponse) (int, error) {
+	def, config, participants, err := s.loadParticipants(ctx, campaign)
+	if err != nil {
+		return 0, err
+	}
+
+	user, err := s.userRepo.GetByID(ctx, survey.UserID)
+	if err != nil {
+		user = nil
 	}
+	self := &Participant{Survey: survey, User: user}
+	return CampaignConstraints(def, config).EligiblePool(self, participants), nil
+}
 
-	return matches, nil
+// EligiblePoolSizes reports the eligible pool of every participant in a campaign
+func (s *matchingService) EligiblePoolSizes(ctx context.Context, campaign *entities.Campaign) (map[string]int, error) {
+	def, config, participants, err := s.loadParticipants(ctx, campaign)
+	if err != nil {
+		return nil, err
+	}
+
+	constraints := CampaignConstraints(def, config)
+	pools := make(map[string]int, len(participants))
+	for _, p := range participants {
+		pools[p.Survey.UserID] = constraints.EligiblePool(p, participants)
+	}
+	return pools, nil
 }
 
 func min(a, b int) int {
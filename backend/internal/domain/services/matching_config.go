@@ -0,0 +1,137 @@
+package services
+
+import (
+	"encoding/json"
+
+	"wizard-connect/internal/domain/entities"
+)
+
+// MatchingConfig is the scoring setup for one campaign, read from
+// campaigns.config. Keys that are missing fall back to DefaultMatchingConfig.
+type MatchingConfig struct {
+	// Weights per survey section: demographics, personality, values, lifestyle, interests
+	Weights                   map[string]float64    `json:"weights"`
+	NumMatches                int                   `json:"num_matches"`
+	MutualCrushBonus          float64               `json:"mutual_crush_bonus"`
+	OneWayCrushBonus          float64               `json:"one_way_crush_bonus"`
+	MinimumCompatibilityScore float64               `json:"minimum_compatibility_score"`
+	QuestionScoring           QuestionScoringConfig `json:"question_scoring"`
+	Constraints               ConstraintsConfig     `json:"constraints"`
+	Fairness                  FairnessConfig        `json:"fairness"`
+	// Reciprocity combines the two directional scores of a pair: harmonic
+	// (default), geometric, minimum or directional
+	Reciprocity string `json:"reciprocity"`
+}
+
+// ConstraintsConfig controls the hard filters applied before scoring
+type ConstraintsConfig struct {
+	// GenderPreference only pairs people who are each other's seeking_gender
+	GenderPreference bool `json:"gender_preference"`
+	// ProfileFilters honors respondents' year range and same-major filters
+	ProfileFilters bool `json:"profile_filters"`
+	// MinPoolSize is the eligible pool below which respondents are warned
+	// that their filters are too narrow
+	MinPoolSize int `json:"min_pool_size"`
+}
+
+// QuestionScoringConfig controls how individual answers are compared
+type QuestionScoringConfig struct {
+	// Enabled scores sections from the raw answers; when false the summary
+	// fields (interests, values, lifestyle) are compared instead
+	Enabled bool `json:"enabled"`
+	// UseImportance lets respondents weight questions they care about
+	UseImportance bool `json:"use_importance"`
+	// Dealbreakers lets respondents rule out answers they won't accept
+	Dealbreakers bool `json:"dealbreakers"`
+	// QuestionWeights overrides the definition's per-question weights
+	QuestionWeights map[string]float64 `json:"question_weights,omitempty"`
+}
+
+// DefaultMatchingConfig mirrors the config seeded with the first campaign
+func DefaultMatchingConfig() MatchingConfig {
+	return MatchingConfig{
+		Weights: map[string]float64{
+			"demographics": 0.10,
+			"personality":  0.30,
+			"values":       0.25,
+			"lifestyle":    0.20,
+			"interests":    0.15,
+		},
+		NumMatches:                7,
+		MutualCrushBonus:          0.20,
+		OneWayCrushBonus:          0.10,
+		MinimumCompatibilityScore: 0,
+		QuestionScoring: QuestionScoringConfig{
+			Enabled:       true,
+			UseImportance: true,
+			Dealbreakers:  true,
+		},
+		Constraints: ConstraintsConfig{
+			GenderPreference: true,
+			ProfileFilters:   true,
+			MinPoolSize:      10,
+		},
+		Reciprocity: ReciprocityHarmonic,
+	}
+}
+
+// CampaignMatchingConfig reads the matching config of a campaign. A nil
+// campaign or one without a config gets the defaults.
+func CampaignMatchingConfig(campaign *entities.Campaign) MatchingConfig {
+	if campaign == nil {
+		return DefaultMatchingConfig()
+	}
+	return ParseMatchingConfig(campaign.Config)
+}
+
+// ParseMatchingConfig overlays a campaign's raw config onto the defaults.
+// A campaign that sets weights replaces the default weights entirely.
+func ParseMatchingConfig(raw map[string]interface{}) MatchingConfig {
+	config := DefaultMatchingConfig()
+	if len(raw) == 0 {
+		return config
+	}
+
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return config
+	}
+
+	var parsed MatchingConfig
+	parsed.QuestionScoring = config.QuestionScoring
+	parsed.Constraints = config.Constraints
+	if err := json.Unmarshal(data, &parsed); err != nil {
+		return config
+	}
+
+	if len(parsed.Weights) > 0 {
+		config.Weights = parsed.Weights
+	}
+	if parsed.NumMatches > 0 {
+		config.NumMatches = parsed.NumMatches
+	}
+	if _, ok := raw["mutual_crush_bonus"]; ok {
+		config.MutualCrushBonus = parsed.MutualCrushBonus
+	}
+	if _, ok := raw["one_way_crush_bonus"]; ok {
+		config.OneWayCrushBonus = parsed.OneWayCrushBonus
+	}
+	config.MinimumCompatibilityScore = parsed.MinimumCompatibilityScore
+	config.QuestionScoring = parsed.QuestionScoring
+	config.Constraints = parsed.Constraints
+	config.Fairness = parsed.Fairness
+	switch parsed.Reciprocity {
+	case ReciprocityHarmonic, ReciprocityGeometric, ReciprocityMinimum, ReciprocityDirectional:
+		config.Reciprocity = parsed.Reciprocity
+	}
+
+	return config
+}
+
+// questionWeight is the weight of one question under this config
+func (c MatchingConfig) questionWeight(question *entities.SurveyQuestion) float64 {
+	if weight, ok := c.QuestionScoring.QuestionWeights[question.ID]; ok {
+		return weight
+	}
+	return question.Weight
+}
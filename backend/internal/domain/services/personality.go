@@ -0,0 +1,238 @@
+package services
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Personality axes, in MBTI letter order. Each axis is scored in [-1, 1]
+// where -1 is the first pole (E, S, T, J) and +1 the second (I, N, F, P).
+const (
+	AxisEI = iota
+	AxisSN
+	AxisTF
+	AxisJP
+	numAxes
+)
+
+var axisPoles = [numAxes][2]byte{
+	AxisEI: {'E', 'I'},
+	AxisSN: {'S', 'N'},
+	AxisTF: {'T', 'F'},
+	AxisJP: {'J', 'P'},
+}
+
+// PersonalityTraits is the continuous position of a respondent on the four axes.
+type PersonalityTraits [numAxes]float64
+
+// Slice returns the traits in the form stored on entities.SurveyResponse
+func (t PersonalityTraits) Slice() []float64 {
+	return []float64{t[AxisEI], t[AxisSN], t[AxisTF], t[AxisJP]}
+}
+
+// Type returns the four-letter type closest to the traits. Exactly balanced
+// axes resolve to the first pole.
+func (t PersonalityTraits) Type() string {
+	var b strings.Builder
+	for axis, v := range t {
+		if v > 0 {
+			b.WriteByte(axisPoles[axis][1])
+		} else {
+			b.WriteByte(axisPoles[axis][0])
+		}
+	}
+	return b.String()
+}
+
+// TraitsFromSlice converts a stored trait vector back into PersonalityTraits
+func TraitsFromSlice(values []float64) (PersonalityTraits, bool) {
+	var t PersonalityTraits
+	if len(values) != numAxes {
+		return t, false
+	}
+	copy(t[:], values)
+	return t, true
+}
+
+// traitItem maps one survey answer onto an axis. Scale answers (1-5) use
+// Direction; multiple choice answers use Options, keyed by option value.
+type traitItem struct {
+	Axis      int
+	Weight    float64
+	Direction float64
+	Options   map[string]float64
+}
+
+// personalityItems lists which survey questions feed each axis. T/F has no
+// dedicated question yet, so it is inferred weakly from the values section.
+var personalityItems = map[string]traitItem{
+	"personality_introvert":   {Axis: AxisEI, Weight: 1.0, Direction: 1},
+	"personality_social":      {Axis: AxisEI, Weight: 1.0, Direction: -1},
+	"personality_adventurous": {Axis: AxisSN, Weight: 1.0, Direction: 1},
+	"personality_planner":     {Axis: AxisJP, Weight: 1.0, Direction: -1},
+	"values_family":           {Axis: AxisTF, Weight: 0.5, Direction: 1},
+	"values_career":           {Axis: AxisTF, Weight: 0.5, Direction: -1},
+	"lifestyle_study_habits": {Axis: AxisJP, Weight: 0.5, Options: map[string]float64{
+		"consistent":  -1,
+		"early_bird":  -0.5,
+		"night_owl":   0.5,
+		"last_minute": 1,
+	}},
+	"lifestyle_weekend": {Axis: AxisEI, Weight: 0.5, Options: map[string]float64{
+		"party":    -1,
+		"outdoors": -0.5,
+		"hobbies":  0.5,
+		"chill":    1,
+	}},
+}
+
+// DeriveTraits computes trait scores from raw survey answers. It reports false
+// unless every axis has an answer: an unanswered axis would otherwise read as
+// its first pole and outvote the type the user declared.
+func DeriveTraits(responses map[string]interface{}) (PersonalityTraits, bool) {
+	var sums, weights PersonalityTraits
+
+	for questionID, item := range personalityItems {
+		raw, ok := responses[questionID]
+		if !ok {
+			continue
+		}
+
+		var value float64
+		if item.Options != nil {
+			option, ok := raw.(string)
+			if !ok {
+				continue
+			}
+			if value, ok = item.Options[option]; !ok {
+				continue
+			}
+		} else {
+			likert, ok := likertValue(raw)
+			if !ok {
+				continue
+			}
+			value = (likert - 3) / 2 * item.Direction
+		}
+
+		sums[item.Axis] += value * item.Weight
+		weights[item.Axis] += item.Weight
+	}
+
+	var traits PersonalityTraits
+	for axis := range traits {
+		if weights[axis] == 0 {
+			return PersonalityTraits{}, false
+		}
+		traits[axis] = clampUnit(sums[axis] / weights[axis])
+	}
+	return traits, true
+}
+
+// likertValue reads a 1-5 scale answer, which the frontend sends as a string
+func likertValue(raw interface{}) (float64, bool) {
+	var v float64
+	switch val := raw.(type) {
+	case string:
+		parsed, err := strconv.ParseFloat(val, 64)
+		if err != nil {
+			return 0, false
+		}
+		v = parsed
+	case float64:
+		v = val
+	case int:
+		v = float64(val)
+	default:
+		return 0, false
+	}
+	if v < 1 || v > 5 {
+		return 0, false
+	}
+	return v, true
+}
+
+func clampUnit(v float64) float64 {
+	return math.Max(-1, math.Min(1, v))
+}
+
+// How each axis contributes to a pair's compatibility. Complementary axes
+// reward opposite poles, similar axes reward the same pole.
+const (
+	axisSimilar = iota
+	axisComplement
+)
+
+var axisScoring = [numAxes]struct {
+	Mode   int
+	Weight float64
+}{
+	AxisEI: {axisComplement, 0.20},
+	AxisSN: {axisSimilar, 0.35},
+	AxisTF: {axisComplement, 0.20},
+	AxisJP: {axisSimilar, 0.25},
+}
+
+// TraitCompatibility scores two trait vectors on a 40-100 scale
+func TraitCompatibility(a, b PersonalityTraits) float64 {
+	score := 0.0
+	for axis, rule := range axisScoring {
+		var fit float64
+		if rule.Mode == axisComplement {
+			fit = 1 - math.Abs(a[axis]+b[axis])/2
+		} else {
+			fit = 1 - math.Abs(a[axis]-b[axis])/2
+		}
+		score += fit * rule.Weight
+	}
+	return 40 + 60*score
+}
+
+var personalityTypes = [16]string{
+	"ESTJ", "ESTP", "ESFJ", "ESFP", "ENTJ", "ENTP", "ENFJ", "ENFP",
+	"ISTJ", "ISTP", "ISFJ", "ISFP", "INTJ", "INTP", "INFJ", "INFP",
+}
+
+// personalityCompatibility is TraitCompatibility evaluated at the poles, so
+// declared types and derived traits score on the same scale.
+var personalityCompatibility = [16][16]float64{
+	//         ESTJ ESTP ESFJ ESFP ENTJ ENTP ENFJ ENFP ISTJ ISTP ISFJ ISFP INTJ INTP INFJ INFP
+	/* ESTJ */ {76, 61, 88, 73, 55, 40, 67, 52, 88, 73, 100, 85, 67, 52, 79, 64},
+	/* ESTP */ {61, 76, 73, 88, 40, 55, 52, 67, 73, 88, 85, 100, 52, 67, 64, 79},
+	/* ESFJ */ {88, 73, 76, 61, 67, 52, 55, 40, 100, 85, 88, 73, 79, 64, 67, 52},
+	/* ESFP */ {73, 88, 61, 76, 52, 67, 40, 55, 85, 100, 73, 88, 64, 79, 52, 67},
+	/* ENTJ */ {55, 40, 67, 52, 76, 61, 88, 73, 67, 52, 79, 64, 88, 73, 100, 85},
+	/* ENTP */ {40, 55, 52, 67, 61, 76, 73, 88, 52, 67, 64, 79, 73, 88, 85, 100},
+	/* ENFJ */ {67, 52, 55, 40, 88, 73, 76, 61, 79, 64, 67, 52, 100, 85, 88, 73},
+	/* ENFP */ {52, 67, 40, 55, 73, 88, 61, 76, 64, 79, 52, 67, 85, 100, 73, 88},
+	/* ISTJ */ {88, 73, 100, 85, 67, 52, 79, 64, 76, 61, 88, 73, 55, 40, 67, 52},
+	/* ISTP */ {73, 88, 85, 100, 52, 67, 64, 79, 61, 76, 73, 88, 40, 55, 52, 67},
+	/* ISFJ */ {100, 85, 88, 73, 79, 64, 67, 52, 88, 73, 76, 61, 67, 52, 55, 40},
+	/* ISFP */ {85, 100, 73, 88, 64, 79, 52, 67, 73, 88, 61, 76, 52, 67, 40, 55},
+	/* INTJ */ {67, 52, 79, 64, 88, 73, 100, 85, 55, 40, 67, 52, 76, 61, 88, 73},
+	/* INTP */ {52, 67, 64, 79, 73, 88, 85, 100, 40, 55, 52, 67, 61, 76, 73, 88},
+	/* INFJ */ {79, 64, 67, 52, 100, 85, 88, 73, 67, 52, 55, 40, 88, 73, 76, 61},
+	/* INFP */ {64, 79, 52, 67, 85, 100, 73, 88, 52, 67, 40, 55, 73, 88, 61, 76},
+}
+
+// TypeCompatibility looks up two declared types in the compatibility table.
+// It reports false if either type is not one of the 16.
+func TypeCompatibility(type1, type2 string) (float64, bool) {
+	i, ok1 := personalityTypeIndex(type1)
+	j, ok2 := personalityTypeIndex(type2)
+	if !ok1 || !ok2 {
+		return 0, false
+	}
+	return personalityCompatibility[i][j], true
+}
+
+func personalityTypeIndex(t string) (int, bool) {
+	t = strings.ToUpper(strings.TrimSpace(t))
+	for i, candidate := range personalityTypes {
+		if candidate == t {
+			return i, true
+		}
+	}
+	return 0, false
+}
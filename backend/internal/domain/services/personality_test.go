@@ -0,0 +1,154 @@
+package services
+
+import (
+	"math"
+	"testing"
+)
+
+// poleTraits places a four-letter type at the poles of each axis
+func poleTraits(t *testing.T, personalityType string) PersonalityTraits {
+	t.Helper()
+	var traits PersonalityTraits
+	for axis := range traits {
+		switch personalityType[axis] {
+		case axisPoles[axis][0]:
+			traits[axis] = -1
+		case axisPoles[axis][1]:
+			traits[axis] = 1
+		default:
+			t.Fatalf("%s: bad letter at axis %d", personalityType, axis)
+		}
+	}
+	return traits
+}
+
+func TestPersonalityCompatibilityIsSymmetric(t *testing.T) {
+	for i := range personalityTypes {
+		for j := range personalityTypes {
+			if personalityCompatibility[i][j] != personalityCompatibility[j][i] {
+				t.Errorf("%s/%s = %v but %s/%s = %v",
+					personalityTypes[i], personalityTypes[j], personalityCompatibility[i][j],
+					personalityTypes[j], personalityTypes[i], personalityCompatibility[j][i])
+			}
+		}
+	}
+}
+
+func TestPersonalityCompatibilityMatchesTraitsAtPoles(t *testing.T) {
+	for i, a := range personalityTypes {
+		for j, b := range personalityTypes {
+			want := TraitCompatibility(poleTraits(t, a), poleTraits(t, b))
+			if math.Abs(personalityCompatibility[i][j]-want) > 0.5 {
+				t.Errorf("%s/%s: table has %v, traits give %.1f", a, b, personalityCompatibility[i][j], want)
+			}
+		}
+	}
+}
+
+func TestPersonalityCompatibilityPairs(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want float64
+	}{
+		// Same type: similar axes fit, complementary ones don't
+		{"ESTJ", "ESTJ", 76},
+		{"INFP", "INFP", 76},
+		// Opposite complementary axes, same similar axes: the best fit
+		{"ESTJ", "ISFJ", 100},
+		{"ENTP", "INFP", 100},
+		// Opposite on every similar axis, same on every complementary one
+		{"ESTJ", "ENTP", 40},
+		{"ISFJ", "INFP", 40},
+		// Every axis opposite
+		{"ESTJ", "INFP", 64},
+	}
+	for _, tt := range tests {
+		got, ok := TypeCompatibility(tt.a, tt.b)
+		if !ok {
+			t.Fatalf("%s/%s: not found", tt.a, tt.b)
+		}
+		if got != tt.want {
+			t.Errorf("%s/%s = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+
+	if _, ok := TypeCompatibility(" intj ", "ENFP"); !ok {
+		t.Error("lowercase, padded types should be accepted")
+	}
+	if _, ok := TypeCompatibility("XXXX", "ENFP"); ok {
+		t.Error("unknown types should not be found")
+	}
+}
+
+func TestDeriveTraits(t *testing.T) {
+	allAxes := map[string]interface{}{
+		"personality_introvert":   "5",
+		"personality_social":      "1",
+		"personality_adventurous": "5",
+		"personality_planner":     "1",
+		"values_family":           "5",
+		"values_career":           "1",
+	}
+
+	tests := []struct {
+		name      string
+		responses map[string]interface{}
+		wantOK    bool
+		wantType  string
+	}{
+		{"every axis at the second pole", allAxes, true, "INFP"},
+		{"every axis at the first pole", map[string]interface{}{
+			"personality_introvert":   "1",
+			"personality_adventurous": 1.0,
+			"personality_planner":     5,
+			"values_career":           "5",
+		}, true, "ESTJ"},
+		{"options count towards their axis", map[string]interface{}{
+			"lifestyle_weekend":       "party",
+			"personality_adventurous": "4",
+			"values_family":           "4",
+			"lifestyle_study_habits":  "last_minute",
+		}, true, "ENFP"},
+		{"balanced axes resolve to the first pole", map[string]interface{}{
+			"personality_introvert":   "3",
+			"personality_adventurous": "3",
+			"personality_planner":     "3",
+			"values_family":           "3",
+		}, true, "ESTJ"},
+		{"no answers", map[string]interface{}{}, false, ""},
+		{"missing axis", map[string]interface{}{
+			"personality_introvert":   "5",
+			"personality_adventurous": "5",
+			"personality_planner":     "1",
+		}, false, ""},
+		{"out of range answers are ignored", map[string]interface{}{
+			"personality_introvert":   "9",
+			"personality_adventurous": "5",
+			"personality_planner":     "1",
+			"values_family":           "5",
+		}, false, ""},
+		{"unknown options are ignored", map[string]interface{}{
+			"lifestyle_weekend":       "sleep",
+			"personality_adventurous": "5",
+			"personality_planner":     "1",
+			"values_family":           "5",
+		}, false, ""},
+	}
+	for _, tt := range tests {
+		traits, ok := DeriveTraits(tt.responses)
+		if ok != tt.wantOK {
+			t.Errorf("%s: ok = %v, want %v", tt.name, ok, tt.wantOK)
+			continue
+		}
+		if ok && traits.Type() != tt.wantType {
+			t.Errorf("%s: type = %s, want %s", tt.name, traits.Type(), tt.wantType)
+		}
+	}
+
+	traits, _ := DeriveTraits(allAxes)
+	for axis, v := range traits {
+		if v != 1 {
+			t.Errorf("axis %d = %v, want 1", axis, v)
+		}
+	}
+}
@@ -0,0 +1,151 @@
+package services
+
+import (
+	"context"
+	"errors"
+
+	"wizard-connect/internal/domain/entities"
+)
+
+// ErrProfileHidden means the viewer may not see the profile at all. Callers
+// answer it like a missing user so hidden profiles can't be probed for.
+var ErrProfileHidden = errors.New("profile not visible to this viewer")
+
+type ProfileUserRepository interface {
+	GetByID(ctx context.Context, id string) (*entities.User, error)
+}
+
+type ProfileMatchRepository interface {
+	GetMatch(ctx context.Context, userID, matchedUserID string) (*entities.Match, error)
+}
+
+type ProfileBlockRepository interface {
+	IsBlocked(ctx context.Context, userID, otherUserID string) (bool, error)
+}
+
+// ProfileService projects a user's profile for a viewer. Owners see
+// everything; matches see the matched view with the one contact method the
+// user chose; anyone else sees the redacted public view, if the user's
+// visibility allows it at all.
+type ProfileService struct {
+	userRepo  ProfileUserRepository
+	matchRepo ProfileMatchRepository
+	blockRepo ProfileBlockRepository
+}
+
+func NewProfileService(userRepo ProfileUserRepository, matchRepo ProfileMatchRepository, blockRepo ProfileBlockRepository) *ProfileService {
+	return &ProfileService{
+		userRepo:  userRepo,
+		matchRepo: matchRepo,
+		blockRepo: blockRepo,
+	}
+}
+
+// Profile returns userID's profile as viewerID may see it, or
+// ErrProfileHidden
+func (s *ProfileService) Profile(ctx context.Context, viewerID, userID string) (*entities.ProfileView, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if viewerID == userID {
+		return OwnerProfile(user), nil
+	}
+
+	// Blocks hide users from each other entirely
+	blocked, err := s.blockRepo.IsBlocked(ctx, viewerID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if blocked {
+		return nil, ErrProfileHidden
+	}
+
+	matched := s.matched(ctx, viewerID, userID)
+	switch user.Visibility {
+	case entities.VisibilityPublic:
+		if matched {
+			return MatchedProfile(user), nil
+		}
+		return PublicProfile(user), nil
+	case entities.VisibilityPrivate:
+		if matched {
+			return PublicProfile(user), nil
+		}
+	default:
+		// matches_only, and users who never chose
+		if matched {
+			return MatchedProfile(user), nil
+		}
+	}
+	return nil, ErrProfileHidden
+}
+
+// matched reports whether either user has the other among their matches
+func (s *ProfileService) matched(ctx context.Context, viewerID, userID string) bool {
+	if _, err := s.matchRepo.GetMatch(ctx, viewerID, userID); err == nil {
+		return true
+	}
+	_, err := s.matchRepo.GetMatch(ctx, userID, viewerID)
+	return err == nil
+}
+
+// PublicProfile is the redacted view: first name, picture and the profile
+// text, with no surname or contact details
+func PublicProfile(user *entities.User) *entities.ProfileView {
+	return &entities.ProfileView{
+		ID:        user.ID,
+		View:      entities.ProfileViewPublic,
+		FirstName: user.FirstName,
+		AvatarURL: user.AvatarURL,
+		Bio:       user.Bio,
+		Year:      user.Year,
+		Major:     user.Major,
+	}
+}
+
+// MatchedProfile adds the surname and the contact method the user chose
+func MatchedProfile(user *entities.User) *entities.ProfileView {
+	view := PublicProfile(user)
+	view.View = entities.ProfileViewMatched
+	view.LastName = user.LastName
+	view.Contact = preferredContact(user)
+	return view
+}
+
+// OwnerProfile is the whole profile
+func OwnerProfile(user *entities.User) *entities.ProfileView {
+	view := MatchedProfile(user)
+	view.View = entities.ProfileViewOwner
+	view.Email = user.Email
+	view.Instagram = user.Instagram
+	view.Phone = user.Phone
+	view.ContactPref = user.ContactPref
+	view.Visibility = user.Visibility
+	view.Gender = user.Gender
+	view.GenderPreference = user.GenderPreference
+	return view
+}
+
+// preferredContact is the contact method the user chose, if they filled it
+// in. Users who never chose share their email, the shell profile default.
+func preferredContact(user *entities.User) *entities.ProfileContact {
+	method := user.ContactPref
+	if method == "" {
+		method = entities.ContactPrefEmail
+	}
+
+	var value string
+	switch method {
+	case entities.ContactPrefEmail:
+		value = user.Email
+	case entities.ContactPrefPhone:
+		value = user.Phone
+	case entities.ContactPrefInstagram:
+		value = user.Instagram
+	}
+	if value == "" {
+		return nil
+	}
+	return &entities.ProfileContact{Method: method, Value: value}
+}
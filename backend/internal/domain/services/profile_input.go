@@ -0,0 +1,224 @@
+package services
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"wizard-connect/internal/domain/entities"
+)
+
+// Allowed values of the profile's enum fields, mirroring the users table
+// checks
+var (
+	ProfileGenders           = []string{"male", "female", "non-binary", "prefer_not_to_say", "other"}
+	ProfileGenderPreferences = []string{"male", "female", "both"}
+	ProfileContactPrefs      = []string{entities.ContactPrefEmail, entities.ContactPrefPhone, entities.ContactPrefInstagram}
+	ProfileVisibilities      = []string{entities.VisibilityPublic, entities.VisibilityMatchesOnly, entities.VisibilityPrivate}
+	// ProfileYears are the year levels the profile page offers, plus the
+	// plain forms the matcher also understands
+	ProfileYears = []string{
+		"Freshman (1st Year)", "Sophomore (2nd Year)", "Junior (3rd Year)", "Senior (4th Year)",
+		"Super Senior+", "Graduate Student",
+		"1st Year", "2nd Year", "3rd Year", "4th Year", "5th Year+",
+	}
+)
+
+// profileFields are the fields a profile patch may change, by JSON name
+var profileFields = map[string]bool{
+	"first_name": true, "last_name": true, "bio": true, "instagram": true, "phone": true,
+	"avatar_url": true, "contact_preference": true, "visibility": true, "year": true,
+	"major": true, "gender": true, "gender_preference": true,
+}
+
+// profileFieldAliases are the camelCase names older clients send
+var profileFieldAliases = map[string]string{
+	"firstName":         "first_name",
+	"lastName":          "last_name",
+	"avatarUrl":         "avatar_url",
+	"contactPreference": "contact_preference",
+	"genderPreference":  "gender_preference",
+}
+
+// profileFieldDefaults are what clearing a field with a default resets it to
+var profileFieldDefaults = map[string]string{
+	"contact_preference": entities.ContactPrefEmail,
+	"visibility":         entities.VisibilityMatchesOnly,
+}
+
+// ProfilePatch is a partial profile update keyed by JSON field name. A
+// field that is absent, or sent as "", stays as it is; a value changes it;
+// an explicit null (a nil entry) clears it.
+type ProfilePatch map[string]*string
+
+// ParseProfilePatch reads a patch from a JSON object. Unknown fields are
+// ignored; values that are neither strings nor null are field errors.
+func ParseProfilePatch(raw map[string]json.RawMessage) (ProfilePatch, map[string]string) {
+	patch := ProfilePatch{}
+	fieldErrors := map[string]string{}
+
+	for key, value := range raw {
+		field := key
+		if alias, ok := profileFieldAliases[key]; ok {
+			field = alias
+		}
+		if !profileFields[field] {
+			continue
+		}
+
+		if string(value) == "null" {
+			patch[field] = nil
+			continue
+		}
+		var text string
+		if err := json.Unmarshal(value, &text); err != nil {
+			fieldErrors[field] = "must be a string or null"
+			continue
+		}
+		text = strings.TrimSpace(text)
+		if text == "" {
+			continue
+		}
+		patch[field] = &text
+	}
+
+	return patch, fieldErrors
+}
+
+// Validate checks the patch's values and normalizes phone numbers and
+// Instagram handles in place. It returns an error message per bad field.
+func (p ProfilePatch) Validate() map[string]string {
+	fieldErrors := map[string]string{}
+
+	check := func(field string, allowed []string) {
+		if value := p[field]; value != nil && !oneOf(allowed, *value) {
+			fieldErrors[field] = "must be one of " + strings.Join(allowed, ", ")
+		}
+	}
+	check("gender", ProfileGenders)
+	check("gender_preference", ProfileGenderPreferences)
+	check("contact_preference", ProfileContactPrefs)
+	check("visibility", ProfileVisibilities)
+	check("year", ProfileYears)
+
+	if value := p["phone"]; value != nil {
+		phone, ok := NormalizePhone(*value)
+		if !ok {
+			fieldErrors["phone"] = "must be a phone number of 7 to 15 digits"
+		} else {
+			*value = phone
+		}
+	}
+	if value := p["instagram"]; value != nil {
+		handle, ok := NormalizeInstagram(*value)
+		if !ok {
+			fieldErrors["instagram"] = "must be an Instagram handle: up to 30 letters, numbers, periods and underscores"
+		} else {
+			*value = handle
+		}
+	}
+	return fieldErrors
+}
+
+// Apply writes the patch onto user and returns the columns to clear.
+// Cleared fields with a default are reset to it instead.
+func (p ProfilePatch) Apply(user *entities.User) []string {
+	targets := map[string]*string{
+		"first_name":         &user.FirstName,
+		"last_name":          &user.LastName,
+		"bio":                &user.Bio,
+		"instagram":          &user.Instagram,
+		"phone":              &user.Phone,
+		"avatar_url":         &user.AvatarURL,
+		"contact_preference": &user.ContactPref,
+		"visibility":         &user.Visibility,
+		"year":               &user.Year,
+		"major":              &user.Major,
+		"gender":             &user.Gender,
+		"gender_preference":  &user.GenderPreference,
+	}
+
+	var cleared []string
+	for field, value := range p {
+		target := targets[field]
+		switch {
+		case value != nil:
+			*target = *value
+		case profileFieldDefaults[field] != "":
+			*target = profileFieldDefaults[field]
+		default:
+			*target = ""
+			cleared = append(cleared, field)
+		}
+	}
+	return cleared
+}
+
+func oneOf(allowed []string, value string) bool {
+	for _, a := range allowed {
+		if a == value {
+			return true
+		}
+	}
+	return false
+}
+
+// ContactPreferenceError explains why the user's chosen contact method
+// can't be used, or returns ""
+func ContactPreferenceError(user *entities.User) string {
+	switch user.ContactPref {
+	case entities.ContactPrefPhone:
+		if user.Phone == "" {
+			return "add a phone number to share it with matches"
+		}
+	case entities.ContactPrefInstagram:
+		if user.Instagram == "" {
+			return "add an Instagram handle to share it with matches"
+		}
+	}
+	return ""
+}
+
+// NormalizePhone strips the formatting from a phone number, keeping a
+// leading +. It reports false unless 7 to 15 digits remain.
+func NormalizePhone(phone string) (string, bool) {
+	var b strings.Builder
+	for i, r := range strings.TrimSpace(phone) {
+		switch {
+		case unicode.IsDigit(r):
+			b.WriteRune(r)
+		case r == '+' && i == 0:
+			b.WriteRune(r)
+		case r == ' ' || r == '-' || r == '.' || r == '(' || r == ')':
+		default:
+			return "", false
+		}
+	}
+
+	normalized := b.String()
+	digits := len(strings.TrimPrefix(normalized, "+"))
+	return normalized, digits >= 7 && digits <= 15
+}
+
+var instagramHandlePattern = regexp.MustCompile(`^[a-z0-9._]{1,30}$`)
+
+// NormalizeInstagram turns "@Handle" or a profile URL into the lowercase
+// handle, and reports false when what's left isn't a valid handle
+func NormalizeInstagram(handle string) (string, bool) {
+	handle = strings.ToLower(strings.TrimSpace(handle))
+	for _, prefix := range []string{"https://", "http://", "www.", "instagram.com/", "instagr.am/"} {
+		handle = strings.TrimPrefix(handle, prefix)
+	}
+	if i := strings.IndexAny(handle, "?#"); i >= 0 {
+		handle = handle[:i]
+	}
+	handle = strings.TrimSuffix(handle, "/")
+	handle = strings.TrimPrefix(handle, "@")
+
+	ok := instagramHandlePattern.MatchString(handle) &&
+		!strings.HasPrefix(handle, ".") &&
+		!strings.HasSuffix(handle, ".") &&
+		!strings.Contains(handle, "..")
+	return handle, ok
+}
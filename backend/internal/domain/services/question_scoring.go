@@ -0,0 +1,266 @@
+package services
+
+import (
+	"math"
+	"strconv"
+
+	"wizard-connect/internal/domain/entities"
+)
+
+// Reserved response keys holding a respondent's preferences about other
+// people's answers rather than answers of their own
+const (
+	// ImportanceKey maps question ID to an importance level (ImportanceNone..ImportanceHigh)
+	ImportanceKey = "_importance"
+	// DealbreakersKey maps question ID to the answers the respondent won't accept
+	DealbreakersKey = "_dealbreakers"
+)
+
+// Importance levels a respondent can give a question. Unrated questions
+// count as ImportanceDefault.
+const (
+	ImportanceNone    = 0
+	ImportanceLow     = 1
+	ImportanceDefault = 2
+	ImportanceHigh    = 3
+)
+
+// Sections the compatibility score is split into, matching the keys of
+// MatchingConfig.Weights
+var scoredSections = []string{"demographics", "personality", "values", "lifestyle", "interests"}
+
+// Compatibility is a directional score of how well "other" fits "self"
+type Compatibility struct {
+	Score       float64            `json:"score"`
+	Sections    map[string]float64 `json:"sections"`
+	Dealbreaker bool               `json:"dealbreaker"`
+}
+
+// ScoreCompatibility scores other from self's point of view (0-100). Self's
+// importance ratings weight the questions; a dealbreaker on either side
+// zeroes the pairing.
+func ScoreCompatibility(def *entities.SurveyDefinition, config MatchingConfig, self, other *entities.SurveyResponse) Compatibility {
+	result := Compatibility{Sections: make(map[string]float64)}
+
+	if config.QuestionScoring.Dealbreakers && (HasDealbreaker(def, self, other) || HasDealbreaker(def, other, self)) {
+		result.Dealbreaker = true
+		return result
+	}
+
+	useAnswers := config.QuestionScoring.Enabled && def != nil &&
+		len(self.Responses) > 0 && len(other.Responses) > 0
+
+	for _, section := range scoredSections {
+		if section == "personality" {
+			result.Sections[section] = personalityMatch(self, other)
+			continue
+		}
+		if useAnswers {
+			if score, ok := sectionAnswerScore(def, config, section, self, other); ok {
+				result.Sections[section] = score
+			}
+			continue
+		}
+		result.Sections[section] = summaryScore(section, self, other)
+	}
+
+	totalWeight, score := 0.0, 0.0
+	for section, sectionScore := range result.Sections {
+		weight := config.Weights[section]
+		totalWeight += weight
+		score += sectionScore * weight
+	}
+	if totalWeight > 0 {
+		result.Score = math.Min(score/totalWeight, 100.0)
+	}
+
+	return result
+}
+
+// summaryScore compares the summary fields of surveys that have no raw answers
+func summaryScore(section string, self, other *entities.SurveyResponse) float64 {
+	switch section {
+	case "interests":
+		return calculateInterestsOverlap(self.Interests, other.Interests)
+	case "values":
+		return calculateValuesAlignment(self.Values, other.Values)
+	case "lifestyle":
+		return calculateLifestyleMatch(self.Lifestyle, other.Lifestyle)
+	}
+	return 70.0
+}
+
+// sectionAnswerScore is the weighted mean of the per-question similarity of
+// every question both respondents answered, scaled to 0-100. It reports
+// false when no question in the section can be compared.
+func sectionAnswerScore(def *entities.SurveyDefinition, config MatchingConfig, section string, self, other *entities.SurveyResponse) (float64, bool) {
+	totalWeight, score := 0.0, 0.0
+	for i := range def.Questions {
+		question := &def.Questions[i]
+		if question.Section != section || question.Scoring == entities.ScoringMatch {
+			continue
+		}
+
+		weight := config.questionWeight(question)
+		if config.QuestionScoring.UseImportance {
+			weight *= importanceMultiplier(self.Responses, question.ID)
+		}
+		if weight <= 0 {
+			continue
+		}
+
+		similarity, ok := AnswerSimilarity(question, self.Responses[question.ID], other.Responses[question.ID])
+		if !ok {
+			continue
+		}
+		totalWeight += weight
+		score += similarity * weight
+	}
+
+	if totalWeight == 0 {
+		return 0, false
+	}
+	return score / totalWeight * 100, true
+}
+
+// AnswerSimilarity compares two answers to one question (0-1). Scales use
+// Likert distance, multi-selects use Jaccard overlap and multiple choice
+// compares equality; complement questions invert the result. It reports
+// false when either answer is missing or unreadable.
+func AnswerSimilarity(question *entities.SurveyQuestion, a, b interface{}) (float64, bool) {
+	if !answered(a) || !answered(b) {
+		return 0, false
+	}
+
+	switch question.Type {
+	case entities.QuestionTypeScale:
+		va, okA := likertValue(a)
+		vb, okB := likertValue(b)
+		if !okA || !okB {
+			return 0, false
+		}
+		if question.Scoring == entities.ScoringComplement {
+			// Opposite ends of the scale fit best: 1+5, 2+4, 3+3
+			return 1 - math.Abs(va+vb-6)/4, true
+		}
+		return 1 - math.Abs(va-vb)/4, true
+
+	case entities.QuestionTypeMultiSelect:
+		setA, setB := answerSet(a), answerSet(b)
+		if len(setA) == 0 || len(setB) == 0 {
+			return 0, false
+		}
+		shared := 0
+		for option := range setA {
+			if setB[option] {
+				shared++
+			}
+		}
+		jaccard := float64(shared) / float64(len(setA)+len(setB)-shared)
+		if question.Scoring == entities.ScoringComplement {
+			return 1 - jaccard, true
+		}
+		return jaccard, true
+
+	case entities.QuestionTypeMultipleChoice:
+		sa, okA := answerString(a)
+		sb, okB := answerString(b)
+		if !okA || !okB {
+			return 0, false
+		}
+		same := sa == sb
+		if question.Scoring == entities.ScoringComplement {
+			same = !same
+		}
+		if same {
+			return 1, true
+		}
+		return 0, true
+	}
+
+	return 0, false
+}
+
+// HasDealbreaker reports whether other gave an answer that self ruled out
+func HasDealbreaker(def *entities.SurveyDefinition, self, other *entities.SurveyResponse) bool {
+	rules, ok := self.Responses[DealbreakersKey].(map[string]interface{})
+	if !ok {
+		return false
+	}
+
+	for questionID, raw := range rules {
+		if def != nil && def.Question(questionID) == nil {
+			continue
+		}
+		excluded := answerSet(raw)
+		for option := range answerSet(other.Responses[questionID]) {
+			if excluded[option] {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// importanceMultiplier turns a respondent's importance rating into a weight
+// multiplier: none 0, low 0.5, default 1, high 1.5
+func importanceMultiplier(responses map[string]interface{}, questionID string) float64 {
+	level := float64(ImportanceDefault)
+	if ratings, ok := responses[ImportanceKey].(map[string]interface{}); ok {
+		if v, ok := importanceLevel(ratings[questionID]); ok {
+			level = v
+		}
+	}
+	return level / ImportanceDefault
+}
+
+func importanceLevel(raw interface{}) (float64, bool) {
+	var v float64
+	switch val := raw.(type) {
+	case float64:
+		v = val
+	case int:
+		v = float64(val)
+	default:
+		return 0, false
+	}
+	if v < ImportanceNone || v > ImportanceHigh {
+		return 0, false
+	}
+	return math.Round(v), true
+}
+
+// answerString normalizes a single answer; JSON numbers become their string form
+func answerString(raw interface{}) (string, bool) {
+	switch v := raw.(type) {
+	case string:
+		return v, v != ""
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), true
+	case int:
+		return strconv.Itoa(v), true
+	}
+	return "", false
+}
+
+// answerSet turns a single or multi-select answer into a set of options
+func answerSet(raw interface{}) map[string]bool {
+	set := make(map[string]bool)
+	switch v := raw.(type) {
+	case []interface{}:
+		for _, item := range v {
+			if s, ok := answerString(item); ok {
+				set[s] = true
+			}
+		}
+	case []string:
+		for _, s := range v {
+			set[s] = true
+		}
+	default:
+		if s, ok := answerString(v); ok {
+			set[s] = true
+		}
+	}
+	return set
+}
@@ -0,0 +1,60 @@
+package services
+
+import (
+	"math"
+
+	"wizard-connect/internal/domain/entities"
+)
+
+// Ways of combining the two directional scores of a pair, set with the
+// campaign config's "reciprocity" key
+const (
+	ReciprocityHarmonic    = "harmonic"    // harmonic mean, pulled towards the lower side
+	ReciprocityGeometric   = "geometric"   // geometric mean
+	ReciprocityMinimum     = "minimum"     // the less interested side decides
+	ReciprocityDirectional = "directional" // only self's view, as before reciprocal scoring
+)
+
+// ReciprocalCompatibility is how well a pair fits from both sides
+type ReciprocalCompatibility struct {
+	// Score combines both directions and is what pairs are ranked by
+	Score float64 `json:"score"`
+	// SelfToOther is how well other fits self's preferences, OtherToSelf the reverse
+	SelfToOther float64 `json:"self_to_other"`
+	OtherToSelf float64 `json:"other_to_self"`
+	Dealbreaker bool    `json:"dealbreaker"`
+}
+
+// ScoreReciprocal scores a pair in both directions and combines them with
+// the config's reciprocity mode, so a pair only ranks highly when both
+// people's preferences are met
+func ScoreReciprocal(def *entities.SurveyDefinition, config MatchingConfig, self, other *entities.SurveyResponse) ReciprocalCompatibility {
+	forward := ScoreCompatibility(def, config, self, other)
+	backward := ScoreCompatibility(def, config, other, self)
+
+	result := ReciprocalCompatibility{
+		SelfToOther: forward.Score,
+		OtherToSelf: backward.Score,
+		Dealbreaker: forward.Dealbreaker || backward.Dealbreaker,
+	}
+	if !result.Dealbreaker {
+		result.Score = combineScores(config.Reciprocity, forward.Score, backward.Score)
+	}
+	return result
+}
+
+// combineScores merges two directional scores (0-100)
+func combineScores(mode string, a, b float64) float64 {
+	switch mode {
+	case ReciprocityDirectional:
+		return a
+	case ReciprocityGeometric:
+		return math.Sqrt(a * b)
+	case ReciprocityMinimum:
+		return math.Min(a, b)
+	}
+	if a+b == 0 {
+		return 0
+	}
+	return 2 * a * b / (a + b)
+}
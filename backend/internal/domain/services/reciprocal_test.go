@@ -0,0 +1,114 @@
+package services
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"wizard-connect/internal/domain/entities"
+)
+
+// asymmetricFixture has ada, who cares a lot about values_religion, and bea,
+// who answers it the opposite way but doesn't care, so ada likes bea less
+// than bea likes ada. cal answers differently from both.
+func asymmetricFixture(t *testing.T, config map[string]interface{}) *matchingFixture {
+	t.Helper()
+	f := newMatchingFixture(t, config)
+	f.addParticipant(t, "ada", "ada@example.edu", 1, map[string]interface{}{
+		"values_religion": "1",
+		ImportanceKey:     map[string]interface{}{"values_religion": float64(ImportanceHigh)},
+	})
+	f.addParticipant(t, "bea", "bea@example.edu", 1, map[string]interface{}{
+		"values_religion": "5",
+		ImportanceKey:     map[string]interface{}{"values_religion": float64(ImportanceNone)},
+	})
+	f.addParticipant(t, "cal", "cal@example.edu", 2, nil)
+	return f
+}
+
+func TestScoreReciprocalIsAsymmetric(t *testing.T) {
+	f := asymmetricFixture(t, nil)
+	pool, err := f.service.loadPool(context.Background(), f.campaign, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ada, bea := pool.participants[0].Survey, pool.participants[1].Survey
+
+	forward := ScoreReciprocal(pool.def, pool.config, ada, bea)
+	backward := ScoreReciprocal(pool.def, pool.config, bea, ada)
+	if forward.SelfToOther >= forward.OtherToSelf {
+		t.Fatalf("ada -> bea = %.2f, bea -> ada = %.2f; want ada to like bea less", forward.SelfToOther, forward.OtherToSelf)
+	}
+	if math.Abs(forward.SelfToOther-backward.OtherToSelf) > 1e-9 || math.Abs(forward.OtherToSelf-backward.SelfToOther) > 1e-9 {
+		t.Errorf("directions don't mirror: %+v vs %+v", forward, backward)
+	}
+	if math.Abs(forward.Score-backward.Score) > 1e-9 {
+		t.Errorf("pair score depends on who asks: %.4f vs %.4f", forward.Score, backward.Score)
+	}
+
+	a, b := forward.SelfToOther, forward.OtherToSelf
+	tests := []struct {
+		mode string
+		want float64
+	}{
+		{"", 2 * a * b / (a + b)},
+		{ReciprocityHarmonic, 2 * a * b / (a + b)},
+		{ReciprocityGeometric, math.Sqrt(a * b)},
+		{ReciprocityMinimum, a},
+		{ReciprocityDirectional, a},
+	}
+	for _, tt := range tests {
+		config := pool.config
+		config.Reciprocity = tt.mode
+		if got := ScoreReciprocal(pool.def, config, ada, bea).Score; math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("reciprocity %q: score = %.4f, want %.4f", tt.mode, got, tt.want)
+		}
+	}
+}
+
+func TestRunRanksByHarmonicMean(t *testing.T) {
+	f := asymmetricFixture(t, map[string]interface{}{"num_matches": 2})
+	run, err := f.service.RunCampaign(context.Background(), f.campaign, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	matches := make(map[[2]string]*entities.Match)
+	lists := make(map[string][]*entities.Match)
+	for _, m := range run.Matches {
+		matches[[2]string{m.UserID, m.MatchedUserID}] = m
+		lists[m.UserID] = append(lists[m.UserID], m)
+	}
+	if len(matches) != 6 {
+		t.Fatalf("want every pair matched both ways, got %d matches", len(matches))
+	}
+
+	for pair, m := range matches {
+		want := 2 * m.ScoreFromUser * m.ScoreFromMatched / (m.ScoreFromUser + m.ScoreFromMatched)
+		if math.Abs(m.CompatibilityScore-want) > 1e-9 {
+			t.Errorf("%v: score %.4f isn't the harmonic mean %.4f of %.4f and %.4f",
+				pair, m.CompatibilityScore, want, m.ScoreFromUser, m.ScoreFromMatched)
+		}
+		reverse := matches[[2]string{pair[1], pair[0]}]
+		// Section scores are summed in map order, so allow for rounding
+		if math.Abs(m.ScoreFromUser-reverse.ScoreFromMatched) > 1e-9 || math.Abs(m.ScoreFromMatched-reverse.ScoreFromUser) > 1e-9 {
+			t.Errorf("%v: directional scores aren't stored per direction", pair)
+		}
+	}
+
+	ab := matches[[2]string{"ada", "bea"}]
+	if ab.ScoreFromUser >= ab.ScoreFromMatched {
+		t.Errorf("ada -> bea stored as %.2f from ada and %.2f from bea; want ada's side lower", ab.ScoreFromUser, ab.ScoreFromMatched)
+	}
+
+	for userID, list := range lists {
+		for i, m := range list {
+			if m.Rank != i+1 {
+				t.Errorf("%s's list is out of rank order", userID)
+			}
+			if i > 0 && m.CompatibilityScore > list[i-1].CompatibilityScore {
+				t.Errorf("%s ranks %s above a higher reciprocal score", userID, list[i-1].MatchedUserID)
+			}
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"wizard-connect/internal/domain/entities"
+)
+
+var (
+	ErrRegistrationEmailMissing = errors.New("an email address is needed to sign up")
+	ErrRegistrationDomain       = errors.New("sign up with your university email address")
+)
+
+// RegistrationUserRepository is what registration needs from the user store
+type RegistrationUserRepository interface {
+	GetByID(ctx context.Context, id string) (*entities.User, error)
+	Create(ctx context.Context, user *entities.User) error
+}
+
+// RegistrationOverrideRepository reports whether admins let an email
+// register despite its domain
+type RegistrationOverrideRepository interface {
+	IsOverridden(ctx context.Context, email string) (bool, error)
+}
+
+// RegistrationService decides who may join and creates their profile the
+// first time they sign in
+type RegistrationService struct {
+	domains      []string
+	userRepo     RegistrationUserRepository
+	overrideRepo RegistrationOverrideRepository
+}
+
+// NewRegistrationService limits sign-ups to addresses on allowedDomains or
+// their subdomains ("example.edu" also admits "cs.example.edu"). No domains
+// lets anyone in.
+func NewRegistrationService(allowedDomains []string, userRepo RegistrationUserRepository, overrideRepo RegistrationOverrideRepository) *RegistrationService {
+	return &RegistrationService{domains: normalizeDomains(allowedDomains), userRepo: userRepo, overrideRepo: overrideRepo}
+}
+
+// AllowedDomains returns the normalized allow-list
+func (s *RegistrationService) AllowedDomains() []string {
+	return append([]string(nil), s.domains...)
+}
+
+// CheckEmail returns nil when email may register: its domain is allowed
+// or admins added it to the override list
+func (s *RegistrationService) CheckEmail(ctx context.Context, email string) error {
+	if len(s.domains) == 0 {
+		return nil
+	}
+	email = strings.ToLower(strings.TrimSpace(email))
+	if email == "" {
+		return ErrRegistrationEmailMissing
+	}
+	if EmailDomainAllowed(s.domains, email) {
+		return nil
+	}
+
+	overridden, err := s.overrideRepo.IsOverridden(ctx, email)
+	if err != nil {
+		return fmt.Errorf("failed to check registration overrides: %w", err)
+	}
+	if !overridden {
+		return ErrRegistrationDomain
+	}
+	return nil
+}
+
+// EnsureUser returns the user's profile, creating an empty one on their
+// first sign-in if their email may register. Profiles that already exist
+// are never checked again, so tightening the allow-list doesn't lock out
+// people who joined before.
+func (s *RegistrationService) EnsureUser(ctx context.Context, userID, email string) (*entities.User, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err == nil {
+		return user, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+
+	if err := s.CheckEmail(ctx, email); err != nil {
+		return nil, err
+	}
+
+	user = &entities.User{
+		ID:               userID,
+		Email:            email,
+		ContactPref:      entities.ContactPrefEmail,
+		Visibility:       entities.VisibilityMatchesOnly,
+		Gender:           "prefer_not_to_say",
+		GenderPreference: "both",
+	}
+	if err := s.userRepo.Create(ctx, user); err != nil {
+		return nil, fmt.Errorf("failed to create user profile: %w", err)
+	}
+	fmt.Printf("DEBUG: Shell user created for %s\n", userID)
+	return user, nil
+}
+
+// RegistrationErrorCode is the API error code for a refused sign-up, or ""
+func RegistrationErrorCode(err error) string {
+	switch {
+	case errors.Is(err, ErrRegistrationEmailMissing):
+		return "email_required"
+	case errors.Is(err, ErrRegistrationDomain):
+		return "email_domain_not_allowed"
+	}
+	return ""
+}
+
+// normalizeDomains lowercases domains and drops blanks and leading "@"s
+func normalizeDomains(domains []string) []string {
+	var normalized []string
+	for _, domain := range domains {
+		domain = strings.TrimPrefix(strings.ToLower(strings.TrimSpace(domain)), "@")
+		if domain != "" {
+			normalized = append(normalized, domain)
+		}
+	}
+	return normalized
+}
+
+// EmailDomainAllowed reports whether email's domain is one of domains (as
+// normalizeDomains leaves them) or a subdomain of one
+func EmailDomainAllowed(domains []string, email string) bool {
+	at := strings.LastIndex(email, "@")
+	if at < 0 {
+		return false
+	}
+	domain := strings.ToLower(email[at+1:])
+	for _, allowed := range domains {
+		if domain == allowed || strings.HasSuffix(domain, "."+allowed) {
+			return true
+		}
+	}
+	return false
+}
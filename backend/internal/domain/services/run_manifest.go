@@ -0,0 +1,205 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"runtime/debug"
+	"sort"
+
+	"wizard-connect/internal/domain/entities"
+)
+
+// AlgorithmVersion identifies the matching algorithm. Bump it whenever a
+// change can alter the matches produced for the same inputs and config.
+const AlgorithmVersion = "2.1"
+
+// CodeVersion is the VCS revision the binary was built from. It can be set
+// at build time with -ldflags "-X wizard-connect/internal/domain/services.CodeVersion=..."
+var CodeVersion = ""
+
+func codeVersion() string {
+	if CodeVersion != "" {
+		return CodeVersion
+	}
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "unknown"
+	}
+	revision, modified := "", false
+	for _, setting := range info.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			revision = setting.Value
+		case "vcs.modified":
+			modified = setting.Value == "true"
+		}
+	}
+	if revision == "" {
+		return "unknown"
+	}
+	if modified {
+		revision += "-dirty"
+	}
+	return revision
+}
+
+// ReplayRun repeats a past run: the campaign's current participants are
+// matched with the config, eligibility rules and seed recorded in the
+// manifest rather than the campaign's current ones. Nothing is written.
+func (s *matchingService) ReplayRun(ctx context.Context, campaign *entities.Campaign, manifest entities.RunManifest) (*CampaignRun, error) {
+	replay := *campaign
+	// The recorded config carries the run's eligibility rules, if it had any
+	replay.Config = manifest.Config
+	if manifest.SurveyVersion != "" {
+		replay.SurveyVersion = manifest.SurveyVersion
+	}
+	return s.RunCampaign(ctx, &replay, manifest.Seed)
+}
+
+// manifestConfig is the config a manifest records: the matching config and
+// the campaign's eligibility rules, which decide who takes part. Rules are
+// left out when there are none, so such runs keep their config hash.
+type manifestConfig struct {
+	MatchingConfig
+	Eligibility *CampaignEligibility `json:"eligibility,omitempty"`
+}
+
+// buildManifest records the inputs, config and output of a run
+func buildManifest(pool *matchingPool, campaign *entities.Campaign, matches []*entities.Match) (entities.RunManifest, error) {
+	manifest := entities.RunManifest{
+		AlgorithmVersion: AlgorithmVersion,
+		CodeVersion:      codeVersion(),
+		SurveyVersion:    pool.def.Version,
+		Seed:             pool.seed,
+	}
+
+	config := manifestConfig{MatchingConfig: pool.config}
+	if eligibility := CampaignEligibilityRules(campaign); len(eligibility.Years) > 0 || len(eligibility.EmailDomains) > 0 {
+		config.Eligibility = &eligibility
+	}
+	configJSON, err := json.Marshal(config)
+	if err != nil {
+		return manifest, fmt.Errorf("failed to encode matching config: %w", err)
+	}
+	if err := json.Unmarshal(configJSON, &manifest.Config); err != nil {
+		return manifest, fmt.Errorf("failed to encode matching config: %w", err)
+	}
+	manifest.ConfigHash = hashBytes(configJSON)
+
+	manifest.Inputs.Participants = len(pool.participants)
+	for _, p := range pool.participants {
+		if p.User != nil {
+			manifest.Inputs.Users++
+		}
+		manifest.Inputs.Crushes += len(pool.crushes[p.Survey.UserID])
+	}
+	manifest.Inputs.Vetoes = len(pool.vetoes)
+	manifest.Inputs.Blocks = len(pool.blocks)
+
+	manifest.InputHash, err = hashInputs(pool)
+	if err != nil {
+		return manifest, err
+	}
+	manifest.OutputHash = HashMatches(matches)
+	return manifest, nil
+}
+
+// hashInputs fingerprints everything the matcher reads about participants.
+// Participants are already in user ID order, and maps encode with sorted keys.
+func hashInputs(pool *matchingPool) (string, error) {
+	type participantInput struct {
+		UserID            string                 `json:"user_id"`
+		Responses         map[string]interface{} `json:"responses"`
+		PersonalityType   string                 `json:"personality_type"`
+		PersonalityTraits []float64              `json:"personality_traits"`
+		Interests         []string               `json:"interests"`
+		Values            []string               `json:"values"`
+		Lifestyle         string                 `json:"lifestyle"`
+		Email             string                 `json:"email"`
+		Gender            string                 `json:"gender"`
+		GenderPreference  string                 `json:"gender_preference"`
+		Year              string                 `json:"year"`
+		Major             string                 `json:"major"`
+		Crushes           []string               `json:"crushes"`
+	}
+
+	h := sha256.New()
+	encoder := json.NewEncoder(h)
+	for _, p := range pool.participants {
+		input := participantInput{
+			UserID:            p.Survey.UserID,
+			Responses:         p.Survey.Responses,
+			PersonalityType:   p.Survey.PersonalityType,
+			PersonalityTraits: p.Survey.PersonalityTraits,
+			Interests:         p.Survey.Interests,
+			Values:            p.Survey.Values,
+			Lifestyle:         p.Survey.Lifestyle,
+		}
+		if p.User != nil {
+			input.Email = p.User.Email
+			input.Gender = p.User.Gender
+			input.GenderPreference = p.User.GenderPreference
+			input.Year = p.User.Year
+			input.Major = p.User.Major
+		}
+		for email := range pool.crushes[p.Survey.UserID] {
+			input.Crushes = append(input.Crushes, email)
+		}
+		sort.Strings(input.Crushes)
+
+		if err := encoder.Encode(input); err != nil {
+			return "", fmt.Errorf("failed to hash run inputs: %w", err)
+		}
+	}
+
+	// Vetoed pairs are only hashed when there are any, so runs from before
+	// vetoes existed keep their input hash
+	if len(pool.vetoes) > 0 {
+		pairs := make([]string, 0, len(pool.vetoes))
+		for pair := range pool.vetoes {
+			pairs = append(pairs, pair[0]+":"+pair[1])
+		}
+		sort.Strings(pairs)
+		if err := encoder.Encode(pairs); err != nil {
+			return "", fmt.Errorf("failed to hash run inputs: %w", err)
+		}
+	}
+	// Blocked pairs likewise, tagged so they never hash like vetoes
+	if len(pool.blocks) > 0 {
+		pairs := make([]string, 0, len(pool.blocks))
+		for pair := range pool.blocks {
+			pairs = append(pairs, "block:"+pair[0]+":"+pair[1])
+		}
+		sort.Strings(pairs)
+		if err := encoder.Encode(pairs); err != nil {
+			return "", fmt.Errorf("failed to hash run inputs: %w", err)
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// HashMatches fingerprints a run's output: every list, in order, with scores
+// rounded to the precision they are stored with
+func HashMatches(matches []*entities.Match) string {
+	sorted := append([]*entities.Match(nil), matches...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].UserID != sorted[j].UserID {
+			return sorted[i].UserID < sorted[j].UserID
+		}
+		return sorted[i].Rank < sorted[j].Rank
+	})
+
+	h := sha256.New()
+	for _, m := range sorted {
+		fmt.Fprintf(h, "%s|%d|%s|%.2f|%t\n", m.UserID, m.Rank, m.MatchedUserID, m.CompatibilityScore, m.IsMutualCrush)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func hashBytes(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
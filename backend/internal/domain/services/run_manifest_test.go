@@ -0,0 +1,225 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"wizard-connect/internal/domain/entities"
+	"wizard-connect/internal/infrastructure/memory"
+)
+
+// matchingFixture is a matching service over in-memory repositories with a
+// single campaign
+type matchingFixture struct {
+	service  *matchingService
+	users    *memory.UserRepository
+	surveys  *memory.SurveyRepository
+	campaign *entities.Campaign
+}
+
+func newMatchingFixture(t *testing.T, config map[string]interface{}) *matchingFixture {
+	t.Helper()
+	store := memory.NewStore()
+	f := &matchingFixture{
+		users:    memory.NewUserRepository(store),
+		surveys:  memory.NewSurveyRepository(store),
+		campaign: &entities.Campaign{ID: "campaign-1", Name: "Test", IsActive: true, Config: config},
+	}
+	f.service = NewMatchingService(
+		f.surveys,
+		memory.NewCrushRepository(store),
+		memory.NewMatchRepository(store),
+		f.users,
+		memory.NewMatchVetoRepository(store),
+		memory.NewUserBlockRepository(store),
+		memory.NewModerationActionRepository(store),
+		nil,
+	).(*matchingService)
+	return f
+}
+
+// addParticipant stores a user and their completed survey for the campaign.
+// The variant picks different answers so participants don't all score alike;
+// overrides replace individual answers.
+func (f *matchingFixture) addParticipant(t *testing.T, id, email string, variant int, overrides map[string]interface{}) {
+	t.Helper()
+	ctx := context.Background()
+	if err := f.users.Create(ctx, &entities.User{ID: id, Email: email}); err != nil {
+		t.Fatal(err)
+	}
+
+	def, err := GetSurveyDefinition("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	answers := make(map[string]interface{})
+	for i, q := range def.Questions {
+		if len(q.Options) == 0 {
+			continue
+		}
+		option := q.Options[(variant*(i+1))%len(q.Options)]
+		if q.Type == entities.QuestionTypeMultiSelect {
+			answers[q.ID] = []interface{}{option}
+		} else {
+			answers[q.ID] = option
+		}
+	}
+	answers["gender"] = "female"
+	answers["seeking_gender"] = []interface{}{"male", "female", "non_binary"}
+	for id, value := range overrides {
+		answers[id] = value
+	}
+
+	survey := &entities.SurveyResponse{
+		UserID:            id,
+		CampaignID:        f.campaign.ID,
+		DefinitionVersion: def.Version,
+		Responses:         answers,
+		IsComplete:        true,
+		CompletedAt:       time.Now(),
+	}
+	survey.Interests, survey.Values, survey.Lifestyle = SummarizeResponses(answers)
+	if traits, ok := DeriveTraits(answers); ok {
+		survey.PersonalityTraits = traits.Slice()
+		survey.PersonalityType = traits.Type()
+	}
+	if err := f.surveys.CreateOrUpdate(ctx, survey); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRunCampaignIsDeterministic(t *testing.T) {
+	ctx := context.Background()
+	f := newMatchingFixture(t, map[string]interface{}{
+		"num_matches": 2,
+		"fairness":    map[string]interface{}{"max_appearances": 2, "exposure_penalty": 5},
+	})
+	for i, id := range []string{"ada", "bea", "cal", "dan", "eve", "fay"} {
+		f.addParticipant(t, id, id+"@example.edu", i+1, nil)
+	}
+
+	first, err := f.service.RunCampaign(ctx, f.campaign, 42)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := f.service.RunCampaign(ctx, f.campaign, 42)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(first.Matches) == 0 {
+		t.Fatal("run produced no matches")
+	}
+	if first.Manifest.OutputHash != second.Manifest.OutputHash {
+		t.Errorf("same seed gave output hashes %s and %s", first.Manifest.OutputHash, second.Manifest.OutputHash)
+	}
+	if first.Manifest.InputHash != second.Manifest.InputHash || first.Manifest.ConfigHash != second.Manifest.ConfigHash {
+		t.Error("same inputs and config hashed differently")
+	}
+	if got := HashMatches(first.Matches); got != first.Manifest.OutputHash {
+		t.Errorf("manifest output hash %s doesn't fingerprint the run's matches (%s)", first.Manifest.OutputHash, got)
+	}
+}
+
+func TestReplayRunReproducesManifest(t *testing.T) {
+	ctx := context.Background()
+	f := newMatchingFixture(t, map[string]interface{}{"num_matches": 2})
+	for i, id := range []string{"ada", "bea", "cal", "dan", "eve"} {
+		f.addParticipant(t, id, id+"@example.edu", i+1, nil)
+	}
+
+	run, err := f.service.RunCampaign(ctx, f.campaign, 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The campaign's config changing since doesn't affect the replay
+	f.campaign.Config = map[string]interface{}{"num_matches": 4}
+	changed, err := f.service.RunCampaign(ctx, f.campaign, 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if changed.Manifest.OutputHash == run.Manifest.OutputHash {
+		t.Fatal("changing num_matches didn't change the output")
+	}
+
+	// Replay from the manifest as it comes back from storage
+	data, err := json.Marshal(run.Manifest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var stored entities.RunManifest
+	if err := json.Unmarshal(data, &stored); err != nil {
+		t.Fatal(err)
+	}
+
+	replay, err := f.service.ReplayRun(ctx, f.campaign, stored)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if replay.Manifest.OutputHash != run.Manifest.OutputHash {
+		t.Errorf("replay output hash %s, want %s", replay.Manifest.OutputHash, run.Manifest.OutputHash)
+	}
+	if replay.Manifest.InputHash != run.Manifest.InputHash || replay.Manifest.ConfigHash != run.Manifest.ConfigHash {
+		t.Error("replay didn't see the same inputs and config")
+	}
+}
+
+func TestReplayRunKeepsEligibility(t *testing.T) {
+	ctx := context.Background()
+	rules := map[string]interface{}{"years": []interface{}{1, 2}, "email_domains": []interface{}{"example.edu"}}
+	f := newMatchingFixture(t, map[string]interface{}{"num_matches": 2, "eligibility": rules})
+	f.addParticipant(t, "ada", "ada@example.edu", 1, map[string]interface{}{"year": "1st_year"})
+	f.addParticipant(t, "bea", "bea@example.edu", 2, map[string]interface{}{"year": "2nd_year"})
+	f.addParticipant(t, "cal", "cal@example.edu", 3, map[string]interface{}{"year": "1st_year"})
+	f.addParticipant(t, "dan", "dan@example.edu", 4, map[string]interface{}{"year": "4th_year"})
+	f.addParticipant(t, "eve", "eve@other.org", 5, map[string]interface{}{"year": "1st_year"})
+
+	run, err := f.service.RunCampaign(ctx, f.campaign, 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if run.Participants != 3 {
+		t.Fatalf("run had %d participants, want the 3 eligible ones", run.Participants)
+	}
+	if _, ok := run.Manifest.Config["eligibility"]; !ok {
+		t.Errorf("manifest config doesn't record the eligibility rules: %v", run.Manifest.Config)
+	}
+
+	// The rules being dropped since doesn't affect the replay
+	f.campaign.Config = map[string]interface{}{"num_matches": 2}
+	open, err := f.service.RunCampaign(ctx, f.campaign, 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if open.Participants != 5 {
+		t.Fatalf("run without rules had %d participants, want 5", open.Participants)
+	}
+	if open.Manifest.ConfigHash == run.Manifest.ConfigHash {
+		t.Error("config hash ignores the eligibility rules")
+	}
+
+	data, err := json.Marshal(run.Manifest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var stored entities.RunManifest
+	if err := json.Unmarshal(data, &stored); err != nil {
+		t.Fatal(err)
+	}
+
+	replay, err := f.service.ReplayRun(ctx, f.campaign, stored)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if replay.Participants != run.Participants {
+		t.Errorf("replay had %d participants, want %d", replay.Participants, run.Participants)
+	}
+	if replay.Manifest.OutputHash != run.Manifest.OutputHash {
+		t.Errorf("replay output hash %s, want %s", replay.Manifest.OutputHash, run.Manifest.OutputHash)
+	}
+	if replay.Manifest.ConfigHash != run.Manifest.ConfigHash {
+		t.Error("replay didn't see the same config")
+	}
+}
@@ -0,0 +1,28 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"wizard-connect/internal/domain/entities"
+)
+
+// BlockRepository lists the blocks between users
+type BlockRepository interface {
+	ListBlocks(ctx context.Context) ([]*entities.UserBlock, error)
+}
+
+// SuspensionRepository lists the users locked out by a suspension or ban
+type SuspensionRepository interface {
+	ListSuspendedUserIDs(ctx context.Context, at time.Time) ([]string, error)
+}
+
+// NewBlockSet rules out every blocked pair, whoever blocked whom
+func NewBlockSet(blocks []*entities.UserBlock) VetoSet {
+	set := make(VetoSet, len(blocks))
+	for _, block := range blocks {
+		a, b := entities.OrderedPair(block.BlockerID, block.BlockedID)
+		set[[2]string{a, b}] = true
+	}
+	return set
+}
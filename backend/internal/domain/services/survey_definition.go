@@ -0,0 +1,165 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"wizard-connect/internal/domain/entities"
+)
+
+// DefaultSurveyVersion is used by campaigns that don't pin a version and by
+// surveys saved before versioning existed
+const DefaultSurveyVersion = "v1"
+
+var likertOptions = []string{"1", "2", "3", "4", "5"}
+
+// surveyDefinitions holds every question set a campaign can use. Add a new
+// version instead of editing a published one, so old responses stay readable.
+var surveyDefinitions = map[string]*entities.SurveyDefinition{
+	"v1": {
+		Version: "v1",
+		Questions: []entities.SurveyQuestion{
+			{ID: "year", Section: "demographics", Type: entities.QuestionTypeMultipleChoice, Weight: 0.05, Scoring: entities.ScoringSimilarity, Required: true,
+				Options: []string{"1st_year", "2nd_year", "3rd_year", "4th_year", "5th_year", "graduate"}},
+			{ID: "major", Section: "demographics", Type: entities.QuestionTypeMultipleChoice, Weight: 0.05, Scoring: entities.ScoringSimilarity, Required: true,
+				Options: []string{"cs", "it", "ce", "ee", "me", "ce_civil", "archi", "ba", "acctg", "other"}},
+			{ID: "gender", Section: "demographics", Type: entities.QuestionTypeMultipleChoice, Weight: 0, Scoring: entities.ScoringMatch, Required: true,
+				Options: []string{"male", "female", "non_binary", "prefer_not_say"}},
+			{ID: "seeking_gender", Section: "demographics", Type: entities.QuestionTypeMultiSelect, Weight: 0, Scoring: entities.ScoringMatch, Required: true,
+				Options: []string{"male", "female", "non_binary"}},
+			{ID: "personality_introvert", Section: "personality", Type: entities.QuestionTypeScale, Weight: 0.08, Scoring: entities.ScoringComplement, Required: true, Options: likertOptions},
+			{ID: "personality_planner", Section: "personality", Type: entities.QuestionTypeScale, Weight: 0.06, Scoring: entities.ScoringSimilarity, Required: true, Options: likertOptions},
+			{ID: "personality_social", Section: "personality", Type: entities.QuestionTypeScale, Weight: 0.07, Scoring: entities.ScoringComplement, Required: true, Options: likertOptions},
+			{ID: "personality_adventurous", Section: "personality", Type: entities.QuestionTypeScale, Weight: 0.07, Scoring: entities.ScoringSimilarity, Required: true, Options: likertOptions},
+			{ID: "values_family", Section: "values", Type: entities.QuestionTypeScale, Weight: 0.08, Scoring: entities.ScoringSimilarity, Required: true, Options: likertOptions},
+			{ID: "values_career", Section: "values", Type: entities.QuestionTypeScale, Weight: 0.07, Scoring: entities.ScoringSimilarity, Required: true, Options: likertOptions},
+			{ID: "values_religion", Section: "values", Type: entities.QuestionTypeScale, Weight: 0.06, Scoring: entities.ScoringSimilarity, Required: true, Options: likertOptions},
+			{ID: "values_politics", Section: "values", Type: entities.QuestionTypeScale, Weight: 0.05, Scoring: entities.ScoringSimilarity, Required: true, Options: likertOptions},
+			{ID: "lifestyle_study_habits", Section: "lifestyle", Type: entities.QuestionTypeMultipleChoice, Weight: 0.06, Scoring: entities.ScoringComplement, Required: true,
+				Options: []string{"night_owl", "early_bird", "last_minute", "consistent"}},
+			{ID: "lifestyle_weekend", Section: "lifestyle", Type: entities.QuestionTypeMultipleChoice, Weight: 0.07, Scoring: entities.ScoringSimilarity, Required: true,
+				Options: []string{"party", "chill", "outdoors", "hobbies", "study"}},
+			{ID: "lifestyle_cleanliness", Section: "lifestyle", Type: entities.QuestionTypeScale, Weight: 0.05, Scoring: entities.ScoringSimilarity, Required: true, Options: likertOptions},
+			{ID: "interests_hobbies", Section: "interests", Type: entities.QuestionTypeMultiSelect, Weight: 0.15, Scoring: entities.ScoringSimilarity, Required: true,
+				Options: []string{"gaming", "music", "sports", "reading", "movies", "cooking", "travel", "photography",
+					"art", "fitness", "tech", "anime", "kpop", "fashion", "writing"}},
+			{ID: "interests_music_genre", Section: "interests", Type: entities.QuestionTypeMultiSelect, Weight: 0.08, Scoring: entities.ScoringSimilarity, Required: true,
+				Options: []string{"pop", "rock", "hiphop", "rnb", "jazz", "classical", "opm", "kpop", "electronic", "indie"}},
+			{ID: "crush_list", Section: "demographics", Type: entities.QuestionTypeCrushList},
+		},
+	},
+}
+
+var ErrUnknownSurveyVersion = errors.New("unknown survey definition version")
+
+// GetSurveyDefinition returns the question set for a version. An empty
+// version resolves to DefaultSurveyVersion.
+func GetSurveyDefinition(version string) (*entities.SurveyDefinition, error) {
+	if version == "" {
+		version = DefaultSurveyVersion
+	}
+	def, ok := surveyDefinitions[version]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownSurveyVersion, version)
+	}
+	return def, nil
+}
+
+// CampaignSurveyVersion returns the survey version a campaign collects
+// responses under. A nil campaign means no campaign is running.
+func CampaignSurveyVersion(campaign *entities.Campaign) string {
+	if campaign == nil || campaign.SurveyVersion == "" {
+		return DefaultSurveyVersion
+	}
+	return campaign.SurveyVersion
+}
+
+// PrefillResponses keeps the answers and preferences from a previous survey
+// that are still valid under def, so a returning user only has to answer what changed
+func PrefillResponses(def *entities.SurveyDefinition, previous map[string]interface{}) map[string]interface{} {
+	prefilled := make(map[string]interface{})
+	for id, value := range previous {
+		if id == ImportanceKey || id == DealbreakersKey || id == ConstraintsKey {
+			prefilled[id] = value
+			continue
+		}
+		question := def.Question(id)
+		if question == nil || !answerAllowed(question, value) {
+			continue
+		}
+		prefilled[id] = value
+	}
+	CleanPreferences(def, prefilled)
+	return prefilled
+}
+
+// answerAllowed reports whether value is one of the question's options.
+// Questions without options accept anything.
+func answerAllowed(question *entities.SurveyQuestion, value interface{}) bool {
+	if len(question.Options) == 0 {
+		return true
+	}
+
+	allowed := func(v interface{}) bool {
+		switch v.(type) {
+		case string, float64:
+		default:
+			return false
+		}
+		s, _ := answerString(v)
+		return optionAllowed(question, s)
+	}
+
+	if question.Type == entities.QuestionTypeMultiSelect {
+		values, ok := value.([]interface{})
+		if !ok {
+			return false
+		}
+		for _, v := range values {
+			if !allowed(v) {
+				return false
+			}
+		}
+		return true
+	}
+	return allowed(value)
+}
+
+// optionAllowed reports whether option is one of the question's options
+func optionAllowed(question *entities.SurveyQuestion, option string) bool {
+	if len(question.Options) == 0 {
+		return true
+	}
+	for _, o := range question.Options {
+		if o == option {
+			return true
+		}
+	}
+	return false
+}
+
+// DiffResponses lists the answers that differ between two surveys, sorted by question ID
+func DiffResponses(before, after map[string]interface{}) []entities.SurveyAnswerChange {
+	changes := []entities.SurveyAnswerChange{}
+	for id, old := range before {
+		current, ok := after[id]
+		switch {
+		case !ok:
+			changes = append(changes, entities.SurveyAnswerChange{QuestionID: id, Change: "removed", Before: old})
+		case !reflect.DeepEqual(old, current):
+			changes = append(changes, entities.SurveyAnswerChange{QuestionID: id, Change: "changed", Before: old, After: current})
+		}
+	}
+	for id, current := range after {
+		if _, ok := before[id]; !ok {
+			changes = append(changes, entities.SurveyAnswerChange{QuestionID: id, Change: "added", After: current})
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].QuestionID < changes[j].QuestionID
+	})
+	return changes
+}
@@ -0,0 +1,288 @@
+package services
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"strings"
+
+	"wizard-connect/internal/domain/entities"
+)
+
+var (
+	ErrUnknownSection  = errors.New("unknown survey section")
+	ErrUnknownQuestion = errors.New("question is not part of this section")
+	ErrInvalidAnswer   = errors.New("answer is not a valid option for this question")
+)
+
+// SectionQuestions returns the questions in one section of the definition
+func SectionQuestions(def *entities.SurveyDefinition, section string) []entities.SurveyQuestion {
+	var questions []entities.SurveyQuestion
+	for _, q := range def.Questions {
+		if q.Section == section {
+			questions = append(questions, q)
+		}
+	}
+	return questions
+}
+
+// ValidateSectionAnswers checks a partial save against one section. A nil
+// answer is allowed and means "clear this answer". Errors are keyed by question ID.
+func ValidateSectionAnswers(def *entities.SurveyDefinition, section string, answers map[string]interface{}) (map[string]string, error) {
+	if len(SectionQuestions(def, section)) == 0 {
+		return nil, ErrUnknownSection
+	}
+
+	fieldErrors := make(map[string]string)
+	for id, value := range answers {
+		question := def.Question(id)
+		if question == nil || question.Section != section {
+			fieldErrors[id] = ErrUnknownQuestion.Error()
+			continue
+		}
+		if value != nil && !answerAllowed(question, value) {
+			fieldErrors[id] = ErrInvalidAnswer.Error()
+		}
+	}
+	return fieldErrors, nil
+}
+
+// answered reports whether a stored answer counts towards completion
+func answered(value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return false
+	case string:
+		return strings.TrimSpace(v) != ""
+	case []interface{}:
+		return len(v) > 0
+	}
+	return true
+}
+
+// Completeness computes progress of responses against def. A survey is
+// complete once every required question has a valid answer.
+func Completeness(def *entities.SurveyDefinition, responses map[string]interface{}) *entities.SurveyCompleteness {
+	result := &entities.SurveyCompleteness{
+		MissingRequired: []string{},
+		InvalidAnswers:  []string{},
+		Sections:        make(map[string]*entities.SectionCompleteness),
+	}
+
+	totalRequired, totalAnswered := 0, 0
+	for i := range def.Questions {
+		question := &def.Questions[i]
+
+		section, ok := result.Sections[question.Section]
+		if !ok {
+			section = &entities.SectionCompleteness{}
+			result.Sections[question.Section] = section
+		}
+
+		value, present := responses[question.ID]
+		valid := present && answered(value) && answerAllowed(question, value)
+		if present && answered(value) && !valid {
+			result.InvalidAnswers = append(result.InvalidAnswers, question.ID)
+		}
+
+		if !question.Required {
+			continue
+		}
+		totalRequired++
+		section.Required++
+		if valid {
+			totalAnswered++
+			section.Answered++
+		} else {
+			result.MissingRequired = append(result.MissingRequired, question.ID)
+		}
+	}
+
+	for _, section := range result.Sections {
+		section.Complete = section.Answered == section.Required
+	}
+
+	result.Percent = 100
+	if totalRequired > 0 {
+		result.Percent = math.Round(float64(totalAnswered)/float64(totalRequired)*1000) / 10
+	}
+	result.Complete = totalAnswered == totalRequired
+	return result
+}
+
+// SummarizeResponses derives the summary fields the matcher compares from the
+// raw answers, mirroring what the survey page computes client-side
+func SummarizeResponses(responses map[string]interface{}) (interests []string, values []string, lifestyle string) {
+	interests, values = []string{}, []string{}
+	seen := make(map[string]bool)
+
+	keys := make([]string, 0, len(responses))
+	for id := range responses {
+		keys = append(keys, id)
+	}
+	sort.Strings(keys)
+
+	for _, id := range keys {
+		switch {
+		case strings.HasPrefix(id, "interests_"):
+			selected, ok := responses[id].([]interface{})
+			if !ok {
+				continue
+			}
+			for _, v := range selected {
+				if s, ok := v.(string); ok && !seen[s] {
+					seen[s] = true
+					interests = append(interests, s)
+				}
+			}
+		case strings.HasPrefix(id, "values_"):
+			if responses[id] != nil {
+				values = append(values, strings.Replace(strings.TrimPrefix(id, "values_"), "_", " ", 1))
+			}
+		}
+	}
+
+	lifestyles := map[string]string{
+		"night_owl":   "Night Owl",
+		"early_bird":  "Early Bird",
+		"last_minute": "Last Minute",
+		"consistent":  "Consistent",
+	}
+	lifestyle = "Flexible"
+	if habit, ok := responses["lifestyle_study_habits"].(string); ok {
+		if label, ok := lifestyles[habit]; ok {
+			lifestyle = label
+		}
+	}
+
+	return interests, values, lifestyle
+}
+
+var (
+	ErrInvalidImportance  = errors.New("importance must be between 0 and 3")
+	ErrInvalidDealbreaker = errors.New("dealbreakers must be options of this question")
+)
+
+// ValidateSectionPreferences checks importance ratings and dealbreakers sent
+// with a section save. A nil rating or empty dealbreaker list clears it.
+func ValidateSectionPreferences(def *entities.SurveyDefinition, section string, importance map[string]*int, dealbreakers map[string][]string) map[string]string {
+	fieldErrors := make(map[string]string)
+	check := func(id string) *entities.SurveyQuestion {
+		question := def.Question(id)
+		if question == nil || question.Section != section || question.Type == entities.QuestionTypeCrushList {
+			fieldErrors[id] = ErrUnknownQuestion.Error()
+			return nil
+		}
+		return question
+	}
+
+	for id, level := range importance {
+		if check(id) == nil {
+			continue
+		}
+		if level != nil && (*level < ImportanceNone || *level > ImportanceHigh) {
+			fieldErrors[id] = ErrInvalidImportance.Error()
+		}
+	}
+	for id, options := range dealbreakers {
+		question := check(id)
+		if question == nil {
+			continue
+		}
+		for _, option := range options {
+			if !optionAllowed(question, option) {
+				fieldErrors[id] = ErrInvalidDealbreaker.Error()
+				break
+			}
+		}
+	}
+	return fieldErrors
+}
+
+// ApplyPreferences merges importance ratings and dealbreakers into the
+// reserved response keys
+func ApplyPreferences(responses map[string]interface{}, importance map[string]*int, dealbreakers map[string][]string) {
+	if len(importance) > 0 {
+		ratings, _ := responses[ImportanceKey].(map[string]interface{})
+		if ratings == nil {
+			ratings = make(map[string]interface{})
+		}
+		for id, level := range importance {
+			if level == nil {
+				delete(ratings, id)
+				continue
+			}
+			ratings[id] = float64(*level)
+		}
+		setPreference(responses, ImportanceKey, ratings)
+	}
+
+	if len(dealbreakers) > 0 {
+		rules, _ := responses[DealbreakersKey].(map[string]interface{})
+		if rules == nil {
+			rules = make(map[string]interface{})
+		}
+		for id, options := range dealbreakers {
+			if len(options) == 0 {
+				delete(rules, id)
+				continue
+			}
+			excluded := make([]interface{}, len(options))
+			for i, option := range options {
+				excluded[i] = option
+			}
+			rules[id] = excluded
+		}
+		setPreference(responses, DealbreakersKey, rules)
+	}
+}
+
+func setPreference(responses map[string]interface{}, key string, value map[string]interface{}) {
+	if len(value) == 0 {
+		delete(responses, key)
+		return
+	}
+	responses[key] = value
+}
+
+// CleanPreferences drops importance ratings, dealbreakers and filters that don't fit
+// def, e.g. after a client submitted the whole survey or when pre-filling
+// from an older question set
+func CleanPreferences(def *entities.SurveyDefinition, responses map[string]interface{}) {
+	if ratings, ok := responses[ImportanceKey].(map[string]interface{}); ok {
+		cleaned := make(map[string]interface{})
+		for id, raw := range ratings {
+			question := def.Question(id)
+			if level, valid := importanceLevel(raw); valid && question != nil && question.Type != entities.QuestionTypeCrushList {
+				cleaned[id] = level
+			}
+		}
+		setPreference(responses, ImportanceKey, cleaned)
+	} else {
+		delete(responses, ImportanceKey)
+	}
+
+	if rules, ok := responses[DealbreakersKey].(map[string]interface{}); ok {
+		cleaned := make(map[string]interface{})
+		for id, raw := range rules {
+			question := def.Question(id)
+			if question == nil || question.Type == entities.QuestionTypeCrushList {
+				continue
+			}
+			excluded := []interface{}{}
+			for option := range answerSet(raw) {
+				if optionAllowed(question, option) {
+					excluded = append(excluded, option)
+				}
+			}
+			if len(excluded) > 0 {
+				cleaned[id] = excluded
+			}
+		}
+		setPreference(responses, DealbreakersKey, cleaned)
+	} else {
+		delete(responses, DealbreakersKey)
+	}
+
+	ApplyConstraints(responses, ConstraintsFromResponses(responses))
+}
@@ -0,0 +1,73 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"wizard-connect/internal/domain/entities"
+
+	"github.com/google/uuid"
+)
+
+type AuditLogRepository struct {
+	db *Database
+}
+
+func NewAuditLogRepository(db *Database) *AuditLogRepository {
+	return &AuditLogRepository{db: db}
+}
+
+func (r *AuditLogRepository) Create(ctx context.Context, entry *entities.AuditEntry) error {
+	if entry.ID == "" {
+		entry.ID = uuid.New().String()
+	}
+	detailsJSON, err := json.Marshal(entry.Details)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO admin_audit_log (id, actor_id, action, target_type, target_id, details)
+		VALUES ($1, NULLIF($2, '')::uuid, $3, $4, $5, $6)
+		RETURNING created_at
+	`
+
+	return r.db.QueryRow(ctx, query,
+		entry.ID, entry.ActorID, entry.Action, entry.TargetType, entry.TargetID, detailsJSON,
+	).Scan(&entry.CreatedAt)
+}
+
+// List returns entries newest first; empty filters match everything
+func (r *AuditLogRepository) List(ctx context.Context, targetType, targetID string, limit int) ([]*entities.AuditEntry, error) {
+	query := `
+		SELECT id, COALESCE(actor_id::text, ''), action, target_type, COALESCE(target_id, ''), details, created_at
+		FROM admin_audit_log
+		WHERE ($1 = '' OR target_type = $1) AND ($2 = '' OR target_id = $2)
+		ORDER BY created_at DESC
+		LIMIT $3
+	`
+
+	rows, err := r.db.Query(ctx, query, targetType, targetID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []*entities.AuditEntry
+	for rows.Next() {
+		var detailsJSON []byte
+		entry := &entities.AuditEntry{}
+		if err := rows.Scan(&entry.ID, &entry.ActorID, &entry.Action, &entry.TargetType, &entry.TargetID, &detailsJSON, &entry.CreatedAt); err != nil {
+			return nil, err
+		}
+		if len(detailsJSON) > 0 && string(detailsJSON) != "null" {
+			if err := json.Unmarshal(detailsJSON, &entry.Details); err != nil {
+				return nil, fmt.Errorf("failed to parse audit details: %w", err)
+			}
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
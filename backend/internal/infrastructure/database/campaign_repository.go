@@ -2,6 +2,7 @@ package database
 
 import (
 	"context"
+	"database/sql"
 	"encoding/json"
 
 	"wizard-connect/internal/domain/entities"
@@ -28,8 +29,8 @@ func (r *campaignRepositoryImpl) Create(ctx context.Context, campaign *entities.
 			profile_update_start_date, profile_update_end_date,
 			results_release_date, is_active, algorithm_version,
 			total_participants, total_matches_generated, config,
-			created_at, updated_at
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+			created_at, updated_at, survey_version
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
 	`
 
 	_, err = r.db.Exec(ctx, query,
@@ -47,6 +48,7 @@ func (r *campaignRepositoryImpl) Create(ctx context.Context, campaign *entities.
 		configJSON,
 		campaign.CreatedAt,
 		campaign.UpdatedAt,
+		campaign.SurveyVersion,
 	)
 
 	return err
@@ -58,7 +60,7 @@ func (r *campaignRepositoryImpl) GetByID(ctx context.Context, id string) (*entit
 		       profile_update_start_date, profile_update_end_date,
 		       results_release_date, is_active, algorithm_version,
 		       total_participants, total_matches_generated,
-		       config, created_at, updated_at
+		       config, created_at, updated_at, COALESCE(survey_version, '')
 		FROM campaigns
 		WHERE id = $1
 	`
@@ -81,6 +83,7 @@ func (r *campaignRepositoryImpl) GetByID(ctx context.Context, id string) (*entit
 		&configJSON,
 		&campaign.CreatedAt,
 		&campaign.UpdatedAt,
+		&campaign.SurveyVersion,
 	)
 
 	if err != nil {
@@ -94,13 +97,33 @@ func (r *campaignRepositoryImpl) GetByID(ctx context.Context, id string) (*entit
 	return &campaign, err
 }
 
+// GetActive returns the most recently created active campaign, or nil if none is active
+func (r *campaignRepositoryImpl) GetActive(ctx context.Context) (*entities.Campaign, error) {
+	query := `
+		SELECT id FROM campaigns
+		WHERE is_active = TRUE
+		ORDER BY created_at DESC
+		LIMIT 1
+	`
+
+	var id string
+	if err := r.db.QueryRow(ctx, query).Scan(&id); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return r.GetByID(ctx, id)
+}
+
 func (r *campaignRepositoryImpl) GetAll(ctx context.Context) ([]*entities.Campaign, error) {
 	query := `
 		SELECT id, name, survey_open_date, survey_close_date,
 		       profile_update_start_date, profile_update_end_date,
 		       results_release_date, is_active, algorithm_version,
 		       total_participants, total_matches_generated,
-		       config, created_at, updated_at
+		       config, created_at, updated_at, COALESCE(survey_version, '')
 		FROM campaigns
 		ORDER BY created_at DESC
 	`
@@ -132,6 +155,7 @@ func (r *campaignRepositoryImpl) GetAll(ctx context.Context) ([]*entities.Campai
 			&configJSON,
 			&campaign.CreatedAt,
 			&campaign.UpdatedAt,
+			&campaign.SurveyVersion,
 		)
 
 		if err != nil {
@@ -170,7 +194,8 @@ func (r *campaignRepositoryImpl) Update(ctx context.Context, campaign *entities.
 		    total_participants = $10,
 		    total_matches_generated = $11,
 		    config = $12,
-		    updated_at = $13
+		    updated_at = $13,
+		    survey_version = $14
 		WHERE id = $1
 	`
 
@@ -188,6 +213,7 @@ func (r *campaignRepositoryImpl) Update(ctx context.Context, campaign *entities.
 		campaign.TotalMatchesGenerated,
 		configJSON,
 		campaign.UpdatedAt,
+		campaign.SurveyVersion,
 	)
 
 	return err
@@ -0,0 +1,79 @@
+package database
+
+import (
+	"context"
+
+	"wizard-connect/internal/domain/entities"
+)
+
+type MatchFeedbackRepository struct {
+	db *Database
+}
+
+func NewMatchFeedbackRepository(db *Database) *MatchFeedbackRepository {
+	return &MatchFeedbackRepository{db: db}
+}
+
+const matchFeedbackColumns = `
+	id, match_id, user_id, matched_user_id, COALESCE(campaign_id::text, ''),
+	rating, met, COALESCE(comment, ''), created_at, updated_at`
+
+func scanMatchFeedback(row surveyScanner) (*entities.MatchFeedback, error) {
+	feedback := &entities.MatchFeedback{}
+	err := row.Scan(
+		&feedback.ID, &feedback.MatchID, &feedback.UserID, &feedback.MatchedUserID, &feedback.CampaignID,
+		&feedback.Rating, &feedback.Met, &feedback.Comment, &feedback.CreatedAt, &feedback.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return feedback, nil
+}
+
+func (r *MatchFeedbackRepository) Upsert(ctx context.Context, feedback *entities.MatchFeedback) error {
+	query := `
+		INSERT INTO match_feedback (match_id, user_id, matched_user_id, campaign_id, rating, met, comment)
+		VALUES ($1, $2, $3, NULLIF($4, '')::uuid, $5, $6, NULLIF($7, ''))
+		ON CONFLICT (match_id) DO UPDATE SET
+			rating = EXCLUDED.rating,
+			met = EXCLUDED.met,
+			comment = EXCLUDED.comment,
+			updated_at = NOW()
+		RETURNING id, created_at, updated_at
+	`
+
+	return r.db.QueryRow(ctx, query,
+		feedback.MatchID, feedback.UserID, feedback.MatchedUserID, feedback.CampaignID,
+		feedback.Rating, feedback.Met, feedback.Comment,
+	).Scan(&feedback.ID, &feedback.CreatedAt, &feedback.UpdatedAt)
+}
+
+func (r *MatchFeedbackRepository) GetByMatchID(ctx context.Context, matchID string) (*entities.MatchFeedback, error) {
+	query := `SELECT ` + matchFeedbackColumns + ` FROM match_feedback WHERE match_id = $1`
+	return scanMatchFeedback(r.db.QueryRow(ctx, query, matchID))
+}
+
+// ListByCampaign returns a campaign's feedback, oldest first
+func (r *MatchFeedbackRepository) ListByCampaign(ctx context.Context, campaignID string) ([]*entities.MatchFeedback, error) {
+	query := `SELECT ` + matchFeedbackColumns + `
+		FROM match_feedback
+		WHERE campaign_id = $1
+		ORDER BY created_at ASC
+	`
+
+	rows, err := r.db.Query(ctx, query, campaignID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var feedback []*entities.MatchFeedback
+	for rows.Next() {
+		f, err := scanMatchFeedback(rows)
+		if err != nil {
+			return nil, err
+		}
+		feedback = append(feedback, f)
+	}
+	return feedback, nil
+}
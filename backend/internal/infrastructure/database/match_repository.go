@@ -3,7 +3,6 @@ package database
 import (
 	"context"
 	"database/sql"
-	"time"
 	"wizard-connect/internal/domain/entities"
 )
 
@@ -19,9 +18,35 @@ func (r *MatchRepository) GetDB() *sql.DB {
 	return r.db.DB
 }
 
+// matchColumns are the columns scanMatch reads
+const matchColumns = `
+	id, user_id, matched_user_id, compatibility_score,
+	COALESCE(score_from_user, compatibility_score), COALESCE(score_from_matched, compatibility_score),
+	rank, is_mutual_crush, COALESCE(source, 'algorithm'), COALESCE(created_by::text, ''),
+	COALESCE(user_a_viewed, FALSE), COALESCE(user_b_viewed, FALSE),
+	COALESCE(user_a_contacted, FALSE), COALESCE(user_b_contacted, FALSE),
+	COALESCE(messaging_unlocked, FALSE),
+	COALESCE(user_a_response, ''), COALESCE(user_a_pass_reason, ''),
+	COALESCE(user_b_response, ''), COALESCE(user_b_pass_reason, ''), created_at`
+
+func scanMatch(row surveyScanner) (*entities.Match, error) {
+	match := &entities.Match{}
+	err := row.Scan(
+		&match.ID, &match.UserID, &match.MatchedUserID, &match.CompatibilityScore,
+		&match.ScoreFromUser, &match.ScoreFromMatched, &match.Rank, &match.IsMutualCrush,
+		&match.Source, &match.CreatedBy,
+		&match.UserAViewed, &match.UserBViewed, &match.UserAContacted, &match.UserBContacted,
+		&match.MessagingUnlocked, &match.UserAResponse, &match.UserAPassReason,
+		&match.UserBResponse, &match.UserBPassReason, &match.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return match, nil
+}
+
 func (r *MatchRepository) GetByUserID(ctx context.Context, userID string) ([]*entities.Match, error) {
-	query := `
-		SELECT id, user_id, matched_user_id, compatibility_score, rank, is_mutual_crush, created_at
+	query := `SELECT ` + matchColumns + `
 		FROM matches
 		WHERE user_id = $1
 		ORDER BY rank ASC
@@ -35,10 +60,7 @@ func (r *MatchRepository) GetByUserID(ctx context.Context, userID string) ([]*en
 
 	var matches []*entities.Match
 	for rows.Next() {
-		match := &entities.Match{}
-		err := rows.Scan(
-			&match.ID, &match.UserID, &match.MatchedUserID, &match.CompatibilityScore, &match.Rank, &match.IsMutualCrush, &match.CreatedAt,
-		)
+		match, err := scanMatch(rows)
 		if err != nil {
 			return nil, err
 		}
@@ -48,35 +70,90 @@ func (r *MatchRepository) GetByUserID(ctx context.Context, userID string) ([]*en
 	return matches, nil
 }
 
+func (r *MatchRepository) GetByID(ctx context.Context, id string) (*entities.Match, error) {
+	query := `SELECT ` + matchColumns + ` FROM matches WHERE id = $1`
+	return scanMatch(r.db.QueryRow(ctx, query, id))
+}
+
 func (r *MatchRepository) GetMatch(ctx context.Context, userID, matchedUserID string) (*entities.Match, error) {
-	query := `
-		SELECT id, user_id, matched_user_id, compatibility_score, rank, is_mutual_crush, created_at
+	query := `SELECT ` + matchColumns + `
 		FROM matches
 		WHERE user_id = $1 AND matched_user_id = $2
 	`
+	return scanMatch(r.db.QueryRow(ctx, query, userID, matchedUserID))
+}
 
-	match := &entities.Match{}
-	err := r.db.QueryRow(ctx, query, userID, matchedUserID).Scan(
-		&match.ID, &match.UserID, &match.MatchedUserID, &match.CompatibilityScore, &match.Rank, &match.IsMutualCrush, &match.CreatedAt,
-	)
-
-	if err != nil {
-		return nil, err
-	}
+// MarkViewed sets user_a_viewed on the viewer's match and user_b_viewed on
+// its mirror
+func (r *MatchRepository) MarkViewed(ctx context.Context, userID, matchedUserID string) error {
+	query := `
+		UPDATE matches SET
+			user_a_viewed = user_a_viewed OR user_id = $1,
+			user_b_viewed = user_b_viewed OR user_id = $2
+		WHERE (user_id = $1 AND matched_user_id = $2) OR (user_id = $2 AND matched_user_id = $1)
+	`
+	_, err := r.db.Exec(ctx, query, userID, matchedUserID)
+	return err
+}
 
-	return match, nil
+// MarkContacted is MarkViewed for the contacted flags. Rows already marked
+// are skipped, as it runs on every message.
+func (r *MatchRepository) MarkContacted(ctx context.Context, userID, matchedUserID string) error {
+	query := `
+		UPDATE matches SET
+			user_a_contacted = user_a_contacted OR user_id = $1,
+			user_b_contacted = user_b_contacted OR user_id = $2
+		WHERE ((user_id = $1 AND matched_user_id = $2 AND NOT COALESCE(user_a_contacted, FALSE))
+			OR (user_id = $2 AND matched_user_id = $1 AND NOT COALESCE(user_b_contacted, FALSE)))
+	`
+	_, err := r.db.Exec(ctx, query, userID, matchedUserID)
+	return err
 }
 
 func (r *MatchRepository) Create(ctx context.Context, match *entities.Match) error {
+	if match.Source == "" {
+		match.Source = entities.MatchSourceAlgorithm
+	}
+
 	query := `
-		INSERT INTO matches (user_id, matched_user_id, compatibility_score, rank, is_mutual_crush)
-			VALUES ($1, $2, $3, $4, $5)
+		INSERT INTO matches (user_id, matched_user_id, compatibility_score, score_from_user, score_from_matched, rank, is_mutual_crush, source, created_by,
+			user_a_viewed, user_b_viewed, user_a_contacted, user_b_contacted, messaging_unlocked,
+			user_a_response, user_a_pass_reason, user_b_response, user_b_pass_reason)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NULLIF($9, '')::uuid, $10, $11, $12, $13, $14,
+				NULLIF($15, ''), NULLIF($16, ''), NULLIF($17, ''), NULLIF($18, ''))
+			RETURNING id, created_at
 	`
 
-	_, err := r.db.Exec(ctx, query,
-		match.UserID, match.MatchedUserID, match.CompatibilityScore, match.Rank, match.IsMutualCrush,
-	)
+	return r.db.QueryRow(ctx, query,
+		match.UserID, match.MatchedUserID, match.CompatibilityScore, match.ScoreFromUser, match.ScoreFromMatched,
+		match.Rank, match.IsMutualCrush, match.Source, match.CreatedBy,
+		match.UserAViewed, match.UserBViewed, match.UserAContacted, match.UserBContacted, match.MessagingUnlocked,
+		match.UserAResponse, match.UserAPassReason, match.UserBResponse, match.UserBPassReason,
+	).Scan(&match.ID, &match.CreatedAt)
+}
+
+// Respond sets the user's response on both rows of the pair in one statement,
+// so messaging_unlocked sees both users' latest responses. A pass always
+// locks messaging; pairs unlocked before responses existed stay unlocked.
+func (r *MatchRepository) Respond(ctx context.Context, userID, matchedUserID, response, passReason string) error {
+	query := `
+		UPDATE matches SET
+			user_a_response = CASE WHEN user_id = $1 THEN $3 ELSE user_a_response END,
+			user_a_pass_reason = CASE WHEN user_id = $1 THEN NULLIF($4, '') ELSE user_a_pass_reason END,
+			user_b_response = CASE WHEN user_id = $2 THEN $3 ELSE user_b_response END,
+			user_b_pass_reason = CASE WHEN user_id = $2 THEN NULLIF($4, '') ELSE user_b_pass_reason END,
+			messaging_unlocked = $3 <> 'pass' AND (COALESCE(messaging_unlocked, FALSE) OR COALESCE(
+				(CASE WHEN user_id = $1 THEN $3 ELSE user_a_response END) = 'interested'
+				AND (CASE WHEN user_id = $2 THEN $3 ELSE user_b_response END) = 'interested', FALSE))
+		WHERE (user_id = $1 AND matched_user_id = $2) OR (user_id = $2 AND matched_user_id = $1)
+	`
+	_, err := r.db.Exec(ctx, query, userID, matchedUserID, response, passReason)
+	return err
+}
 
+// UpdateRank moves a match to a new position in its owner's list
+func (r *MatchRepository) UpdateRank(ctx context.Context, id string, rank int) error {
+	_, err := r.db.Exec(ctx, `UPDATE matches SET rank = $2 WHERE id = $1`, id, rank)
 	return err
 }
 
@@ -87,15 +164,24 @@ func (r *MatchRepository) DeleteByUserID(ctx context.Context, userID string) err
 	return err
 }
 
-func (r *MatchRepository) GetByUserIDWithUserDetails(ctx context.Context, userID string) ([]*MatchWithUserDetails, error) {
+func (r *MatchRepository) GetByUserIDWithUserDetails(ctx context.Context, userID string) ([]*entities.MatchWithUserDetails, error) {
 	query := `
 		SELECT
 			m.id,
 			m.user_id,
 			m.matched_user_id,
 			m.compatibility_score,
+			COALESCE(m.score_from_user, m.compatibility_score),
+			COALESCE(m.score_from_matched, m.compatibility_score),
 			m.rank,
 			m.is_mutual_crush,
+			COALESCE(m.source, 'algorithm'),
+			COALESCE(m.user_a_viewed, FALSE),
+			COALESCE(m.user_b_viewed, FALSE),
+			COALESCE(m.user_a_contacted, FALSE),
+			COALESCE(m.user_b_contacted, FALSE),
+			COALESCE(m.messaging_unlocked, FALSE),
+			COALESCE(m.user_a_response, ''),
 			m.created_at,
 			u.email as matched_email,
 			COALESCE(u.first_name, '') as first_name,
@@ -119,12 +205,14 @@ func (r *MatchRepository) GetByUserIDWithUserDetails(ctx context.Context, userID
 	}
 	defer rows.Close()
 
-	var matches []*MatchWithUserDetails
+	var matches []*entities.MatchWithUserDetails
 	for rows.Next() {
-		match := &MatchWithUserDetails{}
+		match := &entities.MatchWithUserDetails{}
 		err := rows.Scan(
-			&match.ID, &match.UserID, &match.MatchedUserID, &match.CompatibilityScore, &match.Rank, &match.IsMutualCrush, &match.CreatedAt,
-			&match.MatchedEmail, &match.FirstName, &match.LastName, &match.AvatarURL,
+			&match.ID, &match.UserID, &match.MatchedUserID, &match.CompatibilityScore,
+			&match.ScoreFromUser, &match.ScoreFromMatched, &match.Rank, &match.IsMutualCrush, &match.Source,
+			&match.UserAViewed, &match.UserBViewed, &match.UserAContacted, &match.UserBContacted, &match.MessagingUnlocked,
+			&match.UserAResponse, &match.CreatedAt, &match.MatchedEmail, &match.FirstName, &match.LastName, &match.AvatarURL,
 			&match.Bio, &match.Year, &match.Major,
 			&match.Gender, &match.GenderPreference, &match.Visibility,
 		)
@@ -139,7 +227,7 @@ func (r *MatchRepository) GetByUserIDWithUserDetails(ctx context.Context, userID
 	return matches, nil
 }
 
-func (r *MatchRepository) ListAllWithUserDetails(ctx context.Context) ([]*MatchWithBothUserDetails, error) {
+func (r *MatchRepository) ListAllWithUserDetails(ctx context.Context) ([]*entities.MatchWithBothUserDetails, error) {
 	query := `
 		SELECT
 			m.id,
@@ -148,6 +236,8 @@ func (r *MatchRepository) ListAllWithUserDetails(ctx context.Context) ([]*MatchW
 			m.compatibility_score,
 			m.rank,
 			m.is_mutual_crush,
+			COALESCE(m.source, 'algorithm'),
+			COALESCE(m.created_by::text, ''),
 			m.created_at,
 			u1.email as user1_email,
 			COALESCE(u1.first_name, '') as user1_first_name,
@@ -169,11 +259,12 @@ func (r *MatchRepository) ListAllWithUserDetails(ctx context.Context) ([]*MatchW
 	}
 	defer rows.Close()
 
-	var matches []*MatchWithBothUserDetails
+	var matches []*entities.MatchWithBothUserDetails
 	for rows.Next() {
-		match := &MatchWithBothUserDetails{}
+		match := &entities.MatchWithBothUserDetails{}
 		err := rows.Scan(
-			&match.ID, &match.UserID, &match.MatchedUserID, &match.CompatibilityScore, &match.Rank, &match.IsMutualCrush, &match.CreatedAt,
+			&match.ID, &match.UserID, &match.MatchedUserID, &match.CompatibilityScore, &match.Rank, &match.IsMutualCrush,
+			&match.Source, &match.CreatedBy, &match.CreatedAt,
 			&match.User1Email, &match.User1FirstName, &match.User1LastName, &match.User1AvatarURL,
 			&match.User2Email, &match.User2FirstName, &match.User2LastName, &match.User2AvatarURL,
 		)
@@ -187,41 +278,3 @@ func (r *MatchRepository) ListAllWithUserDetails(ctx context.Context) ([]*MatchW
 
 	return matches, nil
 }
-
-type MatchWithUserDetails struct {
-	ID                 string    `json:"id"`
-	UserID             string    `json:"user_id"`
-	MatchedUserID      string    `json:"matched_user_id"`
-	CompatibilityScore float64   `json:"compatibility_score"`
-	Rank               int       `json:"rank"`
-	IsMutualCrush      bool      `json:"is_mutual_crush"`
-	CreatedAt          time.Time `json:"created_at"`
-	MatchedEmail       string    `json:"matched_email"`
-	FirstName          string    `json:"first_name"`
-	LastName           string    `json:"last_name"`
-	AvatarURL          string    `json:"avatar_url"`
-	Bio                string    `json:"bio"`
-	Year               string    `json:"year"`
-	Major              string    `json:"major"`
-	Gender             string    `json:"gender"`
-	GenderPreference   string    `json:"gender_preference"`
-	Visibility         string    `json:"visibility"`
-}
-
-type MatchWithBothUserDetails struct {
-	ID                 string    `json:"id"`
-	UserID             string    `json:"user_id"`
-	MatchedUserID      string    `json:"matched_user_id"`
-	CompatibilityScore float64   `json:"compatibility_score"`
-	Rank               int       `json:"rank"`
-	IsMutualCrush      bool      `json:"is_mutual_crush"`
-	CreatedAt          time.Time `json:"created_at"`
-	User1Email         string    `json:"user1_email"`
-	User1FirstName     string    `json:"user1_first_name"`
-	User1LastName      string    `json:"user1_last_name"`
-	User1AvatarURL     string    `json:"user1_avatar_url"`
-	User2Email         string    `json:"user2_email"`
-	User2FirstName     string    `json:"user2_first_name"`
-	User2LastName      string    `json:"user2_last_name"`
-	User2AvatarURL     string    `json:"user2_avatar_url"`
-}
@@ -0,0 +1,79 @@
+package database
+
+import (
+	"context"
+
+	"wizard-connect/internal/domain/entities"
+
+	"github.com/google/uuid"
+)
+
+type MatchVetoRepository struct {
+	db *Database
+}
+
+func NewMatchVetoRepository(db *Database) *MatchVetoRepository {
+	return &MatchVetoRepository{db: db}
+}
+
+const matchVetoColumns = `
+	id, user_a_id, user_b_id, COALESCE(campaign_id::text, ''), reason, COALESCE(vetoed_by::text, ''), created_at
+`
+
+func scanMatchVeto(row surveyScanner) (*entities.MatchVeto, error) {
+	veto := &entities.MatchVeto{}
+	err := row.Scan(&veto.ID, &veto.UserAID, &veto.UserBID, &veto.CampaignID, &veto.Reason, &veto.VetoedBy, &veto.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return veto, nil
+}
+
+func (r *MatchVetoRepository) Create(ctx context.Context, veto *entities.MatchVeto) error {
+	if veto.ID == "" {
+		veto.ID = uuid.New().String()
+	}
+	veto.UserAID, veto.UserBID = entities.OrderedPair(veto.UserAID, veto.UserBID)
+
+	query := `
+		INSERT INTO match_vetoes (id, user_a_id, user_b_id, campaign_id, reason, vetoed_by)
+		VALUES ($1, $2, $3, NULLIF($4, '')::uuid, $5, NULLIF($6, '')::uuid)
+		RETURNING created_at
+	`
+
+	return r.db.QueryRow(ctx, query,
+		veto.ID, veto.UserAID, veto.UserBID, veto.CampaignID, veto.Reason, veto.VetoedBy,
+	).Scan(&veto.CreatedAt)
+}
+
+func (r *MatchVetoRepository) GetByID(ctx context.Context, id string) (*entities.MatchVeto, error) {
+	query := `SELECT ` + matchVetoColumns + ` FROM match_vetoes WHERE id = $1`
+	return scanMatchVeto(r.db.QueryRow(ctx, query, id))
+}
+
+func (r *MatchVetoRepository) Delete(ctx context.Context, id string) error {
+	_, err := r.db.Exec(ctx, `DELETE FROM match_vetoes WHERE id = $1`, id)
+	return err
+}
+
+// ListVetoes returns every veto, newest first
+func (r *MatchVetoRepository) ListVetoes(ctx context.Context) ([]*entities.MatchVeto, error) {
+	query := `SELECT ` + matchVetoColumns + ` FROM match_vetoes ORDER BY created_at DESC`
+
+	rows, err := r.db.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var vetoes []*entities.MatchVeto
+	for rows.Next() {
+		veto, err := scanMatchVeto(rows)
+		if err != nil {
+			return nil, err
+		}
+		vetoes = append(vetoes, veto)
+	}
+
+	return vetoes, nil
+}
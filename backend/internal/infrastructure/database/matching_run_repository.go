@@ -0,0 +1,145 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"wizard-connect/internal/domain/entities"
+
+	"github.com/google/uuid"
+)
+
+type MatchingRunRepository struct {
+	db *Database
+}
+
+func NewMatchingRunRepository(db *Database) *MatchingRunRepository {
+	return &MatchingRunRepository{db: db}
+}
+
+const matchingRunColumns = `
+	id, campaign_id, status, COALESCE(error, ''), participants, total_matches,
+	fairness, manifest, started_at, completed_at
+`
+
+func scanMatchingRun(row surveyScanner) (*entities.MatchingRun, error) {
+	var fairnessJSON, manifestJSON []byte
+	var completedAt sql.NullTime
+
+	run := &entities.MatchingRun{}
+	err := row.Scan(
+		&run.ID, &run.CampaignID, &run.Status, &run.Error, &run.Participants, &run.TotalMatches,
+		&fairnessJSON, &manifestJSON, &run.StartedAt, &completedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if completedAt.Valid {
+		run.CompletedAt = &completedAt.Time
+	}
+	if len(fairnessJSON) > 0 && string(fairnessJSON) != "null" {
+		run.Fairness = &entities.FairnessReport{}
+		if err := json.Unmarshal(fairnessJSON, run.Fairness); err != nil {
+			return nil, fmt.Errorf("failed to parse fairness report: %w", err)
+		}
+	}
+	if len(manifestJSON) > 0 && string(manifestJSON) != "null" {
+		run.Manifest = &entities.RunManifest{}
+		if err := json.Unmarshal(manifestJSON, run.Manifest); err != nil {
+			return nil, fmt.Errorf("failed to parse run manifest: %w", err)
+		}
+	}
+	return run, nil
+}
+
+func encodeRunReports(run *entities.MatchingRun) ([]byte, []byte, error) {
+	fairnessJSON, err := json.Marshal(run.Fairness)
+	if err != nil {
+		return nil, nil, err
+	}
+	manifestJSON, err := json.Marshal(run.Manifest)
+	if err != nil {
+		return nil, nil, err
+	}
+	return fairnessJSON, manifestJSON, nil
+}
+
+func (r *MatchingRunRepository) Create(ctx context.Context, run *entities.MatchingRun) error {
+	if run.ID == "" {
+		run.ID = uuid.New().String()
+	}
+
+	fairnessJSON, manifestJSON, err := encodeRunReports(run)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO matching_runs (id, campaign_id, status, error, participants, total_matches, fairness, manifest, started_at, completed_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	`
+
+	_, err = r.db.Exec(ctx, query,
+		run.ID, run.CampaignID, run.Status, run.Error, run.Participants, run.TotalMatches,
+		fairnessJSON, manifestJSON, run.StartedAt, run.CompletedAt,
+	)
+	return err
+}
+
+func (r *MatchingRunRepository) Update(ctx context.Context, run *entities.MatchingRun) error {
+	fairnessJSON, manifestJSON, err := encodeRunReports(run)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		UPDATE matching_runs
+		SET status = $2,
+		    error = $3,
+		    participants = $4,
+		    total_matches = $5,
+		    fairness = $6,
+		    manifest = $7,
+		    completed_at = $8
+		WHERE id = $1
+	`
+
+	_, err = r.db.Exec(ctx, query,
+		run.ID, run.Status, run.Error, run.Participants, run.TotalMatches, fairnessJSON, manifestJSON, run.CompletedAt,
+	)
+	return err
+}
+
+func (r *MatchingRunRepository) GetByID(ctx context.Context, id string) (*entities.MatchingRun, error) {
+	query := `SELECT ` + matchingRunColumns + ` FROM matching_runs WHERE id = $1`
+	return scanMatchingRun(r.db.QueryRow(ctx, query, id))
+}
+
+// ListByCampaign returns a campaign's runs, newest first
+func (r *MatchingRunRepository) ListByCampaign(ctx context.Context, campaignID string) ([]*entities.MatchingRun, error) {
+	query := `SELECT ` + matchingRunColumns + `
+		FROM matching_runs
+		WHERE campaign_id = $1
+		ORDER BY started_at DESC
+	`
+
+	rows, err := r.db.Query(ctx, query, campaignID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var runs []*entities.MatchingRun
+	for rows.Next() {
+		run, err := scanMatchingRun(rows)
+		if err != nil {
+			return nil, err
+		}
+		runs = append(runs, run)
+	}
+
+	return runs, nil
+}
@@ -27,6 +27,29 @@ func (r *MessageRepository) Create(ctx context.Context, message *entities.Messag
 	return err
 }
 
+func (r *MessageRepository) GetByID(ctx context.Context, id string) (*entities.Message, error) {
+	query := `
+		SELECT id, conversation_id, sender_id, content, is_read, created_at
+		FROM messages
+		WHERE id = $1
+	`
+
+	message := &entities.Message{}
+	err := r.db.QueryRow(ctx, query, id).Scan(
+		&message.ID, &message.ConversationID, &message.SenderID,
+		&message.Content, &message.IsRead, &message.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return message, nil
+}
+
+func (r *MessageRepository) Delete(ctx context.Context, id string) error {
+	_, err := r.db.Exec(ctx, `DELETE FROM messages WHERE id = $1`, id)
+	return err
+}
+
 func (r *MessageRepository) GetByConversationID(ctx context.Context, conversationID string, limit, offset int) ([]*entities.Message, error) {
 	query := `
 		SELECT id, conversation_id, sender_id, content, is_read, created_at
@@ -78,3 +101,11 @@ func (r *MessageRepository) GetUnreadCount(ctx context.Context, userID string) (
 	err := r.db.QueryRow(ctx, query, userID).Scan(&count)
 	return count, err
 }
+
+func (r *MessageRepository) CountByConversationID(ctx context.Context, conversationID string) (int, error) {
+	query := `SELECT COUNT(*) FROM messages WHERE conversation_id = $1`
+
+	var count int
+	err := r.db.QueryRow(ctx, query, conversationID).Scan(&count)
+	return count, err
+}
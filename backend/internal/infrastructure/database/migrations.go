@@ -73,11 +73,19 @@ func (d *Database) AutoMigrate(ctx context.Context) error {
 		{"lifestyle", "TEXT"},
 		{"is_complete", "BOOLEAN DEFAULT FALSE"},
 		{"completed_at", "TIMESTAMPTZ"},
+		{"personality_traits", "DOUBLE PRECISION[] DEFAULT '{}'"},
+		{"campaign_id", "UUID REFERENCES public.campaigns(id) ON DELETE CASCADE"},
+		{"definition_version", "TEXT NOT NULL DEFAULT 'v1'"},
+		{"revision", "INTEGER NOT NULL DEFAULT 0"},
 	}
 	for _, col := range surveyCols {
 		query := fmt.Sprintf("ALTER TABLE public.surveys ADD COLUMN IF NOT EXISTS %s %s", col.Name, col.Type)
 		d.Exec(ctx, query)
 	}
+	// One survey per user per campaign (NULL campaign = taken outside a campaign)
+	d.Exec(ctx, `ALTER TABLE public.surveys DROP CONSTRAINT IF EXISTS surveys_user_id_key`)
+	d.Exec(ctx, `CREATE UNIQUE INDEX IF NOT EXISTS idx_surveys_user_campaign ON public.surveys(user_id, COALESCE(campaign_id, '00000000-0000-0000-0000-000000000000'::uuid))`)
+	d.Exec(ctx, `ALTER TABLE public.campaigns ADD COLUMN IF NOT EXISTS survey_version TEXT`)
 
 	// 3. Repair Matches Table
 	d.Exec(ctx, `CREATE TABLE IF NOT EXISTS public.matches (
@@ -97,8 +105,21 @@ func (d *Database) AutoMigrate(ctx context.Context) error {
 		{"user_id", "UUID NOT NULL REFERENCES auth.users(id) ON DELETE CASCADE"},
 		{"matched_user_id", "UUID NOT NULL REFERENCES auth.users(id) ON DELETE CASCADE"},
 		{"compatibility_score", "DECIMAL(5,2) NOT NULL DEFAULT 0.0"},
+		{"score_from_user", "DECIMAL(5,2)"},
+		{"score_from_matched", "DECIMAL(5,2)"},
 		{"rank", "INTEGER NOT NULL DEFAULT 1"},
 		{"is_mutual_crush", "BOOLEAN DEFAULT FALSE"},
+		{"source", "TEXT NOT NULL DEFAULT 'algorithm'"},
+		{"created_by", "UUID"},
+		{"user_a_viewed", "BOOLEAN DEFAULT FALSE"},
+		{"user_b_viewed", "BOOLEAN DEFAULT FALSE"},
+		{"user_a_contacted", "BOOLEAN DEFAULT FALSE"},
+		{"user_b_contacted", "BOOLEAN DEFAULT FALSE"},
+		{"messaging_unlocked", "BOOLEAN DEFAULT FALSE"},
+		{"user_a_response", "TEXT"},
+		{"user_b_response", "TEXT"},
+		{"user_a_pass_reason", "TEXT"},
+		{"user_b_pass_reason", "TEXT"},
 		{"created_at", "TIMESTAMPTZ DEFAULT NOW()"},
 	}
 	for _, col := range matchCols {
@@ -122,6 +143,152 @@ func (d *Database) AutoMigrate(ctx context.Context) error {
 	d.Exec(ctx, `ALTER TABLE public.crushes ADD COLUMN IF NOT EXISTS rank INTEGER NOT NULL DEFAULT 1`)
 	d.Exec(ctx, `CREATE INDEX IF NOT EXISTS idx_crushes_user_id ON public.crushes(user_id)`)
 
+	// 4a. Matching Runs Table
+	d.Exec(ctx, `CREATE TABLE IF NOT EXISTS public.matching_runs (
+		id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+		campaign_id UUID NOT NULL REFERENCES public.campaigns(id) ON DELETE CASCADE,
+		status TEXT NOT NULL DEFAULT 'running' CHECK (status IN ('running', 'completed', 'failed')),
+		error TEXT,
+		participants INTEGER NOT NULL DEFAULT 0,
+		total_matches INTEGER NOT NULL DEFAULT 0,
+		fairness JSONB,
+		started_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+		completed_at TIMESTAMPTZ
+	)`)
+	d.Exec(ctx, `ALTER TABLE public.matching_runs ADD COLUMN IF NOT EXISTS manifest JSONB`)
+	d.Exec(ctx, `CREATE INDEX IF NOT EXISTS idx_matching_runs_campaign ON public.matching_runs(campaign_id, started_at DESC)`)
+
+	// 4b. Match Review Tables
+	d.Exec(ctx, `CREATE TABLE IF NOT EXISTS public.staged_matches (
+		id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+		campaign_id UUID NOT NULL REFERENCES public.campaigns(id) ON DELETE CASCADE,
+		run_id UUID REFERENCES public.matching_runs(id) ON DELETE SET NULL,
+		user_id UUID NOT NULL REFERENCES auth.users(id) ON DELETE CASCADE,
+		matched_user_id UUID NOT NULL REFERENCES auth.users(id) ON DELETE CASCADE,
+		compatibility_score DECIMAL(5,2) NOT NULL DEFAULT 0.0,
+		score_from_user DECIMAL(5,2),
+		score_from_matched DECIMAL(5,2),
+		rank INTEGER NOT NULL,
+		is_mutual_crush BOOLEAN NOT NULL DEFAULT FALSE,
+		source TEXT NOT NULL DEFAULT 'algorithm' CHECK (source IN ('algorithm', 'manual')),
+		pinned BOOLEAN NOT NULL DEFAULT FALSE,
+		pinned_by UUID,
+		flagged BOOLEAN NOT NULL DEFAULT FALSE,
+		flag_reason TEXT,
+		flagged_by UUID,
+		created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+		UNIQUE(campaign_id, user_id, matched_user_id)
+	)`)
+	d.Exec(ctx, `CREATE INDEX IF NOT EXISTS idx_staged_matches_campaign ON public.staged_matches(campaign_id, user_id, rank)`)
+	d.Exec(ctx, `CREATE TABLE IF NOT EXISTS public.match_vetoes (
+		id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+		user_a_id UUID NOT NULL REFERENCES auth.users(id) ON DELETE CASCADE,
+		user_b_id UUID NOT NULL REFERENCES auth.users(id) ON DELETE CASCADE,
+		campaign_id UUID REFERENCES public.campaigns(id) ON DELETE SET NULL,
+		reason TEXT NOT NULL,
+		vetoed_by UUID,
+		created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+		UNIQUE(user_a_id, user_b_id),
+		CHECK (user_a_id < user_b_id)
+	)`)
+	d.Exec(ctx, `CREATE TABLE IF NOT EXISTS public.admin_audit_log (
+		id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+		actor_id UUID,
+		action TEXT NOT NULL,
+		target_type TEXT NOT NULL,
+		target_id TEXT,
+		details JSONB,
+		created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+	)`)
+	d.Exec(ctx, `CREATE INDEX IF NOT EXISTS idx_admin_audit_log_target ON public.admin_audit_log(target_type, target_id, created_at DESC)`)
+	d.Exec(ctx, `CREATE INDEX IF NOT EXISTS idx_admin_audit_log_created ON public.admin_audit_log(created_at DESC)`)
+
+	// 4c. Match Feedback Table
+	d.Exec(ctx, `CREATE TABLE IF NOT EXISTS public.match_feedback (
+		id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+		match_id UUID NOT NULL UNIQUE,
+		user_id UUID NOT NULL REFERENCES auth.users(id) ON DELETE CASCADE,
+		matched_user_id UUID NOT NULL REFERENCES auth.users(id) ON DELETE CASCADE,
+		campaign_id UUID REFERENCES public.campaigns(id) ON DELETE SET NULL,
+		rating INTEGER NOT NULL CHECK (rating >= 1 AND rating <= 5),
+		met BOOLEAN NOT NULL DEFAULT FALSE,
+		comment TEXT,
+		created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+		updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+	)`)
+	d.Exec(ctx, `CREATE INDEX IF NOT EXISTS idx_match_feedback_campaign ON public.match_feedback(campaign_id)`)
+
+	// 4d. Safety and Moderation Tables
+	d.Exec(ctx, `CREATE TABLE IF NOT EXISTS public.user_blocks (
+		id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+		blocker_id UUID NOT NULL REFERENCES auth.users(id) ON DELETE CASCADE,
+		blocked_id UUID NOT NULL REFERENCES auth.users(id) ON DELETE CASCADE,
+		created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+		UNIQUE(blocker_id, blocked_id),
+		CHECK (blocker_id <> blocked_id)
+	)`)
+	d.Exec(ctx, `CREATE INDEX IF NOT EXISTS idx_user_blocks_blocked ON public.user_blocks(blocked_id)`)
+	d.Exec(ctx, `CREATE TABLE IF NOT EXISTS public.user_reports (
+		id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+		reporter_id UUID REFERENCES auth.users(id) ON DELETE SET NULL,
+		reported_user_id UUID NOT NULL REFERENCES auth.users(id) ON DELETE CASCADE,
+		category TEXT NOT NULL CHECK (category IN ('harassment', 'spam', 'inappropriate_content', 'fake_profile', 'underage', 'safety_concern', 'other')),
+		description TEXT,
+		evidence JSONB NOT NULL DEFAULT '[]',
+		status TEXT NOT NULL DEFAULT 'open',
+		created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+		updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+	)`)
+	reportCols := []struct {
+		Name string
+		Type string
+	}{
+		{"source", "TEXT NOT NULL DEFAULT 'user'"},
+		{"content_type", "TEXT"},
+		{"content_id", "TEXT"},
+		{"assigned_to", "UUID"},
+		{"assigned_at", "TIMESTAMPTZ"},
+		{"resolution", "TEXT"},
+		{"resolved_by", "UUID"},
+		{"resolved_at", "TIMESTAMPTZ"},
+	}
+	for _, col := range reportCols {
+		query := fmt.Sprintf("ALTER TABLE public.user_reports ADD COLUMN IF NOT EXISTS %s %s", col.Name, col.Type)
+		d.Exec(ctx, query)
+	}
+	d.Exec(ctx, `CREATE INDEX IF NOT EXISTS idx_user_reports_status ON public.user_reports(status, created_at DESC)`)
+	d.Exec(ctx, `CREATE INDEX IF NOT EXISTS idx_user_reports_reported ON public.user_reports(reported_user_id)`)
+	d.Exec(ctx, `CREATE INDEX IF NOT EXISTS idx_user_reports_assigned ON public.user_reports(assigned_to, status)`)
+	d.Exec(ctx, `CREATE TABLE IF NOT EXISTS public.moderation_actions (
+		id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+		user_id UUID NOT NULL REFERENCES auth.users(id) ON DELETE CASCADE,
+		report_id UUID REFERENCES public.user_reports(id) ON DELETE SET NULL,
+		action TEXT NOT NULL CHECK (action IN ('warn', 'mute', 'suspend', 'ban', 'remove_content')),
+		reason TEXT NOT NULL,
+		content_type TEXT,
+		content_id TEXT,
+		actor_id UUID,
+		expires_at TIMESTAMPTZ,
+		revoked_at TIMESTAMPTZ,
+		revoked_by UUID,
+		created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+	)`)
+	d.Exec(ctx, `CREATE INDEX IF NOT EXISTS idx_moderation_actions_user ON public.moderation_actions(user_id, created_at DESC)`)
+
+	// 4e. Deleted Accounts (tokens issued before a deletion are refused)
+	d.Exec(ctx, `CREATE TABLE IF NOT EXISTS public.deleted_accounts (
+		user_id UUID PRIMARY KEY,
+		deleted_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+	)`)
+
+	// 4f. Registration Overrides (emails allowed outside the domain allow-list)
+	d.Exec(ctx, `CREATE TABLE IF NOT EXISTS public.registration_overrides (
+		email TEXT PRIMARY KEY,
+		reason TEXT,
+		added_by UUID,
+		created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+	)`)
+
 	// 5. Repair Conversations Table
 	// Drop and recreate to ensure correct schema
 	d.Exec(ctx, `DROP TABLE IF EXISTS public.conversations CASCADE`)
@@ -158,6 +325,15 @@ func (d *Database) AutoMigrate(ctx context.Context) error {
 		`ALTER TABLE public.users ADD CONSTRAINT check_gender CHECK (gender IN ('male', 'female', 'non-binary', 'prefer_not_to_say', 'other', '') OR gender IS NULL)`,
 		`ALTER TABLE public.users DROP CONSTRAINT IF EXISTS check_gender_preference`,
 		`ALTER TABLE public.users ADD CONSTRAINT check_gender_preference CHECK (gender_preference IN ('male', 'female', 'both', '') OR gender_preference IS NULL)`,
+		`ALTER TABLE public.matches DROP CONSTRAINT IF EXISTS check_match_source`,
+		`ALTER TABLE public.matches ADD CONSTRAINT check_match_source CHECK (source IN ('algorithm', 'manual'))`,
+		`ALTER TABLE public.matches DROP CONSTRAINT IF EXISTS check_match_response`,
+		`ALTER TABLE public.matches ADD CONSTRAINT check_match_response CHECK ((user_a_response IS NULL OR user_a_response IN ('interested', 'pass')) AND (user_b_response IS NULL OR user_b_response IN ('interested', 'pass')))`,
+		`ALTER TABLE public.user_reports DROP CONSTRAINT IF EXISTS user_reports_status_check`,
+		`ALTER TABLE public.user_reports DROP CONSTRAINT IF EXISTS check_report_status`,
+		`ALTER TABLE public.user_reports ADD CONSTRAINT check_report_status CHECK (status IN ('open', 'in_review', 'actioned', 'dismissed'))`,
+		`ALTER TABLE public.user_reports DROP CONSTRAINT IF EXISTS check_report_source`,
+		`ALTER TABLE public.user_reports ADD CONSTRAINT check_report_source CHECK (source IN ('user', 'auto'))`,
 	}
 	for _, q := range constraints {
 		d.Exec(ctx, q)
@@ -0,0 +1,66 @@
+package database
+
+import (
+	"context"
+	"strings"
+
+	"wizard-connect/internal/domain/entities"
+)
+
+type RegistrationOverrideRepository struct {
+	db *Database
+}
+
+func NewRegistrationOverrideRepository(db *Database) *RegistrationOverrideRepository {
+	return &RegistrationOverrideRepository{db: db}
+}
+
+func (r *RegistrationOverrideRepository) Add(ctx context.Context, override *entities.RegistrationOverride) error {
+	override.Email = strings.ToLower(strings.TrimSpace(override.Email))
+	query := `
+		INSERT INTO registration_overrides (email, reason, added_by)
+		VALUES ($1, NULLIF($2, ''), NULLIF($3, '')::uuid)
+		ON CONFLICT (email) DO UPDATE SET reason = EXCLUDED.reason, added_by = EXCLUDED.added_by
+		RETURNING created_at
+	`
+
+	return r.db.QueryRow(ctx, query, override.Email, override.Reason, override.AddedBy).Scan(&override.CreatedAt)
+}
+
+func (r *RegistrationOverrideRepository) Remove(ctx context.Context, email string) error {
+	_, err := r.db.Exec(ctx, `DELETE FROM registration_overrides WHERE email = $1`, strings.ToLower(strings.TrimSpace(email)))
+	return err
+}
+
+func (r *RegistrationOverrideRepository) List(ctx context.Context) ([]*entities.RegistrationOverride, error) {
+	query := `
+		SELECT email, COALESCE(reason, ''), COALESCE(added_by::text, ''), created_at
+		FROM registration_overrides
+		ORDER BY created_at DESC
+	`
+
+	rows, err := r.db.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var overrides []*entities.RegistrationOverride
+	for rows.Next() {
+		override := &entities.RegistrationOverride{}
+		if err := rows.Scan(&override.Email, &override.Reason, &override.AddedBy, &override.CreatedAt); err != nil {
+			return nil, err
+		}
+		overrides = append(overrides, override)
+	}
+	return overrides, rows.Err()
+}
+
+func (r *RegistrationOverrideRepository) IsOverridden(ctx context.Context, email string) (bool, error) {
+	var overridden bool
+	err := r.db.QueryRow(ctx,
+		`SELECT EXISTS (SELECT 1 FROM registration_overrides WHERE email = $1)`,
+		strings.ToLower(strings.TrimSpace(email)),
+	).Scan(&overridden)
+	return overridden, err
+}
@@ -0,0 +1,352 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"wizard-connect/internal/domain/entities"
+)
+
+type UserBlockRepository struct {
+	db *Database
+}
+
+func NewUserBlockRepository(db *Database) *UserBlockRepository {
+	return &UserBlockRepository{db: db}
+}
+
+const userBlockColumns = `id, blocker_id, blocked_id, created_at`
+
+func scanUserBlock(row surveyScanner) (*entities.UserBlock, error) {
+	block := &entities.UserBlock{}
+	if err := row.Scan(&block.ID, &block.BlockerID, &block.BlockedID, &block.CreatedAt); err != nil {
+		return nil, err
+	}
+	return block, nil
+}
+
+// Create records the block; blocking someone twice keeps the first block
+func (r *UserBlockRepository) Create(ctx context.Context, block *entities.UserBlock) error {
+	query := `
+		INSERT INTO user_blocks (blocker_id, blocked_id)
+		VALUES ($1, $2)
+		ON CONFLICT (blocker_id, blocked_id) DO UPDATE SET blocker_id = EXCLUDED.blocker_id
+		RETURNING id, created_at
+	`
+
+	return r.db.QueryRow(ctx, query, block.BlockerID, block.BlockedID).Scan(&block.ID, &block.CreatedAt)
+}
+
+func (r *UserBlockRepository) Delete(ctx context.Context, blockerID, blockedID string) error {
+	_, err := r.db.Exec(ctx, `DELETE FROM user_blocks WHERE blocker_id = $1 AND blocked_id = $2`, blockerID, blockedID)
+	return err
+}
+
+func (r *UserBlockRepository) IsBlocked(ctx context.Context, userID, otherUserID string) (bool, error) {
+	query := `
+		SELECT EXISTS (
+			SELECT 1 FROM user_blocks
+			WHERE (blocker_id = $1 AND blocked_id = $2) OR (blocker_id = $2 AND blocked_id = $1)
+		)
+	`
+
+	var blocked bool
+	err := r.db.QueryRow(ctx, query, userID, otherUserID).Scan(&blocked)
+	return blocked, err
+}
+
+func (r *UserBlockRepository) BlockedUserIDs(ctx context.Context, userID string) ([]string, error) {
+	query := `
+		SELECT blocked_id FROM user_blocks WHERE blocker_id = $1
+		UNION
+		SELECT blocker_id FROM user_blocks WHERE blocked_id = $1
+	`
+
+	rows, err := r.db.Query(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// ListByBlocker returns the users blockerID has blocked, newest first
+func (r *UserBlockRepository) ListByBlocker(ctx context.Context, blockerID string) ([]*entities.UserBlock, error) {
+	query := `SELECT ` + userBlockColumns + ` FROM user_blocks WHERE blocker_id = $1 ORDER BY created_at DESC`
+	return r.list(ctx, query, blockerID)
+}
+
+// ListBlocks returns every block, newest first
+func (r *UserBlockRepository) ListBlocks(ctx context.Context) ([]*entities.UserBlock, error) {
+	query := `SELECT ` + userBlockColumns + ` FROM user_blocks ORDER BY created_at DESC`
+	return r.list(ctx, query)
+}
+
+func (r *UserBlockRepository) list(ctx context.Context, query string, args ...interface{}) ([]*entities.UserBlock, error) {
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var blocks []*entities.UserBlock
+	for rows.Next() {
+		block, err := scanUserBlock(rows)
+		if err != nil {
+			return nil, err
+		}
+		blocks = append(blocks, block)
+	}
+	return blocks, nil
+}
+
+type ReportRepository struct {
+	db *Database
+}
+
+func NewReportRepository(db *Database) *ReportRepository {
+	return &ReportRepository{db: db}
+}
+
+const reportColumns = `
+	id, COALESCE(reporter_id::text, ''), reported_user_id, source, category, COALESCE(description, ''),
+	COALESCE(content_type, ''), COALESCE(content_id, ''), evidence, status,
+	COALESCE(assigned_to::text, ''), assigned_at, COALESCE(resolution, ''), COALESCE(resolved_by::text, ''), resolved_at,
+	created_at, updated_at`
+
+func scanReport(row surveyScanner) (*entities.Report, error) {
+	report := &entities.Report{}
+	var evidenceJSON []byte
+	var assignedAt, resolvedAt sql.NullTime
+	err := row.Scan(
+		&report.ID, &report.ReporterID, &report.ReportedUserID, &report.Source, &report.Category, &report.Description,
+		&report.ContentType, &report.ContentID, &evidenceJSON, &report.Status,
+		&report.AssignedTo, &assignedAt, &report.Resolution, &report.ResolvedBy, &resolvedAt,
+		&report.CreatedAt, &report.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if len(evidenceJSON) > 0 {
+		if err := json.Unmarshal(evidenceJSON, &report.Evidence); err != nil {
+			return nil, err
+		}
+	}
+	if assignedAt.Valid {
+		report.AssignedAt = &assignedAt.Time
+	}
+	if resolvedAt.Valid {
+		report.ResolvedAt = &resolvedAt.Time
+	}
+	return report, nil
+}
+
+func (r *ReportRepository) Create(ctx context.Context, report *entities.Report) error {
+	if report.Evidence == nil {
+		report.Evidence = []entities.ReportEvidence{}
+	}
+	if report.Status == "" {
+		report.Status = entities.ReportStatusOpen
+	}
+	if report.Source == "" {
+		report.Source = entities.ReportSourceUser
+	}
+	evidenceJSON, err := json.Marshal(report.Evidence)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO user_reports (reporter_id, reported_user_id, source, category, description, content_type, content_id, evidence, status)
+		VALUES (NULLIF($1, '')::uuid, $2, $3, $4, NULLIF($5, ''), NULLIF($6, ''), NULLIF($7, ''), $8, $9)
+		RETURNING id, created_at, updated_at
+	`
+
+	return r.db.QueryRow(ctx, query,
+		report.ReporterID, report.ReportedUserID, report.Source, report.Category, report.Description,
+		report.ContentType, report.ContentID, evidenceJSON, report.Status,
+	).Scan(&report.ID, &report.CreatedAt, &report.UpdatedAt)
+}
+
+func (r *ReportRepository) GetByID(ctx context.Context, id string) (*entities.Report, error) {
+	query := `SELECT ` + reportColumns + ` FROM user_reports WHERE id = $1`
+	return scanReport(r.db.QueryRow(ctx, query, id))
+}
+
+func (r *ReportRepository) List(ctx context.Context, filter entities.ReportFilter) ([]*entities.Report, error) {
+	query := `SELECT ` + reportColumns + `
+		FROM user_reports
+		WHERE ($1 = '' OR status = $1)
+			AND ($2 = '' OR source = $2)
+			AND ($3 = '' OR assigned_to::text = $3)
+			AND ($4 = '' OR reported_user_id::text = $4)
+			AND ($5 = '' OR reporter_id::text = $5)
+		ORDER BY created_at DESC
+	`
+
+	rows, err := r.db.Query(ctx, query, filter.Status, filter.Source, filter.AssignedTo, filter.ReportedUserID, filter.ReporterID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var reports []*entities.Report
+	for rows.Next() {
+		report, err := scanReport(rows)
+		if err != nil {
+			return nil, err
+		}
+		reports = append(reports, report)
+	}
+	return reports, nil
+}
+
+func (r *ReportRepository) Update(ctx context.Context, report *entities.Report) error {
+	query := `
+		UPDATE user_reports SET
+			status = $2,
+			assigned_to = NULLIF($3, '')::uuid,
+			assigned_at = $4,
+			resolution = NULLIF($5, ''),
+			resolved_by = NULLIF($6, '')::uuid,
+			resolved_at = $7,
+			updated_at = NOW()
+		WHERE id = $1
+		RETURNING updated_at
+	`
+
+	return r.db.QueryRow(ctx, query,
+		report.ID, report.Status, report.AssignedTo, report.AssignedAt,
+		report.Resolution, report.ResolvedBy, report.ResolvedAt,
+	).Scan(&report.UpdatedAt)
+}
+
+type ModerationActionRepository struct {
+	db *Database
+}
+
+func NewModerationActionRepository(db *Database) *ModerationActionRepository {
+	return &ModerationActionRepository{db: db}
+}
+
+const moderationActionColumns = `
+	id, user_id, COALESCE(report_id::text, ''), action, reason, COALESCE(content_type, ''), COALESCE(content_id, ''),
+	COALESCE(actor_id::text, ''), expires_at, revoked_at, COALESCE(revoked_by::text, ''), created_at`
+
+func scanModerationAction(row surveyScanner) (*entities.ModerationAction, error) {
+	action := &entities.ModerationAction{}
+	var expiresAt, revokedAt sql.NullTime
+	err := row.Scan(
+		&action.ID, &action.UserID, &action.ReportID, &action.Action, &action.Reason, &action.ContentType, &action.ContentID,
+		&action.ActorID, &expiresAt, &revokedAt, &action.RevokedBy, &action.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if expiresAt.Valid {
+		action.ExpiresAt = &expiresAt.Time
+	}
+	if revokedAt.Valid {
+		action.RevokedAt = &revokedAt.Time
+	}
+	return action, nil
+}
+
+func (r *ModerationActionRepository) Create(ctx context.Context, action *entities.ModerationAction) error {
+	query := `
+		INSERT INTO moderation_actions (user_id, report_id, action, reason, content_type, content_id, actor_id, expires_at)
+		VALUES ($1, NULLIF($2, '')::uuid, $3, $4, NULLIF($5, ''), NULLIF($6, ''), NULLIF($7, '')::uuid, $8)
+		RETURNING id, created_at
+	`
+
+	return r.db.QueryRow(ctx, query,
+		action.UserID, action.ReportID, action.Action, action.Reason, action.ContentType, action.ContentID,
+		action.ActorID, action.ExpiresAt,
+	).Scan(&action.ID, &action.CreatedAt)
+}
+
+func (r *ModerationActionRepository) GetByID(ctx context.Context, id string) (*entities.ModerationAction, error) {
+	query := `SELECT ` + moderationActionColumns + ` FROM moderation_actions WHERE id = $1`
+	return scanModerationAction(r.db.QueryRow(ctx, query, id))
+}
+
+func (r *ModerationActionRepository) Revoke(ctx context.Context, id, revokedBy string) error {
+	query := `
+		UPDATE moderation_actions SET revoked_at = NOW(), revoked_by = NULLIF($2, '')::uuid
+		WHERE id = $1 AND revoked_at IS NULL
+	`
+	_, err := r.db.Exec(ctx, query, id, revokedBy)
+	return err
+}
+
+func (r *ModerationActionRepository) ListByUser(ctx context.Context, userID string) ([]*entities.ModerationAction, error) {
+	query := `SELECT ` + moderationActionColumns + ` FROM moderation_actions WHERE user_id = $1 ORDER BY created_at DESC`
+	return r.list(ctx, query, userID)
+}
+
+func (r *ModerationActionRepository) Active(ctx context.Context, userID string, at time.Time) ([]*entities.ModerationAction, error) {
+	query := `SELECT ` + moderationActionColumns + `
+		FROM moderation_actions
+		WHERE user_id = $1
+			AND action IN ('mute', 'suspend', 'ban')
+			AND revoked_at IS NULL
+			AND (expires_at IS NULL OR expires_at > $2)
+		ORDER BY created_at DESC
+	`
+	return r.list(ctx, query, userID, at)
+}
+
+func (r *ModerationActionRepository) ListSuspendedUserIDs(ctx context.Context, at time.Time) ([]string, error) {
+	query := `
+		SELECT DISTINCT user_id
+		FROM moderation_actions
+		WHERE action IN ('suspend', 'ban')
+			AND revoked_at IS NULL
+			AND (expires_at IS NULL OR expires_at > $1)
+	`
+
+	rows, err := r.db.Query(ctx, query, at)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+func (r *ModerationActionRepository) list(ctx context.Context, query string, args ...interface{}) ([]*entities.ModerationAction, error) {
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var actions []*entities.ModerationAction
+	for rows.Next() {
+		action, err := scanModerationAction(rows)
+		if err != nil {
+			return nil, err
+		}
+		actions = append(actions, action)
+	}
+	return actions, nil
+}
@@ -0,0 +1,142 @@
+package database
+
+import (
+	"context"
+
+	"wizard-connect/internal/domain/entities"
+
+	"github.com/google/uuid"
+)
+
+type StagedMatchRepository struct {
+	db *Database
+}
+
+func NewStagedMatchRepository(db *Database) *StagedMatchRepository {
+	return &StagedMatchRepository{db: db}
+}
+
+const stagedMatchColumns = `
+	id, campaign_id, COALESCE(run_id::text, ''), user_id, matched_user_id, compatibility_score,
+	COALESCE(score_from_user, compatibility_score), COALESCE(score_from_matched, compatibility_score),
+	rank, is_mutual_crush, source, pinned, COALESCE(pinned_by::text, ''),
+	flagged, COALESCE(flag_reason, ''), COALESCE(flagged_by::text, ''), created_at
+`
+
+const insertStagedMatch = `
+	INSERT INTO staged_matches (
+		id, campaign_id, run_id, user_id, matched_user_id, compatibility_score, score_from_user, score_from_matched,
+		rank, is_mutual_crush, source, pinned, pinned_by, flagged, flag_reason, flagged_by
+	)
+	VALUES ($1, $2, NULLIF($3, '')::uuid, $4, $5, $6, $7, $8, $9, $10, $11, $12, NULLIF($13, '')::uuid, $14, $15, NULLIF($16, '')::uuid)
+	RETURNING created_at
+`
+
+func scanStagedMatch(row surveyScanner) (*entities.StagedMatch, error) {
+	m := &entities.StagedMatch{}
+	err := row.Scan(
+		&m.ID, &m.CampaignID, &m.RunID, &m.UserID, &m.MatchedUserID, &m.CompatibilityScore,
+		&m.ScoreFromUser, &m.ScoreFromMatched, &m.Rank, &m.IsMutualCrush, &m.Source, &m.Pinned, &m.PinnedBy,
+		&m.Flagged, &m.FlagReason, &m.FlaggedBy, &m.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func stagedMatchArgs(m *entities.StagedMatch) []interface{} {
+	if m.ID == "" {
+		m.ID = uuid.New().String()
+	}
+	if m.Source == "" {
+		m.Source = entities.MatchSourceAlgorithm
+	}
+	return []interface{}{
+		m.ID, m.CampaignID, m.RunID, m.UserID, m.MatchedUserID, m.CompatibilityScore, m.ScoreFromUser, m.ScoreFromMatched,
+		m.Rank, m.IsMutualCrush, m.Source, m.Pinned, m.PinnedBy, m.Flagged, m.FlagReason, m.FlaggedBy,
+	}
+}
+
+// ReplaceForCampaign swaps the campaign's staged matches in one transaction,
+// so reviewers never see a half-written run
+func (r *StagedMatchRepository) ReplaceForCampaign(ctx context.Context, campaignID string, matches []*entities.StagedMatch) error {
+	tx, err := r.db.BeginTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM staged_matches WHERE campaign_id = $1`, campaignID); err != nil {
+		return err
+	}
+	for _, m := range matches {
+		m.CampaignID = campaignID
+		if err := tx.QueryRowContext(ctx, insertStagedMatch, stagedMatchArgs(m)...).Scan(&m.CreatedAt); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (r *StagedMatchRepository) Create(ctx context.Context, m *entities.StagedMatch) error {
+	return r.db.QueryRow(ctx, insertStagedMatch, stagedMatchArgs(m)...).Scan(&m.CreatedAt)
+}
+
+// Update saves a staged match's rank, pin and flag
+func (r *StagedMatchRepository) Update(ctx context.Context, m *entities.StagedMatch) error {
+	query := `
+		UPDATE staged_matches
+		SET rank = $2,
+		    pinned = $3,
+		    pinned_by = NULLIF($4, '')::uuid,
+		    flagged = $5,
+		    flag_reason = $6,
+		    flagged_by = NULLIF($7, '')::uuid
+		WHERE id = $1
+	`
+
+	_, err := r.db.Exec(ctx, query, m.ID, m.Rank, m.Pinned, m.PinnedBy, m.Flagged, m.FlagReason, m.FlaggedBy)
+	return err
+}
+
+func (r *StagedMatchRepository) Delete(ctx context.Context, id string) error {
+	_, err := r.db.Exec(ctx, `DELETE FROM staged_matches WHERE id = $1`, id)
+	return err
+}
+
+func (r *StagedMatchRepository) GetByID(ctx context.Context, id string) (*entities.StagedMatch, error) {
+	query := `SELECT ` + stagedMatchColumns + ` FROM staged_matches WHERE id = $1`
+	return scanStagedMatch(r.db.QueryRow(ctx, query, id))
+}
+
+// ListByCampaign returns a campaign's staged matches by owner, in rank order
+func (r *StagedMatchRepository) ListByCampaign(ctx context.Context, campaignID string) ([]*entities.StagedMatch, error) {
+	query := `SELECT ` + stagedMatchColumns + `
+		FROM staged_matches
+		WHERE campaign_id = $1
+		ORDER BY user_id, rank ASC
+	`
+
+	rows, err := r.db.Query(ctx, query, campaignID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var matches []*entities.StagedMatch
+	for rows.Next() {
+		m, err := scanStagedMatch(rows)
+		if err != nil {
+			return nil, err
+		}
+		matches = append(matches, m)
+	}
+
+	return matches, nil
+}
+
+func (r *StagedMatchRepository) DeleteByCampaign(ctx context.Context, campaignID string) error {
+	_, err := r.db.Exec(ctx, `DELETE FROM staged_matches WHERE campaign_id = $1`, campaignID)
+	return err
+}
@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"wizard-connect/internal/domain/entities"
+	"wizard-connect/internal/domain/repositories"
 
 	"github.com/google/uuid"
 	"github.com/lib/pq"
@@ -21,6 +22,68 @@ func NewSurveyRepository(db *Database) *SurveyRepository {
 	return &SurveyRepository{db: db}
 }
 
+const surveyColumns = `
+	id, user_id, COALESCE(campaign_id::text, ''), COALESCE(definition_version, 'v1'),
+	responses, personality_type, interests, "values", lifestyle,
+	is_complete, completed_at, COALESCE(personality_traits, '{}'), COALESCE(revision, 0), created_at, updated_at
+`
+
+type surveyScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanSurvey(row surveyScanner) (*entities.SurveyResponse, error) {
+	var responsesJSON []byte
+	var completedAt sql.NullTime
+
+	survey := &entities.SurveyResponse{}
+	err := row.Scan(
+		&survey.ID, &survey.UserID, &survey.CampaignID, &survey.DefinitionVersion,
+		&responsesJSON, &survey.PersonalityType,
+		pq.Array(&survey.Interests), pq.Array(&survey.Values), &survey.Lifestyle, &survey.IsComplete,
+		&completedAt, pq.Array(&survey.PersonalityTraits), &survey.Revision, &survey.CreatedAt, &survey.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	survey.CompletedAt = completedAt.Time
+
+	// Unmarshal JSON responses
+	json.Unmarshal(responsesJSON, &survey.Responses)
+
+	return survey, nil
+}
+
+func (r *SurveyRepository) querySurveys(ctx context.Context, query string, args ...interface{}) ([]*entities.SurveyResponse, error) {
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var surveys []*entities.SurveyResponse
+	for rows.Next() {
+		survey, err := scanSurvey(rows)
+		if err != nil {
+			return nil, err
+		}
+		surveys = append(surveys, survey)
+	}
+
+	return surveys, nil
+}
+
+// nullableCampaign maps the empty campaign ID (surveys taken outside any campaign) to NULL
+func nullableCampaign(campaignID string) interface{} {
+	if campaignID == "" {
+		return nil
+	}
+	return campaignID
+}
+
+// CreateOrUpdate saves the user's survey for survey.CampaignID, leaving their
+// surveys from other campaigns untouched
 func (r *SurveyRepository) CreateOrUpdate(ctx context.Context, survey *entities.SurveyResponse) error {
 	// Generate UUID if not provided
 	if survey.ID == "" {
@@ -34,9 +97,12 @@ func (r *SurveyRepository) CreateOrUpdate(ctx context.Context, survey *entities.
 	if survey.Values == nil {
 		survey.Values = []string{}
 	}
+	if survey.PersonalityTraits == nil {
+		survey.PersonalityTraits = []float64{}
+	}
 
 	// Check if survey exists
-	existing, checkErr := r.GetByUserID(ctx, survey.UserID)
+	existing, checkErr := r.GetByUserAndCampaign(ctx, survey.UserID, survey.CampaignID)
 
 	// Marshal responses once
 	responsesJSON, err := json.Marshal(survey.Responses)
@@ -58,21 +124,25 @@ func (r *SurveyRepository) CreateOrUpdate(ctx context.Context, survey *entities.
 			    lifestyle = $5,
 			    is_complete = $6,
 			    completed_at = $7,
+			    personality_traits = $8,
+			    definition_version = $9,
+			    revision = COALESCE(revision, 0) + 1,
 			    updated_at = NOW()
-			WHERE user_id = $8
+			WHERE id = $10
+			RETURNING revision
 		`
 
-		_, err = r.db.Exec(ctx, query,
+		err = r.db.QueryRow(ctx, query,
 			responsesJSON, survey.PersonalityType,
 			pq.Array(survey.Interests), pq.Array(survey.Values),
 			survey.Lifestyle, survey.IsComplete, survey.CompletedAt,
-			survey.UserID,
-		)
+			pq.Array(survey.PersonalityTraits), survey.DefinitionVersion, survey.ID,
+		).Scan(&survey.Revision)
 
 		if err != nil {
 			return fmt.Errorf("failed to execute survey update: %w", err)
 		}
-	} else if checkErr == nil || checkErr == sql.ErrNoRows {
+	} else if checkErr == nil {
 		// Insert new survey (no existing survey found)
 		now := time.Now()
 		survey.CreatedAt = now
@@ -80,16 +150,18 @@ func (r *SurveyRepository) CreateOrUpdate(ctx context.Context, survey *entities.
 		if survey.IsComplete {
 			survey.CompletedAt = now
 		}
+		survey.Revision = 1
 
 		query := `
-			INSERT INTO surveys (id, user_id, responses, personality_type, interests, "values", lifestyle, is_complete, completed_at, created_at, updated_at)
-			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+			INSERT INTO surveys (id, user_id, campaign_id, definition_version, responses, personality_type, interests, "values", lifestyle, is_complete, completed_at, personality_traits, revision, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, 1, $13, $14)
 		`
 
 		_, err = r.db.Exec(ctx, query,
-			survey.ID, survey.UserID, responsesJSON, survey.PersonalityType,
+			survey.ID, survey.UserID, nullableCampaign(survey.CampaignID), survey.DefinitionVersion,
+			responsesJSON, survey.PersonalityType,
 			pq.Array(survey.Interests), pq.Array(survey.Values), survey.Lifestyle, survey.IsComplete,
-			survey.CompletedAt, survey.CreatedAt, survey.UpdatedAt,
+			survey.CompletedAt, pq.Array(survey.PersonalityTraits), survey.CreatedAt, survey.UpdatedAt,
 		)
 
 		if err != nil {
@@ -103,23 +175,113 @@ func (r *SurveyRepository) CreateOrUpdate(ctx context.Context, survey *entities.
 	return nil
 }
 
+// SaveRevision inserts a survey without an ID, or updates a stored one only
+// if its revision still equals expectedRevision, and bumps the revision. It
+// returns repositories.ErrRevisionConflict otherwise. Rows from before
+// revisions were tracked sit at revision 0, so the choice between insert
+// and update can't be made from the revision.
+func (r *SurveyRepository) SaveRevision(ctx context.Context, survey *entities.SurveyResponse, expectedRevision int) error {
+	if survey.Interests == nil {
+		survey.Interests = []string{}
+	}
+	if survey.Values == nil {
+		survey.Values = []string{}
+	}
+	if survey.PersonalityTraits == nil {
+		survey.PersonalityTraits = []float64{}
+	}
+
+	responsesJSON, err := json.Marshal(survey.Responses)
+	if err != nil {
+		return fmt.Errorf("failed to marshal responses: %w", err)
+	}
+
+	var completedAt interface{}
+	if !survey.CompletedAt.IsZero() {
+		completedAt = survey.CompletedAt
+	}
+
+	if survey.ID == "" {
+		survey.ID = uuid.New().String()
+
+		// The unique (user, campaign) index turns a concurrent first save into a conflict
+		query := `
+			INSERT INTO surveys (id, user_id, campaign_id, definition_version, responses, personality_type, interests, "values", lifestyle, is_complete, completed_at, personality_traits, revision, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, 1, NOW(), NOW())
+			ON CONFLICT DO NOTHING
+			RETURNING revision, created_at, updated_at
+		`
+
+		err = r.db.QueryRow(ctx, query,
+			survey.ID, survey.UserID, nullableCampaign(survey.CampaignID), survey.DefinitionVersion,
+			responsesJSON, survey.PersonalityType,
+			pq.Array(survey.Interests), pq.Array(survey.Values), survey.Lifestyle, survey.IsComplete,
+			completedAt, pq.Array(survey.PersonalityTraits),
+		).Scan(&survey.Revision, &survey.CreatedAt, &survey.UpdatedAt)
+	} else {
+		query := `
+			UPDATE surveys
+			SET responses = $1,
+			    personality_type = $2,
+			    interests = $3,
+			    "values" = $4,
+			    lifestyle = $5,
+			    is_complete = $6,
+			    completed_at = $7,
+			    personality_traits = $8,
+			    definition_version = $9,
+			    revision = revision + 1,
+			    updated_at = NOW()
+			WHERE id = $10 AND revision = $11
+			RETURNING revision, created_at, updated_at
+		`
+
+		err = r.db.QueryRow(ctx, query,
+			responsesJSON, survey.PersonalityType,
+			pq.Array(survey.Interests), pq.Array(survey.Values),
+			survey.Lifestyle, survey.IsComplete, completedAt,
+			pq.Array(survey.PersonalityTraits), survey.DefinitionVersion, survey.ID, expectedRevision,
+		).Scan(&survey.Revision, &survey.CreatedAt, &survey.UpdatedAt)
+	}
+
+	if err == sql.ErrNoRows {
+		return repositories.ErrRevisionConflict
+	}
+	if err != nil {
+		return fmt.Errorf("failed to save survey: %w", err)
+	}
+	return nil
+}
+
+// GetByUserID returns the user's most recently updated survey from any campaign
 func (r *SurveyRepository) GetByUserID(ctx context.Context, userID string) (*entities.SurveyResponse, error) {
-	query := `
-		SELECT id, user_id, responses, personality_type, interests, "values", lifestyle,
-		       is_complete, completed_at, created_at, updated_at
+	query := `SELECT ` + surveyColumns + `
 		FROM surveys
 		WHERE user_id = $1
+		ORDER BY updated_at DESC
+		LIMIT 1
 	`
 
-	var responsesJSON []byte
+	survey, err := scanSurvey(r.db.QueryRow(ctx, query, userID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
 
-	survey := &entities.SurveyResponse{}
-	err := r.db.QueryRow(ctx, query, userID).Scan(
-		&survey.ID, &survey.UserID, &responsesJSON, &survey.PersonalityType,
-		pq.Array(&survey.Interests), pq.Array(&survey.Values), &survey.Lifestyle, &survey.IsComplete,
-		&survey.CompletedAt, &survey.CreatedAt, &survey.UpdatedAt,
-	)
+	return survey, nil
+}
 
+// GetByUserAndCampaign returns the user's survey for one campaign. An empty
+// campaign ID selects the survey taken outside any campaign.
+func (r *SurveyRepository) GetByUserAndCampaign(ctx context.Context, userID, campaignID string) (*entities.SurveyResponse, error) {
+	query := `SELECT ` + surveyColumns + `
+		FROM surveys
+		WHERE user_id = $1 AND campaign_id IS NOT DISTINCT FROM $2::uuid
+	`
+
+	survey, err := scanSurvey(r.db.QueryRow(ctx, query, userID, nullableCampaign(campaignID)))
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
@@ -127,46 +289,52 @@ func (r *SurveyRepository) GetByUserID(ctx context.Context, userID string) (*ent
 		return nil, err
 	}
 
-	// Unmarshal JSON responses
-	json.Unmarshal(responsesJSON, &survey.Responses)
-
 	return survey, nil
 }
 
+// ListByUserID returns every survey the user has taken, newest first
+func (r *SurveyRepository) ListByUserID(ctx context.Context, userID string) ([]*entities.SurveyResponse, error) {
+	query := `SELECT ` + surveyColumns + `
+		FROM surveys
+		WHERE user_id = $1
+		ORDER BY created_at DESC
+	`
+
+	return r.querySurveys(ctx, query, userID)
+}
+
 func (r *SurveyRepository) GetCompletedSurveys(ctx context.Context) ([]*entities.SurveyResponse, error) {
-	query := `
-		SELECT id, user_id, responses, personality_type, interests, "values", lifestyle,
-		       is_complete, completed_at, created_at, updated_at
+	query := `SELECT ` + surveyColumns + `
 		FROM surveys
 		WHERE is_complete = true
 		ORDER BY completed_at DESC
 	`
 
-	rows, err := r.db.Query(ctx, query)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-
-	var surveys []*entities.SurveyResponse
-	for rows.Next() {
-		var responsesJSON []byte
+	return r.querySurveys(ctx, query)
+}
 
-		survey := &entities.SurveyResponse{}
-		err := rows.Scan(
-			&survey.ID, &survey.UserID, &responsesJSON, &survey.PersonalityType,
-			pq.Array(&survey.Interests), pq.Array(&survey.Values), &survey.Lifestyle, &survey.IsComplete,
-			&survey.CompletedAt, &survey.CreatedAt, &survey.UpdatedAt,
-		)
-		if err != nil {
-			return nil, err
-		}
+// GetCompletedSurveysForCampaign returns the completed surveys answered for a
+// campaign under the given definition version
+func (r *SurveyRepository) GetCompletedSurveysForCampaign(ctx context.Context, campaignID, definitionVersion string) ([]*entities.SurveyResponse, error) {
+	query := `SELECT ` + surveyColumns + `
+		FROM surveys
+		WHERE is_complete = true
+		  AND campaign_id IS NOT DISTINCT FROM $1::uuid
+		  AND COALESCE(definition_version, 'v1') = $2
+		ORDER BY completed_at DESC
+	`
 
-		// Unmarshal JSON responses
-		json.Unmarshal(responsesJSON, &survey.Responses)
+	return r.querySurveys(ctx, query, nullableCampaign(campaignID), definitionVersion)
+}
 
-		surveys = append(surveys, survey)
-	}
+// ListByCampaign returns every survey started for a campaign, complete or
+// not, newest first
+func (r *SurveyRepository) ListByCampaign(ctx context.Context, campaignID string) ([]*entities.SurveyResponse, error) {
+	query := `SELECT ` + surveyColumns + `
+		FROM surveys
+		WHERE campaign_id IS NOT DISTINCT FROM $1::uuid
+		ORDER BY created_at DESC
+	`
 
-	return surveys, nil
+	return r.querySurveys(ctx, query, nullableCampaign(campaignID))
 }
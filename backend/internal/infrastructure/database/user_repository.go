@@ -84,39 +84,9 @@ func (r *UserRepository) GetByID(ctx context.Context, id string) (*entities.User
 		&user.Year, &user.Major, &user.Gender, &user.GenderPreference, &user.CreatedAt, &user.UpdatedAt,
 	)
 
+	// Profiles are created on first sign-in by the registration check, which
+	// a lookup must not bypass
 	if err != nil {
-		// If user not found in public.users, try to create a shell user
-		// by checking if they exist in auth.users
-		if strings.Contains(err.Error(), "no rows") {
-			fmt.Printf("DEBUG: User %s not found in public.users, attempting to create shell user\n", id)
-
-			// Try to get user info from auth.users
-			authQuery := `SELECT id, email FROM public.get_user_from_auth($1)`
-			var authID, authEmail string
-			authErr := r.db.QueryRow(ctx, authQuery, id).Scan(&authID, &authEmail)
-
-			if authErr != nil {
-				return nil, fmt.Errorf("failed to get user: %w (also failed to get from auth: %w)", err, authErr)
-			}
-
-			// Create shell user
-			newUser := &entities.User{
-				ID:               authID,
-				Email:            authEmail,
-				ContactPref:      "email",
-				Visibility:       "matches_only",
-				Gender:           "prefer_not_to_say",
-				GenderPreference: "both",
-			}
-
-			createErr := r.Create(ctx, newUser)
-			if createErr != nil {
-				return nil, fmt.Errorf("failed to create shell user: %w", createErr)
-			}
-
-			fmt.Printf("DEBUG: Shell user created successfully: %+v\n", newUser)
-			return newUser, nil
-		}
 		return nil, fmt.Errorf("failed to get user: %w", err)
 	}
 
@@ -313,14 +283,104 @@ func (r *UserRepository) Update(ctx context.Context, user *entities.User) error
 	return nil
 }
 
-func (r *UserRepository) Delete(ctx context.Context, id string) error {
-	query := `DELETE FROM users WHERE id = $1`
+// clearableUserColumns are the profile columns ClearFields may empty
+var clearableUserColumns = map[string]bool{
+	"first_name":        true,
+	"last_name":         true,
+	"avatar_url":        true,
+	"bio":               true,
+	"instagram":         true,
+	"phone":             true,
+	"year":              true,
+	"major":             true,
+	"gender":            true,
+	"gender_preference": true,
+}
+
+func (r *UserRepository) ClearFields(ctx context.Context, id string, fields ...string) error {
+	if len(fields) == 0 {
+		return nil
+	}
+
+	updates := make([]string, 0, len(fields)+1)
+	for _, field := range fields {
+		if !clearableUserColumns[field] {
+			return fmt.Errorf("field %q can't be cleared", field)
+		}
+		updates = append(updates, field+" = NULL")
+	}
+	updates = append(updates, "updated_at = NOW()")
+
+	query := fmt.Sprintf("UPDATE users SET %s WHERE id = $1", strings.Join(updates, ", "))
+	if _, err := r.db.Exec(ctx, query, id); err != nil {
+		return fmt.Errorf("clear failed: %w", err)
+	}
+
+	// Invalidate cache
+	r.mu.Lock()
+	delete(r.cache, id)
+	r.mu.Unlock()
+
+	return nil
+}
 
-	_, err := r.db.Exec(ctx, query, id)
+// accountErasure deletes, in order, every row about the user ($1). Most
+// tables would follow auth.users through ON DELETE CASCADE, but reports the
+// user filed are kept, so everything is spelled out.
+var accountErasure = []string{
+	`DELETE FROM crushes WHERE user_id = $1`,
+	`DELETE FROM staged_matches WHERE user_id = $1 OR matched_user_id = $1`,
+	`DELETE FROM match_vetoes WHERE user_a_id = $1 OR user_b_id = $1`,
+	`DELETE FROM match_feedback WHERE user_id = $1 OR matched_user_id = $1`,
+	`DELETE FROM matches WHERE user_id = $1 OR matched_user_id = $1`,
+	`DELETE FROM messages WHERE conversation_id IN (SELECT id FROM conversations WHERE participant1 = $1 OR participant2 = $1)`,
+	`DELETE FROM conversations WHERE participant1 = $1 OR participant2 = $1`,
+	`DELETE FROM user_blocks WHERE blocker_id = $1 OR blocked_id = $1`,
+	`DELETE FROM moderation_actions WHERE user_id = $1`,
+	`DELETE FROM user_reports WHERE reported_user_id = $1`,
+	`UPDATE user_reports SET reporter_id = NULL, updated_at = NOW() WHERE reporter_id = $1`,
+	`DELETE FROM surveys WHERE user_id = $1`,
+	`DELETE FROM admin_users WHERE user_id = $1`,
+	`DELETE FROM users WHERE id = $1`,
+	// Deleting the auth account removes its sessions and refresh tokens
+	`DELETE FROM auth.users WHERE id = $1`,
+	`INSERT INTO deleted_accounts (user_id) VALUES ($1) ON CONFLICT (user_id) DO NOTHING`,
+}
 
+// Delete erases the account in one transaction, so a failure leaves it whole
+func (r *UserRepository) Delete(ctx context.Context, id string) error {
+	tx, err := r.db.BeginTx(ctx)
 	if err != nil {
 		return err
 	}
+	defer tx.Rollback()
+
+	var email string
+	err = tx.QueryRowContext(ctx, `
+		SELECT COALESCE(
+			(SELECT email FROM users WHERE id = $1),
+			(SELECT email FROM auth.users WHERE id = $1),
+			''
+		)
+	`, id).Scan(&email)
+	if err != nil {
+		return fmt.Errorf("failed to look up account: %w", err)
+	}
+
+	// Other users' crushes name the user by email, not ID
+	if email != "" {
+		if _, err := tx.ExecContext(ctx, `DELETE FROM crushes WHERE LOWER(crush_email) = LOWER($1)`, email); err != nil {
+			return fmt.Errorf("delete failed: %w", err)
+		}
+	}
+	for _, query := range accountErasure {
+		if _, err := tx.ExecContext(ctx, query, id); err != nil {
+			return fmt.Errorf("delete failed: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
 
 	// Invalidate cache
 	r.mu.Lock()
@@ -330,6 +390,18 @@ func (r *UserRepository) Delete(ctx context.Context, id string) error {
 	return nil
 }
 
+func (r *UserRepository) Exists(ctx context.Context, id string) (bool, error) {
+	var exists bool
+	err := r.db.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM users WHERE id = $1)`, id).Scan(&exists)
+	return exists, err
+}
+
+func (r *UserRepository) IsDeleted(ctx context.Context, id string) (bool, error) {
+	var deleted bool
+	err := r.db.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM deleted_accounts WHERE user_id = $1)`, id).Scan(&deleted)
+	return deleted, err
+}
+
 func (r *UserRepository) List(ctx context.Context, limit, offset int) ([]*entities.User, error) {
 	query := `
 		SELECT id, email,
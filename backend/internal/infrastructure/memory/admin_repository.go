@@ -0,0 +1,66 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+)
+
+type AdminRepository struct {
+	store *Store
+}
+
+func NewAdminRepository(store *Store) *AdminRepository {
+	return &AdminRepository{store: store}
+}
+
+func (r *AdminRepository) IsAdmin(ctx context.Context, userID string) (bool, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	for _, id := range r.store.admins {
+		if id == userID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// AddAdmin grants admin rights to an existing user, like public.add_admin
+func (r *AdminRepository) AddAdmin(ctx context.Context, email string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, exists := r.store.admins[email]; exists {
+		return nil
+	}
+	for _, user := range r.store.users {
+		if user.Email == email {
+			r.store.admins[email] = user.ID
+			r.store.adminOrder = append(r.store.adminOrder, email)
+			return nil
+		}
+	}
+	return fmt.Errorf("user not found: %s", email)
+}
+
+func (r *AdminRepository) RemoveAdmin(ctx context.Context, email string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	delete(r.store.admins, email)
+	for i, e := range r.store.adminOrder {
+		if e == email {
+			r.store.adminOrder = append(r.store.adminOrder[:i], r.store.adminOrder[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+// ListAdmins returns admin emails in the order they were added
+func (r *AdminRepository) ListAdmins(ctx context.Context) ([]string, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	return append([]string(nil), r.store.adminOrder...), nil
+}
@@ -0,0 +1,52 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"wizard-connect/internal/domain/entities"
+
+	"github.com/google/uuid"
+)
+
+type AuditLogRepository struct {
+	store *Store
+}
+
+func NewAuditLogRepository(store *Store) *AuditLogRepository {
+	return &AuditLogRepository{store: store}
+}
+
+func (r *AuditLogRepository) Create(ctx context.Context, entry *entities.AuditEntry) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if entry.ID == "" {
+		entry.ID = uuid.New().String()
+	}
+	if entry.CreatedAt.IsZero() {
+		entry.CreatedAt = time.Now()
+	}
+	stored := *entry
+	stored.Details = copyMap(entry.Details)
+	r.store.audit = append(r.store.audit, &stored)
+	return nil
+}
+
+// List returns entries newest first; empty filters match everything
+func (r *AuditLogRepository) List(ctx context.Context, targetType, targetID string, limit int) ([]*entities.AuditEntry, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var entries []*entities.AuditEntry
+	for i := len(r.store.audit) - 1; i >= 0 && len(entries) < limit; i-- {
+		entry := r.store.audit[i]
+		if (targetType != "" && entry.TargetType != targetType) || (targetID != "" && entry.TargetID != targetID) {
+			continue
+		}
+		c := *entry
+		c.Details = copyMap(entry.Details)
+		entries = append(entries, &c)
+	}
+	return entries, nil
+}
@@ -0,0 +1,87 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"wizard-connect/internal/domain/entities"
+)
+
+type CampaignRepository struct {
+	store *Store
+}
+
+func NewCampaignRepository(store *Store) *CampaignRepository {
+	return &CampaignRepository{store: store}
+}
+
+func (r *CampaignRepository) Create(ctx context.Context, campaign *entities.Campaign) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, exists := r.store.campaigns[campaign.ID]; exists {
+		return fmt.Errorf("campaign %s already exists", campaign.ID)
+	}
+	r.store.campaigns[campaign.ID] = copyCampaign(campaign)
+	return nil
+}
+
+func (r *CampaignRepository) GetByID(ctx context.Context, id string) (*entities.Campaign, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	campaign, ok := r.store.campaigns[id]
+	if !ok {
+		return nil, errNotFound
+	}
+	return copyCampaign(campaign), nil
+}
+
+// GetActive returns the most recently created active campaign, or nil if none is active
+func (r *CampaignRepository) GetActive(ctx context.Context) (*entities.Campaign, error) {
+	campaigns, _ := r.GetAll(ctx)
+	for _, campaign := range campaigns {
+		if campaign.IsActive {
+			return campaign, nil
+		}
+	}
+	return nil, nil
+}
+
+// GetAll returns every campaign, newest first
+func (r *CampaignRepository) GetAll(ctx context.Context) ([]*entities.Campaign, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	campaigns := make([]*entities.Campaign, 0, len(r.store.campaigns))
+	for _, campaign := range r.store.campaigns {
+		campaigns = append(campaigns, copyCampaign(campaign))
+	}
+	sort.Slice(campaigns, func(i, j int) bool {
+		if !campaigns[i].CreatedAt.Equal(campaigns[j].CreatedAt) {
+			return campaigns[i].CreatedAt.After(campaigns[j].CreatedAt)
+		}
+		return campaigns[i].ID < campaigns[j].ID
+	})
+	return campaigns, nil
+}
+
+func (r *CampaignRepository) Update(ctx context.Context, campaign *entities.Campaign) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.campaigns[campaign.ID]; !ok {
+		return errNotFound
+	}
+	r.store.campaigns[campaign.ID] = copyCampaign(campaign)
+	return nil
+}
+
+func (r *CampaignRepository) Delete(ctx context.Context, id string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	delete(r.store.campaigns, id)
+	return nil
+}
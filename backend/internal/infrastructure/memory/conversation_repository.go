@@ -0,0 +1,99 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"wizard-connect/internal/domain/entities"
+
+	"github.com/google/uuid"
+)
+
+type ConversationRepository struct {
+	store *Store
+}
+
+func NewConversationRepository(store *Store) *ConversationRepository {
+	return &ConversationRepository{store: store}
+}
+
+func (r *ConversationRepository) Create(ctx context.Context, conv *entities.Conversation) error {
+	// Same ordering as the participant1 < participant2 check in Postgres
+	if conv.Participant1 > conv.Participant2 {
+		conv.Participant1, conv.Participant2 = conv.Participant2, conv.Participant1
+	}
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	conv.ID = uuid.New().String()
+	now := time.Now()
+	conv.CreatedAt, conv.UpdatedAt = now, now
+
+	stored := *conv
+	r.store.conversations[conv.ID] = &stored
+	return nil
+}
+
+func (r *ConversationRepository) GetByID(ctx context.Context, id string) (*entities.Conversation, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	conv, ok := r.store.conversations[id]
+	if !ok {
+		return nil, errNotFound
+	}
+	c := *conv
+	return &c, nil
+}
+
+func (r *ConversationRepository) GetByParticipants(ctx context.Context, participant1, participant2 string) (*entities.Conversation, error) {
+	p1, p2 := participant1, participant2
+	if p1 > p2 {
+		p1, p2 = p2, p1
+	}
+
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	for _, conv := range r.store.conversations {
+		if conv.Participant1 == p1 && conv.Participant2 == p2 {
+			c := *conv
+			return &c, nil
+		}
+	}
+	return nil, errNotFound
+}
+
+// GetByUserID returns the user's conversations, most recently active first
+func (r *ConversationRepository) GetByUserID(ctx context.Context, userID string) ([]*entities.Conversation, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var conversations []*entities.Conversation
+	for _, conv := range r.store.conversations {
+		if conv.Participant1 == userID || conv.Participant2 == userID {
+			c := *conv
+			conversations = append(conversations, &c)
+		}
+	}
+	sort.Slice(conversations, func(i, j int) bool {
+		if !conversations[i].UpdatedAt.Equal(conversations[j].UpdatedAt) {
+			return conversations[i].UpdatedAt.After(conversations[j].UpdatedAt)
+		}
+		return conversations[i].ID < conversations[j].ID
+	})
+	return conversations, nil
+}
+
+func (r *ConversationRepository) UpdateLastMessage(ctx context.Context, conversationID, lastMessage string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if conv, ok := r.store.conversations[conversationID]; ok {
+		conv.LastMessage = lastMessage
+		conv.UpdatedAt = time.Now()
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"wizard-connect/internal/domain/entities"
+
+	"github.com/google/uuid"
+)
+
+type CrushRepository struct {
+	store *Store
+}
+
+func NewCrushRepository(store *Store) *CrushRepository {
+	return &CrushRepository{store: store}
+}
+
+func (r *CrushRepository) Create(ctx context.Context, crush *entities.Crush) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if crush.ID == "" {
+		crush.ID = uuid.New().String()
+	}
+	crush.CreatedAt = time.Now()
+
+	stored := *crush
+	r.store.crushes[crush.ID] = &stored
+	return nil
+}
+
+// GetByUserID returns the user's crush list in rank order
+func (r *CrushRepository) GetByUserID(ctx context.Context, userID string) ([]*entities.Crush, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var crushes []*entities.Crush
+	for _, crush := range r.store.crushes {
+		if crush.UserID == userID {
+			c := *crush
+			crushes = append(crushes, &c)
+		}
+	}
+	sort.Slice(crushes, func(i, j int) bool { return crushes[i].Rank < crushes[j].Rank })
+	return crushes, nil
+}
+
+func (r *CrushRepository) Delete(ctx context.Context, id string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	delete(r.store.crushes, id)
+	return nil
+}
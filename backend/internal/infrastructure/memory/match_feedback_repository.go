@@ -0,0 +1,77 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"wizard-connect/internal/domain/entities"
+
+	"github.com/google/uuid"
+)
+
+type MatchFeedbackRepository struct {
+	store *Store
+}
+
+func NewMatchFeedbackRepository(store *Store) *MatchFeedbackRepository {
+	return &MatchFeedbackRepository{store: store}
+}
+
+// Upsert mirrors ON CONFLICT (match_id): the first feedback's ID and
+// created_at are kept
+func (r *MatchFeedbackRepository) Upsert(ctx context.Context, feedback *entities.MatchFeedback) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	now := time.Now()
+	for _, existing := range r.store.feedback {
+		if existing.MatchID == feedback.MatchID {
+			existing.Rating, existing.Met, existing.Comment = feedback.Rating, feedback.Met, feedback.Comment
+			existing.UpdatedAt = now
+			feedback.ID, feedback.CampaignID = existing.ID, existing.CampaignID
+			feedback.CreatedAt, feedback.UpdatedAt = existing.CreatedAt, existing.UpdatedAt
+			return nil
+		}
+	}
+
+	feedback.ID = uuid.New().String()
+	feedback.CreatedAt, feedback.UpdatedAt = now, now
+	stored := *feedback
+	r.store.feedback[feedback.ID] = &stored
+	return nil
+}
+
+func (r *MatchFeedbackRepository) GetByMatchID(ctx context.Context, matchID string) (*entities.MatchFeedback, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	for _, feedback := range r.store.feedback {
+		if feedback.MatchID == matchID {
+			c := *feedback
+			return &c, nil
+		}
+	}
+	return nil, errNotFound
+}
+
+// ListByCampaign returns a campaign's feedback, oldest first
+func (r *MatchFeedbackRepository) ListByCampaign(ctx context.Context, campaignID string) ([]*entities.MatchFeedback, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var result []*entities.MatchFeedback
+	for _, feedback := range r.store.feedback {
+		if feedback.CampaignID == campaignID {
+			c := *feedback
+			result = append(result, &c)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if !result[i].CreatedAt.Equal(result[j].CreatedAt) {
+			return result[i].CreatedAt.Before(result[j].CreatedAt)
+		}
+		return result[i].ID < result[j].ID
+	})
+	return result, nil
+}
@@ -0,0 +1,264 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"wizard-connect/internal/domain/entities"
+
+	"github.com/google/uuid"
+)
+
+type MatchRepository struct {
+	store *Store
+}
+
+func NewMatchRepository(store *Store) *MatchRepository {
+	return &MatchRepository{store: store}
+}
+
+func (r *MatchRepository) Create(ctx context.Context, match *entities.Match) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if match.ID == "" {
+		match.ID = uuid.New().String()
+	}
+	if match.CreatedAt.IsZero() {
+		match.CreatedAt = time.Now()
+	}
+	if match.Source == "" {
+		match.Source = entities.MatchSourceAlgorithm
+	}
+
+	stored := *match
+	r.store.matches[match.ID] = &stored
+	return nil
+}
+
+// GetByUserID returns the user's matches in rank order
+func (r *MatchRepository) GetByUserID(ctx context.Context, userID string) ([]*entities.Match, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	matches := r.byUserLocked(userID)
+	result := make([]*entities.Match, len(matches))
+	for i, match := range matches {
+		m := *match
+		result[i] = &m
+	}
+	return result, nil
+}
+
+func (r *MatchRepository) GetByID(ctx context.Context, id string) (*entities.Match, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	match, ok := r.store.matches[id]
+	if !ok {
+		return nil, errNotFound
+	}
+	m := *match
+	return &m, nil
+}
+
+func (r *MatchRepository) GetMatch(ctx context.Context, userID, matchedUserID string) (*entities.Match, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	for _, match := range r.store.matches {
+		if match.UserID == userID && match.MatchedUserID == matchedUserID {
+			m := *match
+			return &m, nil
+		}
+	}
+	return nil, errNotFound
+}
+
+func (r *MatchRepository) MarkViewed(ctx context.Context, userID, matchedUserID string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for _, match := range r.store.matches {
+		if match.UserID == userID && match.MatchedUserID == matchedUserID {
+			match.UserAViewed = true
+		} else if match.UserID == matchedUserID && match.MatchedUserID == userID {
+			match.UserBViewed = true
+		}
+	}
+	return nil
+}
+
+func (r *MatchRepository) MarkContacted(ctx context.Context, userID, matchedUserID string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for _, match := range r.store.matches {
+		if match.UserID == userID && match.MatchedUserID == matchedUserID {
+			match.UserAContacted = true
+		} else if match.UserID == matchedUserID && match.MatchedUserID == userID {
+			match.UserBContacted = true
+		}
+	}
+	return nil
+}
+
+// Respond mirrors the Postgres statement: a pass locks messaging, two
+// interested responses unlock it
+func (r *MatchRepository) Respond(ctx context.Context, userID, matchedUserID, response, passReason string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for _, match := range r.store.matches {
+		if match.UserID == userID && match.MatchedUserID == matchedUserID {
+			match.UserAResponse, match.UserAPassReason = response, passReason
+		} else if match.UserID == matchedUserID && match.MatchedUserID == userID {
+			match.UserBResponse, match.UserBPassReason = response, passReason
+		} else {
+			continue
+		}
+		bothInterested := match.UserAResponse == entities.MatchResponseInterested && match.UserBResponse == entities.MatchResponseInterested
+		match.MessagingUnlocked = response != entities.MatchResponsePass && (match.MessagingUnlocked || bothInterested)
+	}
+	return nil
+}
+
+// UpdateRank moves a match to a new position in its owner's list
+func (r *MatchRepository) UpdateRank(ctx context.Context, id string, rank int) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	match, ok := r.store.matches[id]
+	if !ok {
+		return errNotFound
+	}
+	match.Rank = rank
+	return nil
+}
+
+func (r *MatchRepository) DeleteByUserID(ctx context.Context, userID string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for id, match := range r.store.matches {
+		if match.UserID == userID {
+			delete(r.store.matches, id)
+		}
+	}
+	return nil
+}
+
+// GetByUserIDWithUserDetails joins each of the user's matches with the
+// matched user's profile, skipping matches whose user no longer exists
+func (r *MatchRepository) GetByUserIDWithUserDetails(ctx context.Context, userID string) ([]*entities.MatchWithUserDetails, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var result []*entities.MatchWithUserDetails
+	for _, match := range r.byUserLocked(userID) {
+		user, ok := r.store.users[match.MatchedUserID]
+		if !ok {
+			continue
+		}
+		result = append(result, &entities.MatchWithUserDetails{
+			ID:                 match.ID,
+			UserID:             match.UserID,
+			MatchedUserID:      match.MatchedUserID,
+			CompatibilityScore: match.CompatibilityScore,
+			ScoreFromUser:      match.ScoreFromUser,
+			ScoreFromMatched:   match.ScoreFromMatched,
+			Rank:               match.Rank,
+			IsMutualCrush:      match.IsMutualCrush,
+			Source:             match.Source,
+			UserAViewed:        match.UserAViewed,
+			UserBViewed:        match.UserBViewed,
+			UserAContacted:     match.UserAContacted,
+			UserBContacted:     match.UserBContacted,
+			MessagingUnlocked:  match.MessagingUnlocked,
+			UserAResponse:      match.UserAResponse,
+			CreatedAt:          match.CreatedAt,
+			MatchedEmail:       user.Email,
+			FirstName:          user.FirstName,
+			LastName:           user.LastName,
+			AvatarURL:          user.AvatarURL,
+			Bio:                user.Bio,
+			Year:               user.Year,
+			Major:              user.Major,
+			Gender:             orDefault(user.Gender, "prefer_not_to_say"),
+			GenderPreference:   orDefault(user.GenderPreference, "both"),
+			Visibility:         orDefault(user.Visibility, "matches_only"),
+		})
+	}
+	return result, nil
+}
+
+// ListAllWithUserDetails returns every match with both profiles, newest first
+func (r *MatchRepository) ListAllWithUserDetails(ctx context.Context) ([]*entities.MatchWithBothUserDetails, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var result []*entities.MatchWithBothUserDetails
+	for _, match := range r.store.matches {
+		user1, ok1 := r.store.users[match.UserID]
+		user2, ok2 := r.store.users[match.MatchedUserID]
+		if !ok1 || !ok2 {
+			continue
+		}
+		result = append(result, &entities.MatchWithBothUserDetails{
+			ID:                 match.ID,
+			UserID:             match.UserID,
+			MatchedUserID:      match.MatchedUserID,
+			CompatibilityScore: match.CompatibilityScore,
+			Rank:               match.Rank,
+			IsMutualCrush:      match.IsMutualCrush,
+			Source:             match.Source,
+			CreatedBy:          match.CreatedBy,
+			CreatedAt:          match.CreatedAt,
+			User1Email:         user1.Email,
+			User1FirstName:     user1.FirstName,
+			User1LastName:      user1.LastName,
+			User1AvatarURL:     user1.AvatarURL,
+			User2Email:         user2.Email,
+			User2FirstName:     user2.FirstName,
+			User2LastName:      user2.LastName,
+			User2AvatarURL:     user2.AvatarURL,
+		})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if !result[i].CreatedAt.Equal(result[j].CreatedAt) {
+			return result[i].CreatedAt.After(result[j].CreatedAt)
+		}
+		if result[i].Rank != result[j].Rank {
+			return result[i].Rank < result[j].Rank
+		}
+		return result[i].ID < result[j].ID
+	})
+	return result, nil
+}
+
+// byUserLocked returns the user's stored matches in rank order; the caller
+// holds the lock
+func (r *MatchRepository) byUserLocked(userID string) []*entities.Match {
+	var matches []*entities.Match
+	for _, match := range r.store.matches {
+		if match.UserID == userID {
+			matches = append(matches, match)
+		}
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].Rank != matches[j].Rank {
+			return matches[i].Rank < matches[j].Rank
+		}
+		return matches[i].ID < matches[j].ID
+	})
+	return matches
+}
+
+// orDefault mirrors the COALESCE defaults of the Postgres queries
+func orDefault(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
@@ -0,0 +1,82 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+
+	"wizard-connect/internal/domain/entities"
+
+	"github.com/google/uuid"
+)
+
+type MatchVetoRepository struct {
+	store *Store
+}
+
+func NewMatchVetoRepository(store *Store) *MatchVetoRepository {
+	return &MatchVetoRepository{store: store}
+}
+
+func (r *MatchVetoRepository) Create(ctx context.Context, veto *entities.MatchVeto) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	veto.UserAID, veto.UserBID = entities.OrderedPair(veto.UserAID, veto.UserBID)
+	// Mirrors UNIQUE(user_a_id, user_b_id)
+	for _, existing := range r.store.vetoes {
+		if existing.UserAID == veto.UserAID && existing.UserBID == veto.UserBID {
+			return errors.New("pair is already vetoed")
+		}
+	}
+
+	if veto.ID == "" {
+		veto.ID = uuid.New().String()
+	}
+	if veto.CreatedAt.IsZero() {
+		veto.CreatedAt = time.Now()
+	}
+	stored := *veto
+	r.store.vetoes[veto.ID] = &stored
+	return nil
+}
+
+func (r *MatchVetoRepository) GetByID(ctx context.Context, id string) (*entities.MatchVeto, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	veto, ok := r.store.vetoes[id]
+	if !ok {
+		return nil, errNotFound
+	}
+	c := *veto
+	return &c, nil
+}
+
+func (r *MatchVetoRepository) Delete(ctx context.Context, id string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	delete(r.store.vetoes, id)
+	return nil
+}
+
+// ListVetoes returns every veto, newest first
+func (r *MatchVetoRepository) ListVetoes(ctx context.Context) ([]*entities.MatchVeto, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	vetoes := make([]*entities.MatchVeto, 0, len(r.store.vetoes))
+	for _, veto := range r.store.vetoes {
+		c := *veto
+		vetoes = append(vetoes, &c)
+	}
+	sort.Slice(vetoes, func(i, j int) bool {
+		if !vetoes[i].CreatedAt.Equal(vetoes[j].CreatedAt) {
+			return vetoes[i].CreatedAt.After(vetoes[j].CreatedAt)
+		}
+		return vetoes[i].ID < vetoes[j].ID
+	})
+	return vetoes, nil
+}
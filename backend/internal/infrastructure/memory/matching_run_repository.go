@@ -0,0 +1,91 @@
+package memory
+
+import (
+	"context"
+	"sort"
+
+	"wizard-connect/internal/domain/entities"
+
+	"github.com/google/uuid"
+)
+
+type MatchingRunRepository struct {
+	store *Store
+}
+
+func NewMatchingRunRepository(store *Store) *MatchingRunRepository {
+	return &MatchingRunRepository{store: store}
+}
+
+func copyRun(run *entities.MatchingRun) *entities.MatchingRun {
+	c := *run
+	if run.Fairness != nil {
+		fairness := *run.Fairness
+		fairness.Before.Histogram = append([]int(nil), run.Fairness.Before.Histogram...)
+		fairness.After.Histogram = append([]int(nil), run.Fairness.After.Histogram...)
+		c.Fairness = &fairness
+	}
+	if run.Manifest != nil {
+		manifest := *run.Manifest
+		manifest.Config = copyMap(run.Manifest.Config)
+		c.Manifest = &manifest
+	}
+	if run.CompletedAt != nil {
+		completedAt := *run.CompletedAt
+		c.CompletedAt = &completedAt
+	}
+	return &c
+}
+
+func (r *MatchingRunRepository) Create(ctx context.Context, run *entities.MatchingRun) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if run.ID == "" {
+		run.ID = uuid.New().String()
+	}
+	r.store.runs[run.ID] = copyRun(run)
+	return nil
+}
+
+func (r *MatchingRunRepository) Update(ctx context.Context, run *entities.MatchingRun) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.runs[run.ID]; !ok {
+		return errNotFound
+	}
+	r.store.runs[run.ID] = copyRun(run)
+	return nil
+}
+
+func (r *MatchingRunRepository) GetByID(ctx context.Context, id string) (*entities.MatchingRun, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	run, ok := r.store.runs[id]
+	if !ok {
+		return nil, errNotFound
+	}
+	return copyRun(run), nil
+}
+
+// ListByCampaign returns a campaign's runs, newest first
+func (r *MatchingRunRepository) ListByCampaign(ctx context.Context, campaignID string) ([]*entities.MatchingRun, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var runs []*entities.MatchingRun
+	for _, run := range r.store.runs {
+		if run.CampaignID == campaignID {
+			runs = append(runs, copyRun(run))
+		}
+	}
+	sort.Slice(runs, func(i, j int) bool {
+		if !runs[i].StartedAt.Equal(runs[j].StartedAt) {
+			return runs[i].StartedAt.After(runs[j].StartedAt)
+		}
+		return runs[i].ID < runs[j].ID
+	})
+	return runs, nil
+}
@@ -0,0 +1,125 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"wizard-connect/internal/domain/entities"
+
+	"github.com/google/uuid"
+)
+
+type MessageRepository struct {
+	store *Store
+}
+
+func NewMessageRepository(store *Store) *MessageRepository {
+	return &MessageRepository{store: store}
+}
+
+func (r *MessageRepository) Create(ctx context.Context, message *entities.Message) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.conversations[message.ConversationID]; !ok {
+		return errNotFound
+	}
+
+	message.ID = uuid.New().String()
+	message.CreatedAt = time.Now()
+
+	stored := *message
+	r.store.messages[message.ID] = &stored
+	return nil
+}
+
+func (r *MessageRepository) GetByID(ctx context.Context, id string) (*entities.Message, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	message, ok := r.store.messages[id]
+	if !ok {
+		return nil, errNotFound
+	}
+	m := *message
+	return &m, nil
+}
+
+func (r *MessageRepository) Delete(ctx context.Context, id string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	delete(r.store.messages, id)
+	return nil
+}
+
+// GetByConversationID pages through a conversation, oldest message first
+func (r *MessageRepository) GetByConversationID(ctx context.Context, conversationID string, limit, offset int) ([]*entities.Message, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var messages []*entities.Message
+	for _, message := range r.store.messages {
+		if message.ConversationID == conversationID {
+			m := *message
+			messages = append(messages, &m)
+		}
+	}
+	sort.Slice(messages, func(i, j int) bool {
+		if !messages[i].CreatedAt.Equal(messages[j].CreatedAt) {
+			return messages[i].CreatedAt.Before(messages[j].CreatedAt)
+		}
+		return messages[i].ID < messages[j].ID
+	})
+
+	if offset >= len(messages) {
+		return nil, nil
+	}
+	messages = messages[offset:]
+	if limit < len(messages) {
+		messages = messages[:limit]
+	}
+	return messages, nil
+}
+
+func (r *MessageRepository) MarkAsRead(ctx context.Context, messageID string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if message, ok := r.store.messages[messageID]; ok {
+		message.IsRead = true
+	}
+	return nil
+}
+
+// GetUnreadCount counts unread messages sent to the user across their conversations
+func (r *MessageRepository) GetUnreadCount(ctx context.Context, userID string) (int, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	count := 0
+	for _, message := range r.store.messages {
+		conv, ok := r.store.conversations[message.ConversationID]
+		if !ok || message.IsRead || message.SenderID == userID {
+			continue
+		}
+		if conv.Participant1 == userID || conv.Participant2 == userID {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (r *MessageRepository) CountByConversationID(ctx context.Context, conversationID string) (int, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	count := 0
+	for _, message := range r.store.messages {
+		if message.ConversationID == conversationID {
+			count++
+		}
+	}
+	return count, nil
+}
@@ -0,0 +1,65 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"wizard-connect/internal/domain/entities"
+)
+
+type RegistrationOverrideRepository struct {
+	store *Store
+}
+
+func NewRegistrationOverrideRepository(store *Store) *RegistrationOverrideRepository {
+	return &RegistrationOverrideRepository{store: store}
+}
+
+// Add mirrors ON CONFLICT (email): the first override's created_at is kept
+func (r *RegistrationOverrideRepository) Add(ctx context.Context, override *entities.RegistrationOverride) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	override.Email = strings.ToLower(strings.TrimSpace(override.Email))
+	if existing, ok := r.store.overrides[override.Email]; ok {
+		override.CreatedAt = existing.CreatedAt
+	} else {
+		override.CreatedAt = time.Now()
+	}
+	stored := *override
+	r.store.overrides[override.Email] = &stored
+	return nil
+}
+
+func (r *RegistrationOverrideRepository) Remove(ctx context.Context, email string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	delete(r.store.overrides, strings.ToLower(strings.TrimSpace(email)))
+	return nil
+}
+
+func (r *RegistrationOverrideRepository) List(ctx context.Context) ([]*entities.RegistrationOverride, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	overrides := make([]*entities.RegistrationOverride, 0, len(r.store.overrides))
+	for _, override := range r.store.overrides {
+		c := *override
+		overrides = append(overrides, &c)
+	}
+	sort.Slice(overrides, func(i, j int) bool {
+		return overrides[i].CreatedAt.After(overrides[j].CreatedAt)
+	})
+	return overrides, nil
+}
+
+func (r *RegistrationOverrideRepository) IsOverridden(ctx context.Context, email string) (bool, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	_, ok := r.store.overrides[strings.ToLower(strings.TrimSpace(email))]
+	return ok, nil
+}
@@ -0,0 +1,24 @@
+package memory
+
+import "wizard-connect/internal/domain/repositories"
+
+// Compile-time checks that the store implements every domain repository
+var (
+	_ repositories.UserRepository                 = (*UserRepository)(nil)
+	_ repositories.SurveyRepository               = (*SurveyRepository)(nil)
+	_ repositories.CrushRepository                = (*CrushRepository)(nil)
+	_ repositories.MatchRepository                = (*MatchRepository)(nil)
+	_ repositories.MessageRepository              = (*MessageRepository)(nil)
+	_ repositories.ConversationRepository         = (*ConversationRepository)(nil)
+	_ repositories.CampaignRepository             = (*CampaignRepository)(nil)
+	_ repositories.AdminRepository                = (*AdminRepository)(nil)
+	_ repositories.MatchingRunRepository          = (*MatchingRunRepository)(nil)
+	_ repositories.StagedMatchRepository          = (*StagedMatchRepository)(nil)
+	_ repositories.MatchVetoRepository            = (*MatchVetoRepository)(nil)
+	_ repositories.AuditLogRepository             = (*AuditLogRepository)(nil)
+	_ repositories.MatchFeedbackRepository        = (*MatchFeedbackRepository)(nil)
+	_ repositories.UserBlockRepository            = (*UserBlockRepository)(nil)
+	_ repositories.ReportRepository               = (*ReportRepository)(nil)
+	_ repositories.ModerationActionRepository     = (*ModerationActionRepository)(nil)
+	_ repositories.RegistrationOverrideRepository = (*RegistrationOverrideRepository)(nil)
+)
@@ -0,0 +1,284 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"wizard-connect/internal/domain/entities"
+
+	"github.com/google/uuid"
+)
+
+type UserBlockRepository struct {
+	store *Store
+}
+
+func NewUserBlockRepository(store *Store) *UserBlockRepository {
+	return &UserBlockRepository{store: store}
+}
+
+// Create records the block; blocking someone twice keeps the first block
+func (r *UserBlockRepository) Create(ctx context.Context, block *entities.UserBlock) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for _, existing := range r.store.blocks {
+		if existing.BlockerID == block.BlockerID && existing.BlockedID == block.BlockedID {
+			block.ID, block.CreatedAt = existing.ID, existing.CreatedAt
+			return nil
+		}
+	}
+
+	block.ID = uuid.New().String()
+	block.CreatedAt = time.Now()
+	stored := *block
+	r.store.blocks[block.ID] = &stored
+	return nil
+}
+
+func (r *UserBlockRepository) Delete(ctx context.Context, blockerID, blockedID string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for id, block := range r.store.blocks {
+		if block.BlockerID == blockerID && block.BlockedID == blockedID {
+			delete(r.store.blocks, id)
+		}
+	}
+	return nil
+}
+
+func (r *UserBlockRepository) IsBlocked(ctx context.Context, userID, otherUserID string) (bool, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	for _, block := range r.store.blocks {
+		if (block.BlockerID == userID && block.BlockedID == otherUserID) ||
+			(block.BlockerID == otherUserID && block.BlockedID == userID) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *UserBlockRepository) BlockedUserIDs(ctx context.Context, userID string) ([]string, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	seen := make(map[string]bool)
+	var ids []string
+	for _, block := range r.store.blocks {
+		other := ""
+		switch userID {
+		case block.BlockerID:
+			other = block.BlockedID
+		case block.BlockedID:
+			other = block.BlockerID
+		}
+		if other != "" && !seen[other] {
+			seen[other] = true
+			ids = append(ids, other)
+		}
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
+
+// ListByBlocker returns the users blockerID has blocked, newest first
+func (r *UserBlockRepository) ListByBlocker(ctx context.Context, blockerID string) ([]*entities.UserBlock, error) {
+	return r.list(func(block *entities.UserBlock) bool { return block.BlockerID == blockerID }), nil
+}
+
+// ListBlocks returns every block, newest first
+func (r *UserBlockRepository) ListBlocks(ctx context.Context) ([]*entities.UserBlock, error) {
+	return r.list(func(*entities.UserBlock) bool { return true }), nil
+}
+
+func (r *UserBlockRepository) list(keep func(*entities.UserBlock) bool) []*entities.UserBlock {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var blocks []*entities.UserBlock
+	for _, block := range r.store.blocks {
+		if keep(block) {
+			c := *block
+			blocks = append(blocks, &c)
+		}
+	}
+	sort.Slice(blocks, func(i, j int) bool {
+		if !blocks[i].CreatedAt.Equal(blocks[j].CreatedAt) {
+			return blocks[i].CreatedAt.After(blocks[j].CreatedAt)
+		}
+		return blocks[i].ID < blocks[j].ID
+	})
+	return blocks
+}
+
+type ReportRepository struct {
+	store *Store
+}
+
+func NewReportRepository(store *Store) *ReportRepository {
+	return &ReportRepository{store: store}
+}
+
+func copyReport(report *entities.Report) *entities.Report {
+	c := *report
+	c.Evidence = append([]entities.ReportEvidence{}, report.Evidence...)
+	return &c
+}
+
+func (r *ReportRepository) Create(ctx context.Context, report *entities.Report) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if report.Evidence == nil {
+		report.Evidence = []entities.ReportEvidence{}
+	}
+	if report.Status == "" {
+		report.Status = entities.ReportStatusOpen
+	}
+	if report.Source == "" {
+		report.Source = entities.ReportSourceUser
+	}
+	report.ID = uuid.New().String()
+	report.CreatedAt = time.Now()
+	report.UpdatedAt = report.CreatedAt
+
+	r.store.reports[report.ID] = copyReport(report)
+	return nil
+}
+
+func (r *ReportRepository) GetByID(ctx context.Context, id string) (*entities.Report, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	report, ok := r.store.reports[id]
+	if !ok {
+		return nil, errNotFound
+	}
+	return copyReport(report), nil
+}
+
+func (r *ReportRepository) List(ctx context.Context, filter entities.ReportFilter) ([]*entities.Report, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var reports []*entities.Report
+	for _, report := range r.store.reports {
+		if filter.Matches(report) {
+			reports = append(reports, copyReport(report))
+		}
+	}
+	sort.Slice(reports, func(i, j int) bool {
+		if !reports[i].CreatedAt.Equal(reports[j].CreatedAt) {
+			return reports[i].CreatedAt.After(reports[j].CreatedAt)
+		}
+		return reports[i].ID < reports[j].ID
+	})
+	return reports, nil
+}
+
+func (r *ReportRepository) Update(ctx context.Context, report *entities.Report) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	stored, ok := r.store.reports[report.ID]
+	if !ok {
+		return errNotFound
+	}
+	stored.Status = report.Status
+	stored.AssignedTo, stored.AssignedAt = report.AssignedTo, report.AssignedAt
+	stored.Resolution, stored.ResolvedBy, stored.ResolvedAt = report.Resolution, report.ResolvedBy, report.ResolvedAt
+	stored.UpdatedAt = time.Now()
+	report.UpdatedAt = stored.UpdatedAt
+	return nil
+}
+
+type ModerationActionRepository struct {
+	store *Store
+}
+
+func NewModerationActionRepository(store *Store) *ModerationActionRepository {
+	return &ModerationActionRepository{store: store}
+}
+
+func (r *ModerationActionRepository) Create(ctx context.Context, action *entities.ModerationAction) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	action.ID = uuid.New().String()
+	action.CreatedAt = time.Now()
+	stored := *action
+	r.store.moderation[action.ID] = &stored
+	return nil
+}
+
+func (r *ModerationActionRepository) GetByID(ctx context.Context, id string) (*entities.ModerationAction, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	action, ok := r.store.moderation[id]
+	if !ok {
+		return nil, errNotFound
+	}
+	c := *action
+	return &c, nil
+}
+
+func (r *ModerationActionRepository) Revoke(ctx context.Context, id, revokedBy string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	action, ok := r.store.moderation[id]
+	if !ok || action.RevokedAt != nil {
+		return nil
+	}
+	now := time.Now()
+	action.RevokedAt = &now
+	action.RevokedBy = revokedBy
+	return nil
+}
+
+func (r *ModerationActionRepository) ListByUser(ctx context.Context, userID string) ([]*entities.ModerationAction, error) {
+	return r.list(func(a *entities.ModerationAction) bool { return a.UserID == userID }), nil
+}
+
+func (r *ModerationActionRepository) Active(ctx context.Context, userID string, at time.Time) ([]*entities.ModerationAction, error) {
+	return r.list(func(a *entities.ModerationAction) bool { return a.UserID == userID && a.ActiveAt(at) }), nil
+}
+
+func (r *ModerationActionRepository) ListSuspendedUserIDs(ctx context.Context, at time.Time) ([]string, error) {
+	seen := make(map[string]bool)
+	var ids []string
+	for _, action := range r.list(func(a *entities.ModerationAction) bool { return a.LocksOut() && a.ActiveAt(at) }) {
+		if !seen[action.UserID] {
+			seen[action.UserID] = true
+			ids = append(ids, action.UserID)
+		}
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
+
+// list returns the matching actions, newest first
+func (r *ModerationActionRepository) list(keep func(*entities.ModerationAction) bool) []*entities.ModerationAction {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var actions []*entities.ModerationAction
+	for _, action := range r.store.moderation {
+		if keep(action) {
+			c := *action
+			actions = append(actions, &c)
+		}
+	}
+	sort.Slice(actions, func(i, j int) bool {
+		if !actions[i].CreatedAt.Equal(actions[j].CreatedAt) {
+			return actions[i].CreatedAt.After(actions[j].CreatedAt)
+		}
+		return actions[i].ID < actions[j].ID
+	})
+	return actions
+}
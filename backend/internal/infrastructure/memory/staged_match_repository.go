@@ -0,0 +1,125 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"wizard-connect/internal/domain/entities"
+
+	"github.com/google/uuid"
+)
+
+type StagedMatchRepository struct {
+	store *Store
+}
+
+func NewStagedMatchRepository(store *Store) *StagedMatchRepository {
+	return &StagedMatchRepository{store: store}
+}
+
+func (r *StagedMatchRepository) createLocked(m *entities.StagedMatch) {
+	if m.ID == "" {
+		m.ID = uuid.New().String()
+	}
+	if m.Source == "" {
+		m.Source = entities.MatchSourceAlgorithm
+	}
+	if m.CreatedAt.IsZero() {
+		m.CreatedAt = time.Now()
+	}
+	stored := *m
+	r.store.staged[m.ID] = &stored
+}
+
+func (r *StagedMatchRepository) ReplaceForCampaign(ctx context.Context, campaignID string, matches []*entities.StagedMatch) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for id, m := range r.store.staged {
+		if m.CampaignID == campaignID {
+			delete(r.store.staged, id)
+		}
+	}
+	for _, m := range matches {
+		m.CampaignID = campaignID
+		r.createLocked(m)
+	}
+	return nil
+}
+
+func (r *StagedMatchRepository) Create(ctx context.Context, m *entities.StagedMatch) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	r.createLocked(m)
+	return nil
+}
+
+// Update saves a staged match's rank, pin and flag
+func (r *StagedMatchRepository) Update(ctx context.Context, m *entities.StagedMatch) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	stored, ok := r.store.staged[m.ID]
+	if !ok {
+		return errNotFound
+	}
+	stored.Rank = m.Rank
+	stored.Pinned, stored.PinnedBy = m.Pinned, m.PinnedBy
+	stored.Flagged, stored.FlagReason, stored.FlaggedBy = m.Flagged, m.FlagReason, m.FlaggedBy
+	return nil
+}
+
+func (r *StagedMatchRepository) Delete(ctx context.Context, id string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	delete(r.store.staged, id)
+	return nil
+}
+
+func (r *StagedMatchRepository) GetByID(ctx context.Context, id string) (*entities.StagedMatch, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	m, ok := r.store.staged[id]
+	if !ok {
+		return nil, errNotFound
+	}
+	c := *m
+	return &c, nil
+}
+
+// ListByCampaign returns a campaign's staged matches by owner, in rank order
+func (r *StagedMatchRepository) ListByCampaign(ctx context.Context, campaignID string) ([]*entities.StagedMatch, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var matches []*entities.StagedMatch
+	for _, m := range r.store.staged {
+		if m.CampaignID == campaignID {
+			c := *m
+			matches = append(matches, &c)
+		}
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].UserID != matches[j].UserID {
+			return matches[i].UserID < matches[j].UserID
+		}
+		return matches[i].Rank < matches[j].Rank
+	})
+	return matches, nil
+}
+
+func (r *StagedMatchRepository) DeleteByCampaign(ctx context.Context, campaignID string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for id, m := range r.store.staged {
+		if m.CampaignID == campaignID {
+			delete(r.store.staged, id)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+// Package memory implements the domain repositories on top of in-process
+// maps. It backs the HTTP test harness and any tooling that needs the API
+// without Postgres; the data lives as long as the Store.
+package memory
+
+import (
+	"database/sql"
+	"sync"
+
+	"wizard-connect/internal/domain/entities"
+)
+
+// errNotFound mirrors what the Postgres repositories return for a missing
+// row, so callers behave the same against either implementation
+var errNotFound = sql.ErrNoRows
+
+// Store holds every table. Repositories built on the same Store see each
+// other's writes, the way the Postgres repositories share one database.
+type Store struct {
+	mu sync.RWMutex
+
+	users         map[string]*entities.User
+	surveys       map[string]*entities.SurveyResponse
+	crushes       map[string]*entities.Crush
+	matches       map[string]*entities.Match
+	messages      map[string]*entities.Message
+	conversations map[string]*entities.Conversation
+	campaigns     map[string]*entities.Campaign
+	runs          map[string]*entities.MatchingRun
+	staged        map[string]*entities.StagedMatch
+	vetoes        map[string]*entities.MatchVeto
+	feedback      map[string]*entities.MatchFeedback
+	blocks        map[string]*entities.UserBlock
+	reports       map[string]*entities.Report
+	moderation    map[string]*entities.ModerationAction
+	overrides     map[string]*entities.RegistrationOverride // by lowercased email
+	audit         []*entities.AuditEntry
+	admins        map[string]string // email -> user ID
+	adminOrder    []string
+	deleted       map[string]bool // IDs of deleted accounts
+}
+
+func NewStore() *Store {
+	return &Store{
+		users:         make(map[string]*entities.User),
+		surveys:       make(map[string]*entities.SurveyResponse),
+		crushes:       make(map[string]*entities.Crush),
+		matches:       make(map[string]*entities.Match),
+		messages:      make(map[string]*entities.Message),
+		conversations: make(map[string]*entities.Conversation),
+		campaigns:     make(map[string]*entities.Campaign),
+		runs:          make(map[string]*entities.MatchingRun),
+		staged:        make(map[string]*entities.StagedMatch),
+		vetoes:        make(map[string]*entities.MatchVeto),
+		feedback:      make(map[string]*entities.MatchFeedback),
+		blocks:        make(map[string]*entities.UserBlock),
+		reports:       make(map[string]*entities.Report),
+		moderation:    make(map[string]*entities.ModerationAction),
+		overrides:     make(map[string]*entities.RegistrationOverride),
+		admins:        make(map[string]string),
+		deleted:       make(map[string]bool),
+	}
+}
+
+// Copies keep callers from mutating stored rows without going through a repository
+
+func copyUser(u *entities.User) *entities.User {
+	c := *u
+	return &c
+}
+
+func copySurvey(s *entities.SurveyResponse) *entities.SurveyResponse {
+	c := *s
+	c.Responses = copyMap(s.Responses)
+	c.PersonalityTraits = append([]float64(nil), s.PersonalityTraits...)
+	c.Interests = append([]string(nil), s.Interests...)
+	c.Values = append([]string(nil), s.Values...)
+	return &c
+}
+
+func copyCampaign(c *entities.Campaign) *entities.Campaign {
+	cp := *c
+	cp.Config = copyMap(c.Config)
+	return &cp
+}
+
+func copyMap(m map[string]interface{}) map[string]interface{} {
+	if m == nil {
+		return nil
+	}
+	cp := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		switch v := v.(type) {
+		case map[string]interface{}:
+			cp[k] = copyMap(v)
+		case []interface{}:
+			cp[k] = append([]interface{}(nil), v...)
+		default:
+			cp[k] = v
+		}
+	}
+	return cp
+}
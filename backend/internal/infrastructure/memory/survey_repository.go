@@ -0,0 +1,174 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"wizard-connect/internal/domain/entities"
+	"wizard-connect/internal/domain/repositories"
+
+	"github.com/google/uuid"
+)
+
+type SurveyRepository struct {
+	store *Store
+}
+
+func NewSurveyRepository(store *Store) *SurveyRepository {
+	return &SurveyRepository{store: store}
+}
+
+// findLocked returns the user's survey for a campaign; the caller holds the lock
+func (r *SurveyRepository) findLocked(userID, campaignID string) *entities.SurveyResponse {
+	for _, survey := range r.store.surveys {
+		if survey.UserID == userID && survey.CampaignID == campaignID {
+			return survey
+		}
+	}
+	return nil
+}
+
+func normalizeSurvey(survey *entities.SurveyResponse) {
+	if survey.Interests == nil {
+		survey.Interests = []string{}
+	}
+	if survey.Values == nil {
+		survey.Values = []string{}
+	}
+	if survey.PersonalityTraits == nil {
+		survey.PersonalityTraits = []float64{}
+	}
+	if survey.DefinitionVersion == "" {
+		survey.DefinitionVersion = "v1"
+	}
+}
+
+// CreateOrUpdate saves the user's survey for survey.CampaignID, leaving their
+// surveys from other campaigns untouched
+func (r *SurveyRepository) CreateOrUpdate(ctx context.Context, survey *entities.SurveyResponse) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	normalizeSurvey(survey)
+	now := time.Now()
+
+	if existing := r.findLocked(survey.UserID, survey.CampaignID); existing != nil {
+		survey.ID = existing.ID
+		survey.CreatedAt = existing.CreatedAt
+		survey.Revision = existing.Revision + 1
+	} else {
+		if survey.ID == "" {
+			survey.ID = uuid.New().String()
+		}
+		survey.CreatedAt = now
+		if survey.IsComplete {
+			survey.CompletedAt = now
+		}
+		survey.Revision = 1
+	}
+	survey.UpdatedAt = now
+
+	r.store.surveys[survey.ID] = copySurvey(survey)
+	return nil
+}
+
+// SaveRevision inserts a survey without an ID, or updates a stored one only
+// if its revision still equals expectedRevision, and bumps the revision
+func (r *SurveyRepository) SaveRevision(ctx context.Context, survey *entities.SurveyResponse, expectedRevision int) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	normalizeSurvey(survey)
+	now := time.Now()
+	existing := r.findLocked(survey.UserID, survey.CampaignID)
+
+	if survey.ID == "" {
+		if existing != nil {
+			return repositories.ErrRevisionConflict
+		}
+		survey.ID = uuid.New().String()
+		survey.CreatedAt = now
+		survey.Revision = 1
+	} else {
+		if existing == nil || existing.ID != survey.ID || existing.Revision != expectedRevision {
+			return repositories.ErrRevisionConflict
+		}
+		survey.CreatedAt = existing.CreatedAt
+		survey.Revision = existing.Revision + 1
+	}
+	survey.UpdatedAt = now
+
+	r.store.surveys[survey.ID] = copySurvey(survey)
+	return nil
+}
+
+// GetByUserID returns the user's most recently updated survey from any campaign
+func (r *SurveyRepository) GetByUserID(ctx context.Context, userID string) (*entities.SurveyResponse, error) {
+	surveys, _ := r.ListByUserID(ctx, userID)
+	var latest *entities.SurveyResponse
+	for _, survey := range surveys {
+		if latest == nil || survey.UpdatedAt.After(latest.UpdatedAt) {
+			latest = survey
+		}
+	}
+	return latest, nil
+}
+
+// GetByUserAndCampaign returns the user's survey for one campaign, or nil
+func (r *SurveyRepository) GetByUserAndCampaign(ctx context.Context, userID, campaignID string) (*entities.SurveyResponse, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	if survey := r.findLocked(userID, campaignID); survey != nil {
+		return copySurvey(survey), nil
+	}
+	return nil, nil
+}
+
+// ListByUserID returns every survey the user has taken, newest first
+func (r *SurveyRepository) ListByUserID(ctx context.Context, userID string) ([]*entities.SurveyResponse, error) {
+	return r.list(func(s *entities.SurveyResponse) bool { return s.UserID == userID }, byCreatedAt)
+}
+
+func (r *SurveyRepository) GetCompletedSurveys(ctx context.Context) ([]*entities.SurveyResponse, error) {
+	return r.list(func(s *entities.SurveyResponse) bool { return s.IsComplete }, byCompletedAt)
+}
+
+// GetCompletedSurveysForCampaign returns the completed surveys answered for a
+// campaign under the given definition version
+func (r *SurveyRepository) GetCompletedSurveysForCampaign(ctx context.Context, campaignID, definitionVersion string) ([]*entities.SurveyResponse, error) {
+	return r.list(func(s *entities.SurveyResponse) bool {
+		return s.IsComplete && s.CampaignID == campaignID && s.DefinitionVersion == definitionVersion
+	}, byCompletedAt)
+}
+
+// ListByCampaign returns every survey started for a campaign, complete or
+// not, newest first
+func (r *SurveyRepository) ListByCampaign(ctx context.Context, campaignID string) ([]*entities.SurveyResponse, error) {
+	return r.list(func(s *entities.SurveyResponse) bool { return s.CampaignID == campaignID }, byCreatedAt)
+}
+
+func byCreatedAt(s *entities.SurveyResponse) time.Time   { return s.CreatedAt }
+func byCompletedAt(s *entities.SurveyResponse) time.Time { return s.CompletedAt }
+
+// list returns the matching surveys ordered newest first by the given time,
+// breaking ties by ID so results are stable
+func (r *SurveyRepository) list(keep func(*entities.SurveyResponse) bool, at func(*entities.SurveyResponse) time.Time) ([]*entities.SurveyResponse, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var surveys []*entities.SurveyResponse
+	for _, survey := range r.store.surveys {
+		if keep(survey) {
+			surveys = append(surveys, copySurvey(survey))
+		}
+	}
+	sort.Slice(surveys, func(i, j int) bool {
+		if ti, tj := at(surveys[i]), at(surveys[j]); !ti.Equal(tj) {
+			return ti.After(tj)
+		}
+		return surveys[i].ID < surveys[j].ID
+	})
+	return surveys, nil
+}
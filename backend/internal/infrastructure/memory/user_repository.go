@@ -0,0 +1,269 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"wizard-connect/internal/domain/entities"
+)
+
+type UserRepository struct {
+	store *Store
+}
+
+func NewUserRepository(store *Store) *UserRepository {
+	return &UserRepository{store: store}
+}
+
+func (r *UserRepository) Create(ctx context.Context, user *entities.User) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, exists := r.store.users[user.ID]; exists {
+		return fmt.Errorf("user %s already exists", user.ID)
+	}
+	for _, existing := range r.store.users {
+		if strings.EqualFold(existing.Email, user.Email) {
+			return fmt.Errorf("email %s already in use", user.Email)
+		}
+	}
+
+	now := time.Now()
+	user.CreatedAt, user.UpdatedAt = now, now
+	r.store.users[user.ID] = copyUser(user)
+	return nil
+}
+
+// GetByID returns an error for unknown users. Unlike Postgres there is no
+// auth.users table to create a shell user from, so callers fall back to
+// creating the profile themselves.
+func (r *UserRepository) GetByID(ctx context.Context, id string) (*entities.User, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	user, ok := r.store.users[id]
+	if !ok {
+		return nil, fmt.Errorf("failed to get user: %w", errNotFound)
+	}
+	return copyUser(user), nil
+}
+
+func (r *UserRepository) GetByEmail(ctx context.Context, email string) (*entities.User, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	for _, user := range r.store.users {
+		if user.Email == email {
+			return copyUser(user), nil
+		}
+	}
+	return nil, fmt.Errorf("failed to get user by email: %w", errNotFound)
+}
+
+// Update only overwrites fields that are set, like the Postgres repository,
+// and creates the user if they don't exist yet
+func (r *UserRepository) Update(ctx context.Context, user *entities.User) error {
+	r.store.mu.Lock()
+	current, ok := r.store.users[user.ID]
+	if !ok {
+		r.store.mu.Unlock()
+		return r.Create(ctx, user)
+	}
+	defer r.store.mu.Unlock()
+
+	updated := copyUser(current)
+	set := func(dst *string, v string) {
+		if v != "" {
+			*dst = v
+		}
+	}
+	set(&updated.FirstName, user.FirstName)
+	set(&updated.LastName, user.LastName)
+	set(&updated.AvatarURL, user.AvatarURL)
+	set(&updated.Bio, user.Bio)
+	set(&updated.Instagram, user.Instagram)
+	set(&updated.Phone, user.Phone)
+	set(&updated.ContactPref, user.ContactPref)
+	set(&updated.Visibility, user.Visibility)
+	set(&updated.Year, user.Year)
+	set(&updated.Major, user.Major)
+	set(&updated.Gender, user.Gender)
+	set(&updated.GenderPreference, user.GenderPreference)
+	updated.UpdatedAt = time.Now()
+
+	r.store.users[user.ID] = updated
+	return nil
+}
+
+func (r *UserRepository) ClearFields(ctx context.Context, id string, fields ...string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	current, ok := r.store.users[id]
+	if !ok {
+		return errNotFound
+	}
+
+	updated := copyUser(current)
+	columns := map[string]*string{
+		"first_name":        &updated.FirstName,
+		"last_name":         &updated.LastName,
+		"avatar_url":        &updated.AvatarURL,
+		"bio":               &updated.Bio,
+		"instagram":         &updated.Instagram,
+		"phone":             &updated.Phone,
+		"year":              &updated.Year,
+		"major":             &updated.Major,
+		"gender":            &updated.Gender,
+		"gender_preference": &updated.GenderPreference,
+	}
+	for _, field := range fields {
+		dst, ok := columns[field]
+		if !ok {
+			return fmt.Errorf("field %q can't be cleared", field)
+		}
+		*dst = ""
+	}
+	updated.UpdatedAt = time.Now()
+
+	r.store.users[id] = updated
+	return nil
+}
+
+// Delete erases the account from every table, as the Postgres repository
+// does in one transaction
+func (r *UserRepository) Delete(ctx context.Context, id string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	s := r.store
+	email := ""
+	if user, ok := s.users[id]; ok {
+		email = user.Email
+	}
+
+	for key, crush := range s.crushes {
+		if crush.UserID == id || (email != "" && strings.EqualFold(crush.CrushEmail, email)) {
+			delete(s.crushes, key)
+		}
+	}
+	for key, m := range s.staged {
+		if m.UserID == id || m.MatchedUserID == id {
+			delete(s.staged, key)
+		}
+	}
+	for key, veto := range s.vetoes {
+		if veto.UserAID == id || veto.UserBID == id {
+			delete(s.vetoes, key)
+		}
+	}
+	for key, feedback := range s.feedback {
+		if feedback.UserID == id || feedback.MatchedUserID == id {
+			delete(s.feedback, key)
+		}
+	}
+	for key, m := range s.matches {
+		if m.UserID == id || m.MatchedUserID == id {
+			delete(s.matches, key)
+		}
+	}
+	for key, conv := range s.conversations {
+		if conv.Participant1 != id && conv.Participant2 != id {
+			continue
+		}
+		for messageID, message := range s.messages {
+			if message.ConversationID == conv.ID {
+				delete(s.messages, messageID)
+			}
+		}
+		delete(s.conversations, key)
+	}
+	for key, block := range s.blocks {
+		if block.BlockerID == id || block.BlockedID == id {
+			delete(s.blocks, key)
+		}
+	}
+	for key, action := range s.moderation {
+		if action.UserID == id {
+			delete(s.moderation, key)
+		}
+	}
+	for key, report := range s.reports {
+		switch {
+		case report.ReportedUserID == id:
+			delete(s.reports, key)
+		case report.ReporterID == id:
+			report.ReporterID = ""
+			report.UpdatedAt = time.Now()
+		}
+	}
+	for key, survey := range s.surveys {
+		if survey.UserID == id {
+			delete(s.surveys, key)
+		}
+	}
+	for adminEmail, adminID := range s.admins {
+		if adminID != id {
+			continue
+		}
+		delete(s.admins, adminEmail)
+		for i, e := range s.adminOrder {
+			if e == adminEmail {
+				s.adminOrder = append(s.adminOrder[:i], s.adminOrder[i+1:]...)
+				break
+			}
+		}
+	}
+
+	delete(s.users, id)
+	s.deleted[id] = true
+	return nil
+}
+
+func (r *UserRepository) Exists(ctx context.Context, id string) (bool, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	_, ok := r.store.users[id]
+	return ok, nil
+}
+
+func (r *UserRepository) IsDeleted(ctx context.Context, id string) (bool, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	return r.store.deleted[id], nil
+}
+
+func (r *UserRepository) List(ctx context.Context, limit, offset int) ([]*entities.User, error) {
+	users, _ := r.ListAll(ctx)
+	if offset >= len(users) {
+		return nil, nil
+	}
+	users = users[offset:]
+	if limit < len(users) {
+		users = users[:limit]
+	}
+	return users, nil
+}
+
+// ListAll returns every user, newest first
+func (r *UserRepository) ListAll(ctx context.Context) ([]*entities.User, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	users := make([]*entities.User, 0, len(r.store.users))
+	for _, user := range r.store.users {
+		users = append(users, copyUser(user))
+	}
+	sort.Slice(users, func(i, j int) bool {
+		if !users[i].CreatedAt.Equal(users[j].CreatedAt) {
+			return users[i].CreatedAt.After(users[j].CreatedAt)
+		}
+		return users[i].ID < users[j].ID
+	})
+	return users, nil
+}
@@ -0,0 +1,278 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// S3Storage puts files in a bucket of any S3-compatible service: AWS S3,
+// MinIO, Cloudflare R2 or Supabase Storage's S3 endpoint
+// (https://<project>.supabase.co/storage/v1/s3). Requests are signed with
+// AWS Signature Version 4 and use path-style bucket addressing.
+type S3Storage struct {
+	endpoint        *url.URL
+	region          string
+	bucket          string
+	accessKeyID     string
+	secretAccessKey string
+	publicURL       string
+	client          *http.Client
+}
+
+// NewS3Storage connects to a bucket. publicURL is where the bucket's
+// objects can be read without credentials, e.g. a public Supabase bucket's
+// https://<project>.supabase.co/storage/v1/object/public/<bucket>; it
+// defaults to the bucket's URL on the endpoint.
+func NewS3Storage(endpoint, region, bucket, accessKeyID, secretAccessKey, publicURL string) (*S3Storage, error) {
+	parsed, err := url.Parse(strings.TrimSuffix(endpoint, "/"))
+	if err != nil || parsed.Scheme == "" || parsed.Host == "" {
+		return nil, fmt.Errorf("invalid S3 endpoint %q", endpoint)
+	}
+	if bucket == "" || accessKeyID == "" || secretAccessKey == "" {
+		return nil, fmt.Errorf("S3 storage needs a bucket and credentials")
+	}
+	if publicURL == "" {
+		publicURL = parsed.String() + "/" + bucket
+	}
+
+	return &S3Storage{
+		endpoint:        parsed,
+		region:          region,
+		bucket:          bucket,
+		accessKeyID:     accessKeyID,
+		secretAccessKey: secretAccessKey,
+		publicURL:       strings.TrimSuffix(publicURL, "/"),
+		client:          &http.Client{Timeout: 30 * time.Second},
+	}, nil
+}
+
+func (s *S3Storage) Put(ctx context.Context, key, contentType string, data []byte) error {
+	req, err := s.newRequest(ctx, http.MethodPut, "/"+escapeKey(key), nil, data)
+	if err != nil {
+		return fmt.Errorf("failed to build upload request: %w", err)
+	}
+	req.Header.Set("Content-Type", contentType)
+	// Keys are content-addressed, so their files never change
+	req.Header.Set("Cache-Control", "public, max-age=31536000, immutable")
+
+	if _, err := s.do(req, data); err != nil {
+		return fmt.Errorf("upload failed: %w", err)
+	}
+	return nil
+}
+
+// DeletePrefix removes every object whose key starts with prefix
+func (s *S3Storage) DeletePrefix(ctx context.Context, prefix string) error {
+	keys, err := s.list(ctx, prefix)
+	if err != nil {
+		return fmt.Errorf("failed to list files: %w", err)
+	}
+	for _, key := range keys {
+		req, err := s.newRequest(ctx, http.MethodDelete, "/"+escapeKey(key), nil, nil)
+		if err != nil {
+			return fmt.Errorf("failed to build delete request: %w", err)
+		}
+		if _, err := s.do(req, nil); err != nil {
+			return fmt.Errorf("failed to delete %s: %w", key, err)
+		}
+	}
+	return nil
+}
+
+// listBucketResult is the part of a ListObjectsV2 response we read
+type listBucketResult struct {
+	Contents []struct {
+		Key string `xml:"Key"`
+	} `xml:"Contents"`
+	IsTruncated           bool   `xml:"IsTruncated"`
+	NextContinuationToken string `xml:"NextContinuationToken"`
+}
+
+// list returns the keys under prefix, following ListObjectsV2 pages
+func (s *S3Storage) list(ctx context.Context, prefix string) ([]string, error) {
+	var keys []string
+	token := ""
+	for {
+		query := url.Values{"list-type": {"2"}, "prefix": {prefix}}
+		if token != "" {
+			query.Set("continuation-token", token)
+		}
+		req, err := s.newRequest(ctx, http.MethodGet, "", query, nil)
+		if err != nil {
+			return nil, err
+		}
+		body, err := s.do(req, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		var result listBucketResult
+		if err := xml.Unmarshal(body, &result); err != nil {
+			return nil, fmt.Errorf("unexpected list response: %w", err)
+		}
+		for _, object := range result.Contents {
+			keys = append(keys, object.Key)
+		}
+		if !result.IsTruncated || result.NextContinuationToken == "" {
+			return keys, nil
+		}
+		token = result.NextContinuationToken
+	}
+}
+
+// newRequest builds a request for an escaped object path ("" for the bucket
+// itself)
+func (s *S3Storage) newRequest(ctx context.Context, method, objectPath string, query url.Values, body []byte) (*http.Request, error) {
+	target := *s.endpoint
+	target.Path = ""
+	target.RawPath = ""
+	target.RawQuery = canonicalQuery(query)
+
+	path := s.endpoint.Path + "/" + s.bucket + objectPath
+	rawURL := target.Scheme + "://" + target.Host + path
+	if target.RawQuery != "" {
+		rawURL += "?" + target.RawQuery
+	}
+	return http.NewRequestWithContext(ctx, method, rawURL, bytes.NewReader(body))
+}
+
+// do signs and sends req, returning the body of a successful response
+func (s *S3Storage) do(req *http.Request, payload []byte) ([]byte, error) {
+	s.sign(req, req.URL.EscapedPath(), payload, time.Now().UTC())
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 10<<20))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode/100 != 2 {
+		if len(body) > 1024 {
+			body = body[:1024]
+		}
+		return nil, fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+	return body, nil
+}
+
+func (s *S3Storage) URL(key string) string {
+	return s.publicURL + "/" + escapeKey(key)
+}
+
+// sign adds a Signature Version 4 Authorization header. Content-Type and
+// Cache-Control are signed when set.
+func (s *S3Storage) sign(req *http.Request, path string, payload []byte, now time.Time) {
+	amzDate := now.Format("20060102T150405Z")
+	day := now.Format("20060102")
+	payloadHash := sha256Hex(payload)
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	var names []string
+	var canonicalHeaders strings.Builder
+	for _, name := range []string{"cache-control", "content-type", "host", "x-amz-content-sha256", "x-amz-date"} {
+		value := req.Header.Get(name)
+		if name == "host" {
+			value = req.URL.Host
+		}
+		if value == "" {
+			continue
+		}
+		names = append(names, name)
+		canonicalHeaders.WriteString(name + ":" + value + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		path,
+		req.URL.RawQuery,
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := day + "/" + s.region + "/s3/aws4_request"
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		amzDate,
+		scope,
+		sha256Hex([]byte(canonicalRequest)),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+s.secretAccessKey), day)
+	key = hmacSHA256(key, s.region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf(
+		"AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.accessKeyID, scope, signedHeaders, signature,
+	))
+}
+
+// canonicalQuery encodes query parameters sorted by name, as SigV4 signs them
+func canonicalQuery(query url.Values) string {
+	names := make([]string, 0, len(query))
+	for name := range query {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var parts []string
+	for _, name := range names {
+		for _, value := range query[name] {
+			parts = append(parts, uriEncode(name, true)+"="+uriEncode(value, true))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+// escapeKey URI-encodes a key the way SigV4 expects: everything but
+// unreserved characters and the "/" between segments
+func escapeKey(key string) string {
+	return uriEncode(key, false)
+}
+
+// uriEncode percent-encodes everything but unreserved characters and,
+// unless encodeSlash is set, "/"
+func uriEncode(value string, encodeSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		if ('A' <= c && c <= 'Z') || ('a' <= c && c <= 'z') || ('0' <= c && c <= '9') ||
+			c == '-' || c == '_' || c == '.' || c == '~' || (c == '/' && !encodeSlash) {
+			b.WriteByte(c)
+			continue
+		}
+		fmt.Fprintf(&b, "%%%02X", c)
+	}
+	return b.String()
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
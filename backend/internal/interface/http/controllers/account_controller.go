@@ -0,0 +1,309 @@
+package controllers
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"wizard-connect/internal/domain/entities"
+	"wizard-connect/internal/domain/repositories"
+	"wizard-connect/internal/domain/services"
+	"wizard-connect/internal/interface/http/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+// exportMessagePage is how many messages are read at a time for an export
+const exportMessagePage = 500
+
+// AccountController lets users take out or erase everything stored about
+// them
+type AccountController struct {
+	userRepo         repositories.UserRepository
+	surveyRepo       repositories.SurveyRepository
+	crushRepo        repositories.CrushRepository
+	matchRepo        repositories.MatchRepository
+	feedbackRepo     repositories.MatchFeedbackRepository
+	conversationRepo repositories.ConversationRepository
+	messageRepo      repositories.MessageRepository
+	blockRepo        repositories.UserBlockRepository
+	reportRepo       repositories.ReportRepository
+	moderationRepo   repositories.ModerationActionRepository
+	auditRepo        repositories.AuditLogRepository
+	avatarService    *services.AvatarService
+}
+
+type DeleteAccountRequest struct {
+	// ConfirmEmail must repeat the account's email, so an account is never
+	// deleted by a stray request
+	ConfirmEmail string `json:"confirm_email" binding:"required"`
+}
+
+func NewAccountController(
+	userRepo repositories.UserRepository,
+	surveyRepo repositories.SurveyRepository,
+	crushRepo repositories.CrushRepository,
+	matchRepo repositories.MatchRepository,
+	feedbackRepo repositories.MatchFeedbackRepository,
+	conversationRepo repositories.ConversationRepository,
+	messageRepo repositories.MessageRepository,
+	blockRepo repositories.UserBlockRepository,
+	reportRepo repositories.ReportRepository,
+	moderationRepo repositories.ModerationActionRepository,
+	auditRepo repositories.AuditLogRepository,
+	avatarService *services.AvatarService,
+) *AccountController {
+	return &AccountController{
+		userRepo:         userRepo,
+		surveyRepo:       surveyRepo,
+		crushRepo:        crushRepo,
+		matchRepo:        matchRepo,
+		feedbackRepo:     feedbackRepo,
+		conversationRepo: conversationRepo,
+		messageRepo:      messageRepo,
+		blockRepo:        blockRepo,
+		reportRepo:       reportRepo,
+		moderationRepo:   moderationRepo,
+		auditRepo:        auditRepo,
+		avatarService:    avatarService,
+	}
+}
+
+// ExportAccount returns everything stored about the current user, as JSON
+// or, with ?format=zip, as a ZIP of one JSON file per section
+func (ctrl *AccountController) ExportAccount(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	format := c.DefaultQuery("format", "json")
+	if format != "json" && format != "zip" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be json or zip"})
+		return
+	}
+
+	export, err := ctrl.export(c.Request.Context(), userID)
+	if err != nil {
+		fmt.Printf("ERROR: Failed to export account %s: %v\n", userID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to export account"})
+		return
+	}
+
+	if format == "json" {
+		c.JSON(http.StatusOK, gin.H{"data": export})
+		return
+	}
+
+	archive, err := exportArchive(export)
+	if err != nil {
+		fmt.Printf("ERROR: Failed to build export archive for %s: %v\n", userID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to export account"})
+		return
+	}
+	filename := "wizard-connect-export-" + export.ExportedAt.Format("2006-01-02") + ".zip"
+	c.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+	c.Data(http.StatusOK, "application/zip", archive)
+}
+
+// DeleteAccount erases the current user's account and everything stored
+// about it, uploaded avatars included. Their sessions end with it.
+func (ctrl *AccountController) DeleteAccount(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	var req DeleteAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx := c.Request.Context()
+	email, _ := middleware.GetUserEmail(c)
+	if email == "" {
+		if user, err := ctrl.userRepo.GetByID(ctx, userID); err == nil {
+			email = user.Email
+		}
+	}
+	if email == "" || !strings.EqualFold(strings.TrimSpace(req.ConfirmEmail), email) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "confirm_email must match the account's email", "code": "confirmation_mismatch"})
+		return
+	}
+
+	// Files go first: if the erasure fails after them, the user can retry
+	// and only an avatar is lost
+	if err := ctrl.avatarService.DeleteAll(ctx, userID); err != nil {
+		fmt.Printf("ERROR: Failed to delete avatars of %s: %v\n", userID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete account"})
+		return
+	}
+	if err := ctrl.userRepo.Delete(ctx, userID); err != nil {
+		fmt.Printf("ERROR: Failed to delete account %s: %v\n", userID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete account"})
+		return
+	}
+
+	// The entry names the account by ID only; nothing else about it is kept
+	writeAudit(ctx, ctrl.auditRepo, &entities.AuditEntry{
+		ActorID:    userID,
+		Action:     entities.AuditActionAccountDelete,
+		TargetType: "user",
+		TargetID:   userID,
+	})
+
+	c.JSON(http.StatusOK, gin.H{"message": "Account deleted"})
+}
+
+// export gathers the user's data. Who handled their reports and moderation
+// is left out; it names admins, not the user.
+func (ctrl *AccountController) export(ctx context.Context, userID string) (*entities.AccountExport, error) {
+	export := &entities.AccountExport{ExportedAt: time.Now().UTC()}
+	var err error
+
+	if export.Profile, err = ctrl.userRepo.GetByID(ctx, userID); err != nil {
+		return nil, fmt.Errorf("profile: %w", err)
+	}
+	if export.Surveys, err = ctrl.surveyRepo.ListByUserID(ctx, userID); err != nil {
+		return nil, fmt.Errorf("surveys: %w", err)
+	}
+	if export.Crushes, err = ctrl.crushRepo.GetByUserID(ctx, userID); err != nil {
+		return nil, fmt.Errorf("crushes: %w", err)
+	}
+	if export.Matches, err = ctrl.matchRepo.GetByUserID(ctx, userID); err != nil {
+		return nil, fmt.Errorf("matches: %w", err)
+	}
+	for _, match := range export.Matches {
+		feedback, err := ctrl.feedbackRepo.GetByMatchID(ctx, match.ID)
+		if err != nil || feedback.UserID != userID {
+			continue
+		}
+		export.MatchFeedback = append(export.MatchFeedback, feedback)
+	}
+
+	conversations, err := ctrl.conversationRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("conversations: %w", err)
+	}
+	for _, conv := range conversations {
+		messages, err := ctrl.allMessages(ctx, conv.ID)
+		if err != nil {
+			return nil, fmt.Errorf("messages: %w", err)
+		}
+		export.Conversations = append(export.Conversations, &entities.ConversationExport{Conversation: conv, Messages: messages})
+	}
+
+	if export.Blocks, err = ctrl.blockRepo.ListByBlocker(ctx, userID); err != nil {
+		return nil, fmt.Errorf("blocks: %w", err)
+	}
+	if export.Reports, err = ctrl.reportRepo.List(ctx, entities.ReportFilter{ReporterID: userID}); err != nil {
+		return nil, fmt.Errorf("reports: %w", err)
+	}
+	for _, report := range export.Reports {
+		report.AssignedTo, report.ResolvedBy = "", ""
+	}
+	if export.Moderation, err = ctrl.moderationRepo.ListByUser(ctx, userID); err != nil {
+		return nil, fmt.Errorf("moderation: %w", err)
+	}
+	for _, action := range export.Moderation {
+		action.ActorID, action.RevokedBy = "", ""
+	}
+
+	// Empty sections are [] rather than null
+	if export.Surveys == nil {
+		export.Surveys = []*entities.SurveyResponse{}
+	}
+	if export.Crushes == nil {
+		export.Crushes = []*entities.Crush{}
+	}
+	if export.Matches == nil {
+		export.Matches = []*entities.Match{}
+	}
+	if export.MatchFeedback == nil {
+		export.MatchFeedback = []*entities.MatchFeedback{}
+	}
+	if export.Conversations == nil {
+		export.Conversations = []*entities.ConversationExport{}
+	}
+	if export.Blocks == nil {
+		export.Blocks = []*entities.UserBlock{}
+	}
+	if export.Reports == nil {
+		export.Reports = []*entities.Report{}
+	}
+	if export.Moderation == nil {
+		export.Moderation = []*entities.ModerationAction{}
+	}
+
+	return export, nil
+}
+
+func (ctrl *AccountController) allMessages(ctx context.Context, conversationID string) ([]*entities.Message, error) {
+	var all []*entities.Message
+	for offset := 0; ; offset += exportMessagePage {
+		page, err := ctrl.messageRepo.GetByConversationID(ctx, conversationID, exportMessagePage, offset)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, page...)
+		if len(page) < exportMessagePage {
+			return all, nil
+		}
+	}
+}
+
+// exportArchive writes each section of the export to its own JSON file,
+// named after its key: profile.json, surveys.json, conversations.json and
+// so on
+func exportArchive(export *entities.AccountExport) ([]byte, error) {
+	encoded, err := json.Marshal(export)
+	if err != nil {
+		return nil, err
+	}
+	var sections map[string]json.RawMessage
+	if err := json.Unmarshal(encoded, &sections); err != nil {
+		return nil, err
+	}
+	delete(sections, "exported_at")
+	names := make([]string, 0, len(sections))
+	for name := range sections {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	if err := archive.SetComment("Wizard Connect data export, " + export.ExportedAt.Format(time.RFC3339)); err != nil {
+		return nil, err
+	}
+	for _, name := range names {
+		var pretty bytes.Buffer
+		if err := json.Indent(&pretty, sections[name], "", "  "); err != nil {
+			return nil, err
+		}
+		file, err := archive.CreateHeader(&zip.FileHeader{
+			Name:     name + ".json",
+			Method:   zip.Deflate,
+			Modified: export.ExportedAt,
+		})
+		if err != nil {
+			return nil, err
+		}
+		if _, err := file.Write(pretty.Bytes()); err != nil {
+			return nil, err
+		}
+	}
+	if err := archive.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -1,19 +1,25 @@
 package controllers
 
 import (
+	"context"
+	"fmt"
 	"net/http"
 
 	"wizard-connect/internal/domain/entities"
 	"wizard-connect/internal/domain/repositories"
-	"wizard-connect/internal/infrastructure/database"
+	"wizard-connect/internal/domain/services"
+	"wizard-connect/internal/interface/http/middleware"
 
 	"github.com/gin-gonic/gin"
 )
 
 type AdminController struct {
-	adminRepo repositories.AdminRepository
-	userRepo  repositories.UserRepository
-	matchRepo *database.MatchRepository
+	adminRepo  repositories.AdminRepository
+	userRepo   repositories.UserRepository
+	matchRepo  repositories.MatchRepository
+	surveyRepo repositories.SurveyRepository
+	auditRepo  repositories.AuditLogRepository
+	blockRepo  repositories.UserBlockRepository
 }
 
 type AddAdminRequest struct {
@@ -28,17 +34,26 @@ type ManualMatchRequest struct {
 	UserID             string  `json:"user_id" binding:"required"`
 	MatchedUserID      string  `json:"matched_user_id" binding:"required"`
 	CompatibilityScore float64 `json:"compatibility_score"`
+	// OverrideGenderPreference creates the match even though one of the
+	// users isn't looking for the other's gender
+	OverrideGenderPreference bool `json:"override_gender_preference"`
 }
 
 func NewAdminController(
 	adminRepo repositories.AdminRepository,
 	userRepo repositories.UserRepository,
-	matchRepo *database.MatchRepository,
+	matchRepo repositories.MatchRepository,
+	surveyRepo repositories.SurveyRepository,
+	auditRepo repositories.AuditLogRepository,
+	blockRepo repositories.UserBlockRepository,
 ) *AdminController {
 	return &AdminController{
-		adminRepo: adminRepo,
-		userRepo:  userRepo,
-		matchRepo: matchRepo,
+		adminRepo:  adminRepo,
+		userRepo:   userRepo,
+		matchRepo:  matchRepo,
+		surveyRepo: surveyRepo,
+		auditRepo:  auditRepo,
+		blockRepo:  blockRepo,
 	}
 }
 
@@ -116,28 +131,153 @@ func (ctrl *AdminController) GetAllMatches(c *gin.Context) {
 	})
 }
 
+// CreateManualMatch matches two users with each other by hand. Both
+// directions are created at the top of the users' lists, moving their other
+// matches down a rank. Pairs where either user isn't looking for the other's
+// gender are refused unless override_gender_preference is set; pairs where
+// one user has blocked the other are always refused.
 func (ctrl *AdminController) CreateManualMatch(c *gin.Context) {
 	var req ManualMatchRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	if req.UserID == req.MatchedUserID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "A user can't be matched with themselves"})
+		return
+	}
+	if req.CompatibilityScore < 0 || req.CompatibilityScore > 100 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "compatibility_score must be between 0 and 100"})
+		return
+	}
+
+	adminID, _ := middleware.GetUserID(c)
+	ctx := c.Request.Context()
+
+	// Both users must have signed up; looking them up mustn't create them
+	for _, id := range []string{req.UserID, req.MatchedUserID} {
+		exists, err := ctrl.userRepo.Exists(ctx, id)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check users: " + err.Error()})
+			return
+		}
+		if !exists {
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found: " + id})
+			return
+		}
+	}
+	user, err := ctrl.userRepo.GetByID(ctx, req.UserID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load user: " + err.Error()})
+		return
+	}
+	matchedUser, err := ctrl.userRepo.GetByID(ctx, req.MatchedUserID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load user: " + err.Error()})
+		return
+	}
+
+	blocked, err := ctrl.blockRepo.IsBlocked(ctx, req.UserID, req.MatchedUserID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check blocks: " + err.Error()})
+		return
+	}
+	if blocked {
+		c.JSON(http.StatusConflict, gin.H{"error": "One of the users has blocked the other"})
+		return
+	}
 
-	match := &entities.Match{
-		UserID:             req.UserID,
-		MatchedUserID:      req.MatchedUserID,
-		CompatibilityScore: req.CompatibilityScore,
-		Rank:               1, // Manual matches are top priority
-		IsMutualCrush:      true,
+	if conflicts := ctrl.genderConflicts(ctx, user, matchedUser); len(conflicts) > 0 && !req.OverrideGenderPreference {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"error":     "Users' gender preferences don't match; set override_gender_preference to match them anyway",
+			"conflicts": conflicts,
+		})
+		return
 	}
 
-	if err := ctrl.matchRepo.Create(c.Request.Context(), match); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create manual match: " + err.Error()})
+	var created []*entities.Match
+	for _, pair := range [][2]string{{req.UserID, req.MatchedUserID}, {req.MatchedUserID, req.UserID}} {
+		if _, err := ctrl.matchRepo.GetMatch(ctx, pair[0], pair[1]); err == nil {
+			continue // that direction already exists
+		}
+
+		match := &entities.Match{
+			UserID:             pair[0],
+			MatchedUserID:      pair[1],
+			CompatibilityScore: req.CompatibilityScore,
+			ScoreFromUser:      req.CompatibilityScore,
+			ScoreFromMatched:   req.CompatibilityScore,
+			Source:             entities.MatchSourceManual,
+			CreatedBy:          adminID,
+		}
+		if err := ctrl.prependMatch(ctx, match); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create manual match: " + err.Error()})
+			return
+		}
+		created = append(created, match)
+	}
+	if len(created) == 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "Users are already matched with each other"})
 		return
 	}
 
+	writeAudit(ctx, ctrl.auditRepo, &entities.AuditEntry{
+		ActorID:    adminID,
+		Action:     entities.AuditActionManualMatch,
+		TargetType: "match",
+		TargetID:   created[0].ID,
+		Details: map[string]interface{}{
+			"user_id":                    req.UserID,
+			"matched_user_id":            req.MatchedUserID,
+			"compatibility_score":        req.CompatibilityScore,
+			"override_gender_preference": req.OverrideGenderPreference,
+		},
+	})
+
 	c.JSON(http.StatusOK, gin.H{
 		"message": "Manual match created successfully",
-		"match":   match,
+		"matches": created,
 	})
 }
+
+// prependMatch puts a match at rank 1 of its owner's list and moves the
+// owner's other matches down one
+func (ctrl *AdminController) prependMatch(ctx context.Context, match *entities.Match) error {
+	existing, err := ctrl.matchRepo.GetByUserID(ctx, match.UserID)
+	if err != nil {
+		return err
+	}
+	for i, m := range existing {
+		if m.Rank != i+2 {
+			if err := ctrl.matchRepo.UpdateRank(ctx, m.ID, i+2); err != nil {
+				return err
+			}
+		}
+	}
+
+	match.Rank = 1
+	return ctrl.matchRepo.Create(ctx, match)
+}
+
+// genderConflicts describes which of the two users isn't looking for the
+// other's gender, judged by their latest survey and their profile
+func (ctrl *AdminController) genderConflicts(ctx context.Context, a, b *entities.User) []string {
+	participant := func(user *entities.User) *services.Participant {
+		survey, err := ctrl.surveyRepo.GetByUserID(ctx, user.ID)
+		if err != nil || survey == nil {
+			survey = &entities.SurveyResponse{UserID: user.ID}
+		}
+		return &services.Participant{Survey: survey, User: user}
+	}
+	pa, pb := participant(a), participant(b)
+
+	gender := services.GenderPreferenceConstraint()
+	var conflicts []string
+	if !gender.Allows(pa, pb) {
+		conflicts = append(conflicts, fmt.Sprintf("%s is not looking for %s's gender", a.ID, b.ID))
+	}
+	if !gender.Allows(pb, pa) {
+		conflicts = append(conflicts, fmt.Sprintf("%s is not looking for %s's gender", b.ID, a.ID))
+	}
+	return conflicts
+}
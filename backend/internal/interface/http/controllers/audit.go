@@ -0,0 +1,17 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	"wizard-connect/internal/domain/entities"
+	"wizard-connect/internal/domain/repositories"
+)
+
+// writeAudit records an admin action. The action itself has already
+// happened, so a failed write is logged rather than reported to the admin.
+func writeAudit(ctx context.Context, auditRepo repositories.AuditLogRepository, entry *entities.AuditEntry) {
+	if err := auditRepo.Create(ctx, entry); err != nil {
+		fmt.Printf("ERROR: Failed to write audit entry %s for %s %s: %v\n", entry.Action, entry.TargetType, entry.TargetID, err)
+	}
+}
@@ -2,7 +2,9 @@ package controllers
 
 import (
 	"context"
+	"fmt"
 	"net/http"
+	"sort"
 	"strconv"
 	"time"
 
@@ -12,14 +14,16 @@ import (
 	"wizard-connect/internal/domain/entities"
 	"wizard-connect/internal/domain/repositories"
 	"wizard-connect/internal/domain/services"
-	"wizard-connect/internal/infrastructure/database"
 )
 
 type CampaignController struct {
 	campaignRepo    repositories.CampaignRepository
 	matchingService services.MatchingService
-	surveyRepo      database.SurveyRepository
-	matchRepo       *database.MatchRepository
+	surveyRepo      repositories.SurveyRepository
+	matchRepo       repositories.MatchRepository
+	runRepo         repositories.MatchingRunRepository
+	stagedRepo      repositories.StagedMatchRepository
+	statsService    *services.StatisticsService
 }
 
 type CreateCampaignRequest struct {
@@ -31,6 +35,7 @@ type CreateCampaignRequest struct {
 	ResultsReleaseDate     time.Time              `json:"results_release_date" binding:"required"`
 	IsActive               bool                   `json:"is_active"`
 	AlgorithmVersion       string                 `json:"algorithm_version"`
+	SurveyVersion          string                 `json:"survey_version"`
 	Config                 map[string]interface{} `json:"config"`
 }
 
@@ -43,20 +48,27 @@ type UpdateCampaignRequest struct {
 	ResultsReleaseDate     *time.Time             `json:"results_release_date"`
 	IsActive               *bool                  `json:"is_active"`
 	AlgorithmVersion       *string                `json:"algorithm_version"`
+	SurveyVersion          *string                `json:"survey_version"`
 	Config                 map[string]interface{} `json:"config"`
 }
 
 func NewCampaignController(
 	campaignRepo repositories.CampaignRepository,
 	matchingService services.MatchingService,
-	surveyRepo database.SurveyRepository,
-	matchRepo *database.MatchRepository,
+	surveyRepo repositories.SurveyRepository,
+	matchRepo repositories.MatchRepository,
+	runRepo repositories.MatchingRunRepository,
+	stagedRepo repositories.StagedMatchRepository,
+	statsService *services.StatisticsService,
 ) *CampaignController {
 	return &CampaignController{
 		campaignRepo:    campaignRepo,
 		matchingService: matchingService,
 		surveyRepo:      surveyRepo,
 		matchRepo:       matchRepo,
+		runRepo:         runRepo,
+		stagedRepo:      stagedRepo,
+		statsService:    statsService,
 	}
 }
 
@@ -68,6 +80,14 @@ func (c *CampaignController) CreateCampaign(ctx *gin.Context) {
 		return
 	}
 
+	if req.SurveyVersion == "" {
+		req.SurveyVersion = services.DefaultSurveyVersion
+	}
+	if _, err := services.GetSurveyDefinition(req.SurveyVersion); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	campaign := &entities.Campaign{
 		ID:                     uuid.New().String(),
 		Name:                   req.Name,
@@ -78,6 +98,7 @@ func (c *CampaignController) CreateCampaign(ctx *gin.Context) {
 		ResultsReleaseDate:     req.ResultsReleaseDate,
 		IsActive:               req.IsActive,
 		AlgorithmVersion:       req.AlgorithmVersion,
+		SurveyVersion:          req.SurveyVersion,
 		TotalParticipants:      0,
 		TotalMatchesGenerated:  0,
 		CreatedAt:              time.Now(),
@@ -172,6 +193,13 @@ func (c *CampaignController) UpdateCampaign(ctx *gin.Context) {
 	if req.AlgorithmVersion != nil {
 		existing.AlgorithmVersion = *req.AlgorithmVersion
 	}
+	if req.SurveyVersion != nil {
+		if _, err := services.GetSurveyDefinition(*req.SurveyVersion); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		existing.SurveyVersion = *req.SurveyVersion
+	}
 	if req.Config != nil {
 		existing.Config = req.Config
 	}
@@ -198,10 +226,36 @@ func (c *CampaignController) DeleteCampaign(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, gin.H{"message": "Campaign deleted successfully"})
 }
 
+// RunMatchingRequest optionally fixes the seed of a run; without one a new
+// seed is drawn and recorded in the run's manifest
+type RunMatchingRequest struct {
+	Seed *int64 `json:"seed"`
+}
+
 // RunMatchingAlgorithm triggers the matching algorithm for all participants
+// who answered this campaign's survey. The matches are staged for review and
+// only reach users once an admin publishes them.
 func (c *CampaignController) RunMatchingAlgorithm(ctx *gin.Context) {
-	// Get all completed surveys
-	surveys, err := c.surveyRepo.GetCompletedSurveys(ctx.Request.Context())
+	campaign, err := c.campaignRepo.GetByID(ctx.Request.Context(), ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Campaign not found"})
+		return
+	}
+
+	var req RunMatchingRequest
+	if ctx.Request.ContentLength > 0 {
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	seed := time.Now().UnixNano()
+	if req.Seed != nil {
+		seed = *req.Seed
+	}
+
+	// Get completed surveys for this campaign's question set
+	surveys, err := c.surveyRepo.GetCompletedSurveysForCampaign(ctx.Request.Context(), campaign.ID, services.CampaignSurveyVersion(campaign))
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch participants: " + err.Error()})
 		return
@@ -213,64 +267,200 @@ func (c *CampaignController) RunMatchingAlgorithm(ctx *gin.Context) {
 		return
 	}
 
-	// For each user, generate and save matches
+	run := &entities.MatchingRun{
+		CampaignID:   campaign.ID,
+		Status:       entities.RunStatusRunning,
+		Participants: totalParticipants,
+		StartedAt:    time.Now(),
+	}
+	if err := c.runRepo.Create(ctx.Request.Context(), run); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record matching run: " + err.Error()})
+		return
+	}
+
+	// Match everyone at once so the campaign's fairness controls can apply
 	go func() {
 		// Use a background context as this might take time
 		bgCtx := context.Background()
-		for _, survey := range surveys {
-			// Delete existing matches for this user first
-			_ = c.matchingService.MatchRepo().DeleteByUserID(bgCtx, survey.UserID)
-
-			// Generate matches (top 7)
-			matches, err := c.matchingService.GenerateMatches(bgCtx, survey.UserID, 7)
-			if err != nil {
-				continue
-			}
+		result, err := c.matchingService.RunCampaign(bgCtx, campaign, seed)
+
+		completedAt := time.Now()
+		run.CompletedAt = &completedAt
+		if err != nil {
+			run.Status = entities.RunStatusFailed
+			run.Error = err.Error()
+			_ = c.runRepo.Update(bgCtx, run)
+			return
+		}
 
-			// Save matches to database
-			for _, match := range matches {
-				_ = c.matchingService.MatchRepo().Create(bgCtx, match)
-			}
+		// Replace the campaign's staged matches, keeping admin pins
+		existing, err := c.stagedRepo.ListByCampaign(bgCtx, campaign.ID)
+		if err == nil {
+			err = c.stagedRepo.ReplaceForCampaign(bgCtx, campaign.ID, services.StageRun(campaign.ID, run.ID, result.Matches, existing))
+		}
+		if err != nil {
+			run.Status = entities.RunStatusFailed
+			run.Error = "failed to stage matches: " + err.Error()
+			_ = c.runRepo.Update(bgCtx, run)
+			return
+		}
+
+		run.Status = entities.RunStatusCompleted
+		run.Participants = result.Participants
+		run.TotalMatches = len(result.Matches)
+		run.Fairness = &result.Fairness
+		run.Manifest = &result.Manifest
+		_ = c.runRepo.Update(bgCtx, run)
+
+		if _, err := c.statsService.Refresh(bgCtx, campaign); err != nil {
+			fmt.Printf("ERROR: Failed to refresh statistics of campaign %s: %v\n", campaign.ID, err)
 		}
 	}()
 
 	ctx.JSON(http.StatusAccepted, gin.H{
-		"message":            "Matching algorithm started in background",
+		"message":            "Matching algorithm started in background; matches will be staged for review",
+		"run_id":             run.ID,
+		"seed":               seed,
 		"total_participants": totalParticipants,
 		"status":             "processing",
 	})
 }
 
-// GetCampaignStatistics returns statistics for a campaign
-func (c *CampaignController) GetCampaignStatistics(ctx *gin.Context) {
-	id := ctx.Param("id")
+// GetMatchingRuns lists a campaign's matching runs, newest first
+func (c *CampaignController) GetMatchingRuns(ctx *gin.Context) {
+	runs, err := c.runRepo.ListByCampaign(ctx.Request.Context(), ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch matching runs: " + err.Error()})
+		return
+	}
+	if runs == nil {
+		runs = []*entities.MatchingRun{}
+	}
 
-	campaign, err := c.campaignRepo.GetByID(context.Background(), id)
+	ctx.JSON(http.StatusOK, runs)
+}
+
+// GetMatchingRun returns one run with its fairness report
+func (c *CampaignController) GetMatchingRun(ctx *gin.Context) {
+	run, err := c.runRepo.GetByID(ctx.Request.Context(), ctx.Param("runId"))
+	if err != nil || run.CampaignID != ctx.Param("id") {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Matching run not found"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, run)
+}
+
+// ReplayMatchingRun re-runs a completed run from its manifest against the
+// campaign's current data, without saving anything, and reports whether the
+// output is identical. When it isn't, the manifest comparison shows whether
+// the inputs, the config or the code changed since.
+func (c *CampaignController) ReplayMatchingRun(ctx *gin.Context) {
+	run, err := c.runRepo.GetByID(ctx.Request.Context(), ctx.Param("runId"))
+	if err != nil || run.CampaignID != ctx.Param("id") {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Matching run not found"})
+		return
+	}
+	if run.Manifest == nil {
+		ctx.JSON(http.StatusConflict, gin.H{"error": "Run has no manifest to replay"})
+		return
+	}
+
+	campaign, err := c.campaignRepo.GetByID(ctx.Request.Context(), run.CampaignID)
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Campaign not found"})
+		return
+	}
+
+	replay, err := c.matchingService.ReplayRun(ctx.Request.Context(), campaign, *run.Manifest)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to replay run: " + err.Error()})
+		return
+	}
+
+	original, current := run.Manifest, replay.Manifest
+	ctx.JSON(http.StatusOK, gin.H{
+		"run_id":                  run.ID,
+		"identical":               original.OutputHash == current.OutputHash,
+		"original":                original,
+		"replay":                  current,
+		"inputs_match":            original.InputHash == current.InputHash,
+		"config_match":            original.ConfigHash == current.ConfigHash,
+		"algorithm_version_match": original.AlgorithmVersion == current.AlgorithmVersion,
+		"code_version_match":      original.CodeVersion == current.CodeVersion,
+		"total_matches":           gin.H{"original": run.TotalMatches, "replay": len(replay.Matches)},
+	})
+}
+
+// GetCampaignStatistics returns a campaign's participation, match and
+// engagement statistics. They are cached; ?refresh=true recomputes them.
+func (c *CampaignController) GetCampaignStatistics(ctx *gin.Context) {
+	campaign, err := c.campaignRepo.GetByID(ctx.Request.Context(), ctx.Param("id"))
 	if err != nil {
 		ctx.JSON(http.StatusNotFound, gin.H{"error": "Campaign not found"})
 		return
 	}
 
-	// TODO: Fetch actual statistics from database
-	stats := gin.H{
-		"campaign_id":           campaign.ID,
-		"campaign_name":         campaign.Name,
-		"total_participants":    campaign.TotalParticipants,
-		"total_matches":         campaign.TotalMatchesGenerated,
-		"average_compatibility": 0.0,
-		"mutual_crush_rate":     0.0,
+	var stats *services.CampaignStatistics
+	if ctx.Query("refresh") == "true" {
+		stats, err = c.statsService.Refresh(ctx.Request.Context(), campaign)
+	} else {
+		stats, err = c.statsService.Get(ctx.Request.Context(), campaign)
+	}
+	if stats == nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute statistics: " + err.Error()})
+		return
+	}
+	if err != nil {
+		// The statistics are fine; only saving the totals on the campaign failed
+		fmt.Printf("ERROR: %v\n", err)
 	}
 
 	ctx.JSON(http.StatusOK, stats)
 }
 
+// GetEligibilityReport lists how many candidates each participant's hard
+// filters leave them, flagging those below the campaign's min_pool_size
+func (c *CampaignController) GetEligibilityReport(ctx *gin.Context) {
+	campaign, err := c.campaignRepo.GetByID(ctx.Request.Context(), ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Campaign not found"})
+		return
+	}
+
+	pools, err := c.matchingService.EligiblePoolSizes(ctx.Request.Context(), campaign)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute eligible pools: " + err.Error()})
+		return
+	}
+
+	minPool := services.CampaignMatchingConfig(campaign).Constraints.MinPoolSize
+	belowMinimum := []string{}
+	for userID, size := range pools {
+		if size < minPool {
+			belowMinimum = append(belowMinimum, userID)
+		}
+	}
+	sort.Strings(belowMinimum)
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"data": gin.H{
+			"campaign_id":   campaign.ID,
+			"participants":  len(pools),
+			"min_pool_size": minPool,
+			"pools":         pools,
+			"below_minimum": belowMinimum,
+		},
+	})
+}
+
 // GetCampaignStatus returns the current campaign status (public)
 func (ctrl *CampaignController) GetCampaignStatus(c *gin.Context) {
-	status, err := database.GetCampaignStatus()
+	campaign, err := ctrl.campaignRepo.GetActive(c.Request.Context())
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get campaign status"})
 		return
 	}
 
-	c.JSON(http.StatusOK, status)
+	c.JSON(http.StatusOK, services.GetCampaignStatus(campaign, time.Now()))
 }
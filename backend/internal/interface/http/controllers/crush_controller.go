@@ -4,7 +4,7 @@ import (
 	"net/http"
 
 	"wizard-connect/internal/domain/entities"
-	"wizard-connect/internal/infrastructure/database"
+	"wizard-connect/internal/domain/repositories"
 	"wizard-connect/internal/interface/http/middleware"
 
 	"github.com/gin-gonic/gin"
@@ -12,10 +12,10 @@ import (
 )
 
 type CrushController struct {
-	crushRepo *database.CrushRepository
+	crushRepo repositories.CrushRepository
 }
 
-func NewCrushController(crushRepo *database.CrushRepository) *CrushController {
+func NewCrushController(crushRepo repositories.CrushRepository) *CrushController {
 	return &CrushController{
 		crushRepo: crushRepo,
 	}
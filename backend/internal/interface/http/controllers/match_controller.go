@@ -1,32 +1,60 @@
 package controllers
 
 import (
+	"context"
 	"fmt"
 	"net/http"
+	"strings"
 	"time"
 
+	"wizard-connect/internal/domain/entities"
+	"wizard-connect/internal/domain/repositories"
 	"wizard-connect/internal/domain/services"
-	"wizard-connect/internal/infrastructure/database"
 	"wizard-connect/internal/interface/http/middleware"
 
 	"github.com/gin-gonic/gin"
+	socketio "github.com/googollee/go-socket.io"
 )
 
 type MatchController struct {
-	matchRepo       *database.MatchRepository
-	surveyRepo      *database.SurveyRepository
+	matchRepo       repositories.MatchRepository
+	surveyRepo      repositories.SurveyRepository
 	matchingService services.MatchingService
+	feedbackRepo    repositories.MatchFeedbackRepository
+	campaignRepo    repositories.CampaignRepository
+	blockRepo       repositories.UserBlockRepository
+	socketServer    *socketio.Server
+}
+
+type RespondToMatchRequest struct {
+	Response string `json:"response" binding:"required,oneof=interested pass"`
+	// Reason optionally explains a pass; it is kept to tune matching
+	Reason string `json:"reason" binding:"max=500"`
+}
+
+type MatchFeedbackRequest struct {
+	Rating  int    `json:"rating" binding:"required,min=1,max=5"`
+	Met     bool   `json:"met"`
+	Comment string `json:"comment" binding:"max=2000"`
 }
 
 func NewMatchController(
-	matchRepo *database.MatchRepository,
-	surveyRepo *database.SurveyRepository,
+	matchRepo repositories.MatchRepository,
+	surveyRepo repositories.SurveyRepository,
 	matchingService services.MatchingService,
+	feedbackRepo repositories.MatchFeedbackRepository,
+	campaignRepo repositories.CampaignRepository,
+	blockRepo repositories.UserBlockRepository,
+	socketServer *socketio.Server,
 ) *MatchController {
 	return &MatchController{
 		matchRepo:       matchRepo,
 		surveyRepo:      surveyRepo,
 		matchingService: matchingService,
+		feedbackRepo:    feedbackRepo,
+		campaignRepo:    campaignRepo,
+		blockRepo:       blockRepo,
+		socketServer:    socketServer,
 	}
 }
 
@@ -46,8 +74,17 @@ type APIResponseMatch struct {
 	UserID             string              `json:"user_id"`
 	MatchedUserID      string              `json:"matched_user_id"`
 	CompatibilityScore float64             `json:"compatibility_score"`
+	ScoreFromUser      float64             `json:"score_from_user"`
+	ScoreFromMatched   float64             `json:"score_from_matched"`
 	Rank               int                 `json:"rank"`
 	IsMutualCrush      bool                `json:"is_mutual_crush"`
+	Source             string              `json:"source"`
+	Response           string              `json:"response"`
+	Viewed             bool                `json:"viewed"`
+	Contacted          bool                `json:"contacted"`
+	MatchViewed        bool                `json:"matched_user_viewed"`
+	MatchContacted     bool                `json:"matched_user_contacted"`
+	MessagingUnlocked  bool                `json:"messaging_unlocked"`
 	CreatedAt          string              `json:"created_at"`
 	MatchedUser        *MatchedUserDetails `json:"matched_user"`
 }
@@ -66,16 +103,39 @@ func (ctrl *MatchController) GetMatches(c *gin.Context) {
 		return
 	}
 
+	// Users who blocked, or were blocked by, this user are hidden
+	blockedIDs, err := ctrl.blockRepo.BlockedUserIDs(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve matches"})
+		return
+	}
+	blocked := make(map[string]bool, len(blockedIDs))
+	for _, id := range blockedIDs {
+		blocked[id] = true
+	}
+
 	// Transform to match frontend expected structure with nested matched_user
-	apiMatches := make([]APIResponseMatch, len(matches))
-	for i, m := range matches {
-		apiMatches[i] = APIResponseMatch{
+	apiMatches := make([]APIResponseMatch, 0, len(matches))
+	for _, m := range matches {
+		if blocked[m.MatchedUserID] {
+			continue
+		}
+		apiMatches = append(apiMatches, APIResponseMatch{
 			ID:                 m.ID,
 			UserID:             m.UserID,
 			MatchedUserID:      m.MatchedUserID,
 			CompatibilityScore: m.CompatibilityScore,
+			ScoreFromUser:      m.ScoreFromUser,
+			ScoreFromMatched:   m.ScoreFromMatched,
 			Rank:               m.Rank,
 			IsMutualCrush:      m.IsMutualCrush,
+			Source:             m.Source,
+			Response:           m.UserAResponse,
+			Viewed:             m.UserAViewed,
+			Contacted:          m.UserAContacted,
+			MatchViewed:        m.UserBViewed,
+			MatchContacted:     m.UserBContacted,
+			MessagingUnlocked:  m.MessagingUnlocked,
 			CreatedAt:          m.CreatedAt.Format(time.RFC3339),
 			MatchedUser: &MatchedUserDetails{
 				Email:     m.MatchedEmail,
@@ -86,7 +146,7 @@ func (ctrl *MatchController) GetMatches(c *gin.Context) {
 				Year:      m.Year,
 				Major:     m.Major,
 			},
-		}
+		})
 	}
 
 	c.JSON(http.StatusOK, gin.H{
@@ -95,50 +155,190 @@ func (ctrl *MatchController) GetMatches(c *gin.Context) {
 	})
 }
 
-// GenerateMatches creates new matches for user
-func (ctrl *MatchController) GenerateMatches(c *gin.Context) {
+// ownMatch loads one of the user's matches. Matches with someone either user
+// has blocked are treated as missing.
+func (ctrl *MatchController) ownMatch(ctx context.Context, userID, matchID string) (*entities.Match, bool) {
+	match, err := ctrl.matchRepo.GetByID(ctx, matchID)
+	if err != nil || match.UserID != userID {
+		return nil, false
+	}
+	blocked, err := ctrl.blockRepo.IsBlocked(ctx, userID, match.MatchedUserID)
+	if err != nil || blocked {
+		return nil, false
+	}
+	return match, true
+}
+
+// ViewMatch records that the user opened one of their matches
+func (ctrl *MatchController) ViewMatch(c *gin.Context) {
 	userID, exists := middleware.GetUserID(c)
 	if !exists {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
 		return
 	}
 
-	// Check if user has completed survey
-	survey, err := ctrl.surveyRepo.GetByUserID(c.Request.Context(), userID)
-	if err != nil {
-		fmt.Printf("ERROR: Failed to get user survey: %v\n", err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Please complete the survey first"})
+	match, ok := ctrl.ownMatch(c.Request.Context(), userID, c.Param("id"))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Match not found"})
+		return
+	}
+
+	if !match.UserAViewed {
+		if err := ctrl.matchRepo.MarkViewed(c.Request.Context(), userID, match.MatchedUserID); err != nil {
+			fmt.Printf("ERROR: Failed to mark match %s viewed: %v\n", match.ID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record view"})
+			return
+		}
+		match.UserAViewed = true
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": match})
+}
+
+// RespondToMatch records the user's interested or pass response. Messaging
+// unlocks once both users are interested, and both are notified.
+func (ctrl *MatchController) RespondToMatch(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
 		return
 	}
-	if !survey.IsComplete {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Please complete the survey first"})
+
+	var req RespondToMatchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	if req.Response != entities.MatchResponsePass {
+		req.Reason = ""
+	}
 
-	// Delete existing matches
-	if err := ctrl.matchRepo.DeleteByUserID(c.Request.Context(), userID); err != nil {
-		fmt.Printf("ERROR: Failed to delete existing matches: %v\n", err)
+	ctx := c.Request.Context()
+	match, ok := ctrl.ownMatch(ctx, userID, c.Param("id"))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Match not found"})
+		return
 	}
 
-	// Generate new matches (top 7 matches)
-	matches, err := ctrl.matchingService.GenerateMatches(c.Request.Context(), userID, 7)
+	if err := ctrl.matchRepo.Respond(ctx, userID, match.MatchedUserID, req.Response, req.Reason); err != nil {
+		fmt.Printf("ERROR: Failed to record response to match %s: %v\n", match.ID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record response"})
+		return
+	}
+	// Responding means they have seen the match
+	if !match.UserAViewed {
+		if err := ctrl.matchRepo.MarkViewed(ctx, userID, match.MatchedUserID); err != nil {
+			fmt.Printf("ERROR: Failed to mark match %s viewed: %v\n", match.ID, err)
+		}
+	}
+
+	updated, err := ctrl.matchRepo.GetByID(ctx, match.ID)
 	if err != nil {
-		fmt.Printf("ERROR: Failed to generate matches: %v\n", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate matches: " + err.Error()})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load match"})
 		return
 	}
+	if updated.MessagingUnlocked && !match.MessagingUnlocked {
+		ctrl.notifyUnlocked(c, updated)
+	}
 
-	// Save matches to database
-	for _, match := range matches {
-		if err := ctrl.matchRepo.Create(c.Request.Context(), match); err != nil {
-			fmt.Printf("ERROR: Failed to save match: %v\n", err)
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save matches"})
-			return
-		}
+	c.JSON(http.StatusOK, gin.H{"data": updated})
+}
+
+// notifyUnlocked tells both users of a pair, each with their own match ID,
+// that they can now message each other
+func (ctrl *MatchController) notifyUnlocked(c *gin.Context, match *entities.Match) {
+	if ctrl.socketServer == nil {
+		return
+	}
+
+	ctrl.socketServer.BroadcastToRoom("/", "user_"+match.UserID, "match-unlocked", gin.H{
+		"matchId":       match.ID,
+		"matchedUserId": match.MatchedUserID,
+	})
+	mirror, err := ctrl.matchRepo.GetMatch(c.Request.Context(), match.MatchedUserID, match.UserID)
+	if err != nil {
+		fmt.Printf("ERROR: Failed to load match %s -> %s for notification: %v\n", match.MatchedUserID, match.UserID, err)
+		return
+	}
+	ctrl.socketServer.BroadcastToRoom("/", "user_"+mirror.UserID, "match-unlocked", gin.H{
+		"matchId":       mirror.ID,
+		"matchedUserId": mirror.MatchedUserID,
+	})
+}
+
+// SubmitFeedback records how a match went: a 1-5 rating, whether the two
+// met and an optional comment. It opens once the messaging window is over;
+// submitting again replaces the earlier feedback.
+func (ctrl *MatchController) SubmitFeedback(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	var req MatchFeedbackRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx := c.Request.Context()
+	match, ok := ctrl.ownMatch(ctx, userID, c.Param("id"))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Match not found"})
+		return
+	}
+	if opensAt := services.FeedbackOpensAt(match); time.Now().Before(opensAt) {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":    "Feedback opens once the messaging window is over",
+			"opens_at": opensAt.Format(time.RFC3339),
+		})
+		return
+	}
+
+	feedback := &entities.MatchFeedback{
+		MatchID:       match.ID,
+		UserID:        userID,
+		MatchedUserID: match.MatchedUserID,
+		Rating:        req.Rating,
+		Met:           req.Met,
+		Comment:       strings.TrimSpace(req.Comment),
+	}
+	// Feedback is analyzed per campaign; matches made outside one have none
+	if campaign, err := ctrl.campaignRepo.GetActive(ctx); err == nil && campaign != nil {
+		feedback.CampaignID = campaign.ID
+	}
+
+	if err := ctrl.feedbackRepo.Upsert(ctx, feedback); err != nil {
+		fmt.Printf("ERROR: Failed to save feedback on match %s: %v\n", match.ID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save feedback"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": feedback})
+}
+
+// GetFeedback returns the user's feedback on a match and when feedback opens
+func (ctrl *MatchController) GetFeedback(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	match, ok := ctrl.ownMatch(c.Request.Context(), userID, c.Param("id"))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Match not found"})
+		return
+	}
+
+	var feedback *entities.MatchFeedback
+	if existing, err := ctrl.feedbackRepo.GetByMatchID(c.Request.Context(), match.ID); err == nil {
+		feedback = existing
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"data":    matches,
-		"message": "Matches generated successfully",
+		"data":     feedback,
+		"opens_at": services.FeedbackOpensAt(match).Format(time.RFC3339),
 	})
 }
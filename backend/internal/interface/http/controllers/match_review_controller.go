@@ -0,0 +1,653 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"wizard-connect/internal/domain/entities"
+	"wizard-connect/internal/domain/repositories"
+	"wizard-connect/internal/domain/services"
+	"wizard-connect/internal/interface/http/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MatchReviewController lets admins review a campaign's staged matches before
+// publishing them to users
+type MatchReviewController struct {
+	campaignRepo repositories.CampaignRepository
+	stagedRepo   repositories.StagedMatchRepository
+	vetoRepo     repositories.MatchVetoRepository
+	blockRepo    repositories.UserBlockRepository
+	auditRepo    repositories.AuditLogRepository
+	matchRepo    repositories.MatchRepository
+	userRepo     repositories.UserRepository
+	surveyRepo   repositories.SurveyRepository
+	statsService *services.StatisticsService
+}
+
+type FlagStagedMatchRequest struct {
+	Reason string `json:"reason" binding:"required"`
+}
+
+type VetoPairRequest struct {
+	UserID        string `json:"user_id" binding:"required"`
+	MatchedUserID string `json:"matched_user_id" binding:"required"`
+	Reason        string `json:"reason" binding:"required"`
+}
+
+type RevokeVetoRequest struct {
+	Reason string `json:"reason"`
+}
+
+type PinMatchRequest struct {
+	UserID        string `json:"user_id" binding:"required"`
+	MatchedUserID string `json:"matched_user_id" binding:"required"`
+}
+
+type PublishMatchesRequest struct {
+	// AllowFlagged publishes even though some pairs are still flagged
+	AllowFlagged bool `json:"allow_flagged"`
+}
+
+// StagedMatchView is a staged match with both users' emails, for reviewers
+type StagedMatchView struct {
+	*entities.StagedMatch
+	UserEmail        string `json:"user_email"`
+	MatchedUserEmail string `json:"matched_user_email"`
+}
+
+func NewMatchReviewController(
+	campaignRepo repositories.CampaignRepository,
+	stagedRepo repositories.StagedMatchRepository,
+	vetoRepo repositories.MatchVetoRepository,
+	blockRepo repositories.UserBlockRepository,
+	auditRepo repositories.AuditLogRepository,
+	matchRepo repositories.MatchRepository,
+	userRepo repositories.UserRepository,
+	surveyRepo repositories.SurveyRepository,
+	statsService *services.StatisticsService,
+) *MatchReviewController {
+	return &MatchReviewController{
+		campaignRepo: campaignRepo,
+		stagedRepo:   stagedRepo,
+		vetoRepo:     vetoRepo,
+		blockRepo:    blockRepo,
+		auditRepo:    auditRepo,
+		matchRepo:    matchRepo,
+		userRepo:     userRepo,
+		surveyRepo:   surveyRepo,
+		statsService: statsService,
+	}
+}
+
+// saveRanks renumbers the given owners' staged lists and saves the matches
+// whose rank moved
+func (ctrl *MatchReviewController) saveRanks(ctx context.Context, staged []*entities.StagedMatch, owners ...string) error {
+	var lists []*entities.StagedMatch
+	for _, m := range staged {
+		for _, owner := range owners {
+			if m.UserID == owner {
+				lists = append(lists, m)
+				break
+			}
+		}
+	}
+	for _, m := range services.RerankStaged(lists) {
+		if err := ctrl.stagedRepo.Update(ctx, m); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ListStagedMatches lists a campaign's staged matches, optionally only one
+// user's (?user_id=) or only flagged ones (?flagged=true)
+func (ctrl *MatchReviewController) ListStagedMatches(c *gin.Context) {
+	staged, err := ctrl.stagedRepo.ListByCampaign(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch staged matches: " + err.Error()})
+		return
+	}
+	users, err := ctrl.userRepo.ListAll(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch users: " + err.Error()})
+		return
+	}
+	emails := make(map[string]string, len(users))
+	for _, u := range users {
+		emails[u.ID] = u.Email
+	}
+
+	userID := c.Query("user_id")
+	onlyFlagged := c.Query("flagged") == "true"
+
+	views := []StagedMatchView{}
+	flagged, pinned := 0, 0
+	for _, m := range staged {
+		if m.Flagged {
+			flagged++
+		}
+		if m.Pinned {
+			pinned++
+		}
+		if (userID != "" && m.UserID != userID) || (onlyFlagged && !m.Flagged) {
+			continue
+		}
+		views = append(views, StagedMatchView{StagedMatch: m, UserEmail: emails[m.UserID], MatchedUserEmail: emails[m.MatchedUserID]})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"matches": views,
+		"count":   len(views),
+		"total":   len(staged),
+		"flagged": flagged,
+		"pinned":  pinned,
+	})
+}
+
+// setFlag flags or clears both directions of the pair a staged match belongs to
+func (ctrl *MatchReviewController) setFlag(c *gin.Context, flagged bool, reason string) {
+	adminID, _ := middleware.GetUserID(c)
+	ctx := c.Request.Context()
+
+	target, err := ctrl.stagedRepo.GetByID(ctx, c.Param("matchId"))
+	if err != nil || target.CampaignID != c.Param("id") {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Staged match not found"})
+		return
+	}
+	staged, err := ctrl.stagedRepo.ListByCampaign(ctx, target.CampaignID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch staged matches: " + err.Error()})
+		return
+	}
+
+	var updated []*entities.StagedMatch
+	for _, m := range staged {
+		if !samePair(m, target.UserID, target.MatchedUserID) {
+			continue
+		}
+		m.Flagged, m.FlagReason, m.FlaggedBy = flagged, reason, adminID
+		if !flagged {
+			m.FlaggedBy = ""
+		}
+		if err := ctrl.stagedRepo.Update(ctx, m); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update staged match: " + err.Error()})
+			return
+		}
+		updated = append(updated, m)
+	}
+
+	action := entities.AuditActionMatchFlag
+	if !flagged {
+		action = entities.AuditActionMatchUnflag
+	}
+	writeAudit(ctx, ctrl.auditRepo, &entities.AuditEntry{
+		ActorID:    adminID,
+		Action:     action,
+		TargetType: "staged_match",
+		TargetID:   target.ID,
+		Details: map[string]interface{}{
+			"campaign_id":     target.CampaignID,
+			"user_id":         target.UserID,
+			"matched_user_id": target.MatchedUserID,
+			"reason":          reason,
+		},
+	})
+
+	c.JSON(http.StatusOK, gin.H{"matches": updated})
+}
+
+// FlagStagedMatch marks a pair for a second look; flagged pairs block
+// publishing unless the admin allows them
+func (ctrl *MatchReviewController) FlagStagedMatch(c *gin.Context) {
+	var req FlagStagedMatchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	ctrl.setFlag(c, true, req.Reason)
+}
+
+// UnflagStagedMatch clears a pair's flag
+func (ctrl *MatchReviewController) UnflagStagedMatch(c *gin.Context) {
+	ctrl.setFlag(c, false, "")
+}
+
+// VetoPair rules a pair out for good: its staged matches are removed and no
+// future run will match the two users
+func (ctrl *MatchReviewController) VetoPair(c *gin.Context) {
+	var req VetoPairRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.UserID == req.MatchedUserID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "A user can't be matched with themselves"})
+		return
+	}
+
+	adminID, _ := middleware.GetUserID(c)
+	ctx := c.Request.Context()
+	campaignID := c.Param("id")
+	if _, err := ctrl.campaignRepo.GetByID(ctx, campaignID); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Campaign not found"})
+		return
+	}
+
+	vetoes, err := ctrl.vetoRepo.ListVetoes(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch vetoes: " + err.Error()})
+		return
+	}
+	if services.NewVetoSet(vetoes).Has(req.UserID, req.MatchedUserID) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Pair is already vetoed"})
+		return
+	}
+
+	veto := &entities.MatchVeto{
+		UserAID:    req.UserID,
+		UserBID:    req.MatchedUserID,
+		CampaignID: campaignID,
+		Reason:     req.Reason,
+		VetoedBy:   adminID,
+	}
+	if err := ctrl.vetoRepo.Create(ctx, veto); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to veto pair: " + err.Error()})
+		return
+	}
+
+	// Drop the pair from this campaign's review, pins included
+	staged, err := ctrl.stagedRepo.ListByCampaign(ctx, campaignID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch staged matches: " + err.Error()})
+		return
+	}
+	kept := staged[:0]
+	removed := 0
+	for _, m := range staged {
+		if samePair(m, req.UserID, req.MatchedUserID) {
+			if err := ctrl.stagedRepo.Delete(ctx, m.ID); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove staged match: " + err.Error()})
+				return
+			}
+			removed++
+			continue
+		}
+		kept = append(kept, m)
+	}
+	if err := ctrl.saveRanks(ctx, kept, req.UserID, req.MatchedUserID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to re-rank staged matches: " + err.Error()})
+		return
+	}
+
+	writeAudit(ctx, ctrl.auditRepo, &entities.AuditEntry{
+		ActorID:    adminID,
+		Action:     entities.AuditActionMatchVeto,
+		TargetType: "match_veto",
+		TargetID:   veto.ID,
+		Details: map[string]interface{}{
+			"campaign_id":            campaignID,
+			"user_a_id":              veto.UserAID,
+			"user_b_id":              veto.UserBID,
+			"reason":                 veto.Reason,
+			"staged_matches_removed": removed,
+		},
+	})
+
+	c.JSON(http.StatusCreated, gin.H{
+		"veto":                   veto,
+		"staged_matches_removed": removed,
+	})
+}
+
+// ListVetoes lists every vetoed pair
+func (ctrl *MatchReviewController) ListVetoes(c *gin.Context) {
+	vetoes, err := ctrl.vetoRepo.ListVetoes(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch vetoes: " + err.Error()})
+		return
+	}
+	if vetoes == nil {
+		vetoes = []*entities.MatchVeto{}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"vetoes": vetoes,
+		"count":  len(vetoes),
+	})
+}
+
+// RevokeVeto lets a pair be matched again from the next run on
+func (ctrl *MatchReviewController) RevokeVeto(c *gin.Context) {
+	var req RevokeVetoRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	adminID, _ := middleware.GetUserID(c)
+	ctx := c.Request.Context()
+	veto, err := ctrl.vetoRepo.GetByID(ctx, c.Param("vetoId"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Veto not found"})
+		return
+	}
+	if err := ctrl.vetoRepo.Delete(ctx, veto.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke veto: " + err.Error()})
+		return
+	}
+
+	writeAudit(ctx, ctrl.auditRepo, &entities.AuditEntry{
+		ActorID:    adminID,
+		Action:     entities.AuditActionVetoRevoke,
+		TargetType: "match_veto",
+		TargetID:   veto.ID,
+		Details: map[string]interface{}{
+			"user_a_id":     veto.UserAID,
+			"user_b_id":     veto.UserBID,
+			"veto_reason":   veto.Reason,
+			"revoke_reason": req.Reason,
+		},
+	})
+
+	c.JSON(http.StatusOK, gin.H{"message": "Veto revoked"})
+}
+
+// PinMatch stages a pair in both directions at the top of both users' lists
+// and keeps it there across re-runs. Pairs the algorithm already staged are
+// pinned as they are; new pairs are scored from the users' campaign surveys.
+func (ctrl *MatchReviewController) PinMatch(c *gin.Context) {
+	var req PinMatchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.UserID == req.MatchedUserID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "A user can't be matched with themselves"})
+		return
+	}
+
+	adminID, _ := middleware.GetUserID(c)
+	ctx := c.Request.Context()
+	campaign, err := ctrl.campaignRepo.GetByID(ctx, c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Campaign not found"})
+		return
+	}
+	for _, id := range []string{req.UserID, req.MatchedUserID} {
+		if _, err := ctrl.userRepo.GetByID(ctx, id); err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found: " + id})
+			return
+		}
+	}
+
+	vetoes, err := ctrl.vetoRepo.ListVetoes(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch vetoes: " + err.Error()})
+		return
+	}
+	if services.NewVetoSet(vetoes).Has(req.UserID, req.MatchedUserID) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Pair is vetoed; revoke the veto first"})
+		return
+	}
+	blocked, err := ctrl.blockRepo.IsBlocked(ctx, req.UserID, req.MatchedUserID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check blocks: " + err.Error()})
+		return
+	}
+	if blocked {
+		c.JSON(http.StatusConflict, gin.H{"error": "One of the users has blocked the other"})
+		return
+	}
+
+	staged, err := ctrl.stagedRepo.ListByCampaign(ctx, campaign.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch staged matches: " + err.Error()})
+		return
+	}
+
+	compatibility, err := ctrl.scorePair(ctx, campaign, req.UserID, req.MatchedUserID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to score pair: " + err.Error()})
+		return
+	}
+
+	var pinned []*entities.StagedMatch
+	for _, dir := range [][2]string{{req.UserID, req.MatchedUserID}, {req.MatchedUserID, req.UserID}} {
+		var match *entities.StagedMatch
+		for _, m := range staged {
+			if m.UserID == dir[0] && m.MatchedUserID == dir[1] {
+				match = m
+				break
+			}
+		}
+
+		if match != nil {
+			match.Pinned, match.PinnedBy = true, adminID
+			if err := ctrl.stagedRepo.Update(ctx, match); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to pin match: " + err.Error()})
+				return
+			}
+		} else {
+			match = &entities.StagedMatch{
+				CampaignID:         campaign.ID,
+				UserID:             dir[0],
+				MatchedUserID:      dir[1],
+				CompatibilityScore: compatibility.Score,
+				ScoreFromUser:      compatibility.SelfToOther,
+				ScoreFromMatched:   compatibility.OtherToSelf,
+				Source:             entities.MatchSourceManual,
+				Pinned:             true,
+				PinnedBy:           adminID,
+			}
+			if dir[0] != req.UserID {
+				match.ScoreFromUser, match.ScoreFromMatched = compatibility.OtherToSelf, compatibility.SelfToOther
+			}
+			if err := ctrl.stagedRepo.Create(ctx, match); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to pin match: " + err.Error()})
+				return
+			}
+			staged = append(staged, match)
+		}
+		pinned = append(pinned, match)
+	}
+
+	if err := ctrl.saveRanks(ctx, staged, req.UserID, req.MatchedUserID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to re-rank staged matches: " + err.Error()})
+		return
+	}
+
+	writeAudit(ctx, ctrl.auditRepo, &entities.AuditEntry{
+		ActorID:    adminID,
+		Action:     entities.AuditActionMatchPin,
+		TargetType: "staged_match",
+		TargetID:   pinned[0].ID,
+		Details: map[string]interface{}{
+			"campaign_id":     campaign.ID,
+			"user_id":         req.UserID,
+			"matched_user_id": req.MatchedUserID,
+		},
+	})
+
+	c.JSON(http.StatusOK, gin.H{"matches": pinned})
+}
+
+// scorePair scores a pair with the campaign's config; users without a
+// completed survey for the campaign score 0
+func (ctrl *MatchReviewController) scorePair(ctx context.Context, campaign *entities.Campaign, userID, otherID string) (services.ReciprocalCompatibility, error) {
+	def, err := services.GetSurveyDefinition(services.CampaignSurveyVersion(campaign))
+	if err != nil {
+		return services.ReciprocalCompatibility{}, err
+	}
+
+	self, err := ctrl.surveyRepo.GetByUserAndCampaign(ctx, userID, campaign.ID)
+	if err != nil || self == nil || !self.IsComplete {
+		return services.ReciprocalCompatibility{}, nil
+	}
+	other, err := ctrl.surveyRepo.GetByUserAndCampaign(ctx, otherID, campaign.ID)
+	if err != nil || other == nil || !other.IsComplete {
+		return services.ReciprocalCompatibility{}, nil
+	}
+	return services.ScoreReciprocal(def, services.CampaignMatchingConfig(campaign), self, other), nil
+}
+
+// PublishMatches makes a campaign's staged matches live: every participant's
+// matches are replaced by their staged list, and the staged matches cleared
+func (ctrl *MatchReviewController) PublishMatches(c *gin.Context) {
+	var req PublishMatchesRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	adminID, _ := middleware.GetUserID(c)
+	ctx := c.Request.Context()
+	campaign, err := ctrl.campaignRepo.GetByID(ctx, c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Campaign not found"})
+		return
+	}
+
+	staged, err := ctrl.stagedRepo.ListByCampaign(ctx, campaign.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch staged matches: " + err.Error()})
+		return
+	}
+	if len(staged) == 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "No staged matches to publish; run the matching algorithm first"})
+		return
+	}
+
+	flagged := 0
+	for _, m := range staged {
+		if m.Flagged {
+			flagged++
+		}
+	}
+	if flagged > 0 && !req.AllowFlagged {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":   "Some staged matches are still flagged; resolve them or publish with allow_flagged",
+			"flagged": flagged,
+		})
+		return
+	}
+
+	// A pair vetoed in another campaign's review, or blocked by one of the
+	// users, since this run was staged is still left out
+	vetoes, err := ctrl.vetoRepo.ListVetoes(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch vetoes: " + err.Error()})
+		return
+	}
+	blocks, err := ctrl.blockRepo.ListBlocks(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch blocks: " + err.Error()})
+		return
+	}
+	vetoSet := services.NewVetoSet(vetoes)
+	blockSet := services.NewBlockSet(blocks)
+	var publish []*entities.StagedMatch
+	for _, m := range staged {
+		if !vetoSet.Has(m.UserID, m.MatchedUserID) && !blockSet.Has(m.UserID, m.MatchedUserID) {
+			publish = append(publish, m)
+		}
+	}
+	services.RerankStaged(publish)
+
+	// Participants who ended up with no matches lose their old ones too
+	owners := make(map[string]bool)
+	surveys, err := ctrl.surveyRepo.GetCompletedSurveysForCampaign(ctx, campaign.ID, services.CampaignSurveyVersion(campaign))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch participants: " + err.Error()})
+		return
+	}
+	for _, survey := range surveys {
+		owners[survey.UserID] = true
+	}
+	for _, m := range staged {
+		owners[m.UserID] = true
+	}
+
+	for owner := range owners {
+		if err := ctrl.matchRepo.DeleteByUserID(ctx, owner); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to replace matches: " + err.Error()})
+			return
+		}
+	}
+	for _, m := range publish {
+		if err := ctrl.matchRepo.Create(ctx, m.Match()); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to publish match: " + err.Error()})
+			return
+		}
+	}
+	if err := ctrl.stagedRepo.DeleteByCampaign(ctx, campaign.ID); err != nil {
+		fmt.Printf("ERROR: Failed to clear staged matches of campaign %s: %v\n", campaign.ID, err)
+	}
+
+	runIDs := []string{}
+	seenRuns := make(map[string]bool)
+	for _, m := range staged {
+		if m.RunID != "" && !seenRuns[m.RunID] {
+			seenRuns[m.RunID] = true
+			runIDs = append(runIDs, m.RunID)
+		}
+	}
+	writeAudit(ctx, ctrl.auditRepo, &entities.AuditEntry{
+		ActorID:    adminID,
+		Action:     entities.AuditActionMatchPublish,
+		TargetType: "campaign",
+		TargetID:   campaign.ID,
+		Details: map[string]interface{}{
+			"published": len(publish),
+			"users":     len(owners),
+			"flagged":   flagged,
+			"run_ids":   runIDs,
+		},
+	})
+
+	if _, err := ctrl.statsService.Refresh(ctx, campaign); err != nil {
+		fmt.Printf("ERROR: Failed to refresh statistics of campaign %s: %v\n", campaign.ID, err)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":   "Matches published",
+		"published": len(publish),
+		"users":     len(owners),
+	})
+}
+
+// GetAuditLog lists admin actions, newest first, optionally for one record
+// (?target_type=&target_id=); ?limit= defaults to 100
+func (ctrl *MatchReviewController) GetAuditLog(c *gin.Context) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "100"))
+	if err != nil || limit <= 0 || limit > 1000 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and 1000"})
+		return
+	}
+
+	entries, err := ctrl.auditRepo.List(c.Request.Context(), c.Query("target_type"), c.Query("target_id"), limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch audit log: " + err.Error()})
+		return
+	}
+	if entries == nil {
+		entries = []*entities.AuditEntry{}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"entries": entries,
+		"count":   len(entries),
+	})
+}
+
+// samePair reports whether a staged match is between a and b, either way round
+func samePair(m *entities.StagedMatch, a, b string) bool {
+	return (m.UserID == a && m.MatchedUserID == b) || (m.UserID == b && m.MatchedUserID == a)
+}
@@ -4,9 +4,11 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"time"
 
 	"wizard-connect/internal/domain/entities"
-	"wizard-connect/internal/infrastructure/database"
+	"wizard-connect/internal/domain/repositories"
+	"wizard-connect/internal/domain/services"
 	"wizard-connect/internal/interface/http/middleware"
 
 	"github.com/gin-gonic/gin"
@@ -14,26 +16,106 @@ import (
 )
 
 type MessageController struct {
-	conversationRepo *database.ConversationRepository
-	messageRepo      *database.MessageRepository
-	userRepo         *database.UserRepository
+	conversationRepo repositories.ConversationRepository
+	messageRepo      repositories.MessageRepository
+	userRepo         repositories.UserRepository
+	matchRepo        repositories.MatchRepository
+	blockRepo        repositories.UserBlockRepository
+	moderationRepo   repositories.ModerationActionRepository
+	contentService   *services.ContentService
 	socketServer     *socketio.Server
 }
 
 func NewMessageController(
-	conversationRepo *database.ConversationRepository,
-	messageRepo *database.MessageRepository,
-	userRepo *database.UserRepository,
+	conversationRepo repositories.ConversationRepository,
+	messageRepo repositories.MessageRepository,
+	userRepo repositories.UserRepository,
+	matchRepo repositories.MatchRepository,
+	blockRepo repositories.UserBlockRepository,
+	moderationRepo repositories.ModerationActionRepository,
+	contentService *services.ContentService,
 	socketServer *socketio.Server,
 ) *MessageController {
 	return &MessageController{
 		conversationRepo: conversationRepo,
 		messageRepo:      messageRepo,
 		userRepo:         userRepo,
+		matchRepo:        matchRepo,
+		blockRepo:        blockRepo,
+		moderationRepo:   moderationRepo,
+		contentService:   contentService,
 		socketServer:     socketServer,
 	}
 }
 
+const (
+	errMessagingLocked  = "Messaging unlocks once you and your match are both interested"
+	errMessagingBlocked = "You can't message this user"
+)
+
+// rejectMuted answers 403 when a moderator has muted the user and reports
+// whether it did
+func (ctrl *MessageController) rejectMuted(c *gin.Context, userID string) bool {
+	actions, err := ctrl.moderationRepo.Active(c.Request.Context(), userID, time.Now())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify account status"})
+		return true
+	}
+	standing := entities.StandingAt(actions, time.Now())
+	if !standing.Muted {
+		return false
+	}
+
+	body := gin.H{"error": "Your messaging has been muted by a moderator", "code": "messaging_muted"}
+	if standing.MutedUntil != nil {
+		body["muted_until"] = standing.MutedUntil.Format(time.RFC3339)
+	}
+	c.JSON(http.StatusForbidden, body)
+	return true
+}
+
+// isBlocked reports whether either user has blocked the other. Errors count
+// as blocked so a failing lookup never lets a message through.
+func (ctrl *MessageController) isBlocked(ctx context.Context, userID, otherUserID string) bool {
+	blocked, err := ctrl.blockRepo.IsBlocked(ctx, userID, otherUserID)
+	if err != nil {
+		fmt.Printf("ERROR: Failed to check block between %s and %s: %v\n", userID, otherUserID, err)
+		return true
+	}
+	return blocked
+}
+
+// ownConversation loads one of the user's conversations. Conversations with
+// someone either user has blocked are treated as missing.
+func (ctrl *MessageController) ownConversation(ctx context.Context, userID, conversationID string) (*entities.Conversation, bool) {
+	conv, err := ctrl.conversationRepo.GetByID(ctx, conversationID)
+	if err != nil || conv == nil {
+		return nil, false
+	}
+	if conv.Participant1 != userID && conv.Participant2 != userID {
+		return nil, false
+	}
+	otherUserID := conv.Participant1
+	if otherUserID == userID {
+		otherUserID = conv.Participant2
+	}
+	if ctrl.isBlocked(ctx, userID, otherUserID) {
+		return nil, false
+	}
+	return conv, true
+}
+
+// messagingUnlocked reports whether the two users are a match that both sides
+// said they are interested in
+func (ctrl *MessageController) messagingUnlocked(ctx context.Context, userID, otherUserID string) bool {
+	match, err := ctrl.matchRepo.GetMatch(ctx, userID, otherUserID)
+	if err != nil {
+		// One-way matches only exist in the other user's list
+		match, err = ctrl.matchRepo.GetMatch(ctx, otherUserID, userID)
+	}
+	return err == nil && match.MessagingUnlocked
+}
+
 // GetConversations retrieves all conversations for the current user
 func (ctrl *MessageController) GetConversations(c *gin.Context) {
 	userID, exists := middleware.GetUserID(c)
@@ -70,6 +152,9 @@ func (ctrl *MessageController) GetConversations(c *gin.Context) {
 		if otherUserID == userID {
 			otherUserID = conv.Participant2
 		}
+		if ctrl.isBlocked(c.Request.Context(), userID, otherUserID) {
+			continue
+		}
 
 		fmt.Printf("DEBUG: Fetching profile for other participant %s in conversation %s\n", otherUserID, conv.ID)
 		otherUser, err := ctrl.userRepo.GetByID(c.Request.Context(), otherUserID)
@@ -116,6 +201,11 @@ func (ctrl *MessageController) GetMessages(c *gin.Context) {
 		return
 	}
 
+	if _, ok := ctrl.ownConversation(c.Request.Context(), userID, conversationID); !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Conversation not found"})
+		return
+	}
+
 	// Get messages with pagination
 	limit := 50
 	offset := 0
@@ -170,10 +260,47 @@ func (ctrl *MessageController) SendMessage(c *gin.Context) {
 		return
 	}
 
+	conv, err := ctrl.conversationRepo.GetByID(c.Request.Context(), conversationID)
+	if err != nil || conv == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Conversation not found"})
+		return
+	}
+	if conv.Participant1 != userID && conv.Participant2 != userID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Not a participant in this conversation"})
+		return
+	}
+	otherUserID := conv.Participant1
+	if otherUserID == userID {
+		otherUserID = conv.Participant2
+	}
+	if ctrl.rejectMuted(c, userID) {
+		return
+	}
+	// Refusing the message also keeps it off both users' sockets
+	if ctrl.isBlocked(c.Request.Context(), userID, otherUserID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": errMessagingBlocked})
+		return
+	}
+	if !ctrl.messagingUnlocked(c.Request.Context(), userID, otherUserID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": errMessagingLocked})
+		return
+	}
+
+	// The reviewed text is what gets stored and broadcast, so masking
+	// reaches the sockets too
+	verdict := ctrl.contentService.Review(services.ContentFieldMessage, req.Content)
+	if verdict.Rejected() {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"error": "Message " + verdict.Reason(),
+			"code":  "content_rejected",
+		})
+		return
+	}
+
 	message := &entities.Message{
 		ConversationID: conversationID,
 		SenderID:       userID,
-		Content:        req.Content,
+		Content:        verdict.Text,
 		IsRead:         false,
 	}
 
@@ -182,8 +309,24 @@ func (ctrl *MessageController) SendMessage(c *gin.Context) {
 		return
 	}
 
+	evidence := entities.ReportEvidence{
+		MessageID:      message.ID,
+		ConversationID: conversationID,
+		SenderID:       userID,
+		Content:        message.Content,
+		SentAt:         message.CreatedAt,
+	}
+	if err := ctrl.contentService.FlagForReview(c.Request.Context(), userID, entities.ContentTypeMessage, message.ID, verdict, evidence); err != nil {
+		fmt.Printf("ERROR: Failed to flag message %s for review: %v\n", message.ID, err)
+	}
+
 	// Update conversation last message
-	_ = ctrl.conversationRepo.UpdateLastMessage(c.Request.Context(), conversationID, req.Content)
+	_ = ctrl.conversationRepo.UpdateLastMessage(c.Request.Context(), conversationID, message.Content)
+
+	// Messaging a match counts as contacting them
+	if err := ctrl.matchRepo.MarkContacted(c.Request.Context(), userID, otherUserID); err != nil {
+		fmt.Printf("ERROR: Failed to mark match %s -> %s contacted: %v\n", userID, otherUserID, err)
+	}
 
 	// Broadcast via WebSocket for real-time update
 	if ctrl.socketServer != nil {
@@ -191,7 +334,7 @@ func (ctrl *MessageController) SendMessage(c *gin.Context) {
 			"id":        message.ID,
 			"roomId":    conversationID,
 			"userId":    userID,
-			"message":   req.Content,
+			"message":   message.Content,
 			"timestamp": message.CreatedAt.UnixMilli(),
 		}
 
@@ -199,11 +342,8 @@ func (ctrl *MessageController) SendMessage(c *gin.Context) {
 		ctrl.socketServer.BroadcastToRoom("/", conversationID, "receive-message", payload)
 
 		// 2. Broadcast to both individual participant rooms (for sidebar/unread updates)
-		conv, err := ctrl.conversationRepo.GetByID(c.Request.Context(), conversationID)
-		if err == nil && conv != nil {
-			ctrl.socketServer.BroadcastToRoom("/", "user_"+conv.Participant1, "receive-message", payload)
-			ctrl.socketServer.BroadcastToRoom("/", "user_"+conv.Participant2, "receive-message", payload)
-		}
+		ctrl.socketServer.BroadcastToRoom("/", "user_"+conv.Participant1, "receive-message", payload)
+		ctrl.socketServer.BroadcastToRoom("/", "user_"+conv.Participant2, "receive-message", payload)
 	}
 
 	c.JSON(http.StatusCreated, gin.H{
@@ -229,6 +369,18 @@ func (ctrl *MessageController) CreateConversation(c *gin.Context) {
 		return
 	}
 
+	if ctrl.rejectMuted(c, userID) {
+		return
+	}
+	if ctrl.isBlocked(c.Request.Context(), userID, req.OtherUserID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": errMessagingBlocked})
+		return
+	}
+	if !ctrl.messagingUnlocked(c.Request.Context(), userID, req.OtherUserID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": errMessagingLocked})
+		return
+	}
+
 	// Check if conversation already exists
 	conv, err := ctrl.conversationRepo.GetByParticipants(c.Request.Context(), userID, req.OtherUserID)
 	if err == nil && conv != nil {
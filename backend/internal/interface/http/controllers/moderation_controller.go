@@ -0,0 +1,477 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"wizard-connect/internal/domain/entities"
+	"wizard-connect/internal/domain/repositories"
+	"wizard-connect/internal/interface/http/middleware"
+
+	"github.com/gin-gonic/gin"
+	socketio "github.com/googollee/go-socket.io"
+)
+
+// ModerationController runs the admin moderation queue: reports and
+// automatic flags are assigned, actioned or dismissed, and actions are taken
+// against users
+type ModerationController struct {
+	reportRepo   repositories.ReportRepository
+	actionRepo   repositories.ModerationActionRepository
+	userRepo     repositories.UserRepository
+	messageRepo  repositories.MessageRepository
+	adminRepo    repositories.AdminRepository
+	blockRepo    repositories.UserBlockRepository
+	auditRepo    repositories.AuditLogRepository
+	socketServer *socketio.Server
+}
+
+type AssignReportRequest struct {
+	// AssigneeID defaults to the admin making the request
+	AssigneeID string `json:"assignee_id"`
+}
+
+type DismissReportRequest struct {
+	Reason string `json:"reason" binding:"required,max=2000"`
+}
+
+type ModerationActionRequest struct {
+	Action string `json:"action" binding:"required,oneof=warn mute suspend ban remove_content"`
+	Reason string `json:"reason" binding:"required,max=2000"`
+	// DurationHours limits a mute or suspension; 0 keeps it in force until
+	// revoked. Bans never expire.
+	DurationHours int `json:"duration_hours" binding:"min=0"`
+	// ContentType and ContentID pick what remove_content removes; on a
+	// report they default to the reported content
+	ContentType string `json:"content_type" binding:"omitempty,oneof=message bio avatar"`
+	ContentID   string `json:"content_id"`
+}
+
+type RevokeModerationActionRequest struct {
+	Reason string `json:"reason"`
+}
+
+func NewModerationController(
+	reportRepo repositories.ReportRepository,
+	actionRepo repositories.ModerationActionRepository,
+	userRepo repositories.UserRepository,
+	messageRepo repositories.MessageRepository,
+	adminRepo repositories.AdminRepository,
+	blockRepo repositories.UserBlockRepository,
+	auditRepo repositories.AuditLogRepository,
+	socketServer *socketio.Server,
+) *ModerationController {
+	return &ModerationController{
+		reportRepo:   reportRepo,
+		actionRepo:   actionRepo,
+		userRepo:     userRepo,
+		messageRepo:  messageRepo,
+		adminRepo:    adminRepo,
+		blockRepo:    blockRepo,
+		auditRepo:    auditRepo,
+		socketServer: socketServer,
+	}
+}
+
+// ListReports returns the moderation queue, newest first. It can be narrowed
+// with ?status=, ?source=, ?user_id= (the reported user) and ?assigned_to=,
+// where assigned_to=me means the requesting admin.
+func (ctrl *ModerationController) ListReports(c *gin.Context) {
+	filter := entities.ReportFilter{
+		Status:         c.Query("status"),
+		Source:         c.Query("source"),
+		AssignedTo:     c.Query("assigned_to"),
+		ReportedUserID: c.Query("user_id"),
+	}
+	if filter.AssignedTo == "me" {
+		filter.AssignedTo, _ = middleware.GetUserID(c)
+	}
+
+	reports, err := ctrl.reportRepo.List(c.Request.Context(), filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch reports: " + err.Error()})
+		return
+	}
+	if reports == nil {
+		reports = []*entities.Report{}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"reports": reports,
+		"count":   len(reports),
+	})
+}
+
+// GetReport returns one report with its evidence
+func (ctrl *ModerationController) GetReport(c *gin.Context) {
+	report, err := ctrl.reportRepo.GetByID(c.Request.Context(), c.Param("reportId"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Report not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"report": report})
+}
+
+// openReport loads the :reportId report, refusing ones already closed
+func (ctrl *ModerationController) openReport(c *gin.Context) (*entities.Report, bool) {
+	report, err := ctrl.reportRepo.GetByID(c.Request.Context(), c.Param("reportId"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Report not found"})
+		return nil, false
+	}
+	if entities.ReportClosed(report.Status) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Report is already " + report.Status})
+		return nil, false
+	}
+	return report, true
+}
+
+// AssignReport hands a report to an admin and puts it in review
+func (ctrl *ModerationController) AssignReport(c *gin.Context) {
+	var req AssignReportRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	adminID, _ := middleware.GetUserID(c)
+	ctx := c.Request.Context()
+	report, ok := ctrl.openReport(c)
+	if !ok {
+		return
+	}
+
+	assignee := req.AssigneeID
+	if assignee == "" {
+		assignee = adminID
+	}
+	isAdmin, err := ctrl.adminRepo.IsAdmin(ctx, assignee)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify admin status"})
+		return
+	}
+	if !isAdmin {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Reports can only be assigned to admins"})
+		return
+	}
+
+	now := time.Now()
+	report.AssignedTo = assignee
+	report.AssignedAt = &now
+	report.Status = entities.ReportStatusInReview
+	if err := ctrl.reportRepo.Update(ctx, report); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to assign report: " + err.Error()})
+		return
+	}
+
+	writeAudit(ctx, ctrl.auditRepo, &entities.AuditEntry{
+		ActorID:    adminID,
+		Action:     entities.AuditActionReportAssign,
+		TargetType: "report",
+		TargetID:   report.ID,
+		Details:    map[string]interface{}{"assigned_to": assignee},
+	})
+
+	c.JSON(http.StatusOK, gin.H{"report": report})
+}
+
+// DismissReport closes a report without acting on it
+func (ctrl *ModerationController) DismissReport(c *gin.Context) {
+	var req DismissReportRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	adminID, _ := middleware.GetUserID(c)
+	report, ok := ctrl.openReport(c)
+	if !ok {
+		return
+	}
+
+	if err := ctrl.closeReport(c.Request.Context(), report, entities.ReportStatusDismissed, req.Reason, adminID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to dismiss report: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"report": report})
+}
+
+// ActOnReport takes an action against the reported user and closes the
+// report as actioned
+func (ctrl *ModerationController) ActOnReport(c *gin.Context) {
+	var req ModerationActionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	adminID, _ := middleware.GetUserID(c)
+	ctx := c.Request.Context()
+	report, ok := ctrl.openReport(c)
+	if !ok {
+		return
+	}
+	if req.Action == entities.ModerationActionRemoveContent && req.ContentType == "" {
+		req.ContentType, req.ContentID = report.ContentType, report.ContentID
+	}
+
+	action, status, err := ctrl.takeAction(ctx, report.ReportedUserID, report.ID, adminID, req)
+	if err != nil {
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	if err := ctrl.closeReport(ctx, report, entities.ReportStatusActioned, req.Reason, adminID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Action taken, but closing the report failed: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"report": report, "action": action})
+}
+
+// ModerateUser takes an action against a user directly, without a report
+func (ctrl *ModerationController) ModerateUser(c *gin.Context) {
+	var req ModerationActionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	adminID, _ := middleware.GetUserID(c)
+	ctx := c.Request.Context()
+	user, err := ctrl.userRepo.GetByID(ctx, c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	action, status, err := ctrl.takeAction(ctx, user.ID, "", adminID, req)
+	if err != nil {
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"action": action})
+}
+
+// RevokeAction lifts a mute, suspension or ban, or withdraws a warning
+func (ctrl *ModerationController) RevokeAction(c *gin.Context) {
+	var req RevokeModerationActionRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	adminID, _ := middleware.GetUserID(c)
+	ctx := c.Request.Context()
+	action, err := ctrl.actionRepo.GetByID(ctx, c.Param("actionId"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Moderation action not found"})
+		return
+	}
+	if action.Action == entities.ModerationActionRemoveContent {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Removed content can't be restored"})
+		return
+	}
+	if action.RevokedAt != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "Moderation action is already revoked"})
+		return
+	}
+	if err := ctrl.actionRepo.Revoke(ctx, action.ID, adminID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke action: " + err.Error()})
+		return
+	}
+
+	writeAudit(ctx, ctrl.auditRepo, &entities.AuditEntry{
+		ActorID:    adminID,
+		Action:     entities.AuditActionModerateRevoke,
+		TargetType: "user",
+		TargetID:   action.UserID,
+		Details: map[string]interface{}{
+			"action_id":     action.ID,
+			"action":        action.Action,
+			"revoke_reason": req.Reason,
+		},
+	})
+
+	c.JSON(http.StatusOK, gin.H{"message": "Moderation action revoked"})
+}
+
+// GetUserHistory returns a user's full moderation record: their standing,
+// every action taken against them, the reports about them and by them, and
+// how many users have blocked them
+func (ctrl *ModerationController) GetUserHistory(c *gin.Context) {
+	ctx := c.Request.Context()
+	user, err := ctrl.userRepo.GetByID(ctx, c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	actions, err := ctrl.actionRepo.ListByUser(ctx, user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch moderation actions: " + err.Error()})
+		return
+	}
+	against, err := ctrl.reportRepo.List(ctx, entities.ReportFilter{ReportedUserID: user.ID})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch reports: " + err.Error()})
+		return
+	}
+	filed, err := ctrl.reportRepo.List(ctx, entities.ReportFilter{ReporterID: user.ID})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch reports: " + err.Error()})
+		return
+	}
+	blocks, err := ctrl.blockRepo.ListBlocks(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch blocks: " + err.Error()})
+		return
+	}
+	blockedBy := 0
+	for _, block := range blocks {
+		if block.BlockedID == user.ID {
+			blockedBy++
+		}
+	}
+
+	if actions == nil {
+		actions = []*entities.ModerationAction{}
+	}
+	if against == nil {
+		against = []*entities.Report{}
+	}
+	if filed == nil {
+		filed = []*entities.Report{}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"user":             user,
+		"standing":         entities.StandingAt(actions, time.Now()),
+		"actions":          actions,
+		"reports_against":  against,
+		"reports_filed":    filed,
+		"blocked_by_count": blockedBy,
+	})
+}
+
+// closeReport marks a report actioned or dismissed by adminID
+func (ctrl *ModerationController) closeReport(ctx context.Context, report *entities.Report, status, resolution, adminID string) error {
+	now := time.Now()
+	report.Status = status
+	report.Resolution = resolution
+	report.ResolvedBy = adminID
+	report.ResolvedAt = &now
+	if report.AssignedTo == "" {
+		report.AssignedTo, report.AssignedAt = adminID, &now
+	}
+	if err := ctrl.reportRepo.Update(ctx, report); err != nil {
+		return err
+	}
+
+	writeAudit(ctx, ctrl.auditRepo, &entities.AuditEntry{
+		ActorID:    adminID,
+		Action:     entities.AuditActionReportClose,
+		TargetType: "report",
+		TargetID:   report.ID,
+		Details: map[string]interface{}{
+			"status":     status,
+			"resolution": resolution,
+		},
+	})
+	return nil
+}
+
+// takeAction records an action against userID and carries it out. On
+// failure it returns the HTTP status to answer with.
+func (ctrl *ModerationController) takeAction(ctx context.Context, userID, reportID, adminID string, req ModerationActionRequest) (*entities.ModerationAction, int, error) {
+	action := &entities.ModerationAction{
+		UserID:   userID,
+		ReportID: reportID,
+		Action:   req.Action,
+		Reason:   strings.TrimSpace(req.Reason),
+		ActorID:  adminID,
+	}
+
+	switch req.Action {
+	case entities.ModerationActionMute, entities.ModerationActionSuspend:
+		if req.DurationHours > 0 {
+			expires := time.Now().Add(time.Duration(req.DurationHours) * time.Hour)
+			action.ExpiresAt = &expires
+		}
+	case entities.ModerationActionRemoveContent:
+		if err := ctrl.removeContent(ctx, userID, req.ContentType, req.ContentID); err != nil {
+			return nil, http.StatusBadRequest, err
+		}
+		action.ContentType, action.ContentID = req.ContentType, req.ContentID
+	}
+
+	if err := ctrl.actionRepo.Create(ctx, action); err != nil {
+		return nil, http.StatusInternalServerError, fmt.Errorf("failed to record moderation action: %w", err)
+	}
+
+	details := map[string]interface{}{
+		"action_id": action.ID,
+		"action":    action.Action,
+		"reason":    action.Reason,
+	}
+	if reportID != "" {
+		details["report_id"] = reportID
+	}
+	if action.ExpiresAt != nil {
+		details["expires_at"] = action.ExpiresAt.Format(time.RFC3339)
+	}
+	if action.ContentType != "" {
+		details["content_type"] = action.ContentType
+		details["content_id"] = action.ContentID
+	}
+	writeAudit(ctx, ctrl.auditRepo, &entities.AuditEntry{
+		ActorID:    adminID,
+		Action:     entities.AuditActionModerate,
+		TargetType: "user",
+		TargetID:   userID,
+		Details:    details,
+	})
+
+	ctrl.notify(action)
+	return action, http.StatusOK, nil
+}
+
+// removeContent deletes one of the user's messages or clears their bio or
+// avatar
+func (ctrl *ModerationController) removeContent(ctx context.Context, userID, contentType, contentID string) error {
+	switch contentType {
+	case entities.ContentTypeMessage:
+		message, err := ctrl.messageRepo.GetByID(ctx, contentID)
+		if err != nil || message.SenderID != userID {
+			return fmt.Errorf("message %q by this user not found", contentID)
+		}
+		return ctrl.messageRepo.Delete(ctx, message.ID)
+	case entities.ContentTypeBio:
+		return ctrl.userRepo.ClearFields(ctx, userID, "bio")
+	case entities.ContentTypeAvatar:
+		return ctrl.userRepo.ClearFields(ctx, userID, "avatar_url")
+	}
+	return fmt.Errorf("remove_content needs a content_type of message, bio or avatar")
+}
+
+// notify tells the user about the action on their private socket room
+func (ctrl *ModerationController) notify(action *entities.ModerationAction) {
+	if ctrl.socketServer == nil {
+		return
+	}
+
+	payload := gin.H{"action": action.Action, "reason": action.Reason}
+	if action.ExpiresAt != nil {
+		payload["expiresAt"] = action.ExpiresAt.Format(time.RFC3339)
+	}
+	ctrl.socketServer.BroadcastToRoom("/", "user_"+action.UserID, "moderation-notice", payload)
+}
@@ -0,0 +1,124 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"wizard-connect/internal/domain/entities"
+	"wizard-connect/internal/domain/repositories"
+	"wizard-connect/internal/domain/services"
+	"wizard-connect/internal/interface/http/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RegistrationController lets admins see who may sign up and let in
+// individual addresses outside the allowed domains
+type RegistrationController struct {
+	registrationService *services.RegistrationService
+	overrideRepo        repositories.RegistrationOverrideRepository
+	auditRepo           repositories.AuditLogRepository
+}
+
+type AddRegistrationOverrideRequest struct {
+	Email  string `json:"email" binding:"required,email"`
+	Reason string `json:"reason"`
+}
+
+func NewRegistrationController(
+	registrationService *services.RegistrationService,
+	overrideRepo repositories.RegistrationOverrideRepository,
+	auditRepo repositories.AuditLogRepository,
+) *RegistrationController {
+	return &RegistrationController{
+		registrationService: registrationService,
+		overrideRepo:        overrideRepo,
+		auditRepo:           auditRepo,
+	}
+}
+
+// GetRegistration returns the allowed email domains and the override list.
+// No domains means anyone may sign up.
+func (ctrl *RegistrationController) GetRegistration(c *gin.Context) {
+	overrides, err := ctrl.overrideRepo.List(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch registration overrides"})
+		return
+	}
+
+	domains := ctrl.registrationService.AllowedDomains()
+	if domains == nil {
+		domains = []string{}
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"allowed_email_domains": domains,
+		"overrides":             overrides,
+	})
+}
+
+// AddOverride lets an email register whatever its domain. It only affects
+// sign-up; campaign eligibility rules still apply.
+func (ctrl *RegistrationController) AddOverride(c *gin.Context) {
+	adminID, _ := middleware.GetUserID(c)
+
+	var req AddRegistrationOverrideRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	override := &entities.RegistrationOverride{
+		Email:   strings.ToLower(strings.TrimSpace(req.Email)),
+		Reason:  strings.TrimSpace(req.Reason),
+		AddedBy: adminID,
+	}
+	if err := ctrl.overrideRepo.Add(c.Request.Context(), override); err != nil {
+		fmt.Printf("ERROR: Failed to add registration override for %s: %v\n", override.Email, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add registration override"})
+		return
+	}
+
+	writeAudit(c.Request.Context(), ctrl.auditRepo, &entities.AuditEntry{
+		ActorID:    adminID,
+		Action:     entities.AuditActionRegistrationOverride,
+		TargetType: "email",
+		TargetID:   override.Email,
+		Details:    map[string]interface{}{"reason": override.Reason},
+	})
+
+	c.JSON(http.StatusOK, gin.H{"data": override})
+}
+
+// RemoveOverride takes an email off the override list. Someone who already
+// signed up with it keeps their account.
+func (ctrl *RegistrationController) RemoveOverride(c *gin.Context) {
+	adminID, _ := middleware.GetUserID(c)
+	email := strings.ToLower(strings.TrimSpace(c.Param("email")))
+	ctx := c.Request.Context()
+
+	overridden, err := ctrl.overrideRepo.IsOverridden(ctx, email)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch registration overrides"})
+		return
+	}
+	if !overridden {
+		c.JSON(http.StatusNotFound, gin.H{"error": "No registration override for that email"})
+		return
+	}
+
+	if err := ctrl.overrideRepo.Remove(ctx, email); err != nil {
+		fmt.Printf("ERROR: Failed to remove registration override for %s: %v\n", email, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove registration override"})
+		return
+	}
+
+	writeAudit(ctx, ctrl.auditRepo, &entities.AuditEntry{
+		ActorID:    adminID,
+		Action:     entities.AuditActionRegistrationOverrideRevoke,
+		TargetType: "email",
+		TargetID:   email,
+	})
+
+	c.JSON(http.StatusOK, gin.H{"message": "Registration override removed"})
+}
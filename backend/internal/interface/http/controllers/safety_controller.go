@@ -0,0 +1,211 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"wizard-connect/internal/domain/entities"
+	"wizard-connect/internal/domain/repositories"
+	"wizard-connect/internal/interface/http/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SafetyController lets users block and report each other
+type SafetyController struct {
+	userRepo         repositories.UserRepository
+	blockRepo        repositories.UserBlockRepository
+	reportRepo       repositories.ReportRepository
+	messageRepo      repositories.MessageRepository
+	conversationRepo repositories.ConversationRepository
+}
+
+type ReportUserRequest struct {
+	Category    string `json:"category" binding:"required"`
+	Description string `json:"description" binding:"max=2000"`
+	// MessageIDs cite messages from a conversation between the two users
+	MessageIDs []string `json:"message_ids" binding:"max=20"`
+	// Block also blocks the reported user
+	Block bool `json:"block"`
+}
+
+func NewSafetyController(
+	userRepo repositories.UserRepository,
+	blockRepo repositories.UserBlockRepository,
+	reportRepo repositories.ReportRepository,
+	messageRepo repositories.MessageRepository,
+	conversationRepo repositories.ConversationRepository,
+) *SafetyController {
+	return &SafetyController{
+		userRepo:         userRepo,
+		blockRepo:        blockRepo,
+		reportRepo:       reportRepo,
+		messageRepo:      messageRepo,
+		conversationRepo: conversationRepo,
+	}
+}
+
+// targetUser resolves the :id user, refusing the caller themselves
+func (ctrl *SafetyController) targetUser(c *gin.Context, userID string) (*entities.User, bool) {
+	targetID := c.Param("id")
+	if targetID == userID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "You can't do that to yourself"})
+		return nil, false
+	}
+	target, err := ctrl.userRepo.GetByID(c.Request.Context(), targetID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return nil, false
+	}
+	return target, true
+}
+
+// BlockUser blocks another user. From then on neither sees the other among
+// their matches, neither can message the other, and the pair is left out of
+// every matching run.
+func (ctrl *SafetyController) BlockUser(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	target, ok := ctrl.targetUser(c, userID)
+	if !ok {
+		return
+	}
+
+	block := &entities.UserBlock{BlockerID: userID, BlockedID: target.ID}
+	if err := ctrl.blockRepo.Create(c.Request.Context(), block); err != nil {
+		fmt.Printf("ERROR: Failed to block %s for %s: %v\n", target.ID, userID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to block user"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": block, "message": "User blocked"})
+}
+
+// UnblockUser lifts the user's own block; a block the other user made stays
+func (ctrl *SafetyController) UnblockUser(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	if err := ctrl.blockRepo.Delete(c.Request.Context(), userID, c.Param("id")); err != nil {
+		fmt.Printf("ERROR: Failed to unblock %s for %s: %v\n", c.Param("id"), userID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unblock user"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "User unblocked"})
+}
+
+// GetBlocks lists the users the current user has blocked
+func (ctrl *SafetyController) GetBlocks(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	blocks, err := ctrl.blockRepo.ListByBlocker(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve blocks"})
+		return
+	}
+	if blocks == nil {
+		blocks = []*entities.UserBlock{}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": blocks})
+}
+
+// ReportUser files a report for the moderation queue. Cited messages are
+// copied into the report; they must come from a conversation between the
+// two users.
+func (ctrl *SafetyController) ReportUser(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	var req ReportUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !entities.IsReportCategory(req.Category) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":      "Unknown report category: " + req.Category,
+			"categories": entities.ReportCategories,
+		})
+		return
+	}
+
+	target, ok := ctrl.targetUser(c, userID)
+	if !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+	report := &entities.Report{
+		ReporterID:     userID,
+		ReportedUserID: target.ID,
+		Category:       req.Category,
+		Description:    strings.TrimSpace(req.Description),
+		Evidence:       []entities.ReportEvidence{},
+	}
+	seen := make(map[string]bool)
+	for _, messageID := range req.MessageIDs {
+		if seen[messageID] {
+			continue
+		}
+		seen[messageID] = true
+
+		message, err := ctrl.messageRepo.GetByID(ctx, messageID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Message not found: " + messageID})
+			return
+		}
+		conv, err := ctrl.conversationRepo.GetByID(ctx, message.ConversationID)
+		if err != nil || conv == nil || !isConversationBetween(conv, userID, target.ID) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Message is not from a conversation with this user: " + messageID})
+			return
+		}
+		report.Evidence = append(report.Evidence, entities.ReportEvidence{
+			MessageID:      message.ID,
+			ConversationID: message.ConversationID,
+			SenderID:       message.SenderID,
+			Content:        message.Content,
+			SentAt:         message.CreatedAt,
+		})
+	}
+
+	if err := ctrl.reportRepo.Create(ctx, report); err != nil {
+		fmt.Printf("ERROR: Failed to save report on %s by %s: %v\n", target.ID, userID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to submit report"})
+		return
+	}
+
+	if req.Block {
+		if err := ctrl.blockRepo.Create(ctx, &entities.UserBlock{BlockerID: userID, BlockedID: target.ID}); err != nil {
+			fmt.Printf("ERROR: Failed to block %s for %s after report: %v\n", target.ID, userID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Report submitted, but blocking the user failed"})
+			return
+		}
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"data":    gin.H{"id": report.ID, "status": report.Status, "created_at": report.CreatedAt},
+		"message": "Report submitted",
+	})
+}
+
+func isConversationBetween(conv *entities.Conversation, userID, otherUserID string) bool {
+	return (conv.Participant1 == userID && conv.Participant2 == otherUserID) ||
+		(conv.Participant1 == otherUserID && conv.Participant2 == userID)
+}
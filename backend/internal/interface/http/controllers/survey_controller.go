@@ -1,28 +1,86 @@
 package controllers
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
 	"wizard-connect/internal/domain/entities"
-	"wizard-connect/internal/infrastructure/database"
+	"wizard-connect/internal/domain/repositories"
+	"wizard-connect/internal/domain/services"
 	"wizard-connect/internal/interface/http/middleware"
 
 	"github.com/gin-gonic/gin"
 )
 
 type SurveyController struct {
-	surveyRepo *database.SurveyRepository
+	surveyRepo      repositories.SurveyRepository
+	campaignRepo    repositories.CampaignRepository
+	userRepo        repositories.UserRepository
+	matchingService services.MatchingService
 }
 
-func NewSurveyController(surveyRepo *database.SurveyRepository) *SurveyController {
+func NewSurveyController(surveyRepo repositories.SurveyRepository, campaignRepo repositories.CampaignRepository, userRepo repositories.UserRepository, matchingService services.MatchingService) *SurveyController {
 	return &SurveyController{
-		surveyRepo: surveyRepo,
+		surveyRepo:      surveyRepo,
+		campaignRepo:    campaignRepo,
+		userRepo:        userRepo,
+		matchingService: matchingService,
 	}
 }
 
-// GetSurvey retrieves the user's survey responses
+// surveyScope resolves the campaign and question set new responses belong to.
+// campaignID is empty when no campaign is active.
+func (ctrl *SurveyController) surveyScope(ctx context.Context) (string, *entities.SurveyDefinition, error) {
+	campaign, err := ctrl.campaignRepo.GetActive(ctx)
+	if err != nil {
+		return "", nil, err
+	}
+
+	def, err := services.GetSurveyDefinition(services.CampaignSurveyVersion(campaign))
+	if err != nil {
+		return "", nil, err
+	}
+
+	if campaign == nil {
+		return "", def, nil
+	}
+	return campaign.ID, def, nil
+}
+
+// checkEligibility answers 403 and returns false when the survey's campaign
+// doesn't admit its owner, under the campaign's eligibility rules
+func (ctrl *SurveyController) checkEligibility(c *gin.Context, survey *entities.SurveyResponse) bool {
+	if survey.CampaignID == "" {
+		return true
+	}
+	campaign, err := ctrl.campaignRepo.GetByID(c.Request.Context(), survey.CampaignID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load campaign"})
+		return false
+	}
+	rules := services.CampaignEligibilityRules(campaign)
+	if len(rules.Years) == 0 && len(rules.EmailDomains) == 0 {
+		return true
+	}
+
+	user, err := ctrl.userRepo.GetByID(c.Request.Context(), survey.UserID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load user profile"})
+		return false
+	}
+	if err := rules.Check(&services.Participant{Survey: survey, User: user}); err != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error(), "code": services.CampaignEligibilityErrorCode(err)})
+		return false
+	}
+	return true
+}
+
+// GetSurvey retrieves the user's survey responses for the current campaign
 func (ctrl *SurveyController) GetSurvey(c *gin.Context) {
 	userID, exists := middleware.GetUserID(c)
 	if !exists {
@@ -30,27 +88,95 @@ func (ctrl *SurveyController) GetSurvey(c *gin.Context) {
 		return
 	}
 
-	survey, err := ctrl.surveyRepo.GetByUserID(c.Request.Context(), userID)
+	campaignID, def, err := ctrl.surveyScope(c.Request.Context())
 	if err != nil {
-		// Return empty survey if not found
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to resolve current survey"})
+		return
+	}
+
+	survey, err := ctrl.surveyRepo.GetByUserAndCampaign(c.Request.Context(), userID, campaignID)
+	if err != nil || survey == nil {
+		// Return empty survey if not found, and let the client offer to
+		// pre-fill it from the user's previous campaign
+		previous, _ := ctrl.surveyRepo.GetByUserID(c.Request.Context(), userID)
 		c.JSON(http.StatusOK, gin.H{
 			"data": &entities.SurveyResponse{
-				UserID:     userID,
-				Responses:  make(map[string]interface{}),
-				Interests:  []string{},
-				Values:     []string{},
-				IsComplete: false,
+				UserID:            userID,
+				CampaignID:        campaignID,
+				DefinitionVersion: def.Version,
+				Responses:         make(map[string]interface{}),
+				Interests:         []string{},
+				Values:            []string{},
+				IsComplete:        false,
 			},
+			"prefill_available": previous != nil,
 		})
 		return
 	}
 
+	setSurveyETag(c, survey)
 	c.JSON(http.StatusOK, gin.H{
-		"data": survey,
+		"data":         survey,
+		"completeness": services.Completeness(def, survey.Responses),
 	})
 }
 
-// SubmitSurvey creates or updates survey responses
+// eligiblePool counts who the survey's filters leave the user to be matched
+// with in the current campaign, with a warning when that's too few
+func (ctrl *SurveyController) eligiblePool(ctx context.Context, survey *entities.SurveyResponse) gin.H {
+	campaign, err := ctrl.campaignRepo.GetActive(ctx)
+	if err != nil {
+		return nil
+	}
+
+	pool, err := ctrl.matchingService.EligiblePool(ctx, campaign, survey)
+	if err != nil {
+		fmt.Printf("ERROR: Failed to compute eligible pool for %s: %v\n", survey.UserID, err)
+		return nil
+	}
+
+	result := gin.H{"size": pool}
+	minPool := services.CampaignMatchingConfig(campaign).Constraints.MinPoolSize
+	if pool < minPool {
+		result["warning"] = fmt.Sprintf("Your filters leave only %d possible matches. Consider loosening your dealbreakers or year/major filters.", pool)
+	}
+	return result
+}
+
+// setSurveyETag exposes the survey revision so clients can send it back in If-Match
+func setSurveyETag(c *gin.Context, survey *entities.SurveyResponse) {
+	c.Header("ETag", fmt.Sprintf(`"%d"`, survey.Revision))
+}
+
+// expectedRevision reads the revision the client last saw, from If-Match or
+// the request body. It reports false when the client sent neither.
+func expectedRevision(c *gin.Context, bodyRevision *int) (int, bool, error) {
+	if ifMatch := c.GetHeader("If-Match"); ifMatch != "" {
+		tag := strings.Trim(strings.TrimPrefix(ifMatch, "W/"), `"`)
+		revision, err := strconv.Atoi(tag)
+		if err != nil {
+			return 0, false, fmt.Errorf("invalid If-Match header: %s", ifMatch)
+		}
+		return revision, true, nil
+	}
+	if bodyRevision != nil {
+		return *bodyRevision, true, nil
+	}
+	return 0, false, nil
+}
+
+// GetSurveyDefinition returns the question set for the current campaign
+func (ctrl *SurveyController) GetSurveyDefinition(c *gin.Context) {
+	_, def, err := ctrl.surveyScope(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to resolve current survey"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": def})
+}
+
+// SubmitSurvey creates or updates survey responses for the current campaign
 func (ctrl *SurveyController) SubmitSurvey(c *gin.Context) {
 	userID, exists := middleware.GetUserID(c)
 	if !exists {
@@ -65,6 +191,7 @@ func (ctrl *SurveyController) SubmitSurvey(c *gin.Context) {
 		Values          []string               `json:"values"`
 		Lifestyle       string                 `json:"lifestyle"`
 		IsComplete      bool                   `json:"is_complete"`
+		Revision        *int                   `json:"revision"`
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -72,43 +199,412 @@ func (ctrl *SurveyController) SubmitSurvey(c *gin.Context) {
 		return
 	}
 
+	expected, conditional, err := expectedRevision(c, req.Revision)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	campaignID, def, err := ctrl.surveyScope(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to resolve current survey"})
+		return
+	}
+
 	// Log survey submission for debugging
-	fmt.Printf("Survey submission - UserID: %s, IsComplete: %v, PersonalityType: %s, Interests: %v, Values: %v\n",
-		userID, req.IsComplete, req.PersonalityType, req.Interests, req.Values)
+	fmt.Printf("Survey submission - UserID: %s, Campaign: %s, Version: %s, IsComplete: %v, Interests: %v, Values: %v\n",
+		userID, campaignID, def.Version, req.IsComplete, req.Interests, req.Values)
 
 	now := time.Now()
 
+	if req.Responses != nil {
+		services.CleanPreferences(def, req.Responses)
+	}
+
+	// The client may ask to complete the survey, but it only counts as
+	// complete once every required question validates
+	completeness := services.Completeness(def, req.Responses)
+
 	survey := &entities.SurveyResponse{
-		UserID:          userID,
-		Responses:       req.Responses,
-		PersonalityType: req.PersonalityType,
-		Interests:       req.Interests,
-		Values:          req.Values,
-		Lifestyle:       req.Lifestyle,
-		IsComplete:      req.IsComplete,
+		UserID:            userID,
+		CampaignID:        campaignID,
+		DefinitionVersion: def.Version,
+		Responses:         req.Responses,
+		PersonalityType:   req.PersonalityType,
+		Interests:         req.Interests,
+		Values:            req.Values,
+		Lifestyle:         req.Lifestyle,
+		IsComplete:        req.IsComplete && completeness.Complete,
 	}
 
-	if req.IsComplete {
+	if survey.IsComplete {
 		survey.CompletedAt = now
 	}
 
-	// Check if survey exists
-	existing, err := ctrl.surveyRepo.GetByUserID(c.Request.Context(), userID)
-	if err == nil && existing != nil {
+	// Personality is derived from the raw answers once every axis is
+	// answered; until then the client-declared type is kept
+	if traits, ok := services.DeriveTraits(req.Responses); ok {
+		survey.PersonalityTraits = traits.Slice()
+		survey.PersonalityType = traits.Type()
+	}
+
+	// Only people the campaign admits may complete its survey
+	if survey.IsComplete && !ctrl.checkEligibility(c, survey) {
+		return
+	}
+
+	// A submit replaces the whole survey, so it is held to the same revision
+	// check as autosaves: a stale tab mustn't overwrite newer answers
+	existing, err := ctrl.surveyRepo.GetByUserAndCampaign(c.Request.Context(), userID, campaignID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load survey"})
+		return
+	}
+	current := 0
+	if existing != nil {
 		survey.ID = existing.ID
-		survey.CreatedAt = existing.CreatedAt
+		current = existing.Revision
+	}
+	if conditional && expected != current {
+		if existing != nil {
+			setSurveyETag(c, existing)
+		}
+		c.JSON(http.StatusConflict, gin.H{
+			"error": "Survey was changed elsewhere, reload before saving",
+			"data":  existing,
+		})
+		return
+	}
+
+	if err := ctrl.surveyRepo.SaveRevision(c.Request.Context(), survey, current); err != nil {
+		if errors.Is(err, repositories.ErrRevisionConflict) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Survey was changed elsewhere, reload before saving"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save survey: " + err.Error()})
+		return
+	}
+
+	setSurveyETag(c, survey)
+
+	// Answers are kept as a draft, but completion is refused
+	if req.IsComplete && !survey.IsComplete {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"error":        "Survey has missing or invalid required answers",
+			"data":         survey,
+			"completeness": completeness,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":          survey,
+		"completeness":  completeness,
+		"eligible_pool": ctrl.eligiblePool(c.Request.Context(), survey),
+		"message":       "Survey saved successfully",
+	})
+}
+
+// SaveSurveySection autosaves the answers for one section of the current
+// campaign's survey, along with how much each question matters to the user
+// and which answers they won't accept in a match. Sending null clears an
+// answer or rating. Clients pass the revision they last saw (If-Match or
+// "revision") to detect concurrent edits; completion is decided by the server.
+func (ctrl *SurveyController) SaveSurveySection(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	var req struct {
+		Responses    map[string]interface{}       `json:"responses"`
+		Importance   map[string]*int              `json:"importance"`
+		Dealbreakers map[string][]string          `json:"dealbreakers"`
+		Constraints  *services.ProfileConstraints `json:"constraints"`
+		Revision     *int                         `json:"revision"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+
+	expected, conditional, err := expectedRevision(c, req.Revision)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	campaignID, def, err := ctrl.surveyScope(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to resolve current survey"})
+		return
+	}
+
+	section := c.Param("section")
+	fieldErrors, err := services.ValidateSectionAnswers(def, section, req.Responses)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	for id, msg := range services.ValidateSectionPreferences(def, section, req.Importance, req.Dealbreakers) {
+		fieldErrors[id] = msg
+	}
+	if err := services.ValidateConstraints(req.Constraints); err != nil {
+		fieldErrors[services.ConstraintsKey] = err.Error()
+	}
+	if len(fieldErrors) > 0 {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "Invalid answers", "fields": fieldErrors})
+		return
+	}
+
+	survey, err := ctrl.surveyRepo.GetByUserAndCampaign(c.Request.Context(), userID, campaignID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load survey"})
+		return
+	}
+	if survey == nil {
+		survey = &entities.SurveyResponse{
+			UserID:     userID,
+			CampaignID: campaignID,
+			Responses:  make(map[string]interface{}),
+		}
+	}
+	if survey.Responses == nil {
+		survey.Responses = make(map[string]interface{})
+	}
+
+	if conditional && expected != survey.Revision {
+		setSurveyETag(c, survey)
+		c.JSON(http.StatusConflict, gin.H{
+			"error": "Survey was changed elsewhere, reload before saving",
+			"data":  survey,
+		})
+		return
+	}
+
+	for id, value := range req.Responses {
+		if value == nil {
+			delete(survey.Responses, id)
+			continue
+		}
+		survey.Responses[id] = value
+	}
+	services.ApplyPreferences(survey.Responses, req.Importance, req.Dealbreakers)
+	if req.Constraints != nil {
+		services.ApplyConstraints(survey.Responses, *req.Constraints)
+	}
+
+	survey.DefinitionVersion = def.Version
+	survey.Interests, survey.Values, survey.Lifestyle = services.SummarizeResponses(survey.Responses)
+	if traits, ok := services.DeriveTraits(survey.Responses); ok {
+		survey.PersonalityTraits = traits.Slice()
+		survey.PersonalityType = traits.Type()
 	} else {
-		survey.CreatedAt = now
+		survey.PersonalityTraits = nil
 	}
-	survey.UpdatedAt = now
 
-	if err := ctrl.surveyRepo.CreateOrUpdate(c.Request.Context(), survey); err != nil {
+	completeness := services.Completeness(def, survey.Responses)
+	if completeness.Complete && !survey.IsComplete {
+		survey.CompletedAt = time.Now()
+	}
+	survey.IsComplete = completeness.Complete
+	if survey.IsComplete && !ctrl.checkEligibility(c, survey) {
+		return
+	}
+
+	if err := ctrl.surveyRepo.SaveRevision(c.Request.Context(), survey, survey.Revision); err != nil {
+		if errors.Is(err, repositories.ErrRevisionConflict) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Survey was changed elsewhere, reload before saving"})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save survey: " + err.Error()})
 		return
 	}
 
+	setSurveyETag(c, survey)
+	response := gin.H{
+		"data":         survey,
+		"completeness": completeness,
+		"message":      "Section saved",
+	}
+	// Only check the pool when the save touched the filters
+	if req.Constraints != nil || len(req.Dealbreakers) > 0 || section == "demographics" {
+		response["eligible_pool"] = ctrl.eligiblePool(c.Request.Context(), survey)
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// GetEligiblePool reports how many people the user's current answers and
+// filters leave them to be matched with
+func (ctrl *SurveyController) GetEligiblePool(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	campaignID, _, err := ctrl.surveyScope(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to resolve current survey"})
+		return
+	}
+
+	survey, err := ctrl.surveyRepo.GetByUserAndCampaign(c.Request.Context(), userID, campaignID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load survey"})
+		return
+	}
+	if survey == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Survey not found"})
+		return
+	}
+
+	pool := ctrl.eligiblePool(c.Request.Context(), survey)
+	if pool == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute eligible pool"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": pool})
+}
+
+// GetSurveyPrefill builds an unsaved draft for the current campaign from the
+// user's most recent survey in another campaign
+func (ctrl *SurveyController) GetSurveyPrefill(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	campaignID, def, err := ctrl.surveyScope(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to resolve current survey"})
+		return
+	}
+
+	history, err := ctrl.surveyRepo.ListByUserID(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve survey history"})
+		return
+	}
+
+	var previous *entities.SurveyResponse
+	for _, survey := range history {
+		if survey.CampaignID != campaignID {
+			previous = survey
+			break
+		}
+	}
+	if previous == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "No previous survey to pre-fill from"})
+		return
+	}
+
+	responses := services.PrefillResponses(def, previous.Responses)
+
+	// Answers that no longer fit the current question set need re-answering
+	dropped := []string{}
+	for id := range previous.Responses {
+		if _, kept := responses[id]; !kept {
+			dropped = append(dropped, id)
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": &entities.SurveyResponse{
+			UserID:            userID,
+			CampaignID:        campaignID,
+			DefinitionVersion: def.Version,
+			Responses:         responses,
+			Interests:         previous.Interests,
+			Values:            previous.Values,
+			Lifestyle:         previous.Lifestyle,
+			IsComplete:        false,
+		},
+		"prefilled_from": gin.H{
+			"survey_id":          previous.ID,
+			"campaign_id":        previous.CampaignID,
+			"definition_version": previous.DefinitionVersion,
+		},
+		"dropped_questions": dropped,
+	})
+}
+
+// GetSurveyHistory lists every survey the user has taken across campaigns
+func (ctrl *SurveyController) GetSurveyHistory(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	history, err := ctrl.surveyRepo.ListByUserID(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve survey history"})
+		return
+	}
+	if history == nil {
+		history = []*entities.SurveyResponse{}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": history})
+}
+
+// DiffSurveys compares two of the user's surveys. Without ?from and ?to it
+// compares their two most recent surveys.
+func (ctrl *SurveyController) DiffSurveys(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	history, err := ctrl.surveyRepo.ListByUserID(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve survey history"})
+		return
+	}
+
+	find := func(id string) *entities.SurveyResponse {
+		for _, survey := range history {
+			if survey.ID == id {
+				return survey
+			}
+		}
+		return nil
+	}
+
+	var from, to *entities.SurveyResponse
+	fromID, toID := c.Query("from"), c.Query("to")
+	if fromID == "" && toID == "" {
+		if len(history) < 2 {
+			c.JSON(http.StatusNotFound, gin.H{"error": "At least two surveys are needed to compare"})
+			return
+		}
+		from, to = history[1], history[0]
+	} else {
+		from, to = find(fromID), find(toID)
+	}
+
+	if from == nil || to == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Survey not found"})
+		return
+	}
+
+	summary := func(s *entities.SurveyResponse) gin.H {
+		return gin.H{
+			"survey_id":          s.ID,
+			"campaign_id":        s.CampaignID,
+			"definition_version": s.DefinitionVersion,
+			"updated_at":         s.UpdatedAt,
+		}
+	}
+
 	c.JSON(http.StatusOK, gin.H{
-		"data":    survey,
-		"message": "Survey saved successfully",
+		"from":    summary(from),
+		"to":      summary(to),
+		"changes": services.DiffResponses(from.Responses, to.Responses),
 	})
 }
@@ -1,27 +1,46 @@
 package controllers
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
 
 	"wizard-connect/internal/domain/entities"
-	"wizard-connect/internal/infrastructure/database"
+	"wizard-connect/internal/domain/repositories"
+	"wizard-connect/internal/domain/services"
 	"wizard-connect/internal/interface/http/middleware"
 
 	"github.com/gin-gonic/gin"
 )
 
 type UserController struct {
-	userRepo *database.UserRepository
+	userRepo            repositories.UserRepository
+	contentService      *services.ContentService
+	profileService      *services.ProfileService
+	avatarService       *services.AvatarService
+	registrationService *services.RegistrationService
 }
 
-func NewUserController(userRepo *database.UserRepository) *UserController {
+func NewUserController(
+	userRepo repositories.UserRepository,
+	contentService *services.ContentService,
+	profileService *services.ProfileService,
+	avatarService *services.AvatarService,
+	registrationService *services.RegistrationService,
+) *UserController {
 	return &UserController{
-		userRepo: userRepo,
+		userRepo:            userRepo,
+		contentService:      contentService,
+		profileService:      profileService,
+		avatarService:       avatarService,
+		registrationService: registrationService,
 	}
 }
 
-// GetProfile returns the current user's profile
+// GetProfile returns the current user's profile, creating it on their first
+// sign-in if their email may register
 func (ctrl *UserController) GetProfile(c *gin.Context) {
 	userID, exists := middleware.GetUserID(c)
 	if !exists {
@@ -33,38 +52,29 @@ func (ctrl *UserController) GetProfile(c *gin.Context) {
 
 	fmt.Printf("DEBUG: Fetching profile for userID=%s, email=%s\n", userID, email)
 
-	user, err := ctrl.userRepo.GetByID(c.Request.Context(), userID)
+	user, err := ctrl.registrationService.EnsureUser(c.Request.Context(), userID, email)
 	if err != nil {
-		fmt.Printf("DEBUG: User not found in database, creating shell user. Error: %v\n", err)
-
-		// Auto-Create shell if user exists in Auth but not in our public table
-		newUser := &entities.User{
-			ID:               userID,
-			Email:            email,
-			ContactPref:      "email",
-			Visibility:       "matches_only",
-			Gender:           "prefer_not_to_say",
-			GenderPreference: "both",
-		}
-
-		createErr := ctrl.userRepo.Create(c.Request.Context(), newUser)
-		if createErr != nil {
-			fmt.Printf("ERROR: Failed to create shell user: %v\n", createErr)
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create user profile"})
+		if code := services.RegistrationErrorCode(err); code != "" {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error(), "code": code})
 			return
 		}
-
-		fmt.Printf("DEBUG: Shell user created successfully: %+v\n", newUser)
-		user = newUser
-	} else {
-		fmt.Printf("DEBUG: User found in database: %+v\n", user)
+		fmt.Printf("ERROR: Failed to load or create profile: %v\n", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create user profile"})
+		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{"data": user})
 }
 
-// GetUserProfileByID returns a specific user's public profile
+// GetUserProfileByID returns a user's profile as the current user may see
+// it: the owner view, the matched view or the redacted public view
 func (ctrl *UserController) GetUserProfileByID(c *gin.Context) {
+	viewerID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
 	id := c.Param("id")
 	if id == "" {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "User ID is required"})
@@ -73,22 +83,19 @@ func (ctrl *UserController) GetUserProfileByID(c *gin.Context) {
 
 	fmt.Printf("DEBUG: Fetching profile for user ID: %s\n", id)
 
-	user, err := ctrl.userRepo.GetByID(c.Request.Context(), id)
+	// Hidden profiles look the same as missing ones
+	profile, err := ctrl.profileService.Profile(c.Request.Context(), viewerID, id)
 	if err != nil {
-		fmt.Printf("DEBUG: Failed to fetch user profile for ID %s: %v\n", id, err)
+		fmt.Printf("DEBUG: Profile %s not available to %s: %v\n", id, viewerID, err)
 		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
 		return
 	}
 
-	fmt.Printf("DEBUG: Successfully fetched user profile for ID %s: %+v\n", id, user)
-
-	// In a real app, you would strip private fields here
-	// For this app, we'll return the full profile since visibility
-	// is managed at the matching layer
-	c.JSON(http.StatusOK, gin.H{"data": user})
+	c.JSON(http.StatusOK, gin.H{"data": profile})
 }
 
-// UpdateProfile updates the current user's profile
+// UpdateProfile changes the fields sent and leaves the rest alone; a field
+// sent as null is cleared. Invalid values answer 422 with an error per field.
 func (ctrl *UserController) UpdateProfile(c *gin.Context) {
 	userID, exists := middleware.GetUserID(c)
 	email, _ := middleware.GetUserEmail(c)
@@ -97,28 +104,63 @@ func (ctrl *UserController) UpdateProfile(c *gin.Context) {
 		return
 	}
 
-	var req struct {
-		FirstName         *string `json:"first_name" alias:"firstName"`
-		LastName          *string `json:"last_name" alias:"lastName"`
-		Bio               *string `json:"bio"`
-		Instagram         *string `json:"instagram"`
-		Phone             *string `json:"phone"`
-		AvatarURL         *string `json:"avatar_url" alias:"avatarUrl"`
-		ContactPreference *string `json:"contact_preference" alias:"contactPreference"`
-		Visibility        *string `json:"visibility"`
-		Year              *string `json:"year"`
-		Major             *string `json:"major"`
-		Gender            *string `json:"gender"`
-		GenderPreference  *string `json:"gender_preference" alias:"genderPreference"`
+	var raw map[string]json.RawMessage
+	if err := c.ShouldBindJSON(&raw); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data", "details": err.Error()})
+		return
 	}
 
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data", "details": err.Error()})
+	patch, fieldErrors := services.ParseProfilePatch(raw)
+	for field, message := range patch.Validate() {
+		fieldErrors[field] = message
+	}
+	// Avatars are set by uploading one; a URL can only pick an upload again
+	if value := patch["avatar_url"]; value != nil && !ctrl.avatarService.IsAvatarURL(userID, *value) {
+		fieldErrors["avatar_url"] = "must be an avatar uploaded with POST /api/v1/users/me/avatar"
+	}
+	if len(fieldErrors) > 0 {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"error":  "Some profile fields are invalid",
+			"code":   "invalid_profile",
+			"fields": fieldErrors,
+		})
 		return
 	}
 
 	// Log full request body for debugging
-	fmt.Printf("DEBUG: Full update request: %+v\n", req)
+	fmt.Printf("DEBUG: Full update request: %s\n", raw)
+
+	// Free text goes through the content policy; masked text replaces what
+	// was sent and flagged text is queued once it is saved
+	var flagged []services.ContentVerdict
+	for _, field := range []string{
+		services.ContentFieldFirstName,
+		services.ContentFieldLastName,
+		services.ContentFieldBio,
+		services.ContentFieldMajor,
+	} {
+		value := patch[field]
+		if value == nil {
+			continue
+		}
+		verdict := ctrl.contentService.Review(field, *value)
+		if verdict.Rejected() {
+			fieldErrors[field] = verdict.Reason()
+			continue
+		}
+		*value = verdict.Text
+		if verdict.Flagged() {
+			flagged = append(flagged, verdict)
+		}
+	}
+	if len(fieldErrors) > 0 {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"error":  "Profile text breaks the content policy",
+			"code":   "content_rejected",
+			"fields": fieldErrors,
+		})
+		return
+	}
 
 	fmt.Printf("DEBUG: Updating profile for userID=%s, email=%s\n", userID, email)
 
@@ -144,45 +186,17 @@ func (ctrl *UserController) UpdateProfile(c *gin.Context) {
 		fmt.Printf("DEBUG: Shell user created successfully: %+v\n", user)
 	}
 
-	if req.FirstName != nil && *req.FirstName != "" {
-		user.FirstName = *req.FirstName
-	}
-	if req.LastName != nil && *req.LastName != "" {
-		user.LastName = *req.LastName
-	}
-	if req.Bio != nil && *req.Bio != "" {
-		user.Bio = *req.Bio
-	}
-	if req.Instagram != nil && *req.Instagram != "" {
-		user.Instagram = *req.Instagram
-	}
-	if req.Phone != nil && *req.Phone != "" {
-		user.Phone = *req.Phone
-	}
-	if req.AvatarURL != nil && *req.AvatarURL != "" {
-		user.AvatarURL = *req.AvatarURL
-	}
-	if req.ContactPreference != nil && *req.ContactPreference != "" {
-		user.ContactPref = *req.ContactPreference
-	}
-	if req.Visibility != nil && *req.Visibility != "" {
-		user.Visibility = *req.Visibility
-	}
-	if req.Year != nil && *req.Year != "" {
-		user.Year = *req.Year
-	}
-	if req.Major != nil && *req.Major != "" {
-		user.Major = *req.Major
-	}
-	if req.Gender != nil && *req.Gender != "" {
-		user.Gender = *req.Gender
-	}
-	if req.GenderPreference != nil && *req.GenderPreference != "" {
-		user.GenderPreference = *req.GenderPreference
+	cleared := patch.Apply(user)
+	if message := services.ContactPreferenceError(user); message != "" {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"error":  "Some profile fields are invalid",
+			"code":   "invalid_profile",
+			"fields": map[string]string{"contact_preference": message},
+		})
+		return
 	}
 
 	fmt.Printf("DEBUG: Updating user with data: %+v\n", user)
-	fmt.Printf("DEBUG: AvatarURL received: %v\n", req.AvatarURL)
 
 	if err := ctrl.userRepo.Update(c.Request.Context(), user); err != nil {
 		fmt.Printf("DATABASE UPDATE ERROR: %v\n", err)
@@ -193,7 +207,94 @@ func (ctrl *UserController) UpdateProfile(c *gin.Context) {
 		return
 	}
 
+	// Update leaves blank fields alone, so nulls are cleared separately
+	if err := ctrl.userRepo.ClearFields(c.Request.Context(), userID, cleared...); err != nil {
+		fmt.Printf("DATABASE UPDATE ERROR: %v\n", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to update profile",
+			"details": err.Error(),
+		})
+		return
+	}
+
 	fmt.Printf("DEBUG: User profile updated successfully\n")
 
+	for _, verdict := range flagged {
+		// Flags outside the bio point at the user rather than one item
+		contentType, contentID := "", ""
+		if verdict.Field == services.ContentFieldBio {
+			contentType, contentID = entities.ContentTypeBio, userID
+		}
+		if err := ctrl.contentService.FlagForReview(c.Request.Context(), userID, contentType, contentID, verdict); err != nil {
+			fmt.Printf("ERROR: Failed to flag %s of user %s for review: %v\n", verdict.Field, userID, err)
+		}
+	}
+
 	c.JSON(http.StatusOK, gin.H{"data": user, "message": "Profile updated successfully"})
 }
+
+// UploadAvatar takes an image in the "avatar" field of a multipart form,
+// stores it with its thumbnails and makes it the user's avatar
+func (ctrl *UserController) UploadAvatar(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	user, err := ctrl.userRepo.GetByID(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	// Leave room for the multipart envelope around the file
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, services.MaxAvatarBytes+64<<10)
+	file, header, err := c.Request.FormFile("avatar")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": services.ErrAvatarTooLarge.Error()})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Send the image as the avatar field of a multipart form"})
+		return
+	}
+	defer file.Close()
+
+	if header.Size > services.MaxAvatarBytes {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": services.ErrAvatarTooLarge.Error()})
+		return
+	}
+	data, err := io.ReadAll(io.LimitReader(file, services.MaxAvatarBytes+1))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read upload"})
+		return
+	}
+
+	avatar, err := ctrl.avatarService.Upload(c.Request.Context(), userID, data)
+	switch {
+	case errors.Is(err, services.ErrAvatarTooLarge):
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, services.ErrAvatarType):
+		c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, services.ErrAvatarSize):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		fmt.Printf("ERROR: Failed to store avatar for user %s: %v\n", userID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to store avatar"})
+		return
+	}
+
+	user.AvatarURL = avatar.URL
+	if err := ctrl.userRepo.Update(c.Request.Context(), user); err != nil {
+		fmt.Printf("DATABASE UPDATE ERROR: %v\n", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update profile"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": avatar, "message": "Avatar updated successfully"})
+}
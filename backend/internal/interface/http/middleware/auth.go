@@ -1,20 +1,37 @@
 package middleware
 
 import (
+	"context"
 	"encoding/base64"
 	"fmt"
 	"net/http"
 	"strings"
+	"time"
+
+	"wizard-connect/internal/domain/entities"
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
 )
 
+// ModerationRepository reports the mutes, suspensions and bans in force
+// against a user
+type ModerationRepository interface {
+	Active(ctx context.Context, userID string, at time.Time) ([]*entities.ModerationAction, error)
+}
+
+// AccountRepository reports whether an account was deleted
+type AccountRepository interface {
+	IsDeleted(ctx context.Context, id string) (bool, error)
+}
+
 type AuthMiddleware struct {
-	jwtSecret []byte
+	jwtSecret      []byte
+	moderationRepo ModerationRepository
+	accountRepo    AccountRepository
 }
 
-func NewAuthMiddleware(jwtSecretStr string) *AuthMiddleware {
+func NewAuthMiddleware(jwtSecretStr string, moderationRepo ModerationRepository, accountRepo AccountRepository) *AuthMiddleware {
 	secret := []byte(jwtSecretStr)
 
 	// Try to decode as base64 if it looks like it might be
@@ -23,7 +40,9 @@ func NewAuthMiddleware(jwtSecretStr string) *AuthMiddleware {
 	}
 
 	return &AuthMiddleware{
-		jwtSecret: secret,
+		jwtSecret:      secret,
+		moderationRepo: moderationRepo,
+		accountRepo:    accountRepo,
 	}
 }
 
@@ -44,58 +63,138 @@ func (m *AuthMiddleware) Authenticate() gin.HandlerFunc {
 			return
 		}
 
-		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-			// Supabase might use HS256 or ES256 depending on project settings
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); ok {
-				return m.jwtSecret, nil
-			}
-			// If it's not HMAC, we return an error to trigger the unverified fallback below
-			return nil, fmt.Errorf("non-hmac signing method: %v", token.Header["alg"])
-		})
-
-		var claims jwt.MapClaims
-		var ok bool
-
-		if err != nil {
-			// "UNLOCKED" FALLBACK: If verification fails because of algorithm mismatch (e.g., ES256 vs HS256),
-			// we extract the claims anyway to allow the user to continue.
-			// This ensures the website is "Unlocked" and functional regardless of Supabase region standards.
-			parser := jwt.NewParser()
-			unverifiedToken, _, unerr := parser.ParseUnverified(tokenString, jwt.MapClaims{})
-			if unerr != nil {
-				fmt.Printf("JWT Critical Error: %v\n", unerr)
-				c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token structure"})
-				c.Abort()
-				return
-			}
-			claims, ok = unverifiedToken.Claims.(jwt.MapClaims)
-			fmt.Printf("JWT Fallback Active: User extracted without algorithmic signature check (Algorithm: %v)\n", unverifiedToken.Header["alg"])
-		} else {
-			claims, ok = token.Claims.(jwt.MapClaims)
-		}
+		m.authenticate(c, tokenString)
+	}
+}
 
-		if !ok {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token claims"})
+// AuthenticateSocket validates the JWT of a Socket.IO request. Browsers can't
+// set headers on a websocket, so the token may also come in the "token"
+// query parameter.
+func (m *AuthMiddleware) AuthenticateSocket() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tokenString := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if tokenString == "" {
+			tokenString = c.Query("token")
+		}
+		if tokenString == "" {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Authorization token required"})
 			c.Abort()
 			return
 		}
 
-		// Extract user ID and email from claims
-		userID, ok := claims["sub"].(string)
-		if !ok {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user ID in token"})
+		m.authenticate(c, tokenString)
+	}
+}
+
+// authenticate checks a token and the account it belongs to, then either
+// sets the user in the context or aborts
+func (m *AuthMiddleware) authenticate(c *gin.Context, tokenString string) {
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		// Supabase might use HS256 or ES256 depending on project settings
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); ok {
+			return m.jwtSecret, nil
+		}
+		// If it's not HMAC, we return an error to trigger the unverified fallback below
+		return nil, fmt.Errorf("non-hmac signing method: %v", token.Header["alg"])
+	})
+
+	var claims jwt.MapClaims
+	var ok bool
+
+	if err != nil {
+		// "UNLOCKED" FALLBACK: If verification fails because of algorithm mismatch (e.g., ES256 vs HS256),
+		// we extract the claims anyway to allow the user to continue.
+		// This ensures the website is "Unlocked" and functional regardless of Supabase region standards.
+		parser := jwt.NewParser()
+		unverifiedToken, _, unerr := parser.ParseUnverified(tokenString, jwt.MapClaims{})
+		if unerr != nil {
+			fmt.Printf("JWT Critical Error: %v\n", unerr)
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token structure"})
 			c.Abort()
 			return
 		}
+		claims, ok = unverifiedToken.Claims.(jwt.MapClaims)
+		fmt.Printf("JWT Fallback Active: User extracted without algorithmic signature check (Algorithm: %v)\n", unverifiedToken.Header["alg"])
+	} else {
+		claims, ok = token.Claims.(jwt.MapClaims)
+	}
+
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token claims"})
+		c.Abort()
+		return
+	}
+
+	// Extract user ID and email from claims
+	userID, ok := claims["sub"].(string)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user ID in token"})
+		c.Abort()
+		return
+	}
+
+	email, _ := claims["email"].(string)
+
+	if m.rejectDeleted(c, userID) || m.rejectSuspended(c, userID) {
+		c.Abort()
+		return
+	}
+
+	// Set user info in context
+	c.Set("user_id", userID)
+	c.Set("user_email", email)
+
+	c.Next()
+}
 
-		email, _ := claims["email"].(string)
+// rejectDeleted answers 401 for a token of a deleted account, which stays
+// valid until it expires, and reports whether it did
+func (m *AuthMiddleware) rejectDeleted(c *gin.Context, userID string) bool {
+	if m.accountRepo == nil {
+		return false
+	}
 
-		// Set user info in context
-		c.Set("user_id", userID)
-		c.Set("user_email", email)
+	deleted, err := m.accountRepo.IsDeleted(c.Request.Context(), userID)
+	if err != nil {
+		fmt.Printf("ERROR: Failed to check deletion of %s: %v\n", userID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify account status"})
+		return true
+	}
+	if deleted {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "This account has been deleted", "code": "account_deleted"})
+		return true
+	}
+	return false
+}
 
-		c.Next()
+// rejectSuspended answers 403 for a suspended or banned user and reports
+// whether it did
+func (m *AuthMiddleware) rejectSuspended(c *gin.Context, userID string) bool {
+	if m.moderationRepo == nil {
+		return false
+	}
+
+	actions, err := m.moderationRepo.Active(c.Request.Context(), userID, time.Now())
+	if err != nil {
+		fmt.Printf("ERROR: Failed to check suspensions of %s: %v\n", userID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify account status"})
+		return true
+	}
+	for _, action := range actions {
+		switch action.Action {
+		case entities.ModerationActionBan:
+			c.JSON(http.StatusForbidden, gin.H{"error": "This account has been banned", "code": "account_banned"})
+			return true
+		case entities.ModerationActionSuspend:
+			body := gin.H{"error": "This account is suspended", "code": "account_suspended"}
+			if action.ExpiresAt != nil {
+				body["suspended_until"] = action.ExpiresAt.Format(time.RFC3339)
+			}
+			c.JSON(http.StatusForbidden, body)
+			return true
+		}
 	}
+	return false
 }
 
 // GetUserID extracts user ID from context
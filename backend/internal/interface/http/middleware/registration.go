@@ -0,0 +1,57 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"wizard-connect/internal/domain/entities"
+	"wizard-connect/internal/domain/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Registrar returns the user's profile, creating it on their first sign-in
+// if they may register
+type Registrar interface {
+	EnsureUser(ctx context.Context, userID, email string) (*entities.User, error)
+}
+
+type RegistrationMiddleware struct {
+	registrar Registrar
+}
+
+func NewRegistrationMiddleware(registrar Registrar) *RegistrationMiddleware {
+	return &RegistrationMiddleware{
+		registrar: registrar,
+	}
+}
+
+// RequireRegistration lets through users with a profile, registering those
+// signing in for the first time. Addresses outside the allowed domains get
+// 403 with a code saying why.
+func (m *RegistrationMiddleware) RequireRegistration() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, exists := GetUserID(c)
+		if !exists {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+			c.Abort()
+			return
+		}
+		email, _ := GetUserEmail(c)
+
+		if _, err := m.registrar.EnsureUser(c.Request.Context(), userID, email); err != nil {
+			if code := services.RegistrationErrorCode(err); code != "" {
+				c.JSON(http.StatusForbidden, gin.H{"error": err.Error(), "code": code})
+				c.Abort()
+				return
+			}
+			fmt.Printf("ERROR: Failed to register %s: %v\n", userID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load user profile"})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,127 @@
+package routes_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"net/http"
+	"testing"
+
+	"wizard-connect/internal/interface/http/harness"
+)
+
+// accountWithData is a user with a survey, an unlocked match they have
+// messaged, a block and a report
+func accountWithData(t *testing.T, h *harness.Harness) (ada, bea testUser) {
+	t.Helper()
+	ada, bea = newUser(t, h, "ada"), newUser(t, h, "bea")
+	eve := newUser(t, h, "eve")
+	submitSurvey(t, h, ada, completeAnswers(t, nil))
+	unlockedPair(t, h, ada, bea)
+	sendMessage(t, h, ada, bea, "Hi Bea!")
+	sendMessage(t, h, bea, ada, "Hi Ada!")
+	rec := h.Do("POST", "/api/v1/users/"+eve.ID+"/report", map[string]interface{}{"category": "spam", "block": true}, ada.Token)
+	expectStatus(t, rec, http.StatusCreated)
+	return ada, bea
+}
+
+func TestExportAccount(t *testing.T) {
+	h := harness.New()
+	ada, _ := accountWithData(t, h)
+
+	rec := h.Do("GET", "/api/v1/users/me/export", nil, ada.Token)
+	expectStatus(t, rec, http.StatusOK)
+	export, _ := decode(t, rec)["data"].(map[string]interface{})
+	if str(export, "profile", "email") != ada.Email {
+		t.Errorf("profile = %v", export["profile"])
+	}
+	for key, want := range map[string]int{"surveys": 1, "matches": 1, "conversations": 1, "blocks": 1, "reports": 1} {
+		if got, _ := export[key].([]interface{}); len(got) != want {
+			t.Errorf("%s = %v, want %d entries", key, export[key], want)
+		}
+	}
+	conversations, _ := export["conversations"].([]interface{})
+	if len(conversations) == 1 {
+		if messages, _ := conversations[0].(map[string]interface{})["messages"].([]interface{}); len(messages) != 2 {
+			t.Errorf("conversation messages = %v, want both sides", messages)
+		}
+	}
+
+	expectStatus(t, h.Do("GET", "/api/v1/users/me/export?format=xml", nil, ada.Token), http.StatusBadRequest)
+}
+
+func TestExportAccountZip(t *testing.T) {
+	h := harness.New()
+	ada, _ := accountWithData(t, h)
+
+	rec := h.Do("GET", "/api/v1/users/me/export?format=zip", nil, ada.Token)
+	expectStatus(t, rec, http.StatusOK)
+	if got := rec.Header().Get("Content-Type"); got != "application/zip" {
+		t.Errorf("Content-Type = %s", got)
+	}
+	if got := rec.Header().Get("Content-Disposition"); got == "" {
+		t.Error("no Content-Disposition")
+	}
+
+	archive, err := zip.NewReader(bytes.NewReader(rec.Body.Bytes()), int64(rec.Body.Len()))
+	if err != nil {
+		t.Fatalf("not a zip archive: %v", err)
+	}
+	files := make(map[string]bool)
+	for _, f := range archive.File {
+		files[f.Name] = true
+	}
+	for _, name := range []string{"profile.json", "surveys.json", "matches.json", "conversations.json"} {
+		if !files[name] {
+			t.Errorf("archive has no %s: %v", name, files)
+		}
+	}
+}
+
+func TestDeleteAccount(t *testing.T) {
+	h := harness.New()
+	admin := newAdmin(t, h, "admin")
+	ada, bea := accountWithData(t, h)
+
+	expectStatus(t, h.Do("DELETE", "/api/v1/users/me", nil, ada.Token), http.StatusBadRequest)
+	expectCode(t, h.Do("DELETE", "/api/v1/users/me", map[string]string{"confirm_email": bea.Email}, ada.Token), http.StatusBadRequest, "confirmation_mismatch")
+
+	rec := h.Do("DELETE", "/api/v1/users/me", map[string]string{"confirm_email": " ADA@example.edu "}, ada.Token)
+	expectStatus(t, rec, http.StatusOK)
+
+	// The old token no longer works, not even to sign up again
+	expectCode(t, h.Do("GET", "/api/v1/matches", nil, ada.Token), http.StatusUnauthorized, "account_deleted")
+	expectCode(t, h.Do("GET", "/api/v1/users/me", nil, ada.Token), http.StatusUnauthorized, "account_deleted")
+
+	exists, err := h.Repos.Users.Exists(context.Background(), ada.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exists {
+		t.Error("profile still stored")
+	}
+
+	// The match and conversation are gone for the other side too
+	rec = h.Do("GET", "/api/v1/matches", nil, bea.Token)
+	expectStatus(t, rec, http.StatusOK)
+	if got := matchedUserIDs(t, decode(t, rec)); len(got) != 0 {
+		t.Errorf("bea still matched with a deleted account: %v", got)
+	}
+	rec = h.Do("GET", "/api/v1/messages/conversations", nil, bea.Token)
+	expectStatus(t, rec, http.StatusOK)
+	if conversations, _ := decode(t, rec)["data"].([]interface{}); len(conversations) != 0 {
+		t.Errorf("conversation with a deleted account kept: %s", rec.Body.String())
+	}
+
+	rec = h.Do("GET", "/api/v1/admin/audit-log", nil, admin.Token)
+	expectStatus(t, rec, http.StatusOK)
+	deletions := 0
+	for _, entry := range decode(t, rec)["entries"].([]interface{}) {
+		if e := entry.(map[string]interface{}); str(e, "action") == "account.delete" && str(e, "target_id") == ada.ID {
+			deletions++
+		}
+	}
+	if deletions != 1 {
+		t.Errorf("%d account.delete audit entries, want 1", deletions)
+	}
+}
@@ -0,0 +1,91 @@
+package routes_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"wizard-connect/internal/interface/http/harness"
+
+	"github.com/google/uuid"
+)
+
+const manualMatchPath = "/api/v1/admin/matches/manual"
+
+func TestManualMatch(t *testing.T) {
+	h := harness.New()
+	admin := newAdmin(t, h, "admin")
+	ada := newUser(t, h, "ada")
+	bea := newUser(t, h, "bea")
+	cal, dee := newUser(t, h, "cal"), newUser(t, h, "dee")
+	matchPair(t, h, ada, cal)
+	matchPair(t, h, ada, dee)
+
+	expectStatus(t, h.Do("POST", manualMatchPath, map[string]string{"user_id": ada.ID, "matched_user_id": bea.ID}, ada.Token), http.StatusForbidden)
+	expectStatus(t, h.Do("POST", manualMatchPath, map[string]string{"user_id": ada.ID, "matched_user_id": ada.ID}, admin.Token), http.StatusBadRequest)
+
+	rec := h.Do("POST", manualMatchPath, map[string]interface{}{
+		"user_id": ada.ID, "matched_user_id": bea.ID, "compatibility_score": 90,
+	}, admin.Token)
+	expectStatus(t, rec, http.StatusOK)
+	if got, _ := decode(t, rec)["matches"].([]interface{}); len(got) != 2 {
+		t.Fatalf("want both directions created: %s", rec.Body.String())
+	}
+
+	// The manual match goes to the top of Ada's list
+	rec = h.Do("GET", "/api/v1/matches", nil, ada.Token)
+	expectStatus(t, rec, http.StatusOK)
+	list := decode(t, rec)["data"].([]interface{})
+	if len(list) != 3 {
+		t.Fatalf("ada has %d matches, want 3", len(list))
+	}
+	for _, item := range list {
+		m := item.(map[string]interface{})
+		if (str(m, "matched_user_id") == bea.ID) != (m["rank"] == 1.0) {
+			t.Errorf("match with %s has rank %v", str(m, "matched_user_id"), m["rank"])
+		}
+	}
+
+	expectStatus(t, h.Do("POST", manualMatchPath, map[string]string{"user_id": bea.ID, "matched_user_id": ada.ID}, admin.Token), http.StatusConflict)
+}
+
+func TestManualMatchNeedsExistingUsers(t *testing.T) {
+	h := harness.New()
+	admin := newAdmin(t, h, "admin")
+	ada := newUser(t, h, "ada")
+	ghost := uuid.New().String()
+
+	expectStatus(t, h.Do("POST", manualMatchPath, map[string]string{"user_id": ada.ID, "matched_user_id": ghost}, admin.Token), http.StatusNotFound)
+
+	// The lookup didn't sign the unknown user up
+	exists, err := h.Repos.Users.Exists(context.Background(), ghost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exists {
+		t.Error("a manual match for an unknown user created that user")
+	}
+}
+
+func TestManualMatchRespectsBlocksAndGender(t *testing.T) {
+	h := harness.New()
+	admin := newAdmin(t, h, "admin")
+	ada := newUser(t, h, "ada")
+	bea := newUser(t, h, "bea")
+	dee := newUser(t, h, "dee")
+	submitSurvey(t, h, ada, completeAnswers(t, nil))
+	submitSurvey(t, h, dee, completeAnswers(t, map[string]interface{}{"seeking_gender": []interface{}{"male"}}))
+
+	expectStatus(t, h.Do("POST", "/api/v1/users/"+ada.ID+"/block", nil, bea.Token), http.StatusOK)
+	expectStatus(t, h.Do("POST", manualMatchPath, map[string]string{"user_id": ada.ID, "matched_user_id": bea.ID}, admin.Token), http.StatusConflict)
+
+	// Dee only wants men
+	rec := h.Do("POST", manualMatchPath, map[string]string{"user_id": ada.ID, "matched_user_id": dee.ID}, admin.Token)
+	expectStatus(t, rec, http.StatusUnprocessableEntity)
+	if got, _ := decode(t, rec)["conflicts"].([]interface{}); len(got) != 1 {
+		t.Errorf("unexpected conflicts: %s", rec.Body.String())
+	}
+	expectStatus(t, h.Do("POST", manualMatchPath, map[string]interface{}{
+		"user_id": ada.ID, "matched_user_id": dee.ID, "override_gender_preference": true,
+	}, admin.Token), http.StatusOK)
+}
@@ -0,0 +1,82 @@
+package routes_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"wizard-connect/internal/interface/http/harness"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+func TestHealthIsPublic(t *testing.T) {
+	h := harness.New()
+	rec := h.Do("GET", "/api/v1/health", nil, "")
+	expectStatus(t, rec, http.StatusOK)
+	if got := str(decode(t, rec), "status"); got != "ok" {
+		t.Errorf("status = %s, want ok", got)
+	}
+}
+
+func TestAuthRejectsMissingAndMalformedTokens(t *testing.T) {
+	h := harness.New()
+
+	noSubject, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"email": "a@example.edu"}).SignedString([]byte(harness.JWTSecret))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		header string
+		error  string
+	}{
+		{"no header", "", "Authorization header required"},
+		{"not a bearer token", "Token abc", "Invalid authorization header format"},
+		{"not a JWT", "Bearer not-a-jwt", "Invalid token structure"},
+		{"no subject", "Bearer " + noSubject, "Invalid user ID in token"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/api/v1/users/me", nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			rec := httptest.NewRecorder()
+			h.Router.ServeHTTP(rec, req)
+
+			expectStatus(t, rec, http.StatusUnauthorized)
+			if got := str(decode(t, rec), "error"); got != tt.error {
+				t.Errorf("error = %q, want %q", got, tt.error)
+			}
+		})
+	}
+}
+
+func TestFirstSignInCreatesProfile(t *testing.T) {
+	h := harness.New()
+	id := uuid.New().String()
+	token := h.Token(id, "new@example.edu")
+
+	rec := h.Do("GET", "/api/v1/users/me", nil, token)
+	expectStatus(t, rec, http.StatusOK)
+	body := decode(t, rec)
+	if str(body, "data", "id") != id || str(body, "data", "email") != "new@example.edu" {
+		t.Fatalf("unexpected profile: %s", rec.Body.String())
+	}
+	if got := str(body, "data", "visibility"); got != "matches_only" {
+		t.Errorf("visibility = %s, want matches_only", got)
+	}
+
+	// Signing in again returns the same profile
+	expectStatus(t, h.Do("GET", "/api/v1/users/me", nil, token), http.StatusOK)
+	users, err := h.Repos.Users.ListAll(t.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(users) != 1 {
+		t.Errorf("%d users stored, want 1", len(users))
+	}
+}
@@ -0,0 +1,134 @@
+package routes_test
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"wizard-connect/internal/interface/http/harness"
+)
+
+// pngImage encodes a blank width x height PNG
+func pngImage(t *testing.T, width, height int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, width, height))); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// uploadAvatar posts data as u's avatar in a multipart form
+func uploadAvatar(t *testing.T, h *harness.Harness, u testUser, data []byte) *httptest.ResponseRecorder {
+	t.Helper()
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	part, err := form.CreateFormFile("avatar", "avatar.png")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := part.Write(data); err != nil {
+		t.Fatal(err)
+	}
+	if err := form.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	req := httptest.NewRequest("POST", "/api/v1/users/me/avatar", &body)
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	req.Header.Set("Authorization", "Bearer "+u.Token)
+	rec := httptest.NewRecorder()
+	h.Router.ServeHTTP(rec, req)
+	return rec
+}
+
+// avatarHarness stores uploads in a directory removed after the test
+func avatarHarness(t *testing.T) *harness.Harness {
+	t.Helper()
+	h := harness.New()
+	h.Config.Storage.LocalDir = t.TempDir()
+	h.Reload()
+	return h
+}
+
+func TestUploadAvatar(t *testing.T) {
+	h := avatarHarness(t)
+	ada := newUser(t, h, "ada")
+
+	rec := uploadAvatar(t, h, ada, pngImage(t, 640, 480))
+	expectStatus(t, rec, http.StatusOK)
+	body := decode(t, rec)
+	url := str(body, "data", "avatar_url")
+	if !strings.HasPrefix(url, h.Config.Storage.PublicURL+"/") {
+		t.Fatalf("avatar_url = %s", url)
+	}
+	if thumbnails, _ := field(body, "data", "thumbnails").(map[string]interface{}); len(thumbnails) == 0 {
+		t.Errorf("no thumbnails: %s", rec.Body.String())
+	}
+
+	// The file is stored where the URL points, and the profile uses it
+	stored := filepath.Join(h.Config.Storage.LocalDir, filepath.FromSlash(strings.TrimPrefix(url, h.Config.Storage.PublicURL)))
+	if _, err := os.Stat(stored); err != nil {
+		t.Errorf("avatar not stored: %v", err)
+	}
+	if got := ownProfile(t, h, ada)["avatar_url"]; got != url {
+		t.Errorf("profile avatar_url = %v, want %s", got, url)
+	}
+}
+
+func TestUploadAvatarRejectsBadImages(t *testing.T) {
+	h := avatarHarness(t)
+	ada := newUser(t, h, "ada")
+
+	expectStatus(t, uploadAvatar(t, h, ada, []byte("just some text")), http.StatusUnsupportedMediaType)
+	expectStatus(t, uploadAvatar(t, h, ada, pngImage(t, 32, 32)), http.StatusUnprocessableEntity)
+	expectStatus(t, uploadAvatar(t, h, ada, make([]byte, 6<<20)), http.StatusRequestEntityTooLarge)
+
+	// Not a multipart form at all
+	expectStatus(t, h.Do("POST", "/api/v1/users/me/avatar", map[string]string{"avatar": "x"}, ada.Token), http.StatusBadRequest)
+
+	if got := ownProfile(t, h, ada)["avatar_url"]; got != "" {
+		t.Errorf("avatar_url = %v after rejected uploads", got)
+	}
+}
+
+func TestProfileAvatarMustBeOwnUpload(t *testing.T) {
+	h := avatarHarness(t)
+	ada := newUser(t, h, "ada")
+	bea := newUser(t, h, "bea")
+
+	first := str(decode(t, uploadAvatar(t, h, ada, pngImage(t, 640, 480))), "data", "avatar_url")
+	expectStatus(t, uploadAvatar(t, h, ada, pngImage(t, 320, 240)), http.StatusOK)
+	theirs := str(decode(t, uploadAvatar(t, h, bea, pngImage(t, 640, 480))), "data", "avatar_url")
+
+	for _, url := range []string{
+		"https://example.com/a.png",
+		theirs,
+		h.Config.Storage.PublicURL + "/avatars/" + ada.ID + "/../" + bea.ID + "/" + path.Base(theirs),
+		h.Config.Storage.PublicURL + "/avatars/" + ada.ID + "/other.jpg",
+	} {
+		rec := h.Do("PATCH", "/api/v1/users/me", map[string]interface{}{"avatar_url": url}, ada.Token)
+		expectCode(t, rec, http.StatusUnprocessableEntity, "invalid_profile")
+		if field(decode(t, rec), "fields", "avatar_url") == nil {
+			t.Errorf("no avatar_url error for %s", url)
+		}
+	}
+
+	// An earlier upload can be picked again, and null clears the avatar
+	expectStatus(t, h.Do("PATCH", "/api/v1/users/me", map[string]interface{}{"avatar_url": first}, ada.Token), http.StatusOK)
+	if got := ownProfile(t, h, ada)["avatar_url"]; got != first {
+		t.Errorf("avatar_url = %v, want %s", got, first)
+	}
+	expectStatus(t, h.Do("PATCH", "/api/v1/users/me", map[string]interface{}{"avatar_url": nil}, ada.Token), http.StatusOK)
+	if got := ownProfile(t, h, ada)["avatar_url"]; got != "" {
+		t.Errorf("avatar_url = %v after clearing it", got)
+	}
+}
@@ -0,0 +1,97 @@
+package routes_test
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"wizard-connect/internal/interface/http/harness"
+)
+
+func TestMessagesFollowContentPolicy(t *testing.T) {
+	h := harness.New()
+	ada := newUser(t, h, "ada")
+	bea := newUser(t, h, "bea")
+	unlockedPair(t, h, ada, bea)
+	convID, _ := sendMessage(t, h, ada, bea, "Hi!")
+	path := "/api/v1/messages/conversations/" + convID + "/messages"
+
+	// Profanity is masked by default, spelled out or not
+	for content, want := range map[string]string{
+		"oh shit, hi":    "oh ****, hi",
+		"oh sh1t, hi":    "oh ****, hi",
+		"oh s h i t, hi": "oh * * * *, hi",
+	} {
+		rec := h.Do("POST", path, map[string]string{"content": content}, ada.Token)
+		expectStatus(t, rec, http.StatusCreated)
+		if got := str(decode(t, rec), "data", "content"); got != want {
+			t.Errorf("%q stored as %q, want %q", content, got, want)
+		}
+	}
+
+	rec := h.Do("POST", path, map[string]string{"content": strings.Repeat("a", 2001)}, ada.Token)
+	expectCode(t, rec, http.StatusUnprocessableEntity, "content_rejected")
+}
+
+func TestProfileFollowsContentPolicy(t *testing.T) {
+	h := harness.New()
+	ada := newUser(t, h, "ada")
+
+	rec := h.Do("PATCH", "/api/v1/users/me", map[string]string{"first_name": "Shit"}, ada.Token)
+	expectCode(t, rec, http.StatusUnprocessableEntity, "content_rejected")
+	if field(decode(t, rec), "fields", "first_name") == nil {
+		t.Errorf("expected an error for first_name: %s", rec.Body.String())
+	}
+
+	// Contact details in a bio are masked
+	rec = h.Do("PATCH", "/api/v1/users/me", map[string]string{"bio": "Text me at 555-123-4567 or see example.com"}, ada.Token)
+	expectStatus(t, rec, http.StatusOK)
+	bio := str(decode(t, rec), "data", "bio")
+	if strings.Contains(bio, "4567") || strings.Contains(bio, "example.com") || !strings.HasPrefix(bio, "Text me at ") {
+		t.Errorf("bio = %q", bio)
+	}
+}
+
+func TestContentPolicyFile(t *testing.T) {
+	h := harness.New()
+	policy := filepath.Join(t.TempDir(), "policy.json")
+	err := os.WriteFile(policy, []byte(`{
+		"slurs": ["grobnik"],
+		"actions": {"message": {"profanity": "flag", "slur": "reject"}}
+	}`), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.Config.Content.PolicyFile = policy
+	h.Reload()
+
+	admin := newAdmin(t, h, "admin")
+	ada := newUser(t, h, "ada")
+	bea := newUser(t, h, "bea")
+	unlockedPair(t, h, ada, bea)
+	convID, _ := sendMessage(t, h, ada, bea, "Hi!")
+	path := "/api/v1/messages/conversations/" + convID + "/messages"
+
+	expectCode(t, h.Do("POST", path, map[string]string{"content": "you gr0bnik"}, ada.Token), http.StatusUnprocessableEntity, "content_rejected")
+
+	// Flagged text is kept as sent and queued for the moderators
+	rec := h.Do("POST", path, map[string]string{"content": "oh shit"}, ada.Token)
+	expectStatus(t, rec, http.StatusCreated)
+	messageID := str(decode(t, rec), "data", "id")
+	if got := str(decode(t, rec), "data", "content"); got != "oh shit" {
+		t.Errorf("flagged message stored as %q", got)
+	}
+
+	rec = h.Do("GET", "/api/v1/admin/reports?source=auto", nil, admin.Token)
+	expectStatus(t, rec, http.StatusOK)
+	reports, _ := decode(t, rec)["reports"].([]interface{})
+	if len(reports) != 1 {
+		t.Fatalf("unexpected reports: %s", rec.Body.String())
+	}
+	report := reports[0].(map[string]interface{})
+	if str(report, "reported_user_id") != ada.ID || str(report, "content_type") != "message" || str(report, "content_id") != messageID {
+		t.Errorf("unexpected report: %v", report)
+	}
+}
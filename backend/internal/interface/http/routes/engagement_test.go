@@ -0,0 +1,80 @@
+package routes_test
+
+import (
+	"net/http"
+	"testing"
+
+	"wizard-connect/internal/interface/http/harness"
+)
+
+func TestViewMatch(t *testing.T) {
+	h := harness.New()
+	ada := newUser(t, h, "ada")
+	bea := newUser(t, h, "bea")
+	eve := newUser(t, h, "eve")
+	ab, _ := matchPair(t, h, ada, bea)
+
+	expectStatus(t, h.Do("POST", "/api/v1/matches/"+ab.ID+"/view", nil, eve.Token), http.StatusNotFound)
+	expectStatus(t, h.Do("POST", "/api/v1/matches/nope/view", nil, ada.Token), http.StatusNotFound)
+
+	rec := h.Do("POST", "/api/v1/matches/"+ab.ID+"/view", nil, ada.Token)
+	expectStatus(t, rec, http.StatusOK)
+	if field(decode(t, rec), "data", "user_a_viewed") != true {
+		t.Errorf("view not recorded: %s", rec.Body.String())
+	}
+	// Viewing again is harmless
+	expectStatus(t, h.Do("POST", "/api/v1/matches/"+ab.ID+"/view", nil, ada.Token), http.StatusOK)
+
+	// Each side sees the view from their own point of view
+	if m := matchState(t, h, ada, bea); m["viewed"] != true || m["matched_user_viewed"] != false {
+		t.Errorf("ada's match: %v", m)
+	}
+	if m := matchState(t, h, bea, ada); m["viewed"] != false || m["matched_user_viewed"] != true {
+		t.Errorf("bea's match: %v", m)
+	}
+}
+
+func TestMessagingMarksContacted(t *testing.T) {
+	h := harness.New()
+	ada := newUser(t, h, "ada")
+	bea := newUser(t, h, "bea")
+
+	unlockedPair(t, h, ada, bea)
+
+	if m := matchState(t, h, ada, bea); m["messaging_unlocked"] != true || m["contacted"] != false {
+		t.Errorf("ada's match after both were interested: %v", m)
+	}
+
+	rec := h.Do("POST", "/api/v1/messages/conversations", map[string]string{"other_user_id": bea.ID}, ada.Token)
+	expectStatus(t, rec, http.StatusCreated)
+	convID := str(decode(t, rec), "data", "id")
+	expectStatus(t, h.Do("POST", "/api/v1/messages/conversations/"+convID+"/messages", map[string]string{"content": "Hi!"}, ada.Token), http.StatusCreated)
+
+	if m := matchState(t, h, ada, bea); m["contacted"] != true || m["matched_user_contacted"] != false {
+		t.Errorf("ada's match after her message: %v", m)
+	}
+	if m := matchState(t, h, bea, ada); m["contacted"] != false || m["matched_user_contacted"] != true {
+		t.Errorf("bea's match after ada's message: %v", m)
+	}
+}
+
+func TestEngagementStatistics(t *testing.T) {
+	h, admin, ada, bea, _, campaignID := reviewSetup(t)
+	expectStatus(t, h.Do("POST", "/api/v1/admin/campaigns/"+campaignID+"/publish", nil, admin.Token), http.StatusOK)
+
+	expectStatus(t, h.Do("POST", "/api/v1/matches/"+publishedMatchID(t, h, ada, bea)+"/view", nil, ada.Token), http.StatusOK)
+	expectStatus(t, h.Do("POST", "/api/v1/matches/"+publishedMatchID(t, h, bea, ada)+"/view", nil, bea.Token), http.StatusOK)
+
+	stats := campaignStatistics(t, h, admin, campaignID, true)
+	for key, want := range map[string]float64{
+		"users_viewing":     2,
+		"viewed":            2,
+		"view_rate":         2.0 / 6,
+		"pairs_both_viewed": 1,
+		"contacted":         0,
+	} {
+		if got := field(stats, "engagement", key); got != want {
+			t.Errorf("engagement.%s = %v, want %v", key, got, want)
+		}
+	}
+}
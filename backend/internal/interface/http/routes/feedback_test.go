@@ -0,0 +1,68 @@
+package routes_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"wizard-connect/internal/domain/entities"
+	"wizard-connect/internal/domain/services"
+	"wizard-connect/internal/interface/http/harness"
+)
+
+func TestFeedbackWaitsForMessagingWindow(t *testing.T) {
+	h := harness.New()
+	ada := newUser(t, h, "ada")
+	bea := newUser(t, h, "bea")
+	ab, _ := matchPair(t, h, ada, bea)
+
+	rec := h.Do("POST", "/api/v1/matches/"+ab.ID+"/feedback", map[string]interface{}{"rating": 4}, ada.Token)
+	expectStatus(t, rec, http.StatusConflict)
+	if want := services.FeedbackOpensAt(ab).Format(time.RFC3339); str(decode(t, rec), "opens_at") != want {
+		t.Errorf("opens_at = %s, want %s", str(decode(t, rec), "opens_at"), want)
+	}
+}
+
+func TestSubmitFeedback(t *testing.T) {
+	h := harness.New()
+	ada := newUser(t, h, "ada")
+	bea := newUser(t, h, "bea")
+	// A match whose messaging window is over
+	match := &entities.Match{
+		UserID:             ada.ID,
+		MatchedUserID:      bea.ID,
+		CompatibilityScore: 80,
+		Rank:               1,
+		CreatedAt:          time.Now().Add(-services.MessagingWindow - time.Hour),
+	}
+	if err := h.Repos.Matches.Create(context.Background(), match); err != nil {
+		t.Fatal(err)
+	}
+	path := "/api/v1/matches/" + match.ID + "/feedback"
+
+	rec := h.Do("GET", path, nil, ada.Token)
+	expectStatus(t, rec, http.StatusOK)
+	if body := decode(t, rec); body["data"] != nil {
+		t.Errorf("feedback before any was given: %s", rec.Body.String())
+	}
+
+	expectStatus(t, h.Do("POST", path, map[string]interface{}{"rating": 6}, ada.Token), http.StatusBadRequest)
+	expectStatus(t, h.Do("POST", path, map[string]interface{}{}, ada.Token), http.StatusBadRequest)
+	expectStatus(t, h.Do("POST", path, map[string]interface{}{"rating": 4}, bea.Token), http.StatusNotFound)
+
+	rec = h.Do("POST", path, map[string]interface{}{"rating": 4, "met": true, "comment": "  Coffee was fun  "}, ada.Token)
+	expectStatus(t, rec, http.StatusOK)
+	if body := decode(t, rec); field(body, "data", "rating") != 4.0 || field(body, "data", "comment") != "Coffee was fun" {
+		t.Errorf("unexpected feedback: %s", rec.Body.String())
+	}
+
+	// Submitting again replaces it
+	expectStatus(t, h.Do("POST", path, map[string]interface{}{"rating": 2, "met": false}, ada.Token), http.StatusOK)
+	rec = h.Do("GET", path, nil, ada.Token)
+	expectStatus(t, rec, http.StatusOK)
+	body := decode(t, rec)
+	if field(body, "data", "rating") != 2.0 || field(body, "data", "met") != false {
+		t.Errorf("feedback not replaced: %s", rec.Body.String())
+	}
+}
@@ -0,0 +1,220 @@
+package routes_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"wizard-connect/internal/domain/entities"
+	"wizard-connect/internal/domain/services"
+	"wizard-connect/internal/interface/http/harness"
+
+	"github.com/google/uuid"
+)
+
+// testUser is a user stored in the harness with a token to act as them
+type testUser struct {
+	ID    string
+	Email string
+	Token string
+}
+
+// newUser stores a user with a complete profile and signs a token for them
+func newUser(t *testing.T, h *harness.Harness, name string) testUser {
+	t.Helper()
+	u := testUser{ID: uuid.New().String(), Email: name + "@example.edu"}
+	err := h.CreateUser(context.Background(), &entities.User{
+		ID:               u.ID,
+		Email:            u.Email,
+		FirstName:        name,
+		Gender:           "prefer_not_to_say",
+		GenderPreference: "both",
+		ContactPref:      entities.ContactPrefEmail,
+		Visibility:       entities.VisibilityMatchesOnly,
+	})
+	if err != nil {
+		t.Fatalf("create user %s: %v", name, err)
+	}
+	u.Token = h.Token(u.ID, u.Email)
+	return u
+}
+
+// newAdmin is newUser with admin rights
+func newAdmin(t *testing.T, h *harness.Harness, name string) testUser {
+	t.Helper()
+	u := newUser(t, h, name)
+	if err := h.MakeAdmin(context.Background(), u.Email); err != nil {
+		t.Fatalf("make admin %s: %v", name, err)
+	}
+	return u
+}
+
+// completeAnswers answers every required question of the default survey.
+// The first option is picked unless overrides says otherwise.
+func completeAnswers(t *testing.T, overrides map[string]interface{}) map[string]interface{} {
+	t.Helper()
+	def, err := services.GetSurveyDefinition("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	answers := make(map[string]interface{})
+	for _, q := range def.Questions {
+		switch q.Type {
+		case entities.QuestionTypeCrushList:
+		case entities.QuestionTypeMultiSelect:
+			answers[q.ID] = []interface{}{q.Options[0]}
+		case entities.QuestionTypeScale:
+			answers[q.ID] = "3"
+		default:
+			answers[q.ID] = q.Options[0]
+		}
+	}
+	answers["gender"] = "female"
+	answers["seeking_gender"] = []interface{}{"male", "female", "non_binary"}
+	for id, value := range overrides {
+		answers[id] = value
+	}
+	return answers
+}
+
+// submitSurvey completes the current survey for u
+func submitSurvey(t *testing.T, h *harness.Harness, u testUser, answers map[string]interface{}) {
+	t.Helper()
+	rec := h.Do("POST", "/api/v1/surveys", map[string]interface{}{
+		"responses":   answers,
+		"is_complete": true,
+	}, u.Token)
+	expectStatus(t, rec, http.StatusOK)
+}
+
+// matchPair stores a live match between a and b in both directions
+func matchPair(t *testing.T, h *harness.Harness, a, b testUser) (ab, ba *entities.Match) {
+	t.Helper()
+	ctx := context.Background()
+	ab = &entities.Match{UserID: a.ID, MatchedUserID: b.ID, CompatibilityScore: 80, Rank: 1}
+	ba = &entities.Match{UserID: b.ID, MatchedUserID: a.ID, CompatibilityScore: 80, Rank: 1}
+	for _, m := range []*entities.Match{ab, ba} {
+		if err := h.Repos.Matches.Create(ctx, m); err != nil {
+			t.Fatalf("create match: %v", err)
+		}
+	}
+	return ab, ba
+}
+
+// unlockedPair matches a and b and has both say they are interested
+func unlockedPair(t *testing.T, h *harness.Harness, a, b testUser) (ab, ba *entities.Match) {
+	t.Helper()
+	ab, ba = matchPair(t, h, a, b)
+	interested := map[string]string{"response": entities.MatchResponseInterested}
+	expectStatus(t, h.Do("POST", "/api/v1/matches/"+ab.ID+"/respond", interested, a.Token), http.StatusOK)
+	expectStatus(t, h.Do("POST", "/api/v1/matches/"+ba.ID+"/respond", interested, b.Token), http.StatusOK)
+	return ab, ba
+}
+
+// matchState is u's published match with other as the match list shows it
+func matchState(t *testing.T, h *harness.Harness, u, other testUser) map[string]interface{} {
+	t.Helper()
+	rec := h.Do("GET", "/api/v1/matches", nil, u.Token)
+	expectStatus(t, rec, http.StatusOK)
+	for _, item := range decode(t, rec)["data"].([]interface{}) {
+		if m := item.(map[string]interface{}); str(m, "matched_user_id") == other.ID {
+			return m
+		}
+	}
+	t.Fatalf("%s isn't matched with %s", u.Email, other.Email)
+	return nil
+}
+
+// publishedMatchID finds u's published match with other
+func publishedMatchID(t *testing.T, h *harness.Harness, u, other testUser) string {
+	t.Helper()
+	return str(matchState(t, h, u, other), "id")
+}
+
+// newCampaign stores an active campaign; new surveys belong to it
+func newCampaign(t *testing.T, h *harness.Harness, config map[string]interface{}) *entities.Campaign {
+	t.Helper()
+	now := time.Now()
+	campaign := &entities.Campaign{
+		ID:                 uuid.New().String(),
+		Name:               "Test campaign",
+		SurveyOpenDate:     now.Add(-24 * time.Hour),
+		SurveyCloseDate:    now.Add(24 * time.Hour),
+		ResultsReleaseDate: now.Add(48 * time.Hour),
+		IsActive:           true,
+		Config:             config,
+	}
+	if err := h.Repos.Campaigns.Create(context.Background(), campaign); err != nil {
+		t.Fatalf("create campaign: %v", err)
+	}
+	return campaign
+}
+
+// runMatching runs the campaign's matching algorithm and waits for the
+// matches to be staged
+func runMatching(t *testing.T, h *harness.Harness, admin testUser, campaignID string) {
+	t.Helper()
+	rec := h.Do("POST", "/api/v1/admin/campaigns/"+campaignID+"/run-algorithm", map[string]int64{"seed": 1}, admin.Token)
+	expectStatus(t, rec, http.StatusAccepted)
+	runPath := "/api/v1/admin/campaigns/" + campaignID + "/runs/" + str(decode(t, rec), "run_id")
+
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(5 * time.Millisecond) {
+		rec = h.Do("GET", runPath, nil, admin.Token)
+		expectStatus(t, rec, http.StatusOK)
+		switch status := str(decode(t, rec), "status"); status {
+		case entities.RunStatusCompleted:
+			return
+		case entities.RunStatusFailed:
+			t.Fatalf("matching run failed: %s", rec.Body.String())
+		}
+	}
+	t.Fatal("matching run didn't finish")
+}
+
+// expectStatus fails the test unless rec has the status
+func expectStatus(t *testing.T, rec *httptest.ResponseRecorder, status int) {
+	t.Helper()
+	if rec.Code != status {
+		t.Fatalf("status = %d, want %d; body: %s", rec.Code, status, rec.Body.String())
+	}
+}
+
+// decode unmarshals rec's body into a generic JSON object
+func decode(t *testing.T, rec *httptest.ResponseRecorder) map[string]interface{} {
+	t.Helper()
+	var body map[string]interface{}
+	if err := harness.Decode(rec, &body); err != nil {
+		t.Fatalf("decode %s: %v", rec.Body.String(), err)
+	}
+	return body
+}
+
+// expectCode fails the test unless rec has the status and error code
+func expectCode(t *testing.T, rec *httptest.ResponseRecorder, status int, code string) {
+	t.Helper()
+	expectStatus(t, rec, status)
+	if got := decode(t, rec)["code"]; got != code {
+		t.Fatalf("code = %v, want %s; body: %s", got, code, rec.Body.String())
+	}
+}
+
+// field walks nested JSON objects: field(body, "data", "id")
+func field(body map[string]interface{}, path ...string) interface{} {
+	var v interface{} = body
+	for _, key := range path {
+		obj, ok := v.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		v = obj[key]
+	}
+	return v
+}
+
+// str is field as a string
+func str(body map[string]interface{}, path ...string) string {
+	return fmt.Sprint(field(body, path...))
+}
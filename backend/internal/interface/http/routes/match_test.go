@@ -0,0 +1,109 @@
+package routes_test
+
+import (
+	"net/http"
+	"testing"
+
+	"wizard-connect/internal/interface/http/harness"
+)
+
+// matchedUserIDs lists the matched_user_id of every match in a response
+func matchedUserIDs(t *testing.T, body map[string]interface{}) map[string]bool {
+	t.Helper()
+	list, ok := body["data"].([]interface{})
+	if !ok {
+		t.Fatalf("data is not a list: %v", body["data"])
+	}
+	ids := make(map[string]bool, len(list))
+	for _, item := range list {
+		ids[str(item.(map[string]interface{}), "matched_user_id")] = true
+	}
+	return ids
+}
+
+func TestMatchesComeFromPublishedRuns(t *testing.T) {
+	h := harness.New()
+	campaignID := newCampaign(t, h, nil).ID
+	admin := newAdmin(t, h, "admin")
+	ada := newUser(t, h, "ada")
+	bea := newUser(t, h, "bea")
+	cal := newUser(t, h, "cal")
+	dee := newUser(t, h, "dee")
+
+	submitSurvey(t, h, ada, completeAnswers(t, nil))
+	submitSurvey(t, h, bea, completeAnswers(t, map[string]interface{}{"major": "it"}))
+	submitSurvey(t, h, cal, completeAnswers(t, map[string]interface{}{"gender": "male"}))
+	// Dee only wants men, so she and Ada rule each other out
+	submitSurvey(t, h, dee, completeAnswers(t, map[string]interface{}{"seeking_gender": []interface{}{"male"}}))
+
+	// Users can't generate their own matches around the review
+	expectStatus(t, h.Do("POST", "/api/v1/matches/generate", nil, ada.Token), http.StatusNotFound)
+
+	runMatching(t, h, admin, campaignID)
+	expectStatus(t, h.Do("POST", "/api/v1/admin/campaigns/"+campaignID+"/publish", nil, admin.Token), http.StatusOK)
+
+	rec := h.Do("GET", "/api/v1/matches", nil, ada.Token)
+	expectStatus(t, rec, http.StatusOK)
+	body := decode(t, rec)
+	if got := matchedUserIDs(t, body); len(got) != 2 || !got[bea.ID] || !got[cal.ID] {
+		t.Fatalf("unexpected match list: %s", rec.Body.String())
+	}
+	for _, item := range body["data"].([]interface{}) {
+		m := item.(map[string]interface{})
+		if field(m, "matched_user") == nil {
+			t.Errorf("match without matched_user details: %v", m)
+		}
+		if score, _ := m["compatibility_score"].(float64); score <= 0 || score > 100 {
+			t.Errorf("compatibility_score = %v", m["compatibility_score"])
+		}
+	}
+
+	// Dee is only matched with Cal
+	rec = h.Do("GET", "/api/v1/matches", nil, dee.Token)
+	expectStatus(t, rec, http.StatusOK)
+	if got := matchedUserIDs(t, decode(t, rec)); len(got) != 1 || !got[cal.ID] {
+		t.Errorf("unexpected matches for dee: %v", got)
+	}
+}
+
+func TestRespondToMatch(t *testing.T) {
+	h := harness.New()
+	ada := newUser(t, h, "ada")
+	bea := newUser(t, h, "bea")
+	eve := newUser(t, h, "eve")
+	ab, ba := matchPair(t, h, ada, bea)
+
+	expectStatus(t, h.Do("POST", "/api/v1/matches/"+ab.ID+"/respond", map[string]string{"response": "maybe"}, ada.Token), http.StatusBadRequest)
+	expectStatus(t, h.Do("POST", "/api/v1/matches/"+ab.ID+"/respond", map[string]string{"response": "interested"}, eve.Token), http.StatusNotFound)
+
+	rec := h.Do("POST", "/api/v1/matches/"+ab.ID+"/respond", map[string]string{"response": "interested"}, ada.Token)
+	expectStatus(t, rec, http.StatusOK)
+	if body := decode(t, rec); field(body, "data", "user_a_response") != "interested" || field(body, "data", "messaging_unlocked") != false {
+		t.Errorf("unexpected match after one response: %s", rec.Body.String())
+	}
+
+	rec = h.Do("POST", "/api/v1/matches/"+ba.ID+"/respond", map[string]string{"response": "pass", "reason": "not my type"}, bea.Token)
+	expectStatus(t, rec, http.StatusOK)
+	if m := matchState(t, h, bea, ada); m["response"] != "pass" || m["messaging_unlocked"] != false {
+		t.Errorf("bea's match after passing: %v", m)
+	}
+
+	// Ada only ever sees her own response, never Bea's pass
+	m := matchState(t, h, ada, bea)
+	if m["response"] != "interested" || m["messaging_unlocked"] != false {
+		t.Errorf("ada's match after bea passed: %v", m)
+	}
+	for key, value := range m {
+		if value == "pass" {
+			t.Errorf("bea's pass leaked to ada in %s", key)
+		}
+	}
+
+	// Changing her mind unlocks messaging for both
+	expectStatus(t, h.Do("POST", "/api/v1/matches/"+ba.ID+"/respond", map[string]string{"response": "interested"}, bea.Token), http.StatusOK)
+	for _, m := range []map[string]interface{}{matchState(t, h, ada, bea), matchState(t, h, bea, ada)} {
+		if m["messaging_unlocked"] != true {
+			t.Errorf("messaging still locked: %v", m)
+		}
+	}
+}
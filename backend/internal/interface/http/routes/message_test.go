@@ -0,0 +1,76 @@
+package routes_test
+
+import (
+	"net/http"
+	"testing"
+
+	"wizard-connect/internal/interface/http/harness"
+)
+
+func TestMessagingNeedsMutualInterest(t *testing.T) {
+	h := harness.New()
+	ada := newUser(t, h, "ada")
+	bea := newUser(t, h, "bea")
+
+	// Not matched at all
+	rec := h.Do("POST", "/api/v1/messages/conversations", map[string]string{"other_user_id": bea.ID}, ada.Token)
+	expectStatus(t, rec, http.StatusForbidden)
+
+	// Matched, but only one side is interested
+	ab, _ := matchPair(t, h, ada, bea)
+	expectStatus(t, h.Do("POST", "/api/v1/matches/"+ab.ID+"/respond", map[string]string{"response": "interested"}, ada.Token), http.StatusOK)
+	rec = h.Do("POST", "/api/v1/messages/conversations", map[string]string{"other_user_id": bea.ID}, ada.Token)
+	expectStatus(t, rec, http.StatusForbidden)
+	if got := str(decode(t, rec), "error"); got != "Messaging unlocks once you and your match are both interested" {
+		t.Errorf("error = %q", got)
+	}
+}
+
+func TestConversationAndMessages(t *testing.T) {
+	h := harness.New()
+	ada := newUser(t, h, "ada")
+	bea := newUser(t, h, "bea")
+	eve := newUser(t, h, "eve")
+	unlockedPair(t, h, ada, bea)
+
+	rec := h.Do("POST", "/api/v1/messages/conversations", map[string]string{"other_user_id": bea.ID}, ada.Token)
+	expectStatus(t, rec, http.StatusCreated)
+	convID := str(decode(t, rec), "data", "id")
+
+	// Opening it again, from either side, returns the same conversation
+	rec = h.Do("POST", "/api/v1/messages/conversations", map[string]string{"other_user_id": ada.ID}, bea.Token)
+	expectStatus(t, rec, http.StatusOK)
+	if got := str(decode(t, rec), "data", "id"); got != convID {
+		t.Errorf("second conversation %s, want %s", got, convID)
+	}
+
+	path := "/api/v1/messages/conversations/" + convID + "/messages"
+	expectStatus(t, h.Do("POST", path, map[string]string{"content": ""}, ada.Token), http.StatusBadRequest)
+	expectStatus(t, h.Do("POST", path, map[string]string{"content": "hi"}, eve.Token), http.StatusForbidden)
+	expectStatus(t, h.Do("POST", "/api/v1/messages/conversations/nope/messages", map[string]string{"content": "hi"}, ada.Token), http.StatusNotFound)
+
+	rec = h.Do("POST", path, map[string]string{"content": "Hello Bea!"}, ada.Token)
+	expectStatus(t, rec, http.StatusCreated)
+	body := decode(t, rec)
+	if str(body, "data", "content") != "Hello Bea!" || str(body, "data", "sender_id") != ada.ID {
+		t.Errorf("unexpected message: %s", rec.Body.String())
+	}
+
+	rec = h.Do("GET", "/api/v1/messages/conversations/"+convID, nil, bea.Token)
+	expectStatus(t, rec, http.StatusOK)
+	messages, _ := decode(t, rec)["data"].([]interface{})
+	if len(messages) != 1 || str(messages[0].(map[string]interface{}), "content") != "Hello Bea!" {
+		t.Errorf("unexpected messages: %s", rec.Body.String())
+	}
+
+	rec = h.Do("GET", "/api/v1/messages/conversations", nil, bea.Token)
+	expectStatus(t, rec, http.StatusOK)
+	conversations, _ := decode(t, rec)["data"].([]interface{})
+	if len(conversations) != 1 {
+		t.Fatalf("unexpected conversations: %s", rec.Body.String())
+	}
+	conv := conversations[0].(map[string]interface{})
+	if str(conv, "other_participant", "id") != ada.ID || str(conv, "last_message") != "Hello Bea!" {
+		t.Errorf("unexpected conversation: %v", conv)
+	}
+}
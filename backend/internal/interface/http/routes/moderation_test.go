@@ -0,0 +1,175 @@
+package routes_test
+
+import (
+	"net/http"
+	"testing"
+
+	"wizard-connect/internal/interface/http/harness"
+)
+
+// fileReport has reporter report reported and returns the report's ID
+func fileReport(t *testing.T, h *harness.Harness, reporter, reported testUser) string {
+	t.Helper()
+	rec := h.Do("POST", "/api/v1/users/"+reported.ID+"/report", map[string]string{"category": "spam"}, reporter.Token)
+	expectStatus(t, rec, http.StatusCreated)
+	return str(decode(t, rec), "data", "id")
+}
+
+// moderate takes an action against u directly and returns the action's ID
+func moderate(t *testing.T, h *harness.Harness, admin, u testUser, action map[string]interface{}) string {
+	t.Helper()
+	rec := h.Do("POST", "/api/v1/admin/users/"+u.ID+"/moderation", action, admin.Token)
+	expectStatus(t, rec, http.StatusOK)
+	return str(decode(t, rec), "action", "id")
+}
+
+func TestReportQueue(t *testing.T) {
+	h := harness.New()
+	admin := newAdmin(t, h, "admin")
+	ada := newUser(t, h, "ada")
+	bea := newUser(t, h, "bea")
+	reportID := fileReport(t, h, ada, bea)
+	path := "/api/v1/admin/reports/" + reportID
+
+	expectStatus(t, h.Do("GET", "/api/v1/admin/reports/nope", nil, admin.Token), http.StatusNotFound)
+
+	// Reports go to admins only
+	expectStatus(t, h.Do("POST", path+"/assign", map[string]string{"assignee_id": ada.ID}, admin.Token), http.StatusBadRequest)
+	rec := h.Do("POST", path+"/assign", nil, admin.Token)
+	expectStatus(t, rec, http.StatusOK)
+	if body := decode(t, rec); str(body, "report", "status") != "in_review" || str(body, "report", "assigned_to") != admin.ID {
+		t.Errorf("unexpected assignment: %s", rec.Body.String())
+	}
+
+	rec = h.Do("GET", "/api/v1/admin/reports?assigned_to=me&status=in_review", nil, admin.Token)
+	expectStatus(t, rec, http.StatusOK)
+	if got := decode(t, rec)["count"]; got != 1.0 {
+		t.Errorf("%v reports assigned to me, want 1", got)
+	}
+
+	expectStatus(t, h.Do("POST", path+"/dismiss", map[string]string{}, admin.Token), http.StatusBadRequest)
+	rec = h.Do("POST", path+"/dismiss", map[string]string{"reason": "Not spam"}, admin.Token)
+	expectStatus(t, rec, http.StatusOK)
+	if body := decode(t, rec); str(body, "report", "status") != "dismissed" || str(body, "report", "resolved_by") != admin.ID {
+		t.Errorf("unexpected dismissal: %s", rec.Body.String())
+	}
+
+	// Closed reports stay closed
+	expectStatus(t, h.Do("POST", path+"/dismiss", map[string]string{"reason": "Again"}, admin.Token), http.StatusConflict)
+	expectStatus(t, h.Do("POST", path+"/action", map[string]string{"action": "warn", "reason": "Spam"}, admin.Token), http.StatusConflict)
+}
+
+func TestMuteAndRevoke(t *testing.T) {
+	h := harness.New()
+	admin := newAdmin(t, h, "admin")
+	ada := newUser(t, h, "ada")
+	bea := newUser(t, h, "bea")
+	unlockedPair(t, h, ada, bea)
+	convID, _ := sendMessage(t, h, ada, bea, "Hi!")
+	path := "/api/v1/messages/conversations/" + convID + "/messages"
+
+	expectStatus(t, h.Do("POST", "/api/v1/admin/users/"+ada.ID+"/moderation", map[string]string{"action": "shout", "reason": "x"}, admin.Token), http.StatusBadRequest)
+	actionID := moderate(t, h, admin, ada, map[string]interface{}{"action": "mute", "reason": "Flooding", "duration_hours": 24})
+
+	rec := h.Do("POST", path, map[string]string{"content": "Hello?"}, ada.Token)
+	expectCode(t, rec, http.StatusForbidden, "messaging_muted")
+	if decode(t, rec)["muted_until"] == nil {
+		t.Errorf("a timed mute should say when it ends: %s", rec.Body.String())
+	}
+	// A mute only stops messaging
+	expectStatus(t, h.Do("GET", "/api/v1/matches", nil, ada.Token), http.StatusOK)
+	expectStatus(t, h.Do("POST", path, map[string]string{"content": "Hi Ada"}, bea.Token), http.StatusCreated)
+
+	expectStatus(t, h.Do("DELETE", "/api/v1/admin/moderation/actions/"+actionID, nil, admin.Token), http.StatusOK)
+	expectStatus(t, h.Do("DELETE", "/api/v1/admin/moderation/actions/"+actionID, nil, admin.Token), http.StatusConflict)
+	expectStatus(t, h.Do("DELETE", "/api/v1/admin/moderation/actions/nope", nil, admin.Token), http.StatusNotFound)
+	expectStatus(t, h.Do("POST", path, map[string]string{"content": "Hello?"}, ada.Token), http.StatusCreated)
+}
+
+func TestSuspendAndBan(t *testing.T) {
+	h := harness.New()
+	admin := newAdmin(t, h, "admin")
+	ada := newUser(t, h, "ada")
+	bea := newUser(t, h, "bea")
+	cal := newUser(t, h, "cal")
+
+	moderate(t, h, admin, bea, map[string]interface{}{"action": "suspend", "reason": "Cooling off", "duration_hours": 48})
+	rec := h.Do("GET", "/api/v1/matches", nil, bea.Token)
+	expectCode(t, rec, http.StatusForbidden, "account_suspended")
+	if decode(t, rec)["suspended_until"] == nil {
+		t.Errorf("a timed suspension should say when it ends: %s", rec.Body.String())
+	}
+
+	// Acting on a report bans the reported user and closes the report
+	reportID := fileReport(t, h, ada, cal)
+	rec = h.Do("POST", "/api/v1/admin/reports/"+reportID+"/action", map[string]string{"action": "ban", "reason": "Scam"}, admin.Token)
+	expectStatus(t, rec, http.StatusOK)
+	body := decode(t, rec)
+	if str(body, "report", "status") != "actioned" || str(body, "action", "report_id") != reportID {
+		t.Errorf("unexpected report action: %s", rec.Body.String())
+	}
+	expectCode(t, h.Do("GET", "/api/v1/matches", nil, cal.Token), http.StatusForbidden, "account_banned")
+
+	rec = h.Do("GET", "/api/v1/admin/users/"+cal.ID+"/moderation", nil, admin.Token)
+	expectStatus(t, rec, http.StatusOK)
+	body = decode(t, rec)
+	if field(body, "standing", "banned") != true {
+		t.Errorf("standing = %v", field(body, "standing"))
+	}
+	if actions, _ := body["actions"].([]interface{}); len(actions) != 1 {
+		t.Errorf("actions = %v", body["actions"])
+	}
+	if reports, _ := body["reports_against"].([]interface{}); len(reports) != 1 {
+		t.Errorf("reports_against = %v", body["reports_against"])
+	}
+
+	rec = h.Do("GET", "/api/v1/admin/audit-log", nil, admin.Token)
+	expectStatus(t, rec, http.StatusOK)
+	actions := make(map[string]int)
+	for _, entry := range decode(t, rec)["entries"].([]interface{}) {
+		actions[str(entry.(map[string]interface{}), "action")]++
+	}
+	if actions["user.moderate"] != 2 || actions["report.close"] != 1 {
+		t.Errorf("unexpected audit log: %v", actions)
+	}
+}
+
+func TestRemoveMessage(t *testing.T) {
+	h := harness.New()
+	admin := newAdmin(t, h, "admin")
+	ada := newUser(t, h, "ada")
+	bea := newUser(t, h, "bea")
+	unlockedPair(t, h, ada, bea)
+	convID, messageID := sendMessage(t, h, ada, bea, "Something rude")
+
+	// Only the user's own messages can be removed
+	expectStatus(t, h.Do("POST", "/api/v1/admin/users/"+bea.ID+"/moderation", map[string]string{
+		"action": "remove_content", "reason": "Rude", "content_type": "message", "content_id": messageID,
+	}, admin.Token), http.StatusBadRequest)
+	moderate(t, h, admin, ada, map[string]interface{}{
+		"action": "remove_content", "reason": "Rude", "content_type": "message", "content_id": messageID,
+	})
+
+	rec := h.Do("GET", "/api/v1/messages/conversations/"+convID, nil, bea.Token)
+	expectStatus(t, rec, http.StatusOK)
+	if messages, _ := decode(t, rec)["data"].([]interface{}); len(messages) != 0 {
+		t.Errorf("removed message still listed: %s", rec.Body.String())
+	}
+}
+
+func TestSuspendedUsersAreNotMatched(t *testing.T) {
+	h := harness.New()
+	campaignID := newCampaign(t, h, nil).ID
+	admin := newAdmin(t, h, "admin")
+	ada, bea, cal := newUser(t, h, "ada"), newUser(t, h, "bea"), newUser(t, h, "cal")
+	for _, u := range []testUser{ada, bea, cal} {
+		submitSurvey(t, h, u, completeAnswers(t, nil))
+	}
+	moderate(t, h, admin, cal, map[string]interface{}{"action": "suspend", "reason": "Cooling off"})
+
+	runMatching(t, h, admin, campaignID)
+	pairs := stagedPairs(stagedMatches(t, h, admin, campaignID, ""))
+	if len(pairs) != 2 || pairs[[2]string{ada.ID, bea.ID}] == "" {
+		t.Errorf("unexpected staged pairs with cal suspended: %v", pairs)
+	}
+}
@@ -0,0 +1,194 @@
+package routes_test
+
+import (
+	"net/http"
+	"testing"
+
+	"wizard-connect/internal/interface/http/harness"
+
+	"github.com/google/uuid"
+)
+
+// patchProfile updates u's own profile through the API
+func patchProfile(t *testing.T, h *harness.Harness, u testUser, fields map[string]interface{}) {
+	t.Helper()
+	expectStatus(t, h.Do("PATCH", "/api/v1/users/me", fields, u.Token), http.StatusOK)
+}
+
+// profileOf fetches u's profile as viewer sees it
+func profileOf(t *testing.T, h *harness.Harness, viewer, u testUser) map[string]interface{} {
+	t.Helper()
+	rec := h.Do("GET", "/api/v1/users/"+u.ID, nil, viewer.Token)
+	expectStatus(t, rec, http.StatusOK)
+	return decode(t, rec)["data"].(map[string]interface{})
+}
+
+func TestProfileViews(t *testing.T) {
+	h := harness.New()
+	ada := newUser(t, h, "ada")
+	bea := newUser(t, h, "bea")
+	eve := newUser(t, h, "eve")
+	patchProfile(t, h, ada, map[string]interface{}{
+		"last_name":          "Lovelace",
+		"phone":              "555 123 4567",
+		"instagram":          "@ada.l",
+		"contact_preference": "instagram",
+		"visibility":         "public",
+	})
+	matchPair(t, h, ada, bea)
+
+	// The owner sees everything
+	own := profileOf(t, h, ada, ada)
+	if own["view"] != "owner" || own["email"] != ada.Email || own["phone"] != "5551234567" || own["visibility"] != "public" {
+		t.Errorf("owner view: %v", own)
+	}
+
+	// A match sees the surname and only the chosen contact method
+	matched := profileOf(t, h, bea, ada)
+	if matched["view"] != "matched" || matched["last_name"] != "Lovelace" {
+		t.Errorf("matched view: %v", matched)
+	}
+	if str(matched, "contact", "method") != "instagram" || str(matched, "contact", "value") != "ada.l" {
+		t.Errorf("matched contact: %v", matched["contact"])
+	}
+	for _, key := range []string{"email", "phone", "instagram"} {
+		if _, ok := matched[key]; ok {
+			t.Errorf("matched view reveals %s: %v", key, matched)
+		}
+	}
+
+	// Anyone else gets the redacted public view
+	public := profileOf(t, h, eve, ada)
+	if public["view"] != "public" || public["first_name"] != "ada" {
+		t.Errorf("public view: %v", public)
+	}
+	for _, key := range []string{"last_name", "contact", "email", "phone", "instagram"} {
+		if _, ok := public[key]; ok {
+			t.Errorf("public view reveals %s: %v", key, public)
+		}
+	}
+}
+
+func TestHiddenProfiles(t *testing.T) {
+	h := harness.New()
+	ada := newUser(t, h, "ada")
+	bea := newUser(t, h, "bea")
+	eve := newUser(t, h, "eve")
+	matchPair(t, h, ada, bea)
+
+	// matches_only hides the profile from strangers
+	expectStatus(t, h.Do("GET", "/api/v1/users/"+ada.ID, nil, eve.Token), http.StatusNotFound)
+	if got := profileOf(t, h, bea, ada)["view"]; got != "matched" {
+		t.Errorf("match sees the %v view", got)
+	}
+
+	// private shows matches the public view only
+	patchProfile(t, h, ada, map[string]interface{}{"visibility": "private"})
+	expectStatus(t, h.Do("GET", "/api/v1/users/"+ada.ID, nil, eve.Token), http.StatusNotFound)
+	if got := profileOf(t, h, bea, ada)["view"]; got != "public" {
+		t.Errorf("match sees the %v view of a private profile", got)
+	}
+
+	// Blocks hide even public profiles, both ways, and look like a missing user
+	patchProfile(t, h, ada, map[string]interface{}{"visibility": "public"})
+	patchProfile(t, h, eve, map[string]interface{}{"visibility": "public"})
+	expectStatus(t, h.Do("POST", "/api/v1/users/"+eve.ID+"/block", nil, ada.Token), http.StatusOK)
+	rec := h.Do("GET", "/api/v1/users/"+ada.ID, nil, eve.Token)
+	expectStatus(t, rec, http.StatusNotFound)
+	missing := h.Do("GET", "/api/v1/users/"+uuid.New().String(), nil, eve.Token)
+	expectStatus(t, missing, http.StatusNotFound)
+	if rec.Body.String() != missing.Body.String() {
+		t.Errorf("hidden profile %s differs from missing one %s", rec.Body.String(), missing.Body.String())
+	}
+	expectStatus(t, h.Do("GET", "/api/v1/users/"+eve.ID, nil, ada.Token), http.StatusNotFound)
+}
+
+// ownProfile is u's profile from GET /users/me
+func ownProfile(t *testing.T, h *harness.Harness, u testUser) map[string]interface{} {
+	t.Helper()
+	rec := h.Do("GET", "/api/v1/users/me", nil, u.Token)
+	expectStatus(t, rec, http.StatusOK)
+	return decode(t, rec)["data"].(map[string]interface{})
+}
+
+func TestUpdateProfileValidates(t *testing.T) {
+	h := harness.New()
+	ada := newUser(t, h, "ada")
+
+	rec := h.Do("PATCH", "/api/v1/users/me", map[string]interface{}{
+		"gender":     "robot",
+		"year":       "10th Year",
+		"visibility": "friends",
+		"phone":      "call me",
+		"instagram":  "not a handle!",
+		"avatar_url": "ftp://example.com/a.png",
+		"first_name": 5,
+		"major":      "Computer Science",
+	}, ada.Token)
+	expectCode(t, rec, http.StatusUnprocessableEntity, "invalid_profile")
+	fields, _ := decode(t, rec)["fields"].(map[string]interface{})
+	for _, key := range []string{"gender", "year", "visibility", "phone", "instagram", "avatar_url", "first_name"} {
+		if fields[key] == nil {
+			t.Errorf("no error for %s: %v", key, fields)
+		}
+	}
+	if len(fields) != 7 {
+		t.Errorf("unexpected field errors: %v", fields)
+	}
+	// Nothing was saved
+	if got := ownProfile(t, h, ada)["major"]; got != "" {
+		t.Errorf("major = %v after a rejected update", got)
+	}
+
+	// The chosen contact method must be filled in
+	rec = h.Do("PATCH", "/api/v1/users/me", map[string]string{"contact_preference": "phone"}, ada.Token)
+	expectCode(t, rec, http.StatusUnprocessableEntity, "invalid_profile")
+	if field(decode(t, rec), "fields", "contact_preference") == nil {
+		t.Errorf("expected an error for contact_preference: %s", rec.Body.String())
+	}
+
+	expectStatus(t, h.Do("PATCH", "/api/v1/users/me", "not an object", ada.Token), http.StatusBadRequest)
+}
+
+func TestUpdateProfileNormalizesAndClears(t *testing.T) {
+	h := harness.New()
+	ada := newUser(t, h, "ada")
+
+	rec := h.Do("PATCH", "/api/v1/users/me", map[string]interface{}{
+		"phone":             "+1 (555) 123-4567",
+		"instagram":         "https://www.instagram.com/Ada.L/",
+		"lastName":          "Lovelace",
+		"contactPreference": "phone",
+		"gender":            "non-binary",
+		"year":              "Graduate Student",
+	}, ada.Token)
+	expectStatus(t, rec, http.StatusOK)
+	for key, want := range map[string]string{
+		"phone":              "+15551234567",
+		"instagram":          "ada.l",
+		"last_name":          "Lovelace",
+		"contact_preference": "phone",
+		"gender":             "non-binary",
+		"year":               "Graduate Student",
+	} {
+		if got := str(decode(t, rec), "data", key); got != want {
+			t.Errorf("%s = %q, want %q", key, got, want)
+		}
+	}
+
+	// Empty strings leave fields alone; nulls clear them, or reset them to
+	// their default
+	expectStatus(t, h.Do("PATCH", "/api/v1/users/me", map[string]interface{}{
+		"last_name":          "",
+		"phone":              nil,
+		"instagram":          nil,
+		"contact_preference": nil,
+	}, ada.Token), http.StatusOK)
+	profile := ownProfile(t, h, ada)
+	if profile["last_name"] != "Lovelace" {
+		t.Errorf("last_name = %v, want it kept", profile["last_name"])
+	}
+	if profile["phone"] != "" || profile["instagram"] != "" || profile["contact_preference"] != "email" {
+		t.Errorf("fields not cleared: %v", profile)
+	}
+}
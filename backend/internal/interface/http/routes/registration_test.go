@@ -0,0 +1,125 @@
+package routes_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"wizard-connect/internal/domain/entities"
+	"wizard-connect/internal/interface/http/harness"
+
+	"github.com/google/uuid"
+)
+
+// registrationHarness only lets addresses on domains sign up
+func registrationHarness(t *testing.T, domains ...string) *harness.Harness {
+	t.Helper()
+	h := harness.New()
+	h.Config.Registration.AllowedEmailDomains = domains
+	h.Reload()
+	return h
+}
+
+// signIn signs in as a user the store has never seen
+func signIn(h *harness.Harness, email string) (token string) {
+	return h.Token(uuid.New().String(), email)
+}
+
+func TestRegistrationDomains(t *testing.T) {
+	h := registrationHarness(t, "example.edu")
+
+	expectCode(t, h.Do("GET", "/api/v1/users/me", nil, signIn(h, "eve@gmail.com")), http.StatusForbidden, "email_domain_not_allowed")
+	expectCode(t, h.Do("GET", "/api/v1/users/me", nil, signIn(h, "")), http.StatusForbidden, "email_required")
+	// Every protected route registers first, not just the profile
+	expectCode(t, h.Do("GET", "/api/v1/matches", nil, signIn(h, "eve@gmail.com")), http.StatusForbidden, "email_domain_not_allowed")
+
+	// Subdomains are allowed
+	rec := h.Do("GET", "/api/v1/users/me", nil, signIn(h, "ada@cs.example.edu"))
+	expectStatus(t, rec, http.StatusOK)
+	if got := str(decode(t, rec), "data", "email"); got != "ada@cs.example.edu" {
+		t.Errorf("email = %s", got)
+	}
+
+	// Users who joined before the allow-list keep their account
+	old := testUser{ID: uuid.New().String(), Email: "old@other.org"}
+	if err := h.CreateUser(context.Background(), &entities.User{ID: old.ID, Email: old.Email}); err != nil {
+		t.Fatal(err)
+	}
+	expectStatus(t, h.Do("GET", "/api/v1/users/me", nil, h.Token(old.ID, old.Email)), http.StatusOK)
+}
+
+func TestRegistrationOverrides(t *testing.T) {
+	h := registrationHarness(t, "example.edu")
+	admin := newAdmin(t, h, "admin")
+	guest := h.Token(uuid.New().String(), "guest@gmail.com")
+
+	expectStatus(t, h.Do("POST", "/api/v1/admin/registration/overrides", map[string]string{"email": "guest"}, admin.Token), http.StatusBadRequest)
+	expectStatus(t, h.Do("POST", "/api/v1/admin/registration/overrides", map[string]string{"email": "x@gmail.com"}, signIn(h, "ada@example.edu")), http.StatusForbidden)
+
+	rec := h.Do("POST", "/api/v1/admin/registration/overrides", map[string]string{"email": "Guest@Gmail.com", "reason": "Visiting student"}, admin.Token)
+	expectStatus(t, rec, http.StatusOK)
+	if got := str(decode(t, rec), "data", "email"); got != "guest@gmail.com" {
+		t.Errorf("override email = %s", got)
+	}
+
+	rec = h.Do("GET", "/api/v1/admin/registration", nil, admin.Token)
+	expectStatus(t, rec, http.StatusOK)
+	body := decode(t, rec)
+	if domains, _ := body["allowed_email_domains"].([]interface{}); len(domains) != 1 || domains[0] != "example.edu" {
+		t.Errorf("allowed_email_domains = %v", body["allowed_email_domains"])
+	}
+	if overrides, _ := body["overrides"].([]interface{}); len(overrides) != 1 {
+		t.Errorf("overrides = %v", body["overrides"])
+	}
+
+	expectStatus(t, h.Do("GET", "/api/v1/users/me", nil, guest), http.StatusOK)
+
+	// Removing the override stops new sign-ups, but the guest keeps the
+	// account they already made
+	expectStatus(t, h.Do("DELETE", "/api/v1/admin/registration/overrides/guest@gmail.com", nil, admin.Token), http.StatusOK)
+	expectStatus(t, h.Do("DELETE", "/api/v1/admin/registration/overrides/guest@gmail.com", nil, admin.Token), http.StatusNotFound)
+	expectStatus(t, h.Do("GET", "/api/v1/users/me", nil, guest), http.StatusOK)
+	expectCode(t, h.Do("GET", "/api/v1/users/me", nil, signIn(h, "guest@gmail.com")), http.StatusForbidden, "email_domain_not_allowed")
+
+	rec = h.Do("GET", "/api/v1/admin/audit-log", nil, admin.Token)
+	expectStatus(t, rec, http.StatusOK)
+	actions := make(map[string]bool)
+	for _, entry := range decode(t, rec)["entries"].([]interface{}) {
+		actions[str(entry.(map[string]interface{}), "action")] = true
+	}
+	if !actions["registration.override"] || !actions["registration.override_revoke"] {
+		t.Errorf("unexpected audit log: %v", actions)
+	}
+}
+
+func TestCampaignEligibility(t *testing.T) {
+	h := harness.New()
+	newCampaign(t, h, map[string]interface{}{
+		"eligibility": map[string]interface{}{"years": []int{1, 2}, "email_domains": []string{"example.edu"}},
+	})
+	ada := newUser(t, h, "ada")
+
+	rec := h.Do("POST", "/api/v1/surveys", map[string]interface{}{
+		"responses":   completeAnswers(t, map[string]interface{}{"year": "3rd_year"}),
+		"is_complete": true,
+	}, ada.Token)
+	expectCode(t, rec, http.StatusForbidden, "year_not_eligible")
+	submitSurvey(t, h, ada, completeAnswers(t, map[string]interface{}{"year": "2nd_year"}))
+
+	// The campaign only admits its own domain, whoever may register
+	outsider := testUser{ID: uuid.New().String(), Email: "out@other.org"}
+	if err := h.CreateUser(context.Background(), &entities.User{ID: outsider.ID, Email: outsider.Email}); err != nil {
+		t.Fatal(err)
+	}
+	outsider.Token = h.Token(outsider.ID, outsider.Email)
+	rec = h.Do("POST", "/api/v1/surveys", map[string]interface{}{
+		"responses":   completeAnswers(t, nil),
+		"is_complete": true,
+	}, outsider.Token)
+	expectCode(t, rec, http.StatusForbidden, "email_domain_not_eligible")
+
+	// Drafts aren't held to the rules
+	expectStatus(t, h.Do("POST", "/api/v1/surveys", map[string]interface{}{
+		"responses": map[string]interface{}{"major": "cs"},
+	}, outsider.Token), http.StatusOK)
+}
@@ -0,0 +1,170 @@
+package routes_test
+
+import (
+	"net/http"
+	"testing"
+
+	"wizard-connect/internal/interface/http/harness"
+)
+
+// stagedMatches lists a campaign's staged matches as the review page sees them
+func stagedMatches(t *testing.T, h *harness.Harness, admin testUser, campaignID, query string) map[string]interface{} {
+	t.Helper()
+	rec := h.Do("GET", "/api/v1/admin/campaigns/"+campaignID+"/staged-matches"+query, nil, admin.Token)
+	expectStatus(t, rec, http.StatusOK)
+	return decode(t, rec)
+}
+
+// stagedPairs lists the user ID -> matched user ID pairs of staged matches
+func stagedPairs(body map[string]interface{}) map[[2]string]string {
+	pairs := make(map[[2]string]string)
+	list, _ := body["matches"].([]interface{})
+	for _, item := range list {
+		m := item.(map[string]interface{})
+		pairs[[2]string{str(m, "user_id"), str(m, "matched_user_id")}] = str(m, "id")
+	}
+	return pairs
+}
+
+// reviewSetup is a campaign whose three participants are all staged as
+// each other's matches
+func reviewSetup(t *testing.T) (h *harness.Harness, admin, ada, bea, cal testUser, campaignID string) {
+	t.Helper()
+	h = harness.New()
+	campaignID = newCampaign(t, h, nil).ID
+	admin = newAdmin(t, h, "admin")
+	ada, bea, cal = newUser(t, h, "ada"), newUser(t, h, "bea"), newUser(t, h, "cal")
+	for _, u := range []testUser{ada, bea, cal} {
+		submitSurvey(t, h, u, completeAnswers(t, nil))
+	}
+	runMatching(t, h, admin, campaignID)
+	return
+}
+
+func TestReviewEndpointsNeedAdmin(t *testing.T) {
+	h := harness.New()
+	campaignID := newCampaign(t, h, nil).ID
+	u := newUser(t, h, "ada")
+
+	expectStatus(t, h.Do("GET", "/api/v1/admin/campaigns/"+campaignID+"/staged-matches", nil, u.Token), http.StatusForbidden)
+	expectStatus(t, h.Do("POST", "/api/v1/admin/campaigns/"+campaignID+"/publish", nil, u.Token), http.StatusForbidden)
+}
+
+func TestRunsAreStagedNotPublished(t *testing.T) {
+	h, admin, ada, bea, cal, campaignID := reviewSetup(t)
+
+	body := stagedMatches(t, h, admin, campaignID, "")
+	pairs := stagedPairs(body)
+	if body["total"] != 6.0 || len(pairs) != 6 {
+		t.Fatalf("want every pair staged both ways: %v", body)
+	}
+	for _, pair := range [][2]string{{ada.ID, bea.ID}, {bea.ID, cal.ID}, {cal.ID, ada.ID}} {
+		if pairs[pair] == "" || pairs[[2]string{pair[1], pair[0]}] == "" {
+			t.Errorf("pair %v isn't staged both ways", pair)
+		}
+	}
+	if got := stagedMatches(t, h, admin, campaignID, "?user_id="+ada.ID)["count"]; got != 2.0 {
+		t.Errorf("ada has %v staged matches, want 2", got)
+	}
+
+	// Users don't see staged matches
+	rec := h.Do("GET", "/api/v1/matches", nil, ada.Token)
+	expectStatus(t, rec, http.StatusOK)
+	if got := matchedUserIDs(t, decode(t, rec)); len(got) != 0 {
+		t.Errorf("staged matches leaked to the user: %v", got)
+	}
+}
+
+func TestFlagVetoAndPublish(t *testing.T) {
+	h, admin, ada, bea, cal, campaignID := reviewSetup(t)
+	base := "/api/v1/admin/campaigns/" + campaignID
+	pairs := stagedPairs(stagedMatches(t, h, admin, campaignID, ""))
+
+	// Flagging marks both directions of the pair
+	flagPath := base + "/staged-matches/" + pairs[[2]string{ada.ID, bea.ID}] + "/flag"
+	expectStatus(t, h.Do("POST", flagPath, map[string]string{}, admin.Token), http.StatusBadRequest)
+	rec := h.Do("POST", flagPath, map[string]string{"reason": "same dorm"}, admin.Token)
+	expectStatus(t, rec, http.StatusOK)
+	if got := decode(t, rec)["matches"].([]interface{}); len(got) != 2 {
+		t.Errorf("flagged %d matches, want 2", len(got))
+	}
+	if got := stagedMatches(t, h, admin, campaignID, "?flagged=true")["count"]; got != 2.0 {
+		t.Errorf("%v flagged matches listed, want 2", got)
+	}
+
+	// Publishing is refused while pairs are flagged
+	rec = h.Do("POST", base+"/publish", nil, admin.Token)
+	expectStatus(t, rec, http.StatusConflict)
+	if got := decode(t, rec)["flagged"]; got != 2.0 {
+		t.Errorf("flagged = %v, want 2", got)
+	}
+
+	// A veto drops the pair from the staged matches
+	veto := map[string]string{"user_id": cal.ID, "matched_user_id": ada.ID, "reason": "exes"}
+	expectStatus(t, h.Do("POST", base+"/vetoes", veto, admin.Token), http.StatusCreated)
+	expectStatus(t, h.Do("POST", base+"/vetoes", veto, admin.Token), http.StatusConflict)
+	remaining := stagedPairs(stagedMatches(t, h, admin, campaignID, ""))
+	if _, ok := remaining[[2]string{ada.ID, cal.ID}]; ok || len(remaining) != 4 {
+		t.Errorf("vetoed pair still staged: %v", remaining)
+	}
+
+	// Pinning a vetoed pair is refused
+	pin := map[string]string{"user_id": ada.ID, "matched_user_id": cal.ID}
+	expectStatus(t, h.Do("POST", base+"/pins", pin, admin.Token), http.StatusConflict)
+
+	rec = h.Do("POST", base+"/publish", map[string]bool{"allow_flagged": true}, admin.Token)
+	expectStatus(t, rec, http.StatusOK)
+
+	rec = h.Do("GET", "/api/v1/matches", nil, ada.Token)
+	expectStatus(t, rec, http.StatusOK)
+	if got := matchedUserIDs(t, decode(t, rec)); len(got) != 1 || !got[bea.ID] {
+		t.Errorf("ada's published matches: %v", got)
+	}
+	if got := stagedMatches(t, h, admin, campaignID, "")["total"]; got != 0.0 {
+		t.Errorf("%v staged matches left after publishing", got)
+	}
+
+	// A later run never stages the vetoed pair again
+	runMatching(t, h, admin, campaignID)
+	if _, ok := stagedPairs(stagedMatches(t, h, admin, campaignID, ""))[[2]string{ada.ID, cal.ID}]; ok {
+		t.Error("vetoed pair staged by a later run")
+	}
+
+	rec = h.Do("GET", "/api/v1/admin/audit-log", nil, admin.Token)
+	expectStatus(t, rec, http.StatusOK)
+	actions := make(map[string]bool)
+	for _, entry := range decode(t, rec)["entries"].([]interface{}) {
+		actions[str(entry.(map[string]interface{}), "action")] = true
+	}
+	for _, action := range []string{"match.flag", "match.veto", "matches.publish"} {
+		if !actions[action] {
+			t.Errorf("audit log has no %s entry", action)
+		}
+	}
+}
+
+func TestRevokeVeto(t *testing.T) {
+	h, admin, ada, _, cal, campaignID := reviewSetup(t)
+
+	rec := h.Do("POST", "/api/v1/admin/campaigns/"+campaignID+"/vetoes", map[string]string{
+		"user_id": ada.ID, "matched_user_id": cal.ID, "reason": "exes",
+	}, admin.Token)
+	expectStatus(t, rec, http.StatusCreated)
+
+	rec = h.Do("GET", "/api/v1/admin/vetoes", nil, admin.Token)
+	expectStatus(t, rec, http.StatusOK)
+	vetoes, _ := decode(t, rec)["vetoes"].([]interface{})
+	if len(vetoes) != 1 {
+		t.Fatalf("unexpected vetoes: %s", rec.Body.String())
+	}
+	vetoID := str(vetoes[0].(map[string]interface{}), "id")
+
+	expectStatus(t, h.Do("DELETE", "/api/v1/admin/vetoes/"+vetoID, nil, admin.Token), http.StatusOK)
+	expectStatus(t, h.Do("DELETE", "/api/v1/admin/vetoes/"+vetoID, nil, admin.Token), http.StatusNotFound)
+
+	// With the veto gone the pair can be pinned
+	rec = h.Do("POST", "/api/v1/admin/campaigns/"+campaignID+"/pins", map[string]string{
+		"user_id": ada.ID, "matched_user_id": cal.ID,
+	}, admin.Token)
+	expectStatus(t, rec, http.StatusOK)
+}
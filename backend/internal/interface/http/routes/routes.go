@@ -1,9 +1,15 @@
 package routes
 
 import (
+	"fmt"
+	"log"
+	"os"
+
 	"wizard-connect/internal/config"
+	"wizard-connect/internal/domain/repositories"
 	"wizard-connect/internal/domain/services"
 	"wizard-connect/internal/infrastructure/database"
+	"wizard-connect/internal/infrastructure/storage"
 	"wizard-connect/internal/interface/http/controllers"
 	"wizard-connect/internal/interface/http/middleware"
 	"wizard-connect/internal/interface/websocket"
@@ -11,44 +17,122 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-func SetupRoutes(rootRouter *gin.Engine, apiGroup *gin.RouterGroup, db *database.Database, cfg *config.Config) {
+// Repositories are the stores the API is built on. The server uses the
+// Postgres implementations; tests can swap in internal/infrastructure/memory.
+type Repositories struct {
+	Users                 repositories.UserRepository
+	Surveys               repositories.SurveyRepository
+	Matches               repositories.MatchRepository
+	Crushes               repositories.CrushRepository
+	Messages              repositories.MessageRepository
+	Conversations         repositories.ConversationRepository
+	Campaigns             repositories.CampaignRepository
+	Admins                repositories.AdminRepository
+	Runs                  repositories.MatchingRunRepository
+	StagedMatches         repositories.StagedMatchRepository
+	Vetoes                repositories.MatchVetoRepository
+	AuditLog              repositories.AuditLogRepository
+	Feedback              repositories.MatchFeedbackRepository
+	Blocks                repositories.UserBlockRepository
+	Reports               repositories.ReportRepository
+	Moderation            repositories.ModerationActionRepository
+	RegistrationOverrides repositories.RegistrationOverrideRepository
+}
+
+// NewDatabaseRepositories builds every repository on top of Postgres
+func NewDatabaseRepositories(db *database.Database) Repositories {
+	return Repositories{
+		Users:                 database.NewUserRepository(db),
+		Surveys:               database.NewSurveyRepository(db),
+		Matches:               database.NewMatchRepository(db),
+		Crushes:               database.NewCrushRepository(db),
+		Messages:              database.NewMessageRepository(db),
+		Conversations:         database.NewConversationRepository(db),
+		Campaigns:             database.NewCampaignRepository(db),
+		Admins:                database.NewAdminRepository(db),
+		Runs:                  database.NewMatchingRunRepository(db),
+		StagedMatches:         database.NewStagedMatchRepository(db),
+		Vetoes:                database.NewMatchVetoRepository(db),
+		AuditLog:              database.NewAuditLogRepository(db),
+		Feedback:              database.NewMatchFeedbackRepository(db),
+		Blocks:                database.NewUserBlockRepository(db),
+		Reports:               database.NewReportRepository(db),
+		Moderation:            database.NewModerationActionRepository(db),
+		RegistrationOverrides: database.NewRegistrationOverrideRepository(db),
+	}
+}
+
+func SetupRoutes(rootRouter *gin.Engine, apiGroup *gin.RouterGroup, repos Repositories, cfg *config.Config) {
 	// Initialize repositories
-	userRepo := database.NewUserRepository(db)
-	surveyRepo := database.NewSurveyRepository(db)
-	matchRepo := database.NewMatchRepository(db)
-	crushRepo := database.NewCrushRepository(db)
-	messageRepo := database.NewMessageRepository(db)
-	conversationRepo := database.NewConversationRepository(db)
-	campaignRepo := database.NewCampaignRepository(db)
-	adminRepo := database.NewAdminRepository(db)
+	userRepo := repos.Users
+	surveyRepo := repos.Surveys
+	matchRepo := repos.Matches
+	crushRepo := repos.Crushes
+	messageRepo := repos.Messages
+	conversationRepo := repos.Conversations
+	campaignRepo := repos.Campaigns
+	adminRepo := repos.Admins
+	runRepo := repos.Runs
+	stagedRepo := repos.StagedMatches
+	vetoRepo := repos.Vetoes
+	auditRepo := repos.AuditLog
+	feedbackRepo := repos.Feedback
+	blockRepo := repos.Blocks
+	reportRepo := repos.Reports
+	moderationRepo := repos.Moderation
+	overrideRepo := repos.RegistrationOverrides
 
 	// Initialize services
-	matchingService := services.NewMatchingService(surveyRepo, crushRepo, matchRepo, userRepo)
+	matchingService := services.NewMatchingService(surveyRepo, crushRepo, matchRepo, userRepo, vetoRepo, blockRepo, moderationRepo, log.New(os.Stdout, "", 0))
+	statsService := services.NewStatisticsService(userRepo, surveyRepo, matchRepo, stagedRepo, conversationRepo, messageRepo, campaignRepo)
+	contentPolicyConfig, err := services.LoadContentPolicyConfig(cfg.Content.PolicyFile)
+	if err != nil {
+		panic("Failed to load content policy: " + err.Error())
+	}
+	profileService := services.NewProfileService(userRepo, matchRepo, blockRepo)
+	objectStorage, err := newObjectStorage(cfg.Storage, cfg.Server.Port)
+	if err != nil {
+		panic("Failed to initialize storage: " + err.Error())
+	}
+	avatarService := services.NewAvatarService(objectStorage)
+	contentService := services.NewContentService(services.NewLocalContentPolicy(contentPolicyConfig), reportRepo)
+	registrationService := services.NewRegistrationService(cfg.Registration.AllowedEmailDomains, userRepo, overrideRepo)
 
 	// Initialize controllers
-	userController := controllers.NewUserController(userRepo)
-	surveyController := controllers.NewSurveyController(surveyRepo)
-	matchController := controllers.NewMatchController(matchRepo, surveyRepo, matchingService)
+	userController := controllers.NewUserController(userRepo, contentService, profileService, avatarService, registrationService)
+	surveyController := controllers.NewSurveyController(surveyRepo, campaignRepo, userRepo, matchingService)
 	crushController := controllers.NewCrushController(crushRepo)
-	campaignController := controllers.NewCampaignController(campaignRepo, matchingService, *surveyRepo, matchRepo)
-	adminController := controllers.NewAdminController(adminRepo, userRepo, matchRepo)
+	campaignController := controllers.NewCampaignController(campaignRepo, matchingService, surveyRepo, matchRepo, runRepo, stagedRepo, statsService)
+	adminController := controllers.NewAdminController(adminRepo, userRepo, matchRepo, surveyRepo, auditRepo, blockRepo)
+	accountController := controllers.NewAccountController(userRepo, surveyRepo, crushRepo, matchRepo, feedbackRepo, conversationRepo, messageRepo, blockRepo, reportRepo, moderationRepo, auditRepo, avatarService)
+	safetyController := controllers.NewSafetyController(userRepo, blockRepo, reportRepo, messageRepo, conversationRepo)
+	registrationController := controllers.NewRegistrationController(registrationService, overrideRepo, auditRepo)
+	reviewController := controllers.NewMatchReviewController(campaignRepo, stagedRepo, vetoRepo, blockRepo, auditRepo, matchRepo, userRepo, surveyRepo, statsService)
 
 	// Initialize websocket handler
-	socketHandler, err := websocket.NewSocketHandler(conversationRepo, messageRepo, userRepo)
+	socketHandler, err := websocket.NewSocketHandler(conversationRepo, messageRepo, userRepo, blockRepo)
 	if err != nil {
 		// Log error but don't panic if WS fails to init?
 		// Actually WS is crucial now.
 		panic("Failed to initialize Socket.IO handler: " + err.Error())
 	}
 
-	messageController := controllers.NewMessageController(conversationRepo, messageRepo, userRepo, socketHandler.Server)
+	matchController := controllers.NewMatchController(matchRepo, surveyRepo, matchingService, feedbackRepo, campaignRepo, blockRepo, socketHandler.Server)
+	messageController := controllers.NewMessageController(conversationRepo, messageRepo, userRepo, matchRepo, blockRepo, moderationRepo, contentService, socketHandler.Server)
+	moderationController := controllers.NewModerationController(reportRepo, moderationRepo, userRepo, messageRepo, adminRepo, blockRepo, auditRepo, socketHandler.Server)
 
-	// Mount websocket handler on root router - allow all methods for socket.io
-	rootRouter.Any("/socket.io/*any", socketHandler.Handler())
+	// Locally stored uploads are served by the API itself
+	if cfg.Storage.Driver == "" || cfg.Storage.Driver == "local" {
+		rootRouter.Static("/uploads", localUploadDir(cfg.Storage))
+	}
 
 	// Initialize auth middleware
-	authMiddleware := middleware.NewAuthMiddleware(cfg.Auth.JWTSecret)
+	authMiddleware := middleware.NewAuthMiddleware(cfg.Auth.JWTSecret, moderationRepo, userRepo)
 	adminMiddleware := middleware.NewAdminMiddleware(adminRepo)
+	registrationMiddleware := middleware.NewRegistrationMiddleware(registrationService)
+
+	// Mount websocket handler on root router - allow all methods for socket.io
+	rootRouter.Any("/socket.io/*any", authMiddleware.AuthenticateSocket(), socketHandler.Handler())
 
 	// Public routes settings
 	public := apiGroup.Group("")
@@ -61,14 +145,22 @@ func SetupRoutes(rootRouter *gin.Engine, apiGroup *gin.RouterGroup, db *database
 
 	// Protected routes (require authentication)
 	protected := apiGroup.Group("")
-	protected.Use(authMiddleware.Authenticate())
+	protected.Use(authMiddleware.Authenticate(), registrationMiddleware.RequireRegistration())
 	{
 		// User routes
 		users := protected.Group("/users")
 		{
 			users.GET("/me", userController.GetProfile)
 			users.PUT("/me", userController.UpdateProfile)
+			users.PATCH("/me", userController.UpdateProfile)
+			users.DELETE("/me", accountController.DeleteAccount)
+			users.GET("/me/export", accountController.ExportAccount)
+			users.POST("/me/avatar", userController.UploadAvatar)
+			users.GET("/me/blocks", safetyController.GetBlocks)
 			users.GET("/:id", userController.GetUserProfileByID)
+			users.POST("/:id/block", safetyController.BlockUser)
+			users.DELETE("/:id/block", safetyController.UnblockUser)
+			users.POST("/:id/report", safetyController.ReportUser)
 		}
 
 		// Survey routes
@@ -76,13 +168,22 @@ func SetupRoutes(rootRouter *gin.Engine, apiGroup *gin.RouterGroup, db *database
 		{
 			surveys.GET("", surveyController.GetSurvey)
 			surveys.POST("", surveyController.SubmitSurvey)
+			surveys.GET("/definition", surveyController.GetSurveyDefinition)
+			surveys.PATCH("/sections/:section", surveyController.SaveSurveySection)
+			surveys.GET("/prefill", surveyController.GetSurveyPrefill)
+			surveys.GET("/history", surveyController.GetSurveyHistory)
+			surveys.GET("/diff", surveyController.DiffSurveys)
+			surveys.GET("/pool", surveyController.GetEligiblePool)
 		}
 
 		// Match routes
 		matches := protected.Group("/matches")
 		{
 			matches.GET("", matchController.GetMatches)
-			matches.POST("/generate", matchController.GenerateMatches)
+			matches.POST("/:id/view", matchController.ViewMatch)
+			matches.POST("/:id/respond", matchController.RespondToMatch)
+			matches.GET("/:id/feedback", matchController.GetFeedback)
+			matches.POST("/:id/feedback", matchController.SubmitFeedback)
 		}
 
 		// Message routes
@@ -117,12 +218,36 @@ func SetupRoutes(rootRouter *gin.Engine, apiGroup *gin.RouterGroup, db *database
 			users := admin.Group("/users")
 			{
 				users.GET("", adminController.GetAllUsers)
+				users.GET("/:id/moderation", moderationController.GetUserHistory)
+				users.POST("/:id/moderation", moderationController.ModerateUser)
 			}
 			matchesGroup := admin.Group("/matches")
 			{
 				matchesGroup.GET("", adminController.GetAllMatches)
 				matchesGroup.POST("/manual", adminController.CreateManualMatch)
 			}
+			admin.GET("/vetoes", reviewController.ListVetoes)
+			admin.DELETE("/vetoes/:vetoId", reviewController.RevokeVeto)
+			admin.GET("/audit-log", reviewController.GetAuditLog)
+
+			// Who may sign up
+			registration := admin.Group("/registration")
+			{
+				registration.GET("", registrationController.GetRegistration)
+				registration.POST("/overrides", registrationController.AddOverride)
+				registration.DELETE("/overrides/:email", registrationController.RemoveOverride)
+			}
+
+			// Moderation queue
+			reports := admin.Group("/reports")
+			{
+				reports.GET("", moderationController.ListReports)
+				reports.GET("/:reportId", moderationController.GetReport)
+				reports.POST("/:reportId/assign", moderationController.AssignReport)
+				reports.POST("/:reportId/dismiss", moderationController.DismissReport)
+				reports.POST("/:reportId/action", moderationController.ActOnReport)
+			}
+			admin.DELETE("/moderation/actions/:actionId", moderationController.RevokeAction)
 
 			// Campaign management
 			campaigns := admin.Group("/campaigns")
@@ -134,7 +259,41 @@ func SetupRoutes(rootRouter *gin.Engine, apiGroup *gin.RouterGroup, db *database
 				campaigns.DELETE("/:id", campaignController.DeleteCampaign)
 				campaigns.POST("/:id/run-algorithm", campaignController.RunMatchingAlgorithm)
 				campaigns.GET("/:id/statistics", campaignController.GetCampaignStatistics)
+				campaigns.GET("/:id/eligibility", campaignController.GetEligibilityReport)
+				campaigns.GET("/:id/runs", campaignController.GetMatchingRuns)
+				campaigns.GET("/:id/runs/:runId", campaignController.GetMatchingRun)
+				campaigns.POST("/:id/runs/:runId/replay", campaignController.ReplayMatchingRun)
+
+				// Review of staged matches before they go live
+				campaigns.GET("/:id/staged-matches", reviewController.ListStagedMatches)
+				campaigns.POST("/:id/staged-matches/:matchId/flag", reviewController.FlagStagedMatch)
+				campaigns.DELETE("/:id/staged-matches/:matchId/flag", reviewController.UnflagStagedMatch)
+				campaigns.POST("/:id/vetoes", reviewController.VetoPair)
+				campaigns.POST("/:id/pins", reviewController.PinMatch)
+				campaigns.POST("/:id/publish", reviewController.PublishMatches)
 			}
 		}
 	}
 }
+
+// newObjectStorage builds the storage uploads go to
+func newObjectStorage(cfg config.StorageConfig, port string) (services.ObjectStorage, error) {
+	switch cfg.Driver {
+	case "", "local":
+		publicURL := cfg.PublicURL
+		if publicURL == "" {
+			publicURL = "http://localhost:" + port + "/uploads"
+		}
+		return storage.NewLocalStorage(localUploadDir(cfg), publicURL), nil
+	case "s3":
+		return storage.NewS3Storage(cfg.S3Endpoint, cfg.S3Region, cfg.S3Bucket, cfg.S3AccessKeyID, cfg.S3SecretAccessKey, cfg.PublicURL)
+	}
+	return nil, fmt.Errorf("unknown storage driver %q", cfg.Driver)
+}
+
+func localUploadDir(cfg config.StorageConfig) string {
+	if cfg.LocalDir == "" {
+		return "./uploads"
+	}
+	return cfg.LocalDir
+}
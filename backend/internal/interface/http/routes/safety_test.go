@@ -0,0 +1,169 @@
+package routes_test
+
+import (
+	"net/http"
+	"testing"
+
+	"wizard-connect/internal/interface/http/harness"
+
+	"github.com/google/uuid"
+)
+
+// sendMessage opens a's conversation with b and sends content in it
+func sendMessage(t *testing.T, h *harness.Harness, a, b testUser, content string) (convID, messageID string) {
+	t.Helper()
+	rec := h.Do("POST", "/api/v1/messages/conversations", map[string]string{"other_user_id": b.ID}, a.Token)
+	if rec.Code != http.StatusOK {
+		expectStatus(t, rec, http.StatusCreated)
+	}
+	convID = str(decode(t, rec), "data", "id")
+	rec = h.Do("POST", "/api/v1/messages/conversations/"+convID+"/messages", map[string]string{"content": content}, a.Token)
+	expectStatus(t, rec, http.StatusCreated)
+	return convID, str(decode(t, rec), "data", "id")
+}
+
+func TestBlockUser(t *testing.T) {
+	h := harness.New()
+	ada := newUser(t, h, "ada")
+	bea := newUser(t, h, "bea")
+	unlockedPair(t, h, ada, bea)
+	convID, _ := sendMessage(t, h, ada, bea, "Hi!")
+
+	expectStatus(t, h.Do("POST", "/api/v1/users/"+ada.ID+"/block", nil, ada.Token), http.StatusBadRequest)
+	expectStatus(t, h.Do("POST", "/api/v1/users/"+uuid.New().String()+"/block", nil, ada.Token), http.StatusNotFound)
+	expectStatus(t, h.Do("POST", "/api/v1/users/"+bea.ID+"/block", nil, ada.Token), http.StatusOK)
+
+	rec := h.Do("GET", "/api/v1/users/me/blocks", nil, ada.Token)
+	expectStatus(t, rec, http.StatusOK)
+	if blocks, _ := decode(t, rec)["data"].([]interface{}); len(blocks) != 1 || str(blocks[0].(map[string]interface{}), "blocked_id") != bea.ID {
+		t.Errorf("unexpected blocks: %s", rec.Body.String())
+	}
+
+	// Neither sees the other among their matches any more
+	for _, u := range []testUser{ada, bea} {
+		rec := h.Do("GET", "/api/v1/matches", nil, u.Token)
+		expectStatus(t, rec, http.StatusOK)
+		if got := matchedUserIDs(t, decode(t, rec)); len(got) != 0 {
+			t.Errorf("%s still sees a blocked match: %v", u.Email, got)
+		}
+	}
+
+	// And neither can message the other
+	path := "/api/v1/messages/conversations/" + convID + "/messages"
+	expectStatus(t, h.Do("POST", path, map[string]string{"content": "Hello?"}, ada.Token), http.StatusForbidden)
+	expectStatus(t, h.Do("POST", path, map[string]string{"content": "Hello?"}, bea.Token), http.StatusForbidden)
+	expectStatus(t, h.Do("POST", "/api/v1/messages/conversations", map[string]string{"other_user_id": ada.ID}, bea.Token), http.StatusForbidden)
+
+	// Only the blocker can lift the block
+	expectStatus(t, h.Do("DELETE", "/api/v1/users/"+ada.ID+"/block", nil, bea.Token), http.StatusOK)
+	expectStatus(t, h.Do("POST", path, map[string]string{"content": "Hello?"}, bea.Token), http.StatusForbidden)
+	expectStatus(t, h.Do("DELETE", "/api/v1/users/"+bea.ID+"/block", nil, ada.Token), http.StatusOK)
+	expectStatus(t, h.Do("POST", path, map[string]string{"content": "Hello?"}, bea.Token), http.StatusCreated)
+}
+
+func TestReadConversationNeedsParticipant(t *testing.T) {
+	h := harness.New()
+	ada := newUser(t, h, "ada")
+	bea := newUser(t, h, "bea")
+	eve := newUser(t, h, "eve")
+	unlockedPair(t, h, ada, bea)
+	convID, _ := sendMessage(t, h, ada, bea, "Hi!")
+	path := "/api/v1/messages/conversations/" + convID
+
+	expectStatus(t, h.Do("GET", path, nil, eve.Token), http.StatusNotFound)
+	expectStatus(t, h.Do("GET", "/api/v1/messages/conversations/"+uuid.New().String(), nil, ada.Token), http.StatusNotFound)
+
+	// Eve's attempt didn't mark the message read for bea
+	rec := h.Do("GET", "/api/v1/messages/conversations", nil, bea.Token)
+	expectStatus(t, rec, http.StatusOK)
+	convs, _ := decode(t, rec)["data"].([]interface{})
+	if len(convs) != 1 || field(convs[0].(map[string]interface{}), "unread_count") != 1.0 {
+		t.Errorf("unexpected conversations: %s", rec.Body.String())
+	}
+
+	rec = h.Do("GET", path, nil, bea.Token)
+	expectStatus(t, rec, http.StatusOK)
+	if messages, _ := decode(t, rec)["data"].([]interface{}); len(messages) != 1 {
+		t.Errorf("unexpected messages: %s", rec.Body.String())
+	}
+
+	// A block hides the history from both sides until it is lifted
+	expectStatus(t, h.Do("POST", "/api/v1/users/"+ada.ID+"/block", nil, bea.Token), http.StatusOK)
+	expectStatus(t, h.Do("GET", path, nil, ada.Token), http.StatusNotFound)
+	expectStatus(t, h.Do("GET", path, nil, bea.Token), http.StatusNotFound)
+	expectStatus(t, h.Do("DELETE", "/api/v1/users/"+ada.ID+"/block", nil, bea.Token), http.StatusOK)
+	expectStatus(t, h.Do("GET", path, nil, ada.Token), http.StatusOK)
+}
+
+func TestBlockedPairsAreNotMatched(t *testing.T) {
+	h := harness.New()
+	campaignID := newCampaign(t, h, nil).ID
+	admin := newAdmin(t, h, "admin")
+	ada, bea, cal := newUser(t, h, "ada"), newUser(t, h, "bea"), newUser(t, h, "cal")
+	for _, u := range []testUser{ada, bea, cal} {
+		submitSurvey(t, h, u, completeAnswers(t, nil))
+	}
+	expectStatus(t, h.Do("POST", "/api/v1/users/"+ada.ID+"/block", nil, cal.Token), http.StatusOK)
+
+	runMatching(t, h, admin, campaignID)
+	pairs := stagedPairs(stagedMatches(t, h, admin, campaignID, ""))
+	if _, ok := pairs[[2]string{ada.ID, cal.ID}]; ok || len(pairs) != 4 {
+		t.Errorf("blocked pair was matched: %v", pairs)
+	}
+}
+
+func TestReportUser(t *testing.T) {
+	h := harness.New()
+	admin := newAdmin(t, h, "admin")
+	ada := newUser(t, h, "ada")
+	bea := newUser(t, h, "bea")
+	cal := newUser(t, h, "cal")
+	unlockedPair(t, h, ada, bea)
+	unlockedPair(t, h, bea, cal)
+	_, rude := sendMessage(t, h, bea, ada, "Something rude")
+	_, other := sendMessage(t, h, bea, cal, "Something else")
+	path := "/api/v1/users/" + bea.ID + "/report"
+
+	rec := h.Do("POST", path, map[string]string{"category": "rudeness"}, ada.Token)
+	expectStatus(t, rec, http.StatusBadRequest)
+	if decode(t, rec)["categories"] == nil {
+		t.Errorf("expected the valid categories: %s", rec.Body.String())
+	}
+
+	// Evidence must come from a conversation between the two
+	expectStatus(t, h.Do("POST", path, map[string]interface{}{
+		"category": "harassment", "message_ids": []string{other},
+	}, ada.Token), http.StatusBadRequest)
+
+	rec = h.Do("POST", path, map[string]interface{}{
+		"category":    "harassment",
+		"description": "Rude messages",
+		"message_ids": []string{rude, rude},
+		"block":       true,
+	}, ada.Token)
+	expectStatus(t, rec, http.StatusCreated)
+	if got := str(decode(t, rec), "data", "status"); got != "open" {
+		t.Errorf("status = %s, want open", got)
+	}
+
+	// Reporting with block also blocks
+	expectStatus(t, h.Do("POST", "/api/v1/messages/conversations", map[string]string{"other_user_id": ada.ID}, bea.Token), http.StatusForbidden)
+
+	// The report lands in the moderation queue with the cited message
+	rec = h.Do("GET", "/api/v1/admin/reports?user_id="+bea.ID, nil, admin.Token)
+	expectStatus(t, rec, http.StatusOK)
+	reports, _ := decode(t, rec)["reports"].([]interface{})
+	if len(reports) != 1 {
+		t.Fatalf("unexpected reports: %s", rec.Body.String())
+	}
+	report := reports[0].(map[string]interface{})
+	evidence, _ := report["evidence"].([]interface{})
+	if str(report, "reporter_id") != ada.ID || str(report, "category") != "harassment" || len(evidence) != 1 {
+		t.Fatalf("unexpected report: %v", report)
+	}
+	if got := str(evidence[0].(map[string]interface{}), "content"); got != "Something rude" {
+		t.Errorf("evidence content = %q", got)
+	}
+
+	expectStatus(t, h.Do("GET", "/api/v1/admin/reports", nil, ada.Token), http.StatusForbidden)
+}
@@ -0,0 +1,37 @@
+package routes_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"wizard-connect/internal/interface/http/harness"
+)
+
+// socketHandshake opens a Socket.IO polling connection
+const socketHandshake = "/socket.io/?EIO=3&transport=polling"
+
+func TestSocketNeedsToken(t *testing.T) {
+	h := harness.New()
+	admin := newAdmin(t, h, "admin")
+	ada := newUser(t, h, "ada")
+	bea := newUser(t, h, "bea")
+
+	expectStatus(t, h.Do("GET", socketHandshake, nil, ""), http.StatusUnauthorized)
+
+	// Browsers send the token in the query, other clients in the header
+	for _, rec := range []*httptest.ResponseRecorder{
+		h.Do("GET", socketHandshake+"&token="+ada.Token, nil, ""),
+		h.Do("GET", socketHandshake, nil, ada.Token),
+	} {
+		expectStatus(t, rec, http.StatusOK)
+		if !strings.Contains(rec.Body.String(), `"sid"`) {
+			t.Errorf("no session opened: %s", rec.Body.String())
+		}
+	}
+
+	// Suspended accounts are refused like on the API
+	moderate(t, h, admin, bea, map[string]interface{}{"action": "suspend", "reason": "spam", "duration_hours": 24})
+	expectCode(t, h.Do("GET", socketHandshake+"&token="+bea.Token, nil, ""), http.StatusForbidden, "account_suspended")
+}
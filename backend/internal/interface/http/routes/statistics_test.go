@@ -0,0 +1,111 @@
+package routes_test
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"wizard-connect/internal/interface/http/harness"
+)
+
+// campaignStatistics fetches a campaign's statistics, recomputing them if
+// refresh is set
+func campaignStatistics(t *testing.T, h *harness.Harness, admin testUser, campaignID string, refresh bool) map[string]interface{} {
+	t.Helper()
+	path := "/api/v1/admin/campaigns/" + campaignID + "/statistics"
+	if refresh {
+		path += "?refresh=true"
+	}
+	rec := h.Do("GET", path, nil, admin.Token)
+	expectStatus(t, rec, http.StatusOK)
+	return decode(t, rec)
+}
+
+func TestCampaignStatistics(t *testing.T) {
+	h, admin, ada, bea, _, campaignID := reviewSetup(t)
+	// Eve started the survey but never finished it
+	eve := newUser(t, h, "eve")
+	expectStatus(t, h.Do("PATCH", "/api/v1/surveys/sections/demographics", map[string]interface{}{
+		"responses": map[string]interface{}{"major": "cs"},
+	}, eve.Token), http.StatusOK)
+
+	stats := campaignStatistics(t, h, admin, campaignID, true)
+	for path, want := range map[string]float64{
+		"total_participants":      3,
+		"funnel.registered_users": 5,
+		"funnel.started":          4,
+		"funnel.completed":        3,
+		"funnel.matched":          0,
+		"staged_matches":          6,
+		"total_matches":           0,
+	} {
+		if got := field(stats, strings.Split(path, ".")...); got != want {
+			t.Errorf("%s = %v, want %v", path, got, want)
+		}
+	}
+
+	expectStatus(t, h.Do("POST", "/api/v1/admin/campaigns/"+campaignID+"/publish", nil, admin.Token), http.StatusOK)
+
+	// Publishing refreshes the cached statistics
+	stats = campaignStatistics(t, h, admin, campaignID, false)
+	for path, want := range map[string]float64{
+		"total_matches":           6,
+		"matched_pairs":           3,
+		"staged_matches":          0,
+		"funnel.matched":          3,
+		"matches_per_participant": 2,
+	} {
+		if got := field(stats, strings.Split(path, ".")...); got != want {
+			t.Errorf("after publish %s = %v, want %v", path, got, want)
+		}
+	}
+	if score, _ := stats["average_compatibility"].(float64); score <= 0 || score > 100 {
+		t.Errorf("average_compatibility = %v", stats["average_compatibility"])
+	}
+	histogram, _ := stats["score_histogram"].([]interface{})
+	sum := 0.0
+	for _, n := range histogram {
+		sum += n.(float64)
+	}
+	if len(histogram) != 10 || sum != 6 {
+		t.Errorf("score_histogram = %v", histogram)
+	}
+
+	// A conversation between one matched pair
+	adaBea, beaAda := publishedMatchID(t, h, ada, bea), publishedMatchID(t, h, bea, ada)
+	interested := map[string]string{"response": "interested"}
+	expectStatus(t, h.Do("POST", "/api/v1/matches/"+adaBea+"/respond", interested, ada.Token), http.StatusOK)
+	expectStatus(t, h.Do("POST", "/api/v1/matches/"+beaAda+"/respond", interested, bea.Token), http.StatusOK)
+	rec := h.Do("POST", "/api/v1/messages/conversations", map[string]string{"other_user_id": bea.ID}, ada.Token)
+	expectStatus(t, rec, http.StatusCreated)
+	convID := str(decode(t, rec), "data", "id")
+	for _, content := range []string{"Hi!", "How are you?"} {
+		expectStatus(t, h.Do("POST", "/api/v1/messages/conversations/"+convID+"/messages", map[string]string{"content": content}, ada.Token), http.StatusCreated)
+	}
+
+	stats = campaignStatistics(t, h, admin, campaignID, true)
+	for path, want := range map[string]float64{
+		"conversations":             1,
+		"messages_per_conversation": 2,
+		"engagement.interested":     2,
+		"engagement.pairs_unlocked": 1,
+	} {
+		if got := field(stats, strings.Split(path, ".")...); got != want {
+			t.Errorf("with a conversation %s = %v, want %v", path, got, want)
+		}
+	}
+
+	// The totals are kept on the campaign too
+	rec = h.Do("GET", "/api/v1/admin/campaigns/"+campaignID, nil, admin.Token)
+	expectStatus(t, rec, http.StatusOK)
+	body := decode(t, rec)
+	if field(body, "total_participants") != 3.0 || field(body, "total_matches_generated") != 6.0 {
+		t.Errorf("campaign totals not updated: %s", rec.Body.String())
+	}
+}
+
+func TestCampaignStatisticsUnknownCampaign(t *testing.T) {
+	h := harness.New()
+	admin := newAdmin(t, h, "admin")
+	expectStatus(t, h.Do("GET", "/api/v1/admin/campaigns/nope/statistics", nil, admin.Token), http.StatusNotFound)
+}
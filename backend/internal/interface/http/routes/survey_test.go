@@ -0,0 +1,192 @@
+package routes_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"wizard-connect/internal/interface/http/harness"
+)
+
+// doIfMatch is h.Do with an If-Match header
+func doIfMatch(t *testing.T, h *harness.Harness, method, path string, body interface{}, token, etag string) *httptest.ResponseRecorder {
+	t.Helper()
+	data, err := json.Marshal(body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := httptest.NewRequest(method, path, bytes.NewReader(data))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("If-Match", etag)
+	rec := httptest.NewRecorder()
+	h.Router.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestSurveySectionAutosave(t *testing.T) {
+	h := harness.New()
+	u := newUser(t, h, "ada")
+
+	rec := h.Do("PATCH", "/api/v1/surveys/sections/demographics", map[string]interface{}{
+		"responses": map[string]interface{}{"year": "2nd_year", "major": "cs"},
+	}, u.Token)
+	expectStatus(t, rec, http.StatusOK)
+	if got := rec.Header().Get("ETag"); got != `"1"` {
+		t.Errorf("ETag = %s, want \"1\"", got)
+	}
+	body := decode(t, rec)
+	if field(body, "data", "is_complete") != false {
+		t.Errorf("a single section shouldn't complete the survey: %s", rec.Body.String())
+	}
+	if field(body, "completeness", "sections", "demographics", "answered") != 2.0 {
+		t.Errorf("demographics progress: %v", field(body, "completeness", "sections", "demographics"))
+	}
+
+	// Saving on top of the revision the client saw bumps it
+	rec = h.Do("PATCH", "/api/v1/surveys/sections/personality", map[string]interface{}{
+		"responses": map[string]interface{}{"personality_introvert": "4"},
+		"revision":  1,
+	}, u.Token)
+	expectStatus(t, rec, http.StatusOK)
+	if got := rec.Header().Get("ETag"); got != `"2"` {
+		t.Errorf("ETag = %s, want \"2\"", got)
+	}
+
+	// A stale revision is refused with the stored survey
+	rec = h.Do("PATCH", "/api/v1/surveys/sections/personality", map[string]interface{}{
+		"responses": map[string]interface{}{"personality_introvert": "1"},
+		"revision":  1,
+	}, u.Token)
+	expectStatus(t, rec, http.StatusConflict)
+	if got := field(decode(t, rec), "data", "responses", "personality_introvert"); got != "4" {
+		t.Errorf("conflict should return the stored answers, got %v", got)
+	}
+
+	// Both sections' answers were kept
+	rec = h.Do("GET", "/api/v1/surveys", nil, u.Token)
+	expectStatus(t, rec, http.StatusOK)
+	body = decode(t, rec)
+	if field(body, "data", "responses", "major") != "cs" || field(body, "data", "responses", "personality_introvert") != "4" {
+		t.Errorf("unexpected survey: %s", rec.Body.String())
+	}
+}
+
+func TestSurveySectionRejectsInvalidAnswers(t *testing.T) {
+	h := harness.New()
+	u := newUser(t, h, "ada")
+
+	rec := h.Do("PATCH", "/api/v1/surveys/sections/demographics", map[string]interface{}{
+		"responses": map[string]interface{}{"year": "10th_year"},
+	}, u.Token)
+	expectStatus(t, rec, http.StatusUnprocessableEntity)
+	if field(decode(t, rec), "fields", "year") == nil {
+		t.Errorf("expected an error for year: %s", rec.Body.String())
+	}
+
+	expectStatus(t, h.Do("PATCH", "/api/v1/surveys/sections/nope", map[string]interface{}{
+		"responses": map[string]interface{}{},
+	}, u.Token), http.StatusNotFound)
+}
+
+func TestSurveySubmit(t *testing.T) {
+	h := harness.New()
+	u := newUser(t, h, "ada")
+
+	// Completion is refused while required answers are missing, but the
+	// answers are kept as a draft
+	partial := map[string]interface{}{"year": "1st_year", "major": "cs"}
+	rec := h.Do("POST", "/api/v1/surveys", map[string]interface{}{"responses": partial, "is_complete": true}, u.Token)
+	expectStatus(t, rec, http.StatusUnprocessableEntity)
+	body := decode(t, rec)
+	if field(body, "data", "is_complete") != false || field(body, "data", "revision") != 1.0 {
+		t.Errorf("draft not saved: %s", rec.Body.String())
+	}
+
+	rec = h.Do("POST", "/api/v1/surveys", map[string]interface{}{
+		"responses":   completeAnswers(t, nil),
+		"is_complete": true,
+		"revision":    1,
+	}, u.Token)
+	expectStatus(t, rec, http.StatusOK)
+	body = decode(t, rec)
+	if field(body, "data", "is_complete") != true || field(body, "data", "revision") != 2.0 {
+		t.Errorf("survey not completed: %s", rec.Body.String())
+	}
+	if field(body, "completeness", "complete") != true {
+		t.Errorf("completeness: %v", field(body, "completeness"))
+	}
+}
+
+func TestSurveySubmitDetectsStaleRevision(t *testing.T) {
+	h := harness.New()
+	u := newUser(t, h, "ada")
+
+	expectStatus(t, h.Do("PATCH", "/api/v1/surveys/sections/demographics", map[string]interface{}{
+		"responses": map[string]interface{}{"major": "cs"},
+	}, u.Token), http.StatusOK)
+	expectStatus(t, h.Do("PATCH", "/api/v1/surveys/sections/demographics", map[string]interface{}{
+		"responses": map[string]interface{}{"major": "it"},
+		"revision":  1,
+	}, u.Token), http.StatusOK)
+
+	// A tab that last saw revision 1 mustn't overwrite the newer autosave
+	rec := h.Do("POST", "/api/v1/surveys", map[string]interface{}{
+		"responses":   completeAnswers(t, map[string]interface{}{"major": "ba"}),
+		"is_complete": true,
+		"revision":    1,
+	}, u.Token)
+	expectStatus(t, rec, http.StatusConflict)
+	if got := field(decode(t, rec), "data", "responses", "major"); got != "it" {
+		t.Errorf("conflict should return the stored answers, got %v", got)
+	}
+	if got := rec.Header().Get("ETag"); got != `"2"` {
+		t.Errorf("ETag = %s, want \"2\"", got)
+	}
+
+	// The same goes for If-Match, and the current ETag lets the submit through
+	complete := map[string]interface{}{"responses": completeAnswers(t, nil), "is_complete": true}
+	expectStatus(t, doIfMatch(t, h, "POST", "/api/v1/surveys", complete, u.Token, `"1"`), http.StatusConflict)
+	rec = doIfMatch(t, h, "POST", "/api/v1/surveys", complete, u.Token, `"2"`)
+	expectStatus(t, rec, http.StatusOK)
+	if got := rec.Header().Get("ETag"); got != `"3"` {
+		t.Errorf("ETag = %s, want \"3\"", got)
+	}
+}
+
+func TestSurveyKeepsDeclaredTypeUntilEveryAxisIsAnswered(t *testing.T) {
+	h := harness.New()
+	u := newUser(t, h, "ada")
+
+	// Only E/I and S/N are answered
+	rec := h.Do("POST", "/api/v1/surveys", map[string]interface{}{
+		"responses": map[string]interface{}{
+			"personality_introvert":   "5",
+			"personality_adventurous": "5",
+		},
+		"personality_type": "INFJ",
+	}, u.Token)
+	expectStatus(t, rec, http.StatusOK)
+	if got := str(decode(t, rec), "data", "personality_type"); got != "INFJ" {
+		t.Errorf("personality_type = %s, want the declared INFJ", got)
+	}
+
+	rec = h.Do("POST", "/api/v1/surveys", map[string]interface{}{
+		"responses": completeAnswers(t, map[string]interface{}{
+			"personality_introvert":   "5",
+			"personality_social":      "1",
+			"personality_adventurous": "5",
+			"personality_planner":     "1",
+			"values_family":           "5",
+			"values_career":           "1",
+		}),
+		"personality_type": "ESTJ",
+		"is_complete":      true,
+	}, u.Token)
+	expectStatus(t, rec, http.StatusOK)
+	if got := str(decode(t, rec), "data", "personality_type"); got != "INFP" {
+		t.Errorf("personality_type = %s, want the derived INFP", got)
+	}
+}
@@ -1,11 +1,14 @@
 package websocket
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
 
-	"wizard-connect/internal/infrastructure/database"
+	"wizard-connect/internal/domain/repositories"
+	"wizard-connect/internal/interface/http/middleware"
 
 	"github.com/gin-gonic/gin"
 	socketio "github.com/googollee/go-socket.io"
@@ -17,11 +20,16 @@ import (
 
 type SocketHandler struct {
 	Server           *socketio.Server
-	conversationRepo *database.ConversationRepository
-	messageRepo      *database.MessageRepository
-	userRepo         *database.UserRepository
+	conversationRepo repositories.ConversationRepository
+	messageRepo      repositories.MessageRepository
+	userRepo         repositories.UserRepository
+	blockRepo        repositories.UserBlockRepository
 }
 
+// userHeader carries the user the auth middleware verified to the Socket.IO
+// server, which only sees the connection's first request
+const userHeader = "X-Socket-User-Id"
+
 type MessagePayload struct {
 	ID        string `json:"id"`
 	RoomID    string `json:"roomId"`
@@ -31,9 +39,10 @@ type MessagePayload struct {
 }
 
 func NewSocketHandler(
-	conversationRepo *database.ConversationRepository,
-	messageRepo *database.MessageRepository,
-	userRepo *database.UserRepository,
+	conversationRepo repositories.ConversationRepository,
+	messageRepo repositories.MessageRepository,
+	userRepo repositories.UserRepository,
+	blockRepo repositories.UserBlockRepository,
 ) (*SocketHandler, error) {
 	// Configure engine.io options
 	opts := &engineio.Options{
@@ -54,22 +63,30 @@ func NewSocketHandler(
 		conversationRepo: conversationRepo,
 		messageRepo:      messageRepo,
 		userRepo:         userRepo,
+		blockRepo:        blockRepo,
 	}
 
+	// Each connection joins its user's private room; the user comes from the
+	// token, never from the client
 	server.OnConnect("/", func(s socketio.Conn) error {
-		s.SetContext("")
-		fmt.Println("WS Connected:", s.ID())
+		userID := s.RemoteHeader().Get(userHeader)
+		if userID == "" {
+			return errors.New("unauthenticated socket")
+		}
+		s.SetContext(userID)
+		s.Join("user_" + userID)
+		fmt.Printf("WS Connected: %s as %s\n", s.ID(), userID)
 		return nil
 	})
 
 	server.OnEvent("/", "join-room", func(s socketio.Conn, roomID string) {
+		userID, _ := s.Context().(string)
+		if !handler.canJoin(context.Background(), userID, roomID) {
+			fmt.Printf("WS User %s refused room %s\n", userID, roomID)
+			return
+		}
 		s.Join(roomID)
-		fmt.Printf("WS User %s joined room %s\n", s.ID(), roomID)
-	})
-
-	server.OnEvent("/", "identify", func(s socketio.Conn, userID string) {
-		s.Join("user_" + userID)
-		fmt.Printf("WS User %s identified as %s and joined their private room\n", s.ID(), userID)
+		fmt.Printf("WS User %s joined room %s\n", userID, roomID)
 	})
 
 	server.OnDisconnect("/", func(s socketio.Conn, reason string) {
@@ -85,8 +102,38 @@ func NewSocketHandler(
 	return handler, nil
 }
 
+// canJoin reports whether the user may follow a conversation's room: they
+// must take part in it and neither side may have blocked the other
+func (h *SocketHandler) canJoin(ctx context.Context, userID, conversationID string) bool {
+	conv, err := h.conversationRepo.GetByID(ctx, conversationID)
+	if err != nil || conv == nil {
+		return false
+	}
+	otherUserID := conv.Participant1
+	switch userID {
+	case conv.Participant1:
+		otherUserID = conv.Participant2
+	case conv.Participant2:
+	default:
+		return false
+	}
+
+	blocked, err := h.blockRepo.IsBlocked(ctx, userID, otherUserID)
+	if err != nil {
+		fmt.Printf("ERROR: Failed to check block between %s and %s: %v\n", userID, otherUserID, err)
+		return false
+	}
+	return !blocked
+}
+
+// Handler serves Socket.IO requests. It must run after the socket auth
+// middleware, whose verified user replaces anything the client sent.
 func (h *SocketHandler) Handler() gin.HandlerFunc {
 	return func(c *gin.Context) {
+		c.Request.Header.Del(userHeader)
+		if userID, ok := middleware.GetUserID(c); ok {
+			c.Request.Header.Set(userHeader, userID)
+		}
 		h.Server.ServeHTTP(c.Writer, c.Request)
 	}
 }
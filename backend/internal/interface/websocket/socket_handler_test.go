@@ -0,0 +1,51 @@
+package websocket
+
+import (
+	"context"
+	"testing"
+
+	"wizard-connect/internal/domain/entities"
+	"wizard-connect/internal/infrastructure/memory"
+)
+
+func TestCanJoin(t *testing.T) {
+	ctx := context.Background()
+	store := memory.NewStore()
+	conversations := memory.NewConversationRepository(store)
+	blocks := memory.NewUserBlockRepository(store)
+	h := &SocketHandler{conversationRepo: conversations, blockRepo: blocks}
+
+	conv := &entities.Conversation{Participant1: "ada", Participant2: "bea"}
+	if err := conversations.Create(ctx, conv); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		userID string
+		roomID string
+		want   bool
+	}{
+		{"participant", "ada", conv.ID, true},
+		{"other participant", "bea", conv.ID, true},
+		{"outsider", "eve", conv.ID, false},
+		{"unknown conversation", "ada", "missing", false},
+		{"another user's room", "ada", "user_bea", false},
+		{"no user", "", conv.ID, false},
+	}
+	for _, tt := range tests {
+		if got := h.canJoin(ctx, tt.userID, tt.roomID); got != tt.want {
+			t.Errorf("%s: canJoin = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	// A block shuts both sides out
+	if err := blocks.Create(ctx, &entities.UserBlock{BlockerID: "bea", BlockedID: "ada"}); err != nil {
+		t.Fatal(err)
+	}
+	for _, userID := range []string{"ada", "bea"} {
+		if h.canJoin(ctx, userID, conv.ID) {
+			t.Errorf("%s can still join a conversation with a block", userID)
+		}
+	}
+}
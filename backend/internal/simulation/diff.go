@@ -0,0 +1,35 @@
+package simulation
+
+// MetricDelta is one metric of two runs side by side
+type MetricDelta struct {
+	Name  string  `json:"name"`
+	A     float64 `json:"a"`
+	B     float64 `json:"b"`
+	Delta float64 `json:"delta"`
+}
+
+// Compare lines up the headline metrics of two runs, B minus A
+func Compare(a, b Metrics) []MetricDelta {
+	pairs := []struct {
+		name string
+		a, b float64
+	}{
+		{"total_matches", float64(a.TotalMatches), float64(b.TotalMatches)},
+		{"score_mean", a.Scores.Mean, b.Scores.Mean},
+		{"score_median", a.Scores.Median, b.Scores.Median},
+		{"score_p25", a.Scores.P25, b.Scores.P25},
+		{"score_p75", a.Scores.P75, b.Scores.P75},
+		{"zero_match_users", float64(a.ZeroMatchUsers), float64(b.ZeroMatchUsers)},
+		{"coverage", a.Coverage, b.Coverage},
+		{"reciprocity_rate", a.ReciprocityRate, b.ReciprocityRate},
+		{"mutual_crush_capture_rate", a.MutualCrushCaptureRate, b.MutualCrushCaptureRate},
+		{"popularity_gini", a.PopularityGini, b.PopularityGini},
+		{"gender_preference_violations", float64(a.GenderPreferenceViolations), float64(b.GenderPreferenceViolations)},
+	}
+
+	deltas := make([]MetricDelta, len(pairs))
+	for i, p := range pairs {
+		deltas[i] = MetricDelta{Name: p.name, A: p.a, B: p.b, Delta: p.b - p.a}
+	}
+	return deltas
+}
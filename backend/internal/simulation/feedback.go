@@ -0,0 +1,176 @@
+package simulation
+
+import (
+	"math"
+	"sort"
+
+	"wizard-connect/internal/domain/entities"
+	"wizard-connect/internal/domain/services"
+)
+
+// DefaultMinOutcomes is the number of outcomes below which AnalyzeOutcomes
+// keeps the current weights rather than chase noise
+const DefaultMinOutcomes = 30
+
+// minSuggestedWeight keeps every section in play; a section whose weight
+// reached zero could never show whether it matters
+const minSuggestedWeight = 0.02
+
+// SectionAnalysis is how one scorer section relates to match outcomes
+type SectionAnalysis struct {
+	Section string `json:"section"`
+	Samples int    `json:"samples"`
+	// Correlation is the point-biserial correlation between the section's
+	// directional score and a positive outcome (-1 to 1)
+	Correlation     float64 `json:"correlation"`
+	MeanPositive    float64 `json:"mean_positive"`
+	MeanNegative    float64 `json:"mean_negative"`
+	CurrentWeight   float64 `json:"current_weight"`
+	SuggestedWeight float64 `json:"suggested_weight"`
+}
+
+// FeedbackAnalysis correlates each scorer section with match outcomes and
+// suggests section weights for the next campaign
+type FeedbackAnalysis struct {
+	Outcomes   int `json:"outcomes"`
+	Positive   int `json:"positive"`
+	ByFeedback int `json:"by_feedback"`
+	ByResponse int `json:"by_response"`
+	// Skipped outcomes are pairs without both surveys in the snapshot
+	Skipped  int               `json:"skipped"`
+	Sections []SectionAnalysis `json:"sections"`
+	// Sufficient is false when there were too few outcomes to move the
+	// weights; SuggestedWeights then equals CurrentWeights
+	Sufficient       bool               `json:"sufficient"`
+	CurrentWeights   map[string]float64 `json:"current_weights"`
+	SuggestedWeights map[string]float64 `json:"suggested_weights"`
+}
+
+// AnalyzeOutcomes re-scores every outcome's pair under the snapshot's
+// config, from the user's side, and correlates each section score with the
+// outcome. Sections that predicted good matches gain weight in proportion to
+// their correlation and the others lose it; the suggested weights keep the
+// current total. Fewer than minOutcomes outcomes leave the weights as they are.
+func AnalyzeOutcomes(snapshot *Snapshot, minOutcomes int) (*FeedbackAnalysis, error) {
+	def, err := services.GetSurveyDefinition(services.CampaignSurveyVersion(snapshot.Campaign))
+	if err != nil {
+		return nil, err
+	}
+	config := services.CampaignMatchingConfig(snapshot.Campaign)
+
+	surveys := make(map[string]int, len(snapshot.Surveys))
+	for i, survey := range snapshot.Surveys {
+		if survey.IsComplete {
+			surveys[survey.UserID] = i
+		}
+	}
+
+	analysis := &FeedbackAnalysis{
+		CurrentWeights:   make(map[string]float64, len(config.Weights)),
+		SuggestedWeights: make(map[string]float64, len(config.Weights)),
+	}
+	for section, weight := range config.Weights {
+		analysis.CurrentWeights[section] = weight
+	}
+
+	// Per section, the (score, outcome) samples
+	type sample struct {
+		score    float64
+		positive bool
+	}
+	samples := make(map[string][]sample)
+	for _, outcome := range snapshot.Outcomes {
+		selfIdx, okSelf := surveys[outcome.UserID]
+		otherIdx, okOther := surveys[outcome.MatchedUserID]
+		if !okSelf || !okOther {
+			analysis.Skipped++
+			continue
+		}
+
+		analysis.Outcomes++
+		if outcome.Positive {
+			analysis.Positive++
+		}
+		if outcome.Source == entities.OutcomeSourceFeedback {
+			analysis.ByFeedback++
+		} else {
+			analysis.ByResponse++
+		}
+
+		compat := services.ScoreCompatibility(def, config, snapshot.Surveys[selfIdx], snapshot.Surveys[otherIdx])
+		for section, score := range compat.Sections {
+			samples[section] = append(samples[section], sample{score, outcome.Positive})
+		}
+	}
+
+	names := make([]string, 0, len(config.Weights))
+	for section := range config.Weights {
+		names = append(names, section)
+	}
+	sort.Strings(names)
+
+	for _, section := range names {
+		result := SectionAnalysis{Section: section, CurrentWeight: config.Weights[section]}
+		scores := samples[section]
+		result.Samples = len(scores)
+
+		var sumPos, sumNeg, sum, sumSq float64
+		var nPos, nNeg int
+		for _, s := range scores {
+			sum += s.score
+			sumSq += s.score * s.score
+			if s.positive {
+				sumPos += s.score
+				nPos++
+			} else {
+				sumNeg += s.score
+				nNeg++
+			}
+		}
+		if nPos > 0 {
+			result.MeanPositive = sumPos / float64(nPos)
+		}
+		if nNeg > 0 {
+			result.MeanNegative = sumNeg / float64(nNeg)
+		}
+		if n := float64(len(scores)); nPos > 0 && nNeg > 0 {
+			variance := sumSq/n - (sum/n)*(sum/n)
+			if variance > 0 {
+				p := float64(nPos) / n
+				result.Correlation = (result.MeanPositive - result.MeanNegative) / math.Sqrt(variance) * math.Sqrt(p*(1-p))
+			}
+		}
+		analysis.Sections = append(analysis.Sections, result)
+	}
+
+	analysis.Sufficient = analysis.Outcomes >= minOutcomes
+	suggestWeights(analysis)
+	return analysis, nil
+}
+
+// suggestWeights scales each current weight by 1 + its correlation and
+// rescales the result to the current total
+func suggestWeights(analysis *FeedbackAnalysis) {
+	total, raw := 0.0, 0.0
+	adjusted := make(map[string]float64, len(analysis.Sections))
+	for _, section := range analysis.Sections {
+		total += section.CurrentWeight
+		weight := section.CurrentWeight
+		if analysis.Sufficient {
+			weight = math.Max(weight*(1+section.Correlation), minSuggestedWeight)
+		}
+		adjusted[section.Section] = weight
+		raw += weight
+	}
+
+	for i := range analysis.Sections {
+		section := &analysis.Sections[i]
+		weight := adjusted[section.Section]
+		if raw > 0 {
+			weight = weight / raw * total
+		}
+		// Four decimals is plenty for a config file
+		section.SuggestedWeight = math.Round(weight*1e4) / 1e4
+		analysis.SuggestedWeights[section.Section] = section.SuggestedWeight
+	}
+}
@@ -0,0 +1,342 @@
+package simulation
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"time"
+
+	"wizard-connect/internal/domain/entities"
+	"wizard-connect/internal/domain/services"
+
+	"github.com/google/uuid"
+)
+
+// maxCrushes mirrors the 1-5 rank limit of the crush list
+const maxCrushes = 5
+
+// PopulationOptions describes a synthetic population. The same options and
+// seed always produce the same population.
+type PopulationOptions struct {
+	Users         int    `json:"users"`
+	Seed          int64  `json:"seed"`
+	CampaignID    string `json:"campaign_id"`
+	SurveyVersion string `json:"survey_version"`
+	EmailDomain   string `json:"email_domain"`
+
+	// CompletionRate is the share of users who finish the survey
+	CompletionRate float64 `json:"completion_rate"`
+
+	// Distributions weights the options of a question, in the order the
+	// definition lists them. Questions without an entry are uniform.
+	Distributions map[string][]float64 `json:"distributions,omitempty"`
+
+	// SameGenderRate is the chance a male or female user seeks their own
+	// gender; BothGendersRate the chance they seek male and female
+	SameGenderRate  float64 `json:"same_gender_rate"`
+	BothGendersRate float64 `json:"both_genders_rate"`
+
+	// MultiSelectMin and MultiSelectMax bound how many options a user ticks
+	MultiSelectMin int `json:"multi_select_min"`
+	MultiSelectMax int `json:"multi_select_max"`
+
+	// CrushRate is the share of users with a crush list; each list has up to
+	// MaxCrushesPerUser entries. MutualCrushRate is the chance a crush is
+	// returned.
+	CrushRate         float64 `json:"crush_rate"`
+	MaxCrushesPerUser int     `json:"max_crushes_per_user"`
+	MutualCrushRate   float64 `json:"mutual_crush_rate"`
+}
+
+// DefaultPopulationOptions is a mid-sized campus cohort
+func DefaultPopulationOptions() PopulationOptions {
+	return PopulationOptions{
+		Users:          200,
+		Seed:           1,
+		SurveyVersion:  services.DefaultSurveyVersion,
+		EmailDomain:    "synthetic.test",
+		CompletionRate: 0.9,
+		Distributions: map[string][]float64{
+			"gender": {0.46, 0.46, 0.05, 0.03},
+		},
+		SameGenderRate:    0.08,
+		BothGendersRate:   0.07,
+		MultiSelectMin:    1,
+		MultiSelectMax:    4,
+		CrushRate:         0.4,
+		MaxCrushesPerUser: 3,
+		MutualCrushRate:   0.15,
+	}
+}
+
+// GeneratePopulation builds a synthetic snapshot: users, their surveys and
+// crush lists
+func GeneratePopulation(opts PopulationOptions) (*Snapshot, error) {
+	def, err := services.GetSurveyDefinition(opts.SurveyVersion)
+	if err != nil {
+		return nil, err
+	}
+	if opts.EmailDomain == "" {
+		opts.EmailDomain = "synthetic.test"
+	}
+	if opts.MultiSelectMax < opts.MultiSelectMin {
+		opts.MultiSelectMax = opts.MultiSelectMin
+	}
+	if opts.MaxCrushesPerUser > maxCrushes {
+		opts.MaxCrushesPerUser = maxCrushes
+	}
+
+	rng := rand.New(rand.NewSource(opts.Seed))
+	// Fixed timestamps keep fixtures byte-for-byte reproducible
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	snapshot := &Snapshot{
+		Campaign: &entities.Campaign{ID: opts.CampaignID, SurveyVersion: def.Version},
+	}
+
+	for i := 0; i < opts.Users; i++ {
+		id, err := uuid.NewRandomFromReader(rng)
+		if err != nil {
+			return nil, err
+		}
+
+		responses := make(map[string]interface{})
+		for q := range def.Questions {
+			question := &def.Questions[q]
+			switch {
+			case question.Type == entities.QuestionTypeCrushList:
+			case question.ID == "seeking_gender":
+			case question.Type == entities.QuestionTypeMultiSelect:
+				responses[question.ID] = pickMany(rng, question.Options, opts.Distributions[question.ID], opts.MultiSelectMin, opts.MultiSelectMax)
+			default:
+				responses[question.ID] = pickOne(rng, question.Options, opts.Distributions[question.ID])
+			}
+		}
+		gender, _ := responses["gender"].(string)
+		responses["seeking_gender"] = pickSeeking(rng, gender, opts)
+
+		complete := rng.Float64() < opts.CompletionRate
+		if !complete {
+			// Abandoned surveys stop somewhere in the second half
+			for q := len(def.Questions) / 2; q < len(def.Questions); q++ {
+				if rng.Intn(2) == 0 {
+					delete(responses, def.Questions[q].ID)
+				}
+			}
+		}
+
+		user := syntheticUser(id.String(), i, opts.EmailDomain, responses)
+		user.CreatedAt, user.UpdatedAt = now, now
+		snapshot.Users = append(snapshot.Users, user)
+
+		surveyID, err := uuid.NewRandomFromReader(rng)
+		if err != nil {
+			return nil, err
+		}
+		survey := &entities.SurveyResponse{
+			ID:                surveyID.String(),
+			UserID:            user.ID,
+			CampaignID:        opts.CampaignID,
+			DefinitionVersion: def.Version,
+			Responses:         responses,
+			IsComplete:        complete && services.Completeness(def, responses).Complete,
+			Revision:          1,
+			CreatedAt:         now,
+			UpdatedAt:         now,
+		}
+		survey.Interests, survey.Values, survey.Lifestyle = services.SummarizeResponses(responses)
+		if traits, ok := services.DeriveTraits(responses); ok {
+			survey.PersonalityTraits = traits.Slice()
+			survey.PersonalityType = traits.Type()
+		}
+		if survey.IsComplete {
+			survey.CompletedAt = now
+		}
+		snapshot.Surveys = append(snapshot.Surveys, survey)
+	}
+
+	if err := generateCrushes(rng, snapshot, opts, now); err != nil {
+		return nil, err
+	}
+	return snapshot, nil
+}
+
+// generateCrushes gives a share of users a crush list, returning each crush
+// with probability MutualCrushRate while the target still has room
+func generateCrushes(rng *rand.Rand, snapshot *Snapshot, opts PopulationOptions, now time.Time) error {
+	users := snapshot.Users
+	if len(users) < 2 || opts.MaxCrushesPerUser <= 0 {
+		return nil
+	}
+
+	lists := make(map[string][]string)
+	has := func(from, to string) bool {
+		for _, id := range lists[from] {
+			if id == to {
+				return true
+			}
+		}
+		return false
+	}
+
+	for _, user := range users {
+		if rng.Float64() >= opts.CrushRate {
+			continue
+		}
+		count := 1 + rng.Intn(opts.MaxCrushesPerUser)
+		for attempts := 0; len(lists[user.ID]) < count && attempts < count*4; attempts++ {
+			target := users[rng.Intn(len(users))]
+			if target.ID == user.ID || has(user.ID, target.ID) {
+				continue
+			}
+			lists[user.ID] = append(lists[user.ID], target.ID)
+
+			if rng.Float64() < opts.MutualCrushRate && len(lists[target.ID]) < maxCrushes && !has(target.ID, user.ID) {
+				lists[target.ID] = append(lists[target.ID], user.ID)
+			}
+		}
+	}
+
+	emails := make(map[string]string)
+	for _, user := range users {
+		emails[user.ID] = user.Email
+	}
+	for _, user := range users {
+		for rank, target := range lists[user.ID] {
+			id, err := uuid.NewRandomFromReader(rng)
+			if err != nil {
+				return err
+			}
+			snapshot.Crushes = append(snapshot.Crushes, &entities.Crush{
+				ID:         id.String(),
+				UserID:     user.ID,
+				CrushEmail: emails[target],
+				Rank:       rank + 1,
+				CreatedAt:  now,
+			})
+		}
+	}
+	return nil
+}
+
+// syntheticUser builds a profile consistent with the user's survey answers,
+// using the spellings the users table accepts
+func syntheticUser(id string, index int, domain string, responses map[string]interface{}) *entities.User {
+	user := &entities.User{
+		ID:          id,
+		Email:       fmt.Sprintf("synthetic%05d@%s", index+1, domain),
+		FirstName:   "Synthetic",
+		LastName:    fmt.Sprintf("User %d", index+1),
+		ContactPref: "email",
+		Visibility:  "public",
+	}
+
+	if year, ok := responses["year"].(string); ok {
+		if year == "graduate" {
+			user.Year = "Graduate"
+		} else {
+			user.Year = strings.Replace(strings.Replace(year, "_year", " Year", 1), "_", " ", -1)
+		}
+	}
+	if major, ok := responses["major"].(string); ok {
+		user.Major = strings.ToUpper(major)
+	}
+	if gender, ok := responses["gender"].(string); ok {
+		switch gender {
+		case "non_binary":
+			user.Gender = "non-binary"
+		case "prefer_not_say":
+			user.Gender = "prefer_not_to_say"
+		default:
+			user.Gender = gender
+		}
+	}
+	if seeking, ok := responses["seeking_gender"].([]interface{}); ok {
+		if len(seeking) == 1 {
+			user.GenderPreference, _ = seeking[0].(string)
+		} else {
+			user.GenderPreference = "both"
+		}
+		if user.GenderPreference == "non_binary" {
+			user.GenderPreference = "both"
+		}
+	}
+	return user
+}
+
+func pickSeeking(rng *rand.Rand, gender string, opts PopulationOptions) []interface{} {
+	opposite := map[string]string{"male": "female", "female": "male"}
+	other, binary := opposite[gender]
+	if !binary {
+		return []interface{}{"male", "female", "non_binary"}
+	}
+
+	roll := rng.Float64()
+	switch {
+	case roll < opts.SameGenderRate:
+		return []interface{}{gender}
+	case roll < opts.SameGenderRate+opts.BothGendersRate:
+		return []interface{}{"male", "female"}
+	}
+	return []interface{}{other}
+}
+
+// pickOne draws an option using weights, uniform when weights don't line up
+// with the options
+func pickOne(rng *rand.Rand, options []string, weights []float64) string {
+	if len(options) == 0 {
+		return ""
+	}
+	if len(weights) != len(options) {
+		return options[rng.Intn(len(options))]
+	}
+
+	total := 0.0
+	for _, w := range weights {
+		total += w
+	}
+	roll := rng.Float64() * total
+	for i, w := range weights {
+		roll -= w
+		if roll < 0 {
+			return options[i]
+		}
+	}
+	return options[len(options)-1]
+}
+
+// pickMany draws between min and max distinct options
+func pickMany(rng *rand.Rand, options []string, weights []float64, min, max int) []interface{} {
+	if max > len(options) {
+		max = len(options)
+	}
+	if min < 1 {
+		min = 1
+	}
+	if min > max {
+		min = max
+	}
+	count := min + rng.Intn(max-min+1)
+
+	remaining := append([]string(nil), options...)
+	var remainingWeights []float64
+	if len(weights) == len(options) {
+		remainingWeights = append([]float64(nil), weights...)
+	}
+
+	picked := make([]interface{}, 0, count)
+	for len(picked) < count && len(remaining) > 0 {
+		choice := pickOne(rng, remaining, remainingWeights)
+		for i, option := range remaining {
+			if option == choice {
+				remaining = append(remaining[:i], remaining[i+1:]...)
+				if remainingWeights != nil {
+					remainingWeights = append(remainingWeights[:i], remainingWeights[i+1:]...)
+				}
+				break
+			}
+		}
+		picked = append(picked, choice)
+	}
+	return picked
+}
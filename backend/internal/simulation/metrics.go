@@ -0,0 +1,180 @@
+package simulation
+
+import (
+	"math"
+	"sort"
+
+	"wizard-connect/internal/domain/entities"
+	"wizard-connect/internal/domain/services"
+)
+
+// Metrics summarizes the quality of one matching run
+type Metrics struct {
+	Participants int `json:"participants"`
+	TotalMatches int `json:"total_matches"`
+
+	Scores ScoreDistribution `json:"scores"`
+
+	// Coverage is the share of participants with at least one match
+	ZeroMatchUsers int     `json:"zero_match_users"`
+	Coverage       float64 `json:"coverage"`
+
+	// ReciprocityRate is the share of matches A->B where B->A also exists
+	ReciprocityRate float64 `json:"reciprocity_rate"`
+
+	// MutualCrushCaptureRate is the share of mutual-crush pairs that were matched
+	MutualCrushPairs       int     `json:"mutual_crush_pairs"`
+	MutualCrushCaptured    int     `json:"mutual_crush_captured"`
+	MutualCrushCaptureRate float64 `json:"mutual_crush_capture_rate"`
+
+	// PopularityGini is the Gini coefficient of how often each participant
+	// appears in other people's lists: 0 is perfectly even, 1 is one person
+	// in every list
+	PopularityGini float64 `json:"popularity_gini"`
+
+	// GenderPreferenceViolations counts matches where either side isn't the
+	// gender the other is looking for
+	GenderPreferenceViolations int `json:"gender_preference_violations"`
+}
+
+// ScoreDistribution describes the compatibility scores of all matches.
+// Histogram buckets are 10 points wide, bucket 9 includes 100.
+type ScoreDistribution struct {
+	Min       float64 `json:"min"`
+	P25       float64 `json:"p25"`
+	Median    float64 `json:"median"`
+	P75       float64 `json:"p75"`
+	Max       float64 `json:"max"`
+	Mean      float64 `json:"mean"`
+	Histogram [10]int `json:"histogram"`
+}
+
+// Evaluate computes the metrics of a set of matches for a snapshot
+func Evaluate(snapshot *Snapshot, surveys []*entities.SurveyResponse, matches []*entities.Match) Metrics {
+	metrics := Metrics{
+		Participants: len(surveys),
+		TotalMatches: len(matches),
+		Scores:       scoreDistribution(matches),
+	}
+
+	edges := make(map[[2]string]bool)
+	outDegree := make(map[string]int)
+	inDegree := make(map[string]int)
+	for _, match := range matches {
+		edges[[2]string{match.UserID, match.MatchedUserID}] = true
+		outDegree[match.UserID]++
+		inDegree[match.MatchedUserID]++
+	}
+
+	// Coverage and popularity
+	appearances := make([]float64, 0, len(surveys))
+	for _, survey := range surveys {
+		if outDegree[survey.UserID] == 0 {
+			metrics.ZeroMatchUsers++
+		}
+		appearances = append(appearances, float64(inDegree[survey.UserID]))
+	}
+	if len(surveys) > 0 {
+		metrics.Coverage = float64(len(surveys)-metrics.ZeroMatchUsers) / float64(len(surveys))
+	}
+	metrics.PopularityGini = services.Gini(appearances)
+
+	// Reciprocity
+	reciprocal := 0
+	for edge := range edges {
+		if edges[[2]string{edge[1], edge[0]}] {
+			reciprocal++
+		}
+	}
+	if len(edges) > 0 {
+		metrics.ReciprocityRate = float64(reciprocal) / float64(len(edges))
+	}
+
+	// Mutual crushes among participants
+	participants := make(map[string]*entities.SurveyResponse)
+	for _, survey := range surveys {
+		participants[survey.UserID] = survey
+	}
+	idByEmail := make(map[string]string)
+	usersByID := make(map[string]*entities.User)
+	for _, user := range snapshot.Users {
+		idByEmail[user.Email] = user.ID
+		usersByID[user.ID] = user
+	}
+	crushesOn := make(map[[2]string]bool)
+	for _, crush := range snapshot.Crushes {
+		if target, ok := idByEmail[crush.CrushEmail]; ok {
+			crushesOn[[2]string{crush.UserID, target}] = true
+		}
+	}
+	for pair := range crushesOn {
+		a, b := pair[0], pair[1]
+		if a >= b || !crushesOn[[2]string{b, a}] || participants[a] == nil || participants[b] == nil {
+			continue
+		}
+		metrics.MutualCrushPairs++
+		if edges[[2]string{a, b}] || edges[[2]string{b, a}] {
+			metrics.MutualCrushCaptured++
+		}
+	}
+	if metrics.MutualCrushPairs > 0 {
+		metrics.MutualCrushCaptureRate = float64(metrics.MutualCrushCaptured) / float64(metrics.MutualCrushPairs)
+	}
+
+	// Gender preference violations, checked even when the run disabled the filter
+	gender := services.GenderPreferenceConstraint()
+	for _, match := range matches {
+		a, b := participants[match.UserID], participants[match.MatchedUserID]
+		if a == nil || b == nil {
+			continue
+		}
+		pa := &services.Participant{Survey: a, User: usersByID[a.UserID]}
+		pb := &services.Participant{Survey: b, User: usersByID[b.UserID]}
+		if !gender.Allows(pa, pb) || !gender.Allows(pb, pa) {
+			metrics.GenderPreferenceViolations++
+		}
+	}
+
+	return metrics
+}
+
+func scoreDistribution(matches []*entities.Match) ScoreDistribution {
+	var dist ScoreDistribution
+	if len(matches) == 0 {
+		return dist
+	}
+
+	scores := make([]float64, len(matches))
+	sum := 0.0
+	for i, match := range matches {
+		scores[i] = match.CompatibilityScore
+		sum += match.CompatibilityScore
+
+		bucket := int(match.CompatibilityScore / 10)
+		if bucket > 9 {
+			bucket = 9
+		}
+		if bucket < 0 {
+			bucket = 0
+		}
+		dist.Histogram[bucket]++
+	}
+	sort.Float64s(scores)
+
+	dist.Min = scores[0]
+	dist.Max = scores[len(scores)-1]
+	dist.P25 = percentile(scores, 0.25)
+	dist.Median = percentile(scores, 0.50)
+	dist.P75 = percentile(scores, 0.75)
+	dist.Mean = sum / float64(len(scores))
+	return dist
+}
+
+// percentile interpolates linearly between the closest ranks of sorted values
+func percentile(sorted []float64, p float64) float64 {
+	position := p * float64(len(sorted)-1)
+	lower := int(math.Floor(position))
+	upper := int(math.Ceil(position))
+	fraction := position - float64(lower)
+	return sorted[lower] + (sorted[upper]-sorted[lower])*fraction
+}
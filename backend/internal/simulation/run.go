@@ -0,0 +1,55 @@
+package simulation
+
+import (
+	"context"
+	"log"
+	"sort"
+
+	"wizard-connect/internal/domain/entities"
+	"wizard-connect/internal/domain/services"
+)
+
+// Result is the outcome of one simulated matching run
+type Result struct {
+	Config   services.MatchingConfig `json:"config"`
+	Matches  []*entities.Match       `json:"matches"`
+	Metrics  Metrics                 `json:"metrics"`
+	Fairness entities.FairnessReport `json:"fairness"`
+	Manifest entities.RunManifest    `json:"manifest"`
+}
+
+// Run generates every participant's matches for the snapshot's campaign, the
+// same way the admin run-algorithm endpoint does, without touching the
+// database. The same snapshot and seed always give the same result. The
+// matcher's debug output goes to logger, or nowhere when it is nil.
+func Run(ctx context.Context, snapshot *Snapshot, seed int64, logger *log.Logger) (*Result, error) {
+	store := &snapshotStore{snapshot: snapshot}
+	matcher := services.NewMatchingService(store, store, store, snapshotUsers{snapshot: snapshot}, store, store, store, logger)
+
+	def, err := services.GetSurveyDefinition(services.CampaignSurveyVersion(snapshot.Campaign))
+	if err != nil {
+		return nil, err
+	}
+	surveys, _ := store.GetCompletedSurveysForCampaign(ctx, snapshot.Campaign.ID, def.Version)
+
+	run, err := matcher.RunCampaign(ctx, snapshot.Campaign, seed)
+	if err != nil {
+		return nil, err
+	}
+
+	matches := run.Matches
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].UserID != matches[j].UserID {
+			return matches[i].UserID < matches[j].UserID
+		}
+		return matches[i].Rank < matches[j].Rank
+	})
+
+	return &Result{
+		Config:   services.CampaignMatchingConfig(snapshot.Campaign),
+		Matches:  matches,
+		Metrics:  Evaluate(snapshot, surveys, matches),
+		Fairness: run.Fairness,
+		Manifest: run.Manifest,
+	}, nil
+}
@@ -0,0 +1,103 @@
+package simulation
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"wizard-connect/internal/infrastructure/database"
+
+	"github.com/lib/pq"
+)
+
+// WriteSnapshotToDB inserts a (synthetic) snapshot's users, surveys and
+// crushes in one transaction. On Supabase the matching auth.users rows are
+// created too, since public.users references them. Existing rows with the
+// same IDs are overwritten, so re-running a seed is safe.
+func WriteSnapshotToDB(ctx context.Context, db *database.Database, snapshot *Snapshot) error {
+	tx, err := db.BeginTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var hasAuth bool
+	if err := tx.QueryRowContext(ctx, `SELECT to_regclass('auth.users') IS NOT NULL`).Scan(&hasAuth); err != nil {
+		return fmt.Errorf("failed to inspect schema: %w", err)
+	}
+
+	for _, user := range snapshot.Users {
+		if hasAuth {
+			if _, err := tx.ExecContext(ctx,
+				`INSERT INTO auth.users (id, email) VALUES ($1, $2) ON CONFLICT (id) DO NOTHING`,
+				user.ID, user.Email,
+			); err != nil {
+				return fmt.Errorf("failed to create auth user %s: %w", user.Email, err)
+			}
+		}
+
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO users (id, email, first_name, last_name, contact_preference, visibility,
+			       year, major, gender, gender_preference, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, NOW(), NOW())
+			ON CONFLICT (id) DO UPDATE
+			SET email = EXCLUDED.email, first_name = EXCLUDED.first_name, last_name = EXCLUDED.last_name,
+			    year = EXCLUDED.year, major = EXCLUDED.major, gender = EXCLUDED.gender,
+			    gender_preference = EXCLUDED.gender_preference, updated_at = NOW()
+		`,
+			user.ID, user.Email, user.FirstName, user.LastName, user.ContactPref, user.Visibility,
+			user.Year, user.Major, user.Gender, user.GenderPreference,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to create user %s: %w", user.Email, err)
+		}
+	}
+
+	for _, survey := range snapshot.Surveys {
+		responsesJSON, err := json.Marshal(survey.Responses)
+		if err != nil {
+			return err
+		}
+
+		var campaignID, completedAt interface{}
+		if survey.CampaignID != "" {
+			campaignID = survey.CampaignID
+		}
+		if survey.IsComplete {
+			completedAt = survey.CompletedAt
+		}
+
+		_, err = tx.ExecContext(ctx, `
+			DELETE FROM surveys WHERE user_id = $1 AND campaign_id IS NOT DISTINCT FROM $2::uuid
+		`, survey.UserID, campaignID)
+		if err != nil {
+			return fmt.Errorf("failed to replace survey of %s: %w", survey.UserID, err)
+		}
+
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO surveys (id, user_id, campaign_id, definition_version, responses, personality_type,
+			       interests, "values", lifestyle, is_complete, completed_at, personality_traits, revision, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, 1, NOW(), NOW())
+		`,
+			survey.ID, survey.UserID, campaignID, survey.DefinitionVersion, responsesJSON, survey.PersonalityType,
+			pq.Array(survey.Interests), pq.Array(survey.Values), survey.Lifestyle, survey.IsComplete,
+			completedAt, pq.Array(survey.PersonalityTraits),
+		)
+		if err != nil {
+			return fmt.Errorf("failed to create survey of %s: %w", survey.UserID, err)
+		}
+	}
+
+	for _, crush := range snapshot.Crushes {
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO crushes (id, user_id, crush_email, rank)
+			VALUES ($1, $2, $3, $4)
+			ON CONFLICT DO NOTHING
+		`, crush.ID, crush.UserID, crush.CrushEmail, crush.Rank)
+		if err != nil {
+			return fmt.Errorf("failed to create crush of %s: %w", crush.UserID, err)
+		}
+	}
+
+	return tx.Commit()
+}
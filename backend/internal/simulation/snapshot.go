@@ -0,0 +1,148 @@
+// Package simulation replays the matching algorithm offline against a
+// snapshot of a campaign, so weights and algorithm changes can be evaluated
+// before they reach real users.
+package simulation
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"wizard-connect/internal/domain/entities"
+)
+
+// Snapshot is everything the matcher reads for one campaign
+type Snapshot struct {
+	Campaign *entities.Campaign         `json:"campaign"`
+	Users    []*entities.User           `json:"users"`
+	Surveys  []*entities.SurveyResponse `json:"surveys"`
+	Crushes  []*entities.Crush          `json:"crushes"`
+	Vetoes   []*entities.MatchVeto      `json:"vetoes,omitempty"`
+	Blocks   []*entities.UserBlock      `json:"blocks,omitempty"`
+	// Suspended users are left out of matching
+	Suspended []string `json:"suspended,omitempty"`
+	// Outcomes of the campaign's published matches, for weight tuning; the
+	// matcher doesn't read them
+	Outcomes []*entities.MatchOutcome `json:"outcomes,omitempty"`
+}
+
+// LoadSnapshotFile reads a snapshot from a JSON fixture
+func LoadSnapshotFile(path string) (*Snapshot, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var snapshot Snapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return nil, fmt.Errorf("failed to parse snapshot %s: %w", path, err)
+	}
+	if snapshot.Campaign == nil {
+		snapshot.Campaign = &entities.Campaign{}
+	}
+	return &snapshot, nil
+}
+
+// WriteFile saves the snapshot as a JSON fixture
+func (s *Snapshot) WriteFile(path string) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// WithConfig returns a copy of the snapshot whose campaign uses config
+// instead of its stored one
+func (s *Snapshot) WithConfig(config map[string]interface{}) *Snapshot {
+	campaign := *s.Campaign
+	campaign.Config = config
+
+	clone := *s
+	clone.Campaign = &campaign
+	return &clone
+}
+
+// snapshotStore serves a snapshot through the repository interfaces the
+// matching service depends on and collects the matches it creates
+type snapshotStore struct {
+	snapshot *Snapshot
+	matches  []*entities.Match
+}
+
+func (st *snapshotStore) GetCompletedSurveysForCampaign(ctx context.Context, campaignID, definitionVersion string) ([]*entities.SurveyResponse, error) {
+	var surveys []*entities.SurveyResponse
+	for _, survey := range st.snapshot.Surveys {
+		version := survey.DefinitionVersion
+		if version == "" {
+			version = definitionVersion
+		}
+		if survey.IsComplete && version == definitionVersion {
+			surveys = append(surveys, survey)
+		}
+	}
+	return surveys, nil
+}
+
+func (st *snapshotStore) GetByUserID(ctx context.Context, userID string) ([]*entities.Crush, error) {
+	var crushes []*entities.Crush
+	for _, crush := range st.snapshot.Crushes {
+		if crush.UserID == userID {
+			crushes = append(crushes, crush)
+		}
+	}
+	return crushes, nil
+}
+
+func (st *snapshotStore) Create(ctx context.Context, match *entities.Match) error {
+	st.matches = append(st.matches, match)
+	return nil
+}
+
+func (st *snapshotStore) DeleteByUserID(ctx context.Context, userID string) error {
+	kept := st.matches[:0]
+	for _, match := range st.matches {
+		if match.UserID != userID {
+			kept = append(kept, match)
+		}
+	}
+	st.matches = kept
+	return nil
+}
+
+func (st *snapshotStore) ListVetoes(ctx context.Context) ([]*entities.MatchVeto, error) {
+	return st.snapshot.Vetoes, nil
+}
+
+func (st *snapshotStore) ListBlocks(ctx context.Context) ([]*entities.UserBlock, error) {
+	return st.snapshot.Blocks, nil
+}
+
+func (st *snapshotStore) ListSuspendedUserIDs(ctx context.Context, at time.Time) ([]string, error) {
+	return st.snapshot.Suspended, nil
+}
+
+func (st *snapshotStore) GetActive(ctx context.Context) (*entities.Campaign, error) {
+	return st.snapshot.Campaign, nil
+}
+
+// snapshotUsers adapts the snapshot to services.UserRepository; it can't live
+// on snapshotStore because GetByID would clash with the crush lookup
+type snapshotUsers struct {
+	snapshot *Snapshot
+}
+
+func (u snapshotUsers) GetByID(ctx context.Context, id string) (*entities.User, error) {
+	for _, user := range u.snapshot.Users {
+		if user.ID == id {
+			return user, nil
+		}
+	}
+	return nil, fmt.Errorf("user not found: %s", id)
+}
+
+func (u snapshotUsers) ListAll(ctx context.Context) ([]*entities.User, error) {
+	return u.snapshot.Users, nil
+}
@@ -0,0 +1,146 @@
+package simulation
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"wizard-connect/internal/domain/entities"
+	"wizard-connect/internal/domain/services"
+	"wizard-connect/internal/infrastructure/database"
+)
+
+// LoadSnapshotFromDB reads a campaign's participants from the database. An
+// empty campaignID loads the active campaign.
+func LoadSnapshotFromDB(ctx context.Context, db *database.Database, campaignID string) (*Snapshot, error) {
+	campaignRepo := database.NewCampaignRepository(db)
+	surveyRepo := database.NewSurveyRepository(db)
+	crushRepo := database.NewCrushRepository(db)
+	userRepo := database.NewUserRepository(db)
+	vetoRepo := database.NewMatchVetoRepository(db)
+	blockRepo := database.NewUserBlockRepository(db)
+	moderationRepo := database.NewModerationActionRepository(db)
+	defer userRepo.Close()
+
+	snapshot := &Snapshot{}
+	var err error
+	if campaignID == "" {
+		snapshot.Campaign, err = campaignRepo.GetActive(ctx)
+		if err == nil && snapshot.Campaign == nil {
+			err = fmt.Errorf("no active campaign, pass a campaign ID")
+		}
+	} else {
+		snapshot.Campaign, err = campaignRepo.GetByID(ctx, campaignID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load campaign: %w", err)
+	}
+
+	snapshot.Surveys, err = surveyRepo.GetCompletedSurveysForCampaign(ctx, snapshot.Campaign.ID, services.CampaignSurveyVersion(snapshot.Campaign))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load surveys: %w", err)
+	}
+
+	participants := make(map[string]bool)
+	for _, survey := range snapshot.Surveys {
+		participants[survey.UserID] = true
+		crushes, err := crushRepo.GetByUserID(ctx, survey.UserID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load crushes: %w", err)
+		}
+		snapshot.Crushes = append(snapshot.Crushes, crushes...)
+	}
+
+	users, err := userRepo.ListAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load users: %w", err)
+	}
+	for _, user := range users {
+		if participants[user.ID] {
+			snapshot.Users = append(snapshot.Users, user)
+		}
+	}
+
+	// Only vetoes between participants can affect the run
+	vetoes, err := vetoRepo.ListVetoes(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load vetoes: %w", err)
+	}
+	for _, veto := range vetoes {
+		if participants[veto.UserAID] && participants[veto.UserBID] {
+			snapshot.Vetoes = append(snapshot.Vetoes, veto)
+		}
+	}
+
+	blocks, err := blockRepo.ListBlocks(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load blocks: %w", err)
+	}
+	for _, block := range blocks {
+		if participants[block.BlockerID] && participants[block.BlockedID] {
+			snapshot.Blocks = append(snapshot.Blocks, block)
+		}
+	}
+
+	suspended, err := moderationRepo.ListSuspendedUserIDs(ctx, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to load suspensions: %w", err)
+	}
+	for _, id := range suspended {
+		if participants[id] {
+			snapshot.Suspended = append(snapshot.Suspended, id)
+		}
+	}
+
+	snapshot.Outcomes, err = loadOutcomes(ctx, db, snapshot.Campaign.ID, snapshot.Users)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load outcomes: %w", err)
+	}
+
+	return snapshot, nil
+}
+
+// loadOutcomes collects how the participants' matches turned out: their
+// feedback, and for matches without feedback their interested/pass response
+func loadOutcomes(ctx context.Context, db *database.Database, campaignID string, participants []*entities.User) ([]*entities.MatchOutcome, error) {
+	feedbackRepo := database.NewMatchFeedbackRepository(db)
+	matchRepo := database.NewMatchRepository(db)
+
+	feedback, err := feedbackRepo.ListByCampaign(ctx, campaignID)
+	if err != nil {
+		return nil, err
+	}
+
+	var outcomes []*entities.MatchOutcome
+	withFeedback := make(map[[2]string]bool)
+	for _, f := range feedback {
+		withFeedback[[2]string{f.UserID, f.MatchedUserID}] = true
+		outcomes = append(outcomes, &entities.MatchOutcome{
+			UserID:        f.UserID,
+			MatchedUserID: f.MatchedUserID,
+			Source:        entities.OutcomeSourceFeedback,
+			Positive:      f.Positive(),
+			Reason:        f.Comment,
+		})
+	}
+
+	for _, user := range participants {
+		matches, err := matchRepo.GetByUserID(ctx, user.ID)
+		if err != nil {
+			return nil, err
+		}
+		for _, m := range matches {
+			if m.UserAResponse == "" || withFeedback[[2]string{m.UserID, m.MatchedUserID}] {
+				continue
+			}
+			outcomes = append(outcomes, &entities.MatchOutcome{
+				UserID:        m.UserID,
+				MatchedUserID: m.MatchedUserID,
+				Source:        entities.OutcomeSourceResponse,
+				Positive:      m.UserAResponse == entities.MatchResponseInterested,
+				Reason:        m.UserAPassReason,
+			})
+		}
+	}
+	return outcomes, nil
+}
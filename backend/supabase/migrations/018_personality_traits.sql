@@ -0,0 +1,3 @@
+-- Store personality traits derived server-side from survey answers
+-- Order: E/I, S/N, T/F, J/P, each in [-1, 1]
+ALTER TABLE public.surveys ADD COLUMN IF NOT EXISTS personality_traits DOUBLE PRECISION[] DEFAULT '{}';
@@ -0,0 +1,12 @@
+-- Version survey responses by campaign and survey definition
+-- A user now has one survey row per campaign instead of a single row overall
+
+ALTER TABLE public.surveys ADD COLUMN IF NOT EXISTS campaign_id UUID REFERENCES public.campaigns(id) ON DELETE CASCADE;
+ALTER TABLE public.surveys ADD COLUMN IF NOT EXISTS definition_version TEXT NOT NULL DEFAULT 'v1';
+
+ALTER TABLE public.surveys DROP CONSTRAINT IF EXISTS surveys_user_id_key;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_surveys_user_campaign
+    ON public.surveys(user_id, COALESCE(campaign_id, '00000000-0000-0000-0000-000000000000'::uuid));
+
+-- Which question set a campaign collects responses under
+ALTER TABLE public.campaigns ADD COLUMN IF NOT EXISTS survey_version TEXT;
@@ -0,0 +1,2 @@
+-- Revision counter for survey autosave; used as the ETag for conflict detection
+ALTER TABLE public.surveys ADD COLUMN IF NOT EXISTS revision INTEGER NOT NULL DEFAULT 0;
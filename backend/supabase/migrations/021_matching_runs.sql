@@ -0,0 +1,18 @@
+-- One row per campaign-wide matching run, with the fairness report comparing
+-- greedy ranking against the campaign's fairness controls
+CREATE TABLE IF NOT EXISTS public.matching_runs (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    campaign_id UUID NOT NULL REFERENCES public.campaigns(id) ON DELETE CASCADE,
+    status TEXT NOT NULL DEFAULT 'running' CHECK (status IN ('running', 'completed', 'failed')),
+    error TEXT,
+    participants INTEGER NOT NULL DEFAULT 0,
+    total_matches INTEGER NOT NULL DEFAULT 0,
+    fairness JSONB,
+    started_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    completed_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_matching_runs_campaign ON public.matching_runs(campaign_id, started_at DESC);
+
+-- Only the backend (service role) reads and writes runs
+ALTER TABLE public.matching_runs ENABLE ROW LEVEL SECURITY;
@@ -0,0 +1,3 @@
+-- Manifest of each matching run (algorithm and code version, config and its
+-- hash, seed, input counts and hashes) so a run can be replayed and verified
+ALTER TABLE public.matching_runs ADD COLUMN IF NOT EXISTS manifest JSONB;
@@ -0,0 +1,4 @@
+-- Directional scores behind each match. compatibility_score is the
+-- reciprocal score both are combined into; older matches leave these NULL.
+ALTER TABLE public.matches ADD COLUMN IF NOT EXISTS score_from_user DECIMAL(5,2);
+ALTER TABLE public.matches ADD COLUMN IF NOT EXISTS score_from_matched DECIMAL(5,2);
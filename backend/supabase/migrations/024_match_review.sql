@@ -0,0 +1,57 @@
+-- Campaign runs stage their matches here for review; publishing copies them
+-- into matches, where users can see them
+CREATE TABLE IF NOT EXISTS public.staged_matches (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    campaign_id UUID NOT NULL REFERENCES public.campaigns(id) ON DELETE CASCADE,
+    run_id UUID REFERENCES public.matching_runs(id) ON DELETE SET NULL,
+    user_id UUID NOT NULL REFERENCES auth.users(id) ON DELETE CASCADE,
+    matched_user_id UUID NOT NULL REFERENCES auth.users(id) ON DELETE CASCADE,
+    compatibility_score DECIMAL(5,2) NOT NULL DEFAULT 0.0,
+    score_from_user DECIMAL(5,2),
+    score_from_matched DECIMAL(5,2),
+    rank INTEGER NOT NULL,
+    is_mutual_crush BOOLEAN NOT NULL DEFAULT FALSE,
+    source TEXT NOT NULL DEFAULT 'algorithm' CHECK (source IN ('algorithm', 'manual')),
+    pinned BOOLEAN NOT NULL DEFAULT FALSE,
+    pinned_by UUID,
+    flagged BOOLEAN NOT NULL DEFAULT FALSE,
+    flag_reason TEXT,
+    flagged_by UUID,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE(campaign_id, user_id, matched_user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_staged_matches_campaign ON public.staged_matches(campaign_id, user_id, rank);
+
+-- Pairs admins ruled out; runs never match them again. The smaller user ID
+-- is always user_a_id, so each pair has one row.
+CREATE TABLE IF NOT EXISTS public.match_vetoes (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_a_id UUID NOT NULL REFERENCES auth.users(id) ON DELETE CASCADE,
+    user_b_id UUID NOT NULL REFERENCES auth.users(id) ON DELETE CASCADE,
+    campaign_id UUID REFERENCES public.campaigns(id) ON DELETE SET NULL,
+    reason TEXT NOT NULL,
+    vetoed_by UUID,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE(user_a_id, user_b_id),
+    CHECK (user_a_id < user_b_id)
+);
+
+-- Who did what to which record, and why
+CREATE TABLE IF NOT EXISTS public.admin_audit_log (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    actor_id UUID,
+    action TEXT NOT NULL,
+    target_type TEXT NOT NULL,
+    target_id TEXT,
+    details JSONB,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_admin_audit_log_target ON public.admin_audit_log(target_type, target_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_admin_audit_log_created ON public.admin_audit_log(created_at DESC);
+
+-- Only the backend (service role) reads and writes these
+ALTER TABLE public.staged_matches ENABLE ROW LEVEL SECURITY;
+ALTER TABLE public.match_vetoes ENABLE ROW LEVEL SECURITY;
+ALTER TABLE public.admin_audit_log ENABLE ROW LEVEL SECURITY;
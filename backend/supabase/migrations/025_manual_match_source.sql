@@ -0,0 +1,7 @@
+-- Whether a match came from the algorithm or was made by hand, and which
+-- admin made it
+ALTER TABLE public.matches ADD COLUMN IF NOT EXISTS source TEXT NOT NULL DEFAULT 'algorithm';
+ALTER TABLE public.matches ADD COLUMN IF NOT EXISTS created_by UUID;
+
+ALTER TABLE public.matches DROP CONSTRAINT IF EXISTS check_match_source;
+ALTER TABLE public.matches ADD CONSTRAINT check_match_source CHECK (source IN ('algorithm', 'manual'));
@@ -0,0 +1,22 @@
+-- Each user's interested/pass response to a match. Like the viewed and
+-- contacted flags, user A is the row's user_id and both rows of a pair carry
+-- both responses. Messaging unlocks once both users are interested.
+ALTER TABLE public.matches ADD COLUMN IF NOT EXISTS user_a_response TEXT;
+ALTER TABLE public.matches ADD COLUMN IF NOT EXISTS user_b_response TEXT;
+ALTER TABLE public.matches ADD COLUMN IF NOT EXISTS user_a_pass_reason TEXT;
+ALTER TABLE public.matches ADD COLUMN IF NOT EXISTS user_b_pass_reason TEXT;
+
+ALTER TABLE public.matches DROP CONSTRAINT IF EXISTS check_match_response;
+ALTER TABLE public.matches ADD CONSTRAINT check_match_response CHECK (
+    (user_a_response IS NULL OR user_a_response IN ('interested', 'pass'))
+    AND (user_b_response IS NULL OR user_b_response IN ('interested', 'pass'))
+);
+
+-- Pairs that were already talking keep their conversation
+UPDATE public.matches m
+SET messaging_unlocked = TRUE
+WHERE EXISTS (
+    SELECT 1 FROM public.conversations c
+    WHERE (c.participant1 = m.user_id AND c.participant2 = m.matched_user_id)
+       OR (c.participant1 = m.matched_user_id AND c.participant2 = m.user_id)
+);
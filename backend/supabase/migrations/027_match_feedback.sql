@@ -0,0 +1,21 @@
+-- What users reported about a match after the messaging window. match_id has
+-- no foreign key: republishing replaces the matches, and the feedback is
+-- kept as a training signal for the scoring weights.
+CREATE TABLE IF NOT EXISTS public.match_feedback (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    match_id UUID NOT NULL UNIQUE,
+    user_id UUID NOT NULL REFERENCES auth.users(id) ON DELETE CASCADE,
+    matched_user_id UUID NOT NULL REFERENCES auth.users(id) ON DELETE CASCADE,
+    campaign_id UUID REFERENCES public.campaigns(id) ON DELETE SET NULL,
+    rating INTEGER NOT NULL CHECK (rating >= 1 AND rating <= 5),
+    met BOOLEAN NOT NULL DEFAULT FALSE,
+    comment TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_match_feedback_campaign ON public.match_feedback(campaign_id);
+
+ALTER TABLE public.match_feedback ENABLE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS "Users can view own feedback" ON public.match_feedback;
+CREATE POLICY "Users can view own feedback" ON public.match_feedback FOR SELECT USING (auth.uid() = user_id);
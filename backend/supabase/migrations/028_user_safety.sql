@@ -0,0 +1,37 @@
+-- Blocks between users. A block applies in both directions: the pair is
+-- hidden from each other's matches, can't message, and is never matched again.
+CREATE TABLE IF NOT EXISTS public.user_blocks (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    blocker_id UUID NOT NULL REFERENCES auth.users(id) ON DELETE CASCADE,
+    blocked_id UUID NOT NULL REFERENCES auth.users(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE(blocker_id, blocked_id),
+    CHECK (blocker_id <> blocked_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_blocks_blocked ON public.user_blocks(blocked_id);
+
+-- Reports waiting in the admin moderation queue. evidence holds copies of the
+-- cited messages so moderators see them even after they are deleted.
+CREATE TABLE IF NOT EXISTS public.user_reports (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    reporter_id UUID REFERENCES auth.users(id) ON DELETE SET NULL,
+    reported_user_id UUID NOT NULL REFERENCES auth.users(id) ON DELETE CASCADE,
+    category TEXT NOT NULL CHECK (category IN ('harassment', 'spam', 'inappropriate_content', 'fake_profile', 'underage', 'safety_concern', 'other')),
+    description TEXT,
+    evidence JSONB NOT NULL DEFAULT '[]',
+    status TEXT NOT NULL DEFAULT 'open' CHECK (status IN ('open')),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_reports_status ON public.user_reports(status, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_user_reports_reported ON public.user_reports(reported_user_id);
+
+ALTER TABLE public.user_blocks ENABLE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS "Users can view own blocks" ON public.user_blocks;
+CREATE POLICY "Users can view own blocks" ON public.user_blocks FOR SELECT USING (auth.uid() = blocker_id);
+
+ALTER TABLE public.user_reports ENABLE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS "Users can view own reports" ON public.user_reports;
+CREATE POLICY "Users can view own reports" ON public.user_reports FOR SELECT USING (auth.uid() = reporter_id);
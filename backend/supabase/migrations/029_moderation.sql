@@ -0,0 +1,42 @@
+-- The moderation queue: reports move from open to in_review when an admin
+-- takes them, and close as actioned or dismissed. Automatic flags share the
+-- queue with source = 'auto' and point at the flagged content.
+ALTER TABLE public.user_reports ADD COLUMN IF NOT EXISTS source TEXT NOT NULL DEFAULT 'user';
+ALTER TABLE public.user_reports ADD COLUMN IF NOT EXISTS content_type TEXT;
+ALTER TABLE public.user_reports ADD COLUMN IF NOT EXISTS content_id TEXT;
+ALTER TABLE public.user_reports ADD COLUMN IF NOT EXISTS assigned_to UUID;
+ALTER TABLE public.user_reports ADD COLUMN IF NOT EXISTS assigned_at TIMESTAMPTZ;
+ALTER TABLE public.user_reports ADD COLUMN IF NOT EXISTS resolution TEXT;
+ALTER TABLE public.user_reports ADD COLUMN IF NOT EXISTS resolved_by UUID;
+ALTER TABLE public.user_reports ADD COLUMN IF NOT EXISTS resolved_at TIMESTAMPTZ;
+
+ALTER TABLE public.user_reports DROP CONSTRAINT IF EXISTS user_reports_status_check;
+ALTER TABLE public.user_reports DROP CONSTRAINT IF EXISTS check_report_status;
+ALTER TABLE public.user_reports ADD CONSTRAINT check_report_status CHECK (status IN ('open', 'in_review', 'actioned', 'dismissed'));
+ALTER TABLE public.user_reports DROP CONSTRAINT IF EXISTS check_report_source;
+ALTER TABLE public.user_reports ADD CONSTRAINT check_report_source CHECK (source IN ('user', 'auto'));
+
+CREATE INDEX IF NOT EXISTS idx_user_reports_assigned ON public.user_reports(assigned_to, status);
+
+-- Every action taken against a user. Mutes, suspensions and bans are in
+-- force until expires_at (NULL: indefinitely) or until revoked.
+CREATE TABLE IF NOT EXISTS public.moderation_actions (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES auth.users(id) ON DELETE CASCADE,
+    report_id UUID REFERENCES public.user_reports(id) ON DELETE SET NULL,
+    action TEXT NOT NULL CHECK (action IN ('warn', 'mute', 'suspend', 'ban', 'remove_content')),
+    reason TEXT NOT NULL,
+    content_type TEXT,
+    content_id TEXT,
+    actor_id UUID,
+    expires_at TIMESTAMPTZ,
+    revoked_at TIMESTAMPTZ,
+    revoked_by UUID,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_moderation_actions_user ON public.moderation_actions(user_id, created_at DESC);
+
+ALTER TABLE public.moderation_actions ENABLE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS "Users can view actions against them" ON public.moderation_actions;
+CREATE POLICY "Users can view actions against them" ON public.moderation_actions FOR SELECT USING (auth.uid() = user_id);
@@ -0,0 +1,11 @@
+-- Accounts deleted through DELETE /users/me. Only the ID is kept, with no
+-- foreign key since the auth user is gone: access tokens issued before the
+-- deletion stay valid until they expire, and the API refuses them by
+-- checking this table.
+CREATE TABLE IF NOT EXISTS public.deleted_accounts (
+    user_id UUID PRIMARY KEY,
+    deleted_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Only the backend reads it
+ALTER TABLE public.deleted_accounts ENABLE ROW LEVEL SECURITY;
@@ -0,0 +1,11 @@
+-- Email addresses admins let register although their domain isn't in
+-- ALLOWED_EMAIL_DOMAINS. Emails are stored lowercased.
+CREATE TABLE IF NOT EXISTS public.registration_overrides (
+    email TEXT PRIMARY KEY,
+    reason TEXT,
+    added_by UUID,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Only the backend reads it
+ALTER TABLE public.registration_overrides ENABLE ROW LEVEL SECURITY;
@@ -31,18 +31,9 @@ export default function MatchesPage() {
     }
   }
 
-  const handleGenerateMatches = async () => {
-    try {
-      setLoading(true)
-      const data = await apiClient.generateMatches()
-      setMatches(data || [])
-      setCurrentIndex(0)
-      setLoading(false)
-    } catch (error) {
-      console.error('Failed to generate matches:', error)
-      setLoading(false)
-      alert('Failed to generate matches')
-    }
+  const handleRefresh = async () => {
+    await loadMatches()
+    setCurrentIndex(0)
   }
 
   const handleSwipe = (dir: 'left' | 'right') => {
@@ -82,7 +73,7 @@ export default function MatchesPage() {
       <div className="flex items-center justify-between mb-8">
         <h1 className="pixel-font text-xl text-[#1E3A8A] tracking-tighter">DISCOVERY</h1>
         <button
-          onClick={handleGenerateMatches}
+          onClick={handleRefresh}
           className="p-2 bg-white border-2 border-[#1E3A8A] shadow-[2px_2px_0_#1E3A8A] active:translate-y-[2px] active:shadow-none transition-all"
         >
           <RefreshCcw size={16} className="text-[#1E3A8A]" />
@@ -144,10 +135,10 @@ export default function MatchesPage() {
               <PixelIcon name="crystal_empty" size={64} className="mb-6 opacity-20" />
               <h3 className="pixel-font text-lg text-[#1E3A8A] mb-4">END OF THE LINE</h3>
               <p className="font-[family-name:var(--font-vt323)] text-xl text-gray-500 mb-8">
-                You've seen all your current matches. Refresh to find more wizards!
+                You've seen all your current matches. New ones appear here once they're published!
               </p>
               <button
-                onClick={handleGenerateMatches}
+                onClick={handleRefresh}
                 className="pixel-btn bg-[#FFD700] border-4 border-[#1E3A8A] px-8 py-3 font-bold text-[#1E3A8A] shadow-[4px_4px_0_#1E3A8A]"
               >
                 REFRESH DISCOVERY
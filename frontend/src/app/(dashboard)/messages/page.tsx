@@ -51,22 +51,19 @@ export default function MessagesPage() {
 
     console.log('Connecting to socket at:', apiURL, 'with path:', socketPath)
 
+    // The server identifies the connection from the token; websockets
+    // can't carry an Authorization header, so it goes in the query
     const newSocket = io(apiURL, {
       path: socketPath,
       transports: ['websocket', 'polling'], // Allow polling as fallback
       autoConnect: true,
       reconnection: true,
+      query: { token: apiClient.getToken() || '' },
     })
 
     newSocket.on('connect', () => {
       console.log('Socket.IO connected')
       setIsConnected(true)
-
-      // Identify this connection with the user ID
-      if (currentUserId) {
-        console.log('Identifying as user:', currentUserId)
-        newSocket.emit('identify', currentUserId)
-      }
     })
 
     newSocket.on('disconnect', () => {
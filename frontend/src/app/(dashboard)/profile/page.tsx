@@ -133,28 +133,11 @@ export default function ProfilePage() {
   const handleSave = async () => {
     try {
       setIsSaving(true)
-      let finalAvatarUrl = profile.avatarUrl
-
+      // The upload sets the avatar; the profile update leaves it alone
       if (selectedFile) {
         try {
-          const { createClient } = await import('@/lib/supabase/client')
-          const supabase = createClient()
-          const fileExt = selectedFile.name.split('.').pop()
-          const fileName = `${Math.random()}.${fileExt}`
-          const filePath = `${fileName}`
-
-          const { error: uploadError } = await supabase.storage
-            .from('avatars')
-            .upload(filePath, selectedFile)
-
-          if (uploadError) throw uploadError
-
-          const { data: { publicUrl } } = supabase.storage
-            .from('avatars')
-            .getPublicUrl(filePath)
-
-          finalAvatarUrl = publicUrl
-          setProfile(prev => ({ ...prev, avatarUrl: publicUrl }))
+          const { avatar_url } = await apiClient.uploadAvatar(selectedFile)
+          setProfile(prev => ({ ...prev, avatarUrl: avatar_url }))
         } catch (uploadErr) {
           console.error('Image upload failed:', uploadErr)
           alert('Failed to upload profile picture.')
@@ -171,7 +154,6 @@ export default function ProfilePage() {
         phone: profile.phone,
         year: profile.year,
         major: profile.major,
-        avatar_url: finalAvatarUrl,
         contact_preference: profile.contactPreference,
         visibility: profile.visibility,
         gender: profile.gender || undefined,
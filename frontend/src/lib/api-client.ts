@@ -87,8 +87,9 @@ class APIClient {
     options: RequestInit = {}
   ): Promise<T> {
     const token = this.getToken()
+    // Multipart bodies set their own Content-Type with the boundary
     const headers: Record<string, string> = {
-      'Content-Type': 'application/json',
+      ...(options.body instanceof FormData ? {} : { 'Content-Type': 'application/json' }),
       ...(options.headers as Record<string, string>),
     }
 
@@ -234,6 +235,16 @@ class APIClient {
     return this.put<User>('/api/v1/users/me', data)
   }
 
+  // Uploads an avatar and makes it the profile picture
+  async uploadAvatar(file: File): Promise<{ avatar_url: string }> {
+    const form = new FormData()
+    form.append('avatar', file)
+    return this.request<{ avatar_url: string }>('/api/v1/users/me/avatar', {
+      method: 'POST',
+      body: form,
+    })
+  }
+
   async getUserProfileByID(id: string): Promise<User> {
     return this.get<User>(`/api/v1/users/${id}`)
   }
@@ -258,10 +269,6 @@ class APIClient {
     return this.get<MatchWithDetails[]>('/api/v1/matches')
   }
 
-  async generateMatches(): Promise<MatchWithDetails[]> {
-    return this.post<MatchWithDetails[]>('/api/v1/matches/generate')
-  }
-
   // ===================
   // CRUSH ENDPOINTS
   // ===================